@@ -0,0 +1,157 @@
+// Package backoff provides delay strategies for retrying operations, shared by the
+// reconnect, retry, and rate-limiting features of the other packages.
+package backoff
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Strategy computes the delay to wait before retrying a failed operation.
+type Strategy interface {
+	// Next returns the delay to wait after the given failed attempt, numbered from 1.
+	Next(attempt int) time.Duration
+
+	// Reset clears any state accumulated by the strategy, e.g. after a success.
+	Reset()
+}
+
+var (
+	randMu sync.Mutex
+	random = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// randomFloat returns a pseudo-random number in [0, 1) and is safe for concurrent use.
+func randomFloat() float64 {
+	randMu.Lock()
+	defer randMu.Unlock()
+	return random.Float64()
+}
+
+// Constant is a Strategy returning the same delay after every attempt.
+type Constant struct {
+	delay time.Duration
+}
+
+var _ Strategy = (*Constant)(nil)
+
+// NewConstant creates a Constant strategy waiting delay between attempts.
+func NewConstant(delay time.Duration) *Constant {
+	return &Constant{delay: delay}
+}
+
+// Next returns the constant delay.
+func (c *Constant) Next(int) time.Duration {
+	return c.delay
+}
+
+// Reset is a no-op since Constant is stateless.
+func (c *Constant) Reset() {}
+
+// Exponential is a Strategy doubling the delay after every attempt, starting at a base
+// delay and capped at a maximum. A jitter fraction randomly shortens each delay to spread
+// the retries of concurrent clients.
+type Exponential struct {
+	base   time.Duration
+	max    time.Duration
+	jitter float64
+}
+
+var _ Strategy = (*Exponential)(nil)
+
+// NewExponential creates an Exponential strategy whose delay is base after the first attempt,
+// doubles after each following attempt, and never exceeds max (no cap if max is 0).
+// Each delay is reduced by a random amount of up to jitter times the delay; jitter is clamped
+// to [0, 1], so 0 disables jitter and 1 gives "full jitter".
+//
+//	strategy := backoff.NewExponential(100*time.Millisecond, 10*time.Second, 0.2)
+func NewExponential(base, max time.Duration, jitter float64) *Exponential {
+	if jitter < 0 {
+		jitter = 0
+	} else if jitter > 1 {
+		jitter = 1
+	}
+	return &Exponential{base: base, max: max, jitter: jitter}
+}
+
+// Next returns base * 2^(attempt-1), capped at max and reduced by the jitter. Without a cap,
+// the delay saturates at the largest time.Duration instead of overflowing.
+func (e *Exponential) Next(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	limit := e.max
+	if limit <= 0 {
+		limit = math.MaxInt64
+	}
+	delay := float64(e.base) * math.Pow(2, float64(attempt-1))
+	if delay > float64(limit) {
+		delay = float64(limit)
+	}
+	if e.jitter > 0 {
+		delay -= delay * e.jitter * randomFloat()
+	}
+	// float64(math.MaxInt64) rounds up to 2^63, which does not convert back to an int64.
+	if delay >= float64(math.MaxInt64) {
+		return math.MaxInt64
+	}
+	return time.Duration(delay)
+}
+
+// Reset is a no-op since Exponential is stateless.
+func (e *Exponential) Reset() {}
+
+// Decorrelated is a Strategy implementing "decorrelated jitter": each delay is drawn at
+// random between the base delay and three times the previous delay, capped at a maximum.
+// It spreads retries well while still growing the delay. It is safe for concurrent use,
+// but its state is shared, so each retry loop should use its own instance.
+type Decorrelated struct {
+	base time.Duration
+	max  time.Duration
+
+	mu   sync.Mutex
+	last time.Duration
+}
+
+var _ Strategy = (*Decorrelated)(nil)
+
+// NewDecorrelated creates a Decorrelated strategy with the given base and maximum delays
+// (no cap if max is 0).
+func NewDecorrelated(base, max time.Duration) *Decorrelated {
+	return &Decorrelated{base: base, max: max, last: base}
+}
+
+// Next returns a random delay between base and three times the previous delay, capped at max.
+// Without a cap, the delay saturates at the largest time.Duration instead of overflowing.
+// The attempt number is ignored since the delay depends on the previous one.
+func (d *Decorrelated) Next(int) time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	upper := time.Duration(math.MaxInt64)
+	if d.last <= upper/3 {
+		upper = 3 * d.last
+	}
+	if upper < d.base {
+		upper = d.base
+	}
+	spread := time.Duration(randomFloat() * float64(upper-d.base))
+	if spread < 0 || spread > upper-d.base {
+		// The float64 conversion rounds large spreads past the range.
+		spread = upper - d.base
+	}
+	delay := d.base + spread
+	if d.max > 0 && delay > d.max {
+		delay = d.max
+	}
+	d.last = delay
+	return delay
+}
+
+// Reset makes the next delay start again from the base delay.
+func (d *Decorrelated) Reset() {
+	d.mu.Lock()
+	d.last = d.base
+	d.mu.Unlock()
+}
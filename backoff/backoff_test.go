@@ -0,0 +1,124 @@
+package backoff
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestConstant(t *testing.T) {
+	c := NewConstant(time.Second)
+	for _, attempt := range []int{0, 1, 2, 10} {
+		if got := c.Next(attempt); got != time.Second {
+			t.Errorf("Next(%d) = %v, want 1s", attempt, got)
+		}
+	}
+}
+
+func TestExponential(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    time.Duration
+		max     time.Duration
+		attempt int
+		want    time.Duration
+	}{
+		{"first attempt", 100 * time.Millisecond, 0, 1, 100 * time.Millisecond},
+		{"attempt below one", 100 * time.Millisecond, 0, 0, 100 * time.Millisecond},
+		{"doubling", 100 * time.Millisecond, 0, 4, 800 * time.Millisecond},
+		{"capped", 100 * time.Millisecond, time.Second, 10, time.Second},
+		{"below cap", 100 * time.Millisecond, time.Second, 3, 400 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewExponential(tt.base, tt.max, 0).Next(tt.attempt); got != tt.want {
+				t.Errorf("Next(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExponentialLargeAttempts(t *testing.T) {
+	for _, jitter := range []float64{0, 0.5} {
+		e := NewExponential(100*time.Millisecond, 0, jitter)
+		for _, attempt := range []int{37, 38, 64, 1000, math.MaxInt32} {
+			if got := e.Next(attempt); got < 100*time.Millisecond {
+				t.Errorf("jitter %v: Next(%d) = %v, want a positive delay", jitter, attempt, got)
+			}
+		}
+	}
+	if got := NewExponential(100*time.Millisecond, 0, 0).Next(1000); got != math.MaxInt64 {
+		t.Errorf("Next(1000) = %v, want the largest duration", got)
+	}
+	if got := NewExponential(100*time.Millisecond, time.Minute, 0).Next(1000); got != time.Minute {
+		t.Errorf("capped Next(1000) = %v, want 1m", got)
+	}
+}
+
+func TestExponentialJitter(t *testing.T) {
+	tests := []struct {
+		name    string
+		jitter  float64
+		wantMin time.Duration
+	}{
+		{"partial jitter", 0.25, 600 * time.Millisecond},
+		{"full jitter", 1, 0},
+		{"clamped above one", 5, 0},
+		{"clamped below zero", -1, 800 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewExponential(100*time.Millisecond, 0, tt.jitter)
+			for i := 0; i < 100; i++ {
+				if got := e.Next(4); got < tt.wantMin || got > 800*time.Millisecond {
+					t.Fatalf("Next(4) = %v, want within [%v, 800ms]", got, tt.wantMin)
+				}
+			}
+		})
+	}
+}
+
+func TestDecorrelated(t *testing.T) {
+	base, max := 10*time.Millisecond, 200*time.Millisecond
+	d := NewDecorrelated(base, max)
+	last := base
+	for i := 0; i < 100; i++ {
+		got := d.Next(i + 1)
+		upper := 3 * last
+		if upper > max {
+			upper = max
+		}
+		if got < base || got > upper {
+			t.Fatalf("delay %d = %v, want within [%v, %v]", i, got, base, upper)
+		}
+		last = got
+	}
+
+	d.Reset()
+	if got := d.Next(1); got > 3*base {
+		t.Errorf("delay after Reset = %v, want at most %v", got, 3*base)
+	}
+}
+
+func TestDecorrelatedUncapped(t *testing.T) {
+	d := NewDecorrelated(time.Millisecond, 0)
+	var longest time.Duration
+	for i := 0; i < 200; i++ {
+		if got := d.Next(i + 1); got > longest {
+			longest = got
+		}
+	}
+	if longest <= 3*time.Millisecond {
+		t.Errorf("longest delay = %v, want the delay to grow past 3ms", longest)
+	}
+}
+
+func TestDecorrelatedSaturates(t *testing.T) {
+	base := time.Hour
+	d := NewDecorrelated(base, 0)
+	for i := 0; i < 1000; i++ {
+		if got := d.Next(i + 1); got < base {
+			t.Fatalf("delay %d = %v, want at least %v", i, got, base)
+		}
+	}
+}
@@ -23,4 +23,39 @@ type Bucket interface {
 	// It accepts a context and the name of the object. It returns the object's metadata
 	// and any error encountered during the operation.
 	Stats(ctx context.Context, objectName string) (*Stats, error)
+
+	// ListObjectVersions retrieves the versions of an object, most recent first.
+	// Versioning must be enabled on the bucket (e.g. for MinIO). It returns ErrNotFound if the
+	// object has no versions, and ErrUnsupported if the backend has no versioning.
+	ListObjectVersions(ctx context.Context, objectName string) ([]ObjectVersion, error)
+
+	// GetObjectVersion downloads a specific version of an object. It returns ErrNotFound if the
+	// version does not exist, and ErrUnsupported if the backend has no versioning.
+	GetObjectVersion(ctx context.Context, objectName, versionID string) (io.ReadCloser, error)
+
+	// SetLifecycleRule adds the expiration rule to the storage bucket, replacing any existing
+	// rule with the same ID. It returns ErrUnsupported if the backend has no lifecycle management.
+	SetLifecycleRule(ctx context.Context, rule LifecycleRule) error
+
+	// GetLifecycleRules retrieves the expiration rules configured on the storage bucket.
+	// It returns ErrUnsupported if the backend has no lifecycle management.
+	GetLifecycleRules(ctx context.Context) ([]LifecycleRule, error)
+
+	// StartResumableUpload starts a multipart upload of the named object and returns its session.
+	// The session can be persisted and reloaded to resume the upload after a failure or restart.
+	StartResumableUpload(ctx context.Context, objectName string) (UploadSession, error)
+
+	// UploadPart uploads one part of a resumable upload. Parts are numbered from 1 and may be
+	// uploaded in any order; uploading a part number again replaces the previous data.
+	// If size is non-negative, exactly size bytes must be read from reader.
+	// It returns ErrUploadNotFound if the session does not exist.
+	UploadPart(ctx context.Context, session UploadSession, partNum int, reader io.Reader, size int64) error
+
+	// CompleteResumable assembles the uploaded parts, ordered by part number, into the object
+	// and ends the session. It returns ErrUploadNotFound if the session does not exist.
+	CompleteResumable(ctx context.Context, session UploadSession) error
+
+	// AbortResumable discards the uploaded parts and ends the session.
+	// It returns ErrUploadNotFound if the session does not exist.
+	AbortResumable(ctx context.Context, session UploadSession) error
 }
@@ -0,0 +1,387 @@
+package bucket
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// cacheFileSuffix is the extension of the files of cached objects.
+const cacheFileSuffix = ".obj"
+
+// cachedFile is an object file tracked by a CachingBucket.
+type cachedFile struct {
+	name string
+	size int64
+	// object is the name of the object whose current version the file holds, or "" if it is
+	// not known yet, e.g. for files left by a previous CachingBucket.
+	object string
+}
+
+// CachingOption configures a CachingBucket.
+type CachingOption func(*CachingBucket)
+
+// WithRevalidation makes GetObject call Stats on the remote bucket on every read, so that
+// objects rewritten through other clients are downloaded again instead of being served from
+// a stale cached file.
+func WithRevalidation() CachingOption {
+	return func(c *CachingBucket) {
+		c.revalidate = true
+	}
+}
+
+// CachingBucket is a Bucket decorator that keeps downloaded objects on local disk, so hot
+// objects are served without downloading them again from the remote bucket.
+//
+// Cached files are keyed by object name and version, the ETag reported by Stats, or the size
+// and last modified time for backends without entity tags, as recorded when the file is
+// filled. Objects are assumed immutable: once an object is cached, GetObject serves it from
+// disk without calling the remote bucket, and only objects rewritten through the CachingBucket
+// itself are refreshed. With WithRevalidation, GetObject calls Stats on every read to detect
+// objects rewritten elsewhere, but still never downloads an object whose version is cached.
+// The cache is bounded by the total size of the files and evicts the least recently used ones
+// first; objects larger than the bound are never cached. Failures of the local disk never fail
+// a read or a write: the object is then served from, or written to, the remote bucket only.
+//
+// The other methods are delegated to the remote bucket. CachingBucket is safe for concurrent use.
+type CachingBucket struct {
+	remote     Bucket
+	dir        string
+	maxBytes   int64
+	revalidate bool
+
+	mu    sync.Mutex
+	files map[string]*list.Element
+	order *list.List
+	size  int64
+	// objects maps the names of the objects to their current cache file.
+	objects map[string]string
+}
+
+var _ Bucket = (*CachingBucket)(nil)
+
+// NewCachingBucket creates a CachingBucket caching the objects of remote in cacheDir, using
+// at most maxBytes bytes of disk. cacheDir is created on the first download if it does not
+// exist; files left in it by a previous CachingBucket are reused, oldest first for eviction.
+//
+//	b = bucket.NewCachingBucket(b, "/var/cache/objects", 10<<30)
+func NewCachingBucket(remote Bucket, cacheDir string, maxBytes int64, opts ...CachingOption) *CachingBucket {
+	c := &CachingBucket{
+		remote:   remote,
+		dir:      cacheDir,
+		maxBytes: maxBytes,
+		files:    make(map[string]*list.Element),
+		order:    list.New(),
+		objects:  make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.load()
+	return c
+}
+
+// load indexes the files already present in the cache directory, most recently modified
+// first, evicting files beyond the size bound. Unreadable entries are ignored.
+func (c *CachingBucket) load() {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	type existing struct {
+		file    cachedFile
+		modTime int64
+	}
+	var found []existing
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), cacheFileSuffix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		found = append(found, existing{
+			file:    cachedFile{name: entry.Name(), size: info.Size()},
+			modTime: info.ModTime().UnixNano(),
+		})
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].modTime < found[j].modTime })
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, f := range found {
+		c.add(f.file)
+	}
+}
+
+// PutObject uploads the object to the remote bucket and, once the upload succeeds, stores
+// its data in the cache. If the cache cannot be written, the upload still succeeds and the
+// object is simply not cached.
+func (c *CachingBucket) PutObject(ctx context.Context, objectName string, reader io.Reader, readerLen int64) error {
+	if readerLen > c.maxBytes {
+		return c.remote.PutObject(ctx, objectName, reader, readerLen)
+	}
+	tmp, err := c.createTemp()
+	if err != nil {
+		return c.remote.PutObject(ctx, objectName, reader, readerLen)
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := &cacheWriter{file: tmp}
+	err = c.remote.PutObject(ctx, objectName, io.TeeReader(reader, writer), readerLen)
+	if closeErr := tmp.Close(); writer.err == nil {
+		writer.err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if writer.err != nil || writer.size > c.maxBytes {
+		return nil
+	}
+
+	stats, err := c.remote.Stats(ctx, objectName)
+	if err != nil || stats.Size != writer.size {
+		return nil
+	}
+	c.commit(tmp.Name(), c.fileName(objectName, stats), objectName, writer.size)
+	return nil
+}
+
+// GetObject returns the cached file of the object, downloading it from the remote bucket
+// into the cache first if needed. If the object cannot be cached, e.g. because it is larger
+// than the size bound or the cache file cannot be written, the object is served from the
+// remote bucket instead.
+func (c *CachingBucket) GetObject(ctx context.Context, objectName string) (io.ReadCloser, error) {
+	if !c.revalidate {
+		if file, ok := c.openObject(objectName); ok {
+			return file, nil
+		}
+	}
+	stats, err := c.remote.Stats(ctx, objectName)
+	if err != nil {
+		return nil, err
+	}
+	name := c.fileName(objectName, stats)
+	if file, ok := c.open(name, objectName); ok {
+		return file, nil
+	}
+
+	reader, err := c.remote.GetObject(ctx, objectName)
+	if err != nil {
+		return nil, err
+	}
+	if stats.Size > c.maxBytes {
+		return reader, nil
+	}
+	tmp, err := c.createTemp()
+	if err != nil {
+		return reader, nil
+	}
+	defer os.Remove(tmp.Name())
+
+	// The cacheWriter never fails, so a copy error is a download error.
+	writer := &cacheWriter{file: tmp}
+	_, err = io.Copy(writer, reader)
+	_ = reader.Close()
+	if closeErr := tmp.Close(); writer.err == nil {
+		writer.err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFailedToDownload, err)
+	}
+	if writer.err != nil {
+		return c.remote.GetObject(ctx, objectName)
+	}
+	// The file is opened before it is moved into place, so that a concurrent eviction
+	// cannot remove it before it is read.
+	file, err := os.Open(tmp.Name())
+	if err != nil {
+		return c.remote.GetObject(ctx, objectName)
+	}
+	if writer.size <= c.maxBytes {
+		c.commit(tmp.Name(), name, objectName, writer.size)
+	}
+	// Otherwise the object grew since Stats; serve it once without caching it.
+	return file, nil
+}
+
+// Stats delegates to the remote bucket.
+func (c *CachingBucket) Stats(ctx context.Context, objectName string) (*Stats, error) {
+	return c.remote.Stats(ctx, objectName)
+}
+
+// ListObjectVersions delegates to the remote bucket.
+func (c *CachingBucket) ListObjectVersions(ctx context.Context, objectName string) ([]ObjectVersion, error) {
+	return c.remote.ListObjectVersions(ctx, objectName)
+}
+
+// GetObjectVersion delegates to the remote bucket. Versions are not cached.
+func (c *CachingBucket) GetObjectVersion(ctx context.Context, objectName, versionID string) (io.ReadCloser, error) {
+	return c.remote.GetObjectVersion(ctx, objectName, versionID)
+}
+
+// SetLifecycleRule delegates to the remote bucket.
+func (c *CachingBucket) SetLifecycleRule(ctx context.Context, rule LifecycleRule) error {
+	return c.remote.SetLifecycleRule(ctx, rule)
+}
+
+// GetLifecycleRules delegates to the remote bucket.
+func (c *CachingBucket) GetLifecycleRules(ctx context.Context) ([]LifecycleRule, error) {
+	return c.remote.GetLifecycleRules(ctx)
+}
+
+// StartResumableUpload delegates to the remote bucket.
+func (c *CachingBucket) StartResumableUpload(ctx context.Context, objectName string) (UploadSession, error) {
+	return c.remote.StartResumableUpload(ctx, objectName)
+}
+
+// UploadPart delegates to the remote bucket.
+func (c *CachingBucket) UploadPart(ctx context.Context, session UploadSession, partNum int, reader io.Reader, size int64) error {
+	return c.remote.UploadPart(ctx, session, partNum, reader, size)
+}
+
+// CompleteResumable delegates to the remote bucket. The completed object is cached on its
+// first download.
+func (c *CachingBucket) CompleteResumable(ctx context.Context, session UploadSession) error {
+	return c.remote.CompleteResumable(ctx, session)
+}
+
+// AbortResumable delegates to the remote bucket.
+func (c *CachingBucket) AbortResumable(ctx context.Context, session UploadSession) error {
+	return c.remote.AbortResumable(ctx, session)
+}
+
+// fileName returns the name of the cache file of the version of objectName described by stats.
+func (c *CachingBucket) fileName(objectName string, stats *Stats) string {
+	version := stats.ETag
+	if version == "" {
+		version = strconv.FormatInt(stats.Size, 10) + "-" + strconv.FormatInt(stats.LastModified.UnixNano(), 10)
+	}
+	sum := sha256.Sum256([]byte(objectName + "\x00" + version))
+	return hex.EncodeToString(sum[:]) + cacheFileSuffix
+}
+
+// createTemp creates a temporary file in the cache directory.
+func (c *CachingBucket) createTemp() (*os.File, error) {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return nil, err
+	}
+	return os.CreateTemp(c.dir, ".*.tmp")
+}
+
+// openObject opens the cache file recorded for objectName and marks it as recently used.
+func (c *CachingBucket) openObject(objectName string) (*os.File, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name, ok := c.objects[objectName]
+	if !ok {
+		return nil, false
+	}
+	return c.openLocked(name, objectName)
+}
+
+// open opens the cache file name holding the current version of objectName and marks it
+// as recently used.
+func (c *CachingBucket) open(name, objectName string) (*os.File, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.openLocked(name, objectName)
+}
+
+// openLocked implements open. c.mu must be held.
+func (c *CachingBucket) openLocked(name, objectName string) (*os.File, bool) {
+	elem, ok := c.files[name]
+	if !ok {
+		return nil, false
+	}
+	file, err := os.Open(filepath.Join(c.dir, name))
+	if err != nil {
+		// The file was removed behind our back; forget it.
+		c.remove(elem)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	c.track(elem.Value.(*cachedFile), objectName)
+	return file, true
+}
+
+// commit moves the temporary file tmp into place as the cache file name of the given size,
+// holding the current version of objectName, and evicts the least recently used files to
+// stay within the size bound.
+func (c *CachingBucket) commit(tmp, name, objectName string, size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.Rename(tmp, filepath.Join(c.dir, name)); err != nil {
+		return
+	}
+	if elem, ok := c.files[name]; ok {
+		c.remove(elem)
+	}
+	c.add(cachedFile{name: name, size: size})
+	if elem, ok := c.files[name]; ok {
+		c.track(elem.Value.(*cachedFile), objectName)
+	}
+}
+
+// track records file as the cache file of the current version of objectName, replacing the
+// file of its previous version. c.mu must be held.
+func (c *CachingBucket) track(file *cachedFile, objectName string) {
+	if previous, ok := c.objects[objectName]; ok && previous != file.name {
+		if elem, ok := c.files[previous]; ok {
+			elem.Value.(*cachedFile).object = ""
+		}
+	}
+	file.object = objectName
+	c.objects[objectName] = file.name
+}
+
+// add tracks file as the most recently used file and evicts files beyond the size bound.
+// c.mu must be held.
+func (c *CachingBucket) add(file cachedFile) {
+	c.files[file.name] = c.order.PushFront(&file)
+	c.size += file.size
+	for c.size > c.maxBytes && c.order.Len() > 0 {
+		back := c.order.Back()
+		_ = os.Remove(filepath.Join(c.dir, back.Value.(*cachedFile).name))
+		c.remove(back)
+	}
+}
+
+// remove stops tracking the file of elem. c.mu must be held.
+func (c *CachingBucket) remove(elem *list.Element) {
+	file := elem.Value.(*cachedFile)
+	c.order.Remove(elem)
+	delete(c.files, file.name)
+	c.size -= file.size
+	if file.object != "" && c.objects[file.object] == file.name {
+		delete(c.objects, file.object)
+	}
+}
+
+// cacheWriter writes to a cache file, recording the first error instead of returning it so
+// that a failing cache never fails the upload it is teed from.
+type cacheWriter struct {
+	file *os.File
+	size int64
+	err  error
+}
+
+// Write writes p to the file unless a previous write failed. It always reports success.
+func (w *cacheWriter) Write(p []byte) (int, error) {
+	if w.err == nil {
+		var n int
+		n, w.err = w.file.Write(p)
+		w.size += int64(n)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,196 @@
+package bucket_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeroxsolutions/barbatos/bucket"
+)
+
+// cachedFiles returns the number of cached object files in dir.
+func cachedFiles(t *testing.T, dir string) int {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, "*.obj"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(matches)
+}
+
+func TestCachingBucketGetObject(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxBytes      int64
+		reads         []string
+		wantDownloads int
+		wantFiles     int
+	}{
+		{"cached after the first download", 100, []string{"aaa", "aaa", "aaa"}, 1, 1},
+		{"larger than the bound", 2, []string{"aaa", "aaa"}, 2, 0},
+		{"least recently used evicted", 6, []string{"aaa", "bbb", "aaa", "ccc", "bbb", "ccc"}, 4, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote := newTrackingBucket(t, "aaa", "bbb", "ccc")
+			dir := t.TempDir()
+			c := bucket.NewCachingBucket(remote, dir, tt.maxBytes)
+
+			for _, name := range tt.reads {
+				if got := readObject(t, c, name); got != name {
+					t.Errorf("object %q = %q", name, got)
+				}
+			}
+			if got := len(remote.readers); got != tt.wantDownloads {
+				t.Errorf("downloads = %d, want %d", got, tt.wantDownloads)
+			}
+			if got := cachedFiles(t, dir); got != tt.wantFiles {
+				t.Errorf("cached files = %d, want %d", got, tt.wantFiles)
+			}
+		})
+	}
+}
+
+func TestCachingBucketServesFromDisk(t *testing.T) {
+	remote := newTrackingBucket(t, "doc")
+	c := bucket.NewCachingBucket(remote, t.TempDir(), 100)
+	readObject(t, c, "doc")
+	remote.stats = 0
+
+	if got := readObject(t, c, "doc"); got != "doc" {
+		t.Errorf("object = %q, want %q", got, "doc")
+	}
+	if len(remote.readers) != 1 || remote.stats != 0 {
+		t.Errorf("second read made %d downloads and %d Stats calls, want none", len(remote.readers)-1, remote.stats)
+	}
+}
+
+func TestCachingBucketRewrittenObject(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          []bucket.CachingOption
+		want          string
+		wantDownloads int
+	}{
+		{"assumed immutable", nil, "doc", 1},
+		{"revalidated", []bucket.CachingOption{bucket.WithRevalidation()}, "version 2", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			remote := newTrackingBucket(t, "doc")
+			c := bucket.NewCachingBucket(remote, t.TempDir(), 100, tt.opts...)
+			readObject(t, c, "doc")
+
+			// The object is rewritten through another client.
+			if err := remote.Bucket.PutObject(ctx, "doc", strings.NewReader("version 2"), 9); err != nil {
+				t.Fatal(err)
+			}
+			if got := readObject(t, c, "doc"); got != tt.want {
+				t.Errorf("object = %q, want %q", got, tt.want)
+			}
+			if got := len(remote.readers); got != tt.wantDownloads {
+				t.Errorf("downloads = %d, want %d", got, tt.wantDownloads)
+			}
+		})
+	}
+}
+
+func TestCachingBucketRewrittenThroughCache(t *testing.T) {
+	ctx := context.Background()
+	remote := newTrackingBucket(t, "doc")
+	c := bucket.NewCachingBucket(remote, t.TempDir(), 100)
+	readObject(t, c, "doc")
+
+	if err := c.PutObject(ctx, "doc", strings.NewReader("version 2"), 9); err != nil {
+		t.Fatal(err)
+	}
+	if got := readObject(t, c, "doc"); got != "version 2" {
+		t.Errorf("object = %q, want the rewritten data", got)
+	}
+	if got := len(remote.readers); got != 1 {
+		t.Errorf("downloads = %d, want 1", got)
+	}
+}
+
+func TestCachingBucketPutObject(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxBytes      int64
+		wantDownloads int
+	}{
+		{"cached on upload", 100, 0},
+		{"larger than the bound", 2, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote := newTrackingBucket(t)
+			c := bucket.NewCachingBucket(remote, t.TempDir(), tt.maxBytes)
+			if err := c.PutObject(context.Background(), "doc", strings.NewReader("data"), 4); err != nil {
+				t.Fatal(err)
+			}
+			if got := readObject(t, c, "doc"); got != "data" {
+				t.Errorf("object = %q, want %q", got, "data")
+			}
+			if got := len(remote.readers); got != tt.wantDownloads {
+				t.Errorf("downloads = %d, want %d", got, tt.wantDownloads)
+			}
+		})
+	}
+}
+
+func TestCachingBucketReusesDirectory(t *testing.T) {
+	remote := newTrackingBucket(t, "aaa", "bbb")
+	dir := t.TempDir()
+	first := bucket.NewCachingBucket(remote, dir, 100)
+	readObject(t, first, "aaa")
+	readObject(t, first, "bbb")
+	// A leftover file that is not a cached object is ignored.
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	second := bucket.NewCachingBucket(remote, dir, 100)
+	readObject(t, second, "aaa")
+	readObject(t, second, "bbb")
+	if got := len(remote.readers); got != 2 {
+		t.Errorf("downloads = %d, want 2", got)
+	}
+
+	// Reopening with a smaller bound evicts the oldest files.
+	bucket.NewCachingBucket(remote, dir, 3)
+	if got := cachedFiles(t, dir); got != 1 {
+		t.Errorf("cached files = %d, want 1", got)
+	}
+}
+
+func TestCachingBucketErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		object  string
+		failing map[string]error
+		wantErr error
+	}{
+		{"missing object", "missing", nil, bucket.ErrNotFound},
+		{"download failure", "aaa", map[string]error{"aaa": errBackend}, errBackend},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remote := newTrackingBucket(t, "aaa")
+			for name, err := range tt.failing {
+				remote.failing[name] = err
+			}
+			dir := t.TempDir()
+			c := bucket.NewCachingBucket(remote, dir, 100)
+			if _, err := c.GetObject(context.Background(), tt.object); !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got := cachedFiles(t, dir); got != 0 {
+				t.Errorf("cached files = %d, want 0", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package bucket
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// GetObjectDecoded downloads an object and transparently decompresses it if its Stats report
+// a gzip content encoding, so the returned reader always yields the original bytes.
+// Objects without a content encoding are returned as-is. Closing the returned reader closes
+// both the decompressor and the underlying object reader.
+//
+//	reader, err := bucket.GetObjectDecoded(ctx, b, "assets/app.js")
+func GetObjectDecoded(ctx context.Context, b Bucket, objectName string) (io.ReadCloser, error) {
+	stats, err := b.Stats(ctx, objectName)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := b.GetObject(ctx, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(stats.ContentEncoding)) {
+	case "", "identity":
+		return reader, nil
+	case "gzip", "x-gzip":
+		decoder, err := gzip.NewReader(reader)
+		if err != nil {
+			_ = reader.Close()
+			return nil, fmt.Errorf("%w: %v", ErrFailedToDownload, err)
+		}
+		return &decodedReader{Reader: decoder, decoder: decoder, object: reader}, nil
+	default:
+		_ = reader.Close()
+		return nil, fmt.Errorf("%w: content encoding %q", ErrUnsupported, stats.ContentEncoding)
+	}
+}
+
+// decodedReader reads decompressed data and closes both the decompressor and the object reader.
+type decodedReader struct {
+	io.Reader
+	decoder io.Closer
+	object  io.Closer
+}
+
+// Close closes the decompressor and the object reader, returning the first error.
+func (r *decodedReader) Close() error {
+	err := r.decoder.Close()
+	if closeErr := r.object.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+package bucket_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/zeroxsolutions/barbatos/bucket"
+)
+
+// encodedBucket is a trackingBucket reporting encoding as the content encoding of every object.
+type encodedBucket struct {
+	*trackingBucket
+	encoding string
+}
+
+func (b *encodedBucket) Stats(ctx context.Context, objectName string) (*bucket.Stats, error) {
+	stats, err := b.trackingBucket.Stats(ctx, objectName)
+	if err != nil {
+		return nil, err
+	}
+	stats.ContentEncoding = b.encoding
+	return stats, nil
+}
+
+func gzipped(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGetObjectDecoded(t *testing.T) {
+	const content = "console.log('hello');"
+	tests := []struct {
+		name     string
+		stored   func(t *testing.T) []byte
+		encoding string
+		want     string
+		wantErr  error
+	}{
+		{"no encoding", func(*testing.T) []byte { return []byte(content) }, "", content, nil},
+		{"identity", func(*testing.T) []byte { return []byte(content) }, "identity", content, nil},
+		{"gzip", func(t *testing.T) []byte { return gzipped(t, content) }, "gzip", content, nil},
+		{"x-gzip with spaces and case", func(t *testing.T) []byte { return gzipped(t, content) }, " X-GZIP ", content, nil},
+		{"invalid gzip", func(*testing.T) []byte { return []byte(content) }, "gzip", "", bucket.ErrFailedToDownload},
+		{"unsupported encoding", func(*testing.T) []byte { return []byte(content) }, "br", "", bucket.ErrUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			b := &encodedBucket{trackingBucket: newTrackingBucket(t), encoding: tt.encoding}
+			data := tt.stored(t)
+			if err := b.PutObject(ctx, "app.js", bytes.NewReader(data), int64(len(data))); err != nil {
+				t.Fatal(err)
+			}
+
+			reader, err := bucket.GetObjectDecoded(ctx, b, "app.js")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil {
+				got, err := io.ReadAll(reader)
+				if err != nil {
+					t.Fatalf("read: %v", err)
+				}
+				if string(got) != tt.want {
+					t.Errorf("content = %q, want %q", got, tt.want)
+				}
+				if err := reader.Close(); err != nil {
+					t.Fatalf("Close: %v", err)
+				}
+			}
+			// The object reader is closed, whether decoding succeeded or not.
+			if len(b.readers) != 1 || !b.readers[0].closed {
+				t.Error("object reader not closed")
+			}
+		})
+	}
+}
+
+func TestGetObjectDecodedNotFound(t *testing.T) {
+	b := newTrackingBucket(t)
+	if _, err := bucket.GetObjectDecoded(context.Background(), b, "missing"); !errors.Is(err, bucket.ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
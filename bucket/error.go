@@ -17,3 +17,15 @@ var ErrFailedToDownload = errors.New("bucket: failed to download")
 // ErrFailedToStats represents the error returned when an object stats operation fails.
 // This error is used to indicate that the metadata of the object could not be retrieved from the storage bucket.
 var ErrFailedToStats = errors.New("bucket: failed to get stats")
+
+// ErrInvalidObjectName represents the error returned when an object name is empty or malformed.
+// This error is used to reject names that could escape the bucket, such as names containing ".." segments.
+var ErrInvalidObjectName = errors.New("bucket: invalid object name")
+
+// ErrUnsupported represents the error returned when an operation is not supported by the storage backend.
+// This error is used, for example, by backends without lifecycle management.
+var ErrUnsupported = errors.New("bucket: unsupported operation")
+
+// ErrUploadNotFound represents the error returned when a resumable upload session does not exist.
+// This error is used to indicate that the session was never started, or was already completed or aborted.
+var ErrUploadNotFound = errors.New("bucket: upload not found")
@@ -0,0 +1,197 @@
+// Package fs provides a bucket.Bucket implementation backed by the local filesystem.
+// It is intended for local development and tests where running an object storage
+// server such as MinIO is not practical.
+package fs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/zeroxsolutions/barbatos/bucket"
+)
+
+// sniffLen is the number of bytes read from an object to detect its content type.
+const sniffLen = 512
+
+// Bucket is a bucket.Bucket that stores each object as a file under a root directory.
+// Object names use forward slashes as separators and are mapped to nested directories.
+// Names that are empty, absolute, or contain "." or ".." segments are rejected with
+// bucket.ErrInvalidObjectName, so objects can never be read or written outside the root.
+// The top-level ".uploads" directory is reserved for the parts of resumable uploads.
+type Bucket struct {
+	root string
+}
+
+var _ bucket.Bucket = (*Bucket)(nil)
+
+// NewBucket creates a Bucket that stores objects under root.
+// The root directory is created on the first upload if it does not exist.
+//
+//	b := fs.NewBucket("/tmp/objects")
+//	err := b.PutObject(ctx, "reports/2024.csv", reader, size)
+func NewBucket(root string) *Bucket {
+	return &Bucket{root: root}
+}
+
+// objectPath maps objectName to a file path under the root directory.
+func (b *Bucket) objectPath(objectName string) (string, error) {
+	if objectName == "" || strings.HasPrefix(objectName, "/") || strings.Contains(objectName, "\\") {
+		return "", fmt.Errorf("%w: %q", bucket.ErrInvalidObjectName, objectName)
+	}
+	for i, segment := range strings.Split(objectName, "/") {
+		if segment == "" || segment == "." || segment == ".." || (i == 0 && segment == uploadsDir) {
+			return "", fmt.Errorf("%w: %q", bucket.ErrInvalidObjectName, objectName)
+		}
+	}
+	return filepath.Join(b.root, filepath.FromSlash(path.Clean(objectName))), nil
+}
+
+// PutObject writes the object to a file under the root directory.
+// The data is first written to a temporary file that is renamed into place once
+// complete, so readers never observe a partially written object. If readerLen is
+// non-negative, exactly readerLen bytes must be read from reader.
+//
+// If ctx is cancelled during the transfer, the copy stops, the temporary file is
+// removed, and the returned error matches both bucket.ErrFailedToUpload and ctx.Err().
+func (b *Bucket) PutObject(ctx context.Context, objectName string, reader io.Reader, readerLen int64) error {
+	name, err := b.objectPath(objectName)
+	if err != nil {
+		return err
+	}
+	return writeFile(ctx, name, reader, readerLen)
+}
+
+// writeFile atomically writes the data of reader to the file name, creating its directory.
+// Errors match bucket.ErrFailedToUpload, and also ctx.Err() if ctx was cancelled.
+func writeFile(ctx context.Context, name string, reader io.Reader, readerLen int64) error {
+	if err := cancellationError(ctx, bucket.ErrFailedToUpload, nil); err != nil {
+		return err
+	}
+	reader = &contextReader{ctx: ctx, r: reader}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return fmt.Errorf("%w: %v", bucket.ErrFailedToUpload, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("%w: %v", bucket.ErrFailedToUpload, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if readerLen >= 0 {
+		_, err = io.CopyN(tmp, reader, readerLen)
+	} else {
+		_, err = io.Copy(tmp, reader)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if ctxErr := cancellationError(ctx, bucket.ErrFailedToUpload, err); ctxErr != nil {
+		return ctxErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), name)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", bucket.ErrFailedToUpload, err)
+	}
+	return nil
+}
+
+// GetObject opens the file of the object for reading.
+// It returns bucket.ErrNotFound if the object does not exist. Reads from the returned
+// reader fail with ctx.Err() once ctx is cancelled, aborting the download.
+func (b *Bucket) GetObject(ctx context.Context, objectName string) (io.ReadCloser, error) {
+	name, err := b.objectPath(objectName)
+	if err != nil {
+		return nil, err
+	}
+	if err := cancellationError(ctx, bucket.ErrFailedToDownload, nil); err != nil {
+		return nil, err
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, translateError(err, bucket.ErrFailedToDownload)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, translateError(err, bucket.ErrFailedToDownload)
+	}
+	if info.IsDir() {
+		_ = file.Close()
+		return nil, bucket.ErrNotFound
+	}
+	return &contextReadCloser{contextReader: contextReader{ctx: ctx, r: file}, closer: file}, nil
+}
+
+// Stats returns the metadata of the object from its file.
+// The size and last modified time come from the filesystem, and the content type is
+// detected from the first bytes of the file. It returns bucket.ErrNotFound if the object
+// does not exist.
+func (b *Bucket) Stats(ctx context.Context, objectName string) (*bucket.Stats, error) {
+	name, err := b.objectPath(objectName)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, translateError(err, bucket.ErrFailedToStats)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, translateError(err, bucket.ErrFailedToStats)
+	}
+	if info.IsDir() {
+		return nil, bucket.ErrNotFound
+	}
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("%w: %v", bucket.ErrFailedToStats, err)
+	}
+
+	return &bucket.Stats{
+		Size:         info.Size(),
+		ContentType:  http.DetectContentType(head[:n]),
+		LastModified: info.ModTime(),
+	}, nil
+}
+
+// ListObjectVersions is not supported by the filesystem backend and always returns bucket.ErrUnsupported.
+func (b *Bucket) ListObjectVersions(ctx context.Context, objectName string) ([]bucket.ObjectVersion, error) {
+	return nil, bucket.ErrUnsupported
+}
+
+// GetObjectVersion is not supported by the filesystem backend and always returns bucket.ErrUnsupported.
+func (b *Bucket) GetObjectVersion(ctx context.Context, objectName, versionID string) (io.ReadCloser, error) {
+	return nil, bucket.ErrUnsupported
+}
+
+// SetLifecycleRule is not supported by the filesystem backend and always returns bucket.ErrUnsupported.
+func (b *Bucket) SetLifecycleRule(ctx context.Context, rule bucket.LifecycleRule) error {
+	return bucket.ErrUnsupported
+}
+
+// GetLifecycleRules is not supported by the filesystem backend and always returns bucket.ErrUnsupported.
+func (b *Bucket) GetLifecycleRules(ctx context.Context) ([]bucket.LifecycleRule, error) {
+	return nil, bucket.ErrUnsupported
+}
+
+// translateError maps a filesystem error to bucket.ErrNotFound or wraps it with sentinel.
+func translateError(err error, sentinel error) error {
+	if errors.Is(err, os.ErrNotExist) {
+		return bucket.ErrNotFound
+	}
+	return fmt.Errorf("%w: %v", sentinel, err)
+}
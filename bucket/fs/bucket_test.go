@@ -0,0 +1,259 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/bucket"
+)
+
+// readObject returns the content of the object, failing the test on error.
+func readObject(t *testing.T, b *Bucket, objectName string) string {
+	t.Helper()
+	reader, err := b.GetObject(context.Background(), objectName)
+	if err != nil {
+		t.Fatalf("GetObject(%q): %v", objectName, err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("read %q: %v", objectName, err)
+	}
+	return string(data)
+}
+
+func TestBucketPutGet(t *testing.T) {
+	tests := []struct {
+		name      string
+		object    string
+		data      string
+		readerLen int64
+	}{
+		{"known length", "report.csv", "a,b\n1,2\n", 8},
+		{"unknown length", "notes.txt", "hello", -1},
+		{"nested name", "reports/2024/q1.txt", "q1", 2},
+		{"empty object", "empty", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			b := NewBucket(root)
+			if err := b.PutObject(context.Background(), tt.object, strings.NewReader(tt.data), tt.readerLen); err != nil {
+				t.Fatalf("PutObject: %v", err)
+			}
+			if got := readObject(t, b, tt.object); got != tt.data {
+				t.Errorf("object = %q, want %q", got, tt.data)
+			}
+			if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(tt.object))); err != nil {
+				t.Errorf("object file: %v", err)
+			}
+		})
+	}
+}
+
+func TestBucketOverwrite(t *testing.T) {
+	ctx := context.Background()
+	b := NewBucket(t.TempDir())
+	for _, data := range []string{"first version", "second"} {
+		if err := b.PutObject(ctx, "a.txt", strings.NewReader(data), -1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := readObject(t, b, "a.txt"); got != "second" {
+		t.Errorf("object = %q, want second", got)
+	}
+}
+
+func TestBucketStats(t *testing.T) {
+	ctx := context.Background()
+	b := NewBucket(t.TempDir())
+	objects := map[string]string{
+		"page.html": "<html><body>hi</body></html>",
+		"notes.txt": "plain text",
+	}
+	for name, data := range objects {
+		if err := b.PutObject(ctx, name, strings.NewReader(data), -1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		object   string
+		wantSize int64
+		wantType string
+	}{
+		{"page.html", int64(len(objects["page.html"])), "text/html; charset=utf-8"},
+		{"notes.txt", int64(len(objects["notes.txt"])), "text/plain; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.object, func(t *testing.T) {
+			stats, err := b.Stats(ctx, tt.object)
+			if err != nil {
+				t.Fatalf("Stats: %v", err)
+			}
+			if stats.Size != tt.wantSize || stats.ContentType != tt.wantType || stats.LastModified.IsZero() {
+				t.Errorf("Stats = %+v, want size %d and type %q", stats, tt.wantSize, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestBucketNotFound(t *testing.T) {
+	ctx := context.Background()
+	b := NewBucket(t.TempDir())
+	if err := b.PutObject(ctx, "dir/a.txt", strings.NewReader("a"), 1); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"missing", "dir"} {
+		if _, err := b.GetObject(ctx, name); !errors.Is(err, bucket.ErrNotFound) {
+			t.Errorf("GetObject(%q): err = %v, want ErrNotFound", name, err)
+		}
+		if _, err := b.Stats(ctx, name); !errors.Is(err, bucket.ErrNotFound) {
+			t.Errorf("Stats(%q): err = %v, want ErrNotFound", name, err)
+		}
+	}
+}
+
+func TestBucketInvalidObjectName(t *testing.T) {
+	ctx := context.Background()
+	b := NewBucket(t.TempDir())
+	for _, name := range []string{"", "/etc/passwd", "../x", "a/../../x", "a//b", "a/./b", `a\b`, uploadsDir + "/x"} {
+		t.Run(name, func(t *testing.T) {
+			if err := b.PutObject(ctx, name, strings.NewReader("x"), 1); !errors.Is(err, bucket.ErrInvalidObjectName) {
+				t.Errorf("PutObject: err = %v, want ErrInvalidObjectName", err)
+			}
+			if _, err := b.GetObject(ctx, name); !errors.Is(err, bucket.ErrInvalidObjectName) {
+				t.Errorf("GetObject: err = %v, want ErrInvalidObjectName", err)
+			}
+			if _, err := b.Stats(ctx, name); !errors.Is(err, bucket.ErrInvalidObjectName) {
+				t.Errorf("Stats: err = %v, want ErrInvalidObjectName", err)
+			}
+		})
+	}
+}
+
+func TestBucketShortReader(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	b := NewBucket(root)
+	if err := b.PutObject(ctx, "short", strings.NewReader("ab"), 5); !errors.Is(err, bucket.ErrFailedToUpload) {
+		t.Fatalf("err = %v, want ErrFailedToUpload", err)
+	}
+	if _, err := b.Stats(ctx, "short"); !errors.Is(err, bucket.ErrNotFound) {
+		t.Errorf("Stats: err = %v, want ErrNotFound", err)
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("files left behind: %v", entries)
+	}
+}
+
+func TestBucketLifecycleUnsupported(t *testing.T) {
+	ctx := context.Background()
+	b := NewBucket(t.TempDir())
+	if err := b.SetLifecycleRule(ctx, bucket.LifecycleRule{ID: "tmp", Prefix: "tmp/", ExpireAfterDays: 1}); !errors.Is(err, bucket.ErrUnsupported) {
+		t.Errorf("SetLifecycleRule: err = %v, want ErrUnsupported", err)
+	}
+	if _, err := b.GetLifecycleRules(ctx); !errors.Is(err, bucket.ErrUnsupported) {
+		t.Errorf("GetLifecycleRules: err = %v, want ErrUnsupported", err)
+	}
+}
+
+// endlessReader is a reader that never reaches EOF, yielding a few bytes at a time.
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	time.Sleep(time.Millisecond)
+	n := copy(p, "data")
+	return n, nil
+}
+
+func TestBucketPutObjectCancelled(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     func() (context.Context, context.CancelFunc)
+		wantErr error
+	}{
+		{
+			name: "already cancelled",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			wantErr: context.Canceled,
+		},
+		{
+			name: "deadline during upload",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 20*time.Millisecond)
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			b := NewBucket(root)
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			err := b.PutObject(ctx, "big", endlessReader{}, -1)
+			if !errors.Is(err, bucket.ErrFailedToUpload) || !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want ErrFailedToUpload and %v", err, tt.wantErr)
+			}
+			entries, err := os.ReadDir(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 0 {
+				t.Errorf("files left behind: %v", entries)
+			}
+		})
+	}
+}
+
+func TestBucketGetObjectCancelled(t *testing.T) {
+	b := NewBucket(t.TempDir())
+	data := strings.Repeat("x", 1<<20)
+	if err := b.PutObject(context.Background(), "big", strings.NewReader(data), int64(len(data))); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reader, err := b.GetObject(ctx, "big")
+	if err != nil {
+		t.Fatalf("GetObject: %v", err)
+	}
+	defer reader.Close()
+	if _, err := reader.Read(make([]byte, 16)); err != nil {
+		t.Fatalf("Read before cancellation: %v", err)
+	}
+	cancel()
+	if _, err := io.ReadAll(reader); !errors.Is(err, context.Canceled) {
+		t.Errorf("Read after cancellation: err = %v, want context.Canceled", err)
+	}
+}
+
+func TestBucketVersionsUnsupported(t *testing.T) {
+	ctx := context.Background()
+	b := NewBucket(t.TempDir())
+	if err := b.PutObject(ctx, "a.txt", strings.NewReader("a"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.ListObjectVersions(ctx, "a.txt"); !errors.Is(err, bucket.ErrUnsupported) {
+		t.Errorf("ListObjectVersions: err = %v, want ErrUnsupported", err)
+	}
+	if _, err := b.GetObjectVersion(ctx, "a.txt", "v1"); !errors.Is(err, bucket.ErrUnsupported) {
+		t.Errorf("GetObjectVersion: err = %v, want ErrUnsupported", err)
+	}
+}
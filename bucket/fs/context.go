@@ -0,0 +1,65 @@
+package fs
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// contextError reports a transfer aborted by context cancellation. It matches both the
+// bucket sentinel of the operation and the context error, so callers can use either
+// errors.Is(err, bucket.ErrFailedToUpload) or errors.Is(err, context.Canceled).
+type contextError struct {
+	sentinel error
+	err      error
+}
+
+// Error returns the sentinel message followed by the context error.
+func (e *contextError) Error() string {
+	return e.sentinel.Error() + ": " + e.err.Error()
+}
+
+// Is reports whether target is the sentinel of the operation.
+func (e *contextError) Is(target error) bool {
+	return target == e.sentinel
+}
+
+// Unwrap returns the context error.
+func (e *contextError) Unwrap() error {
+	return e.err
+}
+
+// cancellationError returns a *contextError wrapping the error of ctx with sentinel if ctx
+// is done and err is nil or was caused by the cancellation. Otherwise it returns nil.
+func cancellationError(ctx context.Context, sentinel, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil && (err == nil || errors.Is(err, ctxErr)) {
+		return &contextError{sentinel: sentinel, err: ctxErr}
+	}
+	return nil
+}
+
+// contextReader is an io.Reader that stops with the context error once ctx is done,
+// so long copies abort promptly when the request is cancelled.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// Read reads from the underlying reader unless the context is done.
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// contextReadCloser is a contextReader that also closes the underlying reader.
+type contextReadCloser struct {
+	contextReader
+	closer io.Closer
+}
+
+// Close closes the underlying reader.
+func (r *contextReadCloser) Close() error {
+	return r.closer.Close()
+}
@@ -0,0 +1,151 @@
+package fs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zeroxsolutions/barbatos/bucket"
+)
+
+// uploadsDir is the directory under the root holding the parts of resumable uploads,
+// one subdirectory per session.
+const uploadsDir = ".uploads"
+
+// partSuffix is the file extension of uploaded parts.
+const partSuffix = ".part"
+
+// StartResumableUpload creates a directory for the parts of the upload and returns its session.
+// The parts are kept on disk, so the session can be resumed by another Bucket using the same root.
+func (b *Bucket) StartResumableUpload(ctx context.Context, objectName string) (bucket.UploadSession, error) {
+	if _, err := b.objectPath(objectName); err != nil {
+		return bucket.UploadSession{}, err
+	}
+	if err := cancellationError(ctx, bucket.ErrFailedToUpload, nil); err != nil {
+		return bucket.UploadSession{}, err
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return bucket.UploadSession{}, fmt.Errorf("%w: %v", bucket.ErrFailedToUpload, err)
+	}
+	session := bucket.UploadSession{ID: hex.EncodeToString(id), ObjectName: objectName}
+	if err := os.MkdirAll(b.uploadPath(session.ID), 0o755); err != nil {
+		return bucket.UploadSession{}, fmt.Errorf("%w: %v", bucket.ErrFailedToUpload, err)
+	}
+	return session, nil
+}
+
+// UploadPart writes the part to a file in the directory of the session.
+func (b *Bucket) UploadPart(ctx context.Context, session bucket.UploadSession, partNum int, reader io.Reader, size int64) error {
+	if partNum < 1 {
+		return fmt.Errorf("%w: invalid part number %d", bucket.ErrFailedToUpload, partNum)
+	}
+	dir, err := b.sessionPath(session)
+	if err != nil {
+		return err
+	}
+	return writeFile(ctx, filepath.Join(dir, strconv.Itoa(partNum)+partSuffix), reader, size)
+}
+
+// CompleteResumable concatenates the parts in part number order into the object and
+// removes the directory of the session.
+func (b *Bucket) CompleteResumable(ctx context.Context, session bucket.UploadSession) error {
+	dir, err := b.sessionPath(session)
+	if err != nil {
+		return err
+	}
+	name, err := b.objectPath(session.ObjectName)
+	if err != nil {
+		return err
+	}
+	parts, err := listParts(dir)
+	if err != nil {
+		return err
+	}
+	if len(parts) == 0 {
+		return fmt.Errorf("%w: upload %s has no parts", bucket.ErrFailedToUpload, session.ID)
+	}
+
+	readers := make([]io.Reader, 0, len(parts))
+	for _, part := range parts {
+		file, err := os.Open(part)
+		if err != nil {
+			return fmt.Errorf("%w: %v", bucket.ErrFailedToUpload, err)
+		}
+		defer file.Close()
+		readers = append(readers, file)
+	}
+	if err := writeFile(ctx, name, io.MultiReader(readers...), -1); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("%w: %v", bucket.ErrFailedToUpload, err)
+	}
+	return nil
+}
+
+// AbortResumable removes the directory of the session and its parts.
+func (b *Bucket) AbortResumable(ctx context.Context, session bucket.UploadSession) error {
+	dir, err := b.sessionPath(session)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("%w: %v", bucket.ErrFailedToUpload, err)
+	}
+	return nil
+}
+
+// uploadPath returns the directory holding the parts of the upload id.
+func (b *Bucket) uploadPath(id string) string {
+	return filepath.Join(b.root, uploadsDir, id)
+}
+
+// sessionPath returns the directory of an existing session. Sessions may be reloaded from
+// untrusted storage, so the ID is validated before being used as a path.
+func (b *Bucket) sessionPath(session bucket.UploadSession) (string, error) {
+	if _, err := hex.DecodeString(session.ID); err != nil || session.ID == "" {
+		return "", bucket.ErrUploadNotFound
+	}
+	dir := b.uploadPath(session.ID)
+	info, err := os.Stat(dir)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && !info.IsDir()) {
+		return "", bucket.ErrUploadNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", bucket.ErrFailedToUpload, err)
+	}
+	return dir, nil
+}
+
+// listParts returns the part files in dir ordered by part number.
+func listParts(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", bucket.ErrFailedToUpload, err)
+	}
+	numbers := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		number, err := strconv.Atoi(strings.TrimSuffix(entry.Name(), partSuffix))
+		if err != nil || entry.IsDir() || !strings.HasSuffix(entry.Name(), partSuffix) {
+			continue
+		}
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	parts := make([]string, len(numbers))
+	for i, number := range numbers {
+		parts[i] = filepath.Join(dir, strconv.Itoa(number)+partSuffix)
+	}
+	return parts, nil
+}
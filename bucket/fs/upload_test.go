@@ -0,0 +1,165 @@
+package fs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zeroxsolutions/barbatos/bucket"
+)
+
+func TestResumableUpload(t *testing.T) {
+	type part struct {
+		num  int
+		data string
+	}
+	tests := []struct {
+		name  string
+		parts []part
+		want  string
+	}{
+		{"in order", []part{{1, "hello "}, {2, "world"}}, "hello world"},
+		{"out of order", []part{{2, "world"}, {1, "hello "}}, "hello world"},
+		{"numeric order", []part{{10, "!"}, {2, "world"}, {1, "hello "}}, "hello world!"},
+		{"reuploaded part", []part{{1, "bye "}, {2, "world"}, {1, "hello "}}, "hello world"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			root := t.TempDir()
+			b := NewBucket(root)
+			session, err := b.StartResumableUpload(ctx, "big/file.bin")
+			if err != nil {
+				t.Fatalf("StartResumableUpload: %v", err)
+			}
+			for _, p := range tt.parts {
+				if err := b.UploadPart(ctx, session, p.num, strings.NewReader(p.data), int64(len(p.data))); err != nil {
+					t.Fatalf("UploadPart(%d): %v", p.num, err)
+				}
+			}
+			if err := b.CompleteResumable(ctx, session); err != nil {
+				t.Fatalf("CompleteResumable: %v", err)
+			}
+			if got := readObject(t, b, "big/file.bin"); got != tt.want {
+				t.Errorf("object = %q, want %q", got, tt.want)
+			}
+			if _, err := os.Stat(filepath.Join(root, uploadsDir, session.ID)); !os.IsNotExist(err) {
+				t.Errorf("session directory left behind: %v", err)
+			}
+		})
+	}
+}
+
+func TestResumableUploadAcrossBuckets(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	first := NewBucket(root)
+	session, err := first.StartResumableUpload(ctx, "file.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := first.UploadPart(ctx, session, 1, strings.NewReader("hello "), -1); err != nil {
+		t.Fatal(err)
+	}
+
+	// The session is serialized and resumed by another bucket on the same root.
+	data, err := json.Marshal(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resumed bucket.UploadSession
+	if err := json.Unmarshal(data, &resumed); err != nil {
+		t.Fatal(err)
+	}
+	second := NewBucket(root)
+	if err := second.UploadPart(ctx, resumed, 2, strings.NewReader("world"), -1); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.CompleteResumable(ctx, resumed); err != nil {
+		t.Fatal(err)
+	}
+	if got := readObject(t, second, "file.bin"); got != "hello world" {
+		t.Errorf("object = %q, want hello world", got)
+	}
+}
+
+func TestResumableUploadErrors(t *testing.T) {
+	ctx := context.Background()
+	b := NewBucket(t.TempDir())
+	session, err := b.StartResumableUpload(ctx, "file.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	completed, err := b.StartResumableUpload(ctx, "done.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.UploadPart(ctx, completed, 1, strings.NewReader("x"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.CompleteResumable(ctx, completed); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		run     func() error
+		wantErr error
+	}{
+		{"start with invalid name", func() error {
+			_, err := b.StartResumableUpload(ctx, "../x")
+			return err
+		}, bucket.ErrInvalidObjectName},
+		{"invalid part number", func() error {
+			return b.UploadPart(ctx, session, 0, strings.NewReader("x"), 1)
+		}, bucket.ErrFailedToUpload},
+		{"complete without parts", func() error { return b.CompleteResumable(ctx, session) }, bucket.ErrFailedToUpload},
+		{"complete twice", func() error { return b.CompleteResumable(ctx, completed) }, bucket.ErrUploadNotFound},
+		{"part of completed upload", func() error {
+			return b.UploadPart(ctx, completed, 2, strings.NewReader("x"), 1)
+		}, bucket.ErrUploadNotFound},
+		{"path traversal in ID", func() error {
+			return b.AbortResumable(ctx, bucket.UploadSession{ID: "../x", ObjectName: "x"})
+		}, bucket.ErrUploadNotFound},
+		{"empty ID", func() error { return b.AbortResumable(ctx, bucket.UploadSession{ObjectName: "x"}) }, bucket.ErrUploadNotFound},
+		{"unknown ID", func() error {
+			return b.CompleteResumable(ctx, bucket.UploadSession{ID: "abcdef", ObjectName: "x"})
+		}, bucket.ErrUploadNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAbortResumable(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	b := NewBucket(root)
+	session, err := b.StartResumableUpload(ctx, "file.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.UploadPart(ctx, session, 1, strings.NewReader("x"), 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.AbortResumable(ctx, session); err != nil {
+		t.Fatalf("AbortResumable: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, uploadsDir, session.ID)); !os.IsNotExist(err) {
+		t.Errorf("session directory left behind: %v", err)
+	}
+	if err := b.CompleteResumable(ctx, session); !errors.Is(err, bucket.ErrUploadNotFound) {
+		t.Errorf("CompleteResumable after abort: err = %v, want ErrUploadNotFound", err)
+	}
+	if _, err := b.Stats(ctx, "file.bin"); !errors.Is(err, bucket.ErrNotFound) {
+		t.Errorf("Stats: err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,69 @@
+package bucket_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zeroxsolutions/barbatos/bucket"
+)
+
+// memBucket is a map-backed bucket.Bucket used by the tests of the package. The methods
+// it does not implement panic through the nil embedded Bucket.
+type memBucket struct {
+	bucket.Bucket
+
+	mu      sync.Mutex
+	objects map[string]string
+}
+
+func newMemBucket() *memBucket {
+	return &memBucket{objects: make(map[string]string)}
+}
+
+func (b *memBucket) PutObject(ctx context.Context, objectName string, reader io.Reader, readerLen int64) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.objects[objectName] = string(data)
+	return nil
+}
+
+func (b *memBucket) GetObject(ctx context.Context, objectName string) (io.ReadCloser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	data, ok := b.objects[objectName]
+	if !ok {
+		return nil, bucket.ErrNotFound
+	}
+	return io.NopCloser(strings.NewReader(data)), nil
+}
+
+func (b *memBucket) Stats(ctx context.Context, objectName string) (*bucket.Stats, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	data, ok := b.objects[objectName]
+	if !ok {
+		return nil, bucket.ErrNotFound
+	}
+	return &bucket.Stats{Size: int64(len(data))}, nil
+}
+
+func readObject(t *testing.T, b bucket.Bucket, objectName string) string {
+	t.Helper()
+	reader, err := b.GetObject(context.Background(), objectName)
+	if err != nil {
+		t.Fatalf("GetObject(%q): %v", objectName, err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,13 @@
+package bucket
+
+// LifecycleRule represents an expiration rule of the storage bucket.
+// Objects whose names start with Prefix are deleted automatically once they are
+// older than ExpireAfterDays days.
+type LifecycleRule struct {
+	// ID uniquely identifies the rule within the bucket. Setting a rule with an existing ID replaces it.
+	ID string `json:"id" yaml:"id"`
+	// Prefix restricts the rule to objects whose names start with it. An empty prefix matches every object.
+	Prefix string `json:"prefix" yaml:"prefix"`
+	// ExpireAfterDays is the age in days after which matching objects are deleted.
+	ExpireAfterDays int `json:"expireAfterDays" yaml:"expireAfterDays"`
+}
@@ -0,0 +1,384 @@
+package bucket
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/backoff"
+)
+
+// MirrorMode defines when a MirroredBucket replicates writes to its secondary bucket.
+type MirrorMode int
+
+const (
+	// MirrorSync replicates each write before returning; the write fails if either bucket fails.
+	MirrorSync MirrorMode = iota
+	// MirrorAsync returns once the primary write succeeds and replicates it in the background,
+	// retrying failures.
+	MirrorAsync
+)
+
+const (
+	// DefaultMirrorAttempts is the default number of attempts made by a MirroredBucket in
+	// MirrorAsync mode to replicate a write before reporting it as failed.
+	DefaultMirrorAttempts = 5
+	// DefaultMirrorAttemptTimeout is the default bound of each replication attempt of a
+	// MirroredBucket in MirrorAsync mode.
+	DefaultMirrorAttemptTimeout = 5 * time.Minute
+)
+
+// defaultMirrorBackoff returns the default delay strategy between the attempts of a
+// MirroredBucket in MirrorAsync mode.
+func defaultMirrorBackoff() backoff.Strategy {
+	return backoff.NewExponential(100*time.Millisecond, 10*time.Second, 0.2)
+}
+
+// MirrorOption configures a MirroredBucket.
+type MirrorOption func(*MirroredBucket)
+
+// WithMirrorAttempts sets the number of attempts made in MirrorAsync mode to replicate a
+// write before reporting it as failed. It defaults to DefaultMirrorAttempts. A value below 1
+// is treated as 1, so that every write is attempted.
+func WithMirrorAttempts(n int) MirrorOption {
+	return func(m *MirroredBucket) {
+		if n < 1 {
+			n = 1
+		}
+		m.attempts = n
+	}
+}
+
+// WithMirrorBackoff sets the function returning the delay strategy between the attempts of
+// a replication in MirrorAsync mode. It defaults to an exponential backoff from 100ms to 10s.
+func WithMirrorBackoff(strategy func() backoff.Strategy) MirrorOption {
+	return func(m *MirroredBucket) {
+		m.backoff = strategy
+	}
+}
+
+// WithMirrorAttemptTimeout sets the bound of each replication attempt in MirrorAsync mode,
+// so that a hung secondary bucket cannot block a replication forever. It defaults to
+// DefaultMirrorAttemptTimeout.
+func WithMirrorAttemptTimeout(d time.Duration) MirrorOption {
+	return func(m *MirroredBucket) {
+		m.attemptTimeout = d
+	}
+}
+
+// MirroredBucket is a Bucket decorator that replicates every write of a primary bucket to a
+// secondary bucket, e.g. in another region for disaster recovery, and fails reads over to
+// the secondary bucket when the primary one fails.
+//
+// Object data is spooled to a temporary file while it is uploaded to the primary bucket, so
+// it can be uploaded again to the secondary bucket without reading the source twice.
+// Resumable uploads run on the primary bucket only; the completed object is then copied to the
+// secondary bucket. Lifecycle rules are set on both buckets and read from the primary one.
+//
+// In MirrorAsync mode, the replications of an object run one at a time in write order, so an
+// older version never overwrites a newer one in the secondary bucket. Writes made while a
+// replication of the same object is running are coalesced: only the latest one is replicated
+// next, since it would overwrite the others anyway. Each attempt is bounded by a timeout, and
+// Close cancels the pending replications.
+type MirroredBucket struct {
+	primary        Bucket
+	secondary      Bucket
+	mode           MirrorMode
+	attempts       int
+	backoff        func() backoff.Strategy
+	attemptTimeout time.Duration
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	onError func(objectName string, err error)
+	// running holds the objects being replicated, with the latest write waiting behind the
+	// running replication, or nil.
+	running map[string]*replication
+}
+
+// replication is a pending write of MirrorAsync mode.
+type replication struct {
+	write   func(context.Context) error
+	release func()
+}
+
+var _ Bucket = (*MirroredBucket)(nil)
+
+// NewMirroredBucket creates a MirroredBucket writing to primary and secondary according to mode.
+// In MirrorAsync mode, Wait must be called before exiting to let pending replications finish,
+// or Close to cancel them.
+//
+//	b := bucket.NewMirroredBucket(euBucket, usBucket, bucket.MirrorAsync)
+//	b.OnError(func(name string, err error) { logger.Errorw("mirror failed", "object", name, "error", err) })
+//	defer b.Wait()
+func NewMirroredBucket(primary, secondary Bucket, mode MirrorMode, opts ...MirrorOption) *MirroredBucket {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &MirroredBucket{
+		primary:        primary,
+		secondary:      secondary,
+		mode:           mode,
+		attempts:       DefaultMirrorAttempts,
+		backoff:        defaultMirrorBackoff,
+		attemptTimeout: DefaultMirrorAttemptTimeout,
+		ctx:            ctx,
+		cancel:         cancel,
+		running:        make(map[string]*replication),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// OnError registers a callback invoked when a background replication of MirrorAsync mode
+// fails after all its attempts, or is canceled by Close.
+func (m *MirroredBucket) OnError(fn func(objectName string, err error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onError = fn
+}
+
+// Wait blocks until every pending background replication has finished.
+func (m *MirroredBucket) Wait() {
+	m.wg.Wait()
+}
+
+// Close cancels the pending background replications, reporting them to the OnError
+// callback, and waits for them to stop. Writes replicated in MirrorAsync mode after Close
+// fail the same way. It closes neither the primary nor the secondary bucket.
+func (m *MirroredBucket) Close() error {
+	m.cancel()
+	m.wg.Wait()
+	return nil
+}
+
+// PutObject uploads the object to the primary bucket, then replicates it to the secondary
+// bucket according to the mirror mode. In MirrorSync mode, a failure of the secondary upload
+// returns an error wrapping ErrFailedToUpload even though the primary upload succeeded.
+func (m *MirroredBucket) PutObject(ctx context.Context, objectName string, reader io.Reader, readerLen int64) error {
+	spool, err := os.CreateTemp("", "bucket-mirror-*")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrFailedToUpload, err)
+	}
+	release := func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}
+
+	if err := m.primary.PutObject(ctx, objectName, io.TeeReader(reader, spool), readerLen); err != nil {
+		release()
+		return err
+	}
+	size, err := spool.Seek(0, io.SeekCurrent)
+	if err == nil {
+		_, err = spool.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		release()
+		return fmt.Errorf("%w: mirror: %v", ErrFailedToUpload, err)
+	}
+
+	put := func(ctx context.Context) error {
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		return m.secondary.PutObject(ctx, objectName, spool, size)
+	}
+	return m.mirror(ctx, objectName, put, release)
+}
+
+// GetObject downloads the object from the primary bucket, or from the secondary bucket if
+// the primary one fails. If both fail, the error of the primary bucket is returned.
+func (m *MirroredBucket) GetObject(ctx context.Context, objectName string) (io.ReadCloser, error) {
+	reader, err := m.primary.GetObject(ctx, objectName)
+	if err == nil {
+		return reader, nil
+	}
+	if reader, secondaryErr := m.secondary.GetObject(ctx, objectName); secondaryErr == nil {
+		return reader, nil
+	}
+	return nil, err
+}
+
+// Stats retrieves the metadata of the object from the primary bucket, or from the secondary
+// bucket if the primary one fails.
+func (m *MirroredBucket) Stats(ctx context.Context, objectName string) (*Stats, error) {
+	stats, err := m.primary.Stats(ctx, objectName)
+	if err == nil {
+		return stats, nil
+	}
+	if stats, secondaryErr := m.secondary.Stats(ctx, objectName); secondaryErr == nil {
+		return stats, nil
+	}
+	return nil, err
+}
+
+// ListObjectVersions retrieves the versions of the object from the primary bucket, or from
+// the secondary bucket if the primary one fails.
+func (m *MirroredBucket) ListObjectVersions(ctx context.Context, objectName string) ([]ObjectVersion, error) {
+	versions, err := m.primary.ListObjectVersions(ctx, objectName)
+	if err == nil {
+		return versions, nil
+	}
+	if versions, secondaryErr := m.secondary.ListObjectVersions(ctx, objectName); secondaryErr == nil {
+		return versions, nil
+	}
+	return nil, err
+}
+
+// GetObjectVersion downloads a version of the object from the primary bucket, or from the
+// secondary bucket if the primary one fails. Version IDs are usually specific to a bucket,
+// so the failover only helps buckets sharing version IDs.
+func (m *MirroredBucket) GetObjectVersion(ctx context.Context, objectName, versionID string) (io.ReadCloser, error) {
+	reader, err := m.primary.GetObjectVersion(ctx, objectName, versionID)
+	if err == nil {
+		return reader, nil
+	}
+	if reader, secondaryErr := m.secondary.GetObjectVersion(ctx, objectName, versionID); secondaryErr == nil {
+		return reader, nil
+	}
+	return nil, err
+}
+
+// SetLifecycleRule sets the rule on both buckets and returns the first error.
+func (m *MirroredBucket) SetLifecycleRule(ctx context.Context, rule LifecycleRule) error {
+	if err := m.primary.SetLifecycleRule(ctx, rule); err != nil {
+		return err
+	}
+	return m.secondary.SetLifecycleRule(ctx, rule)
+}
+
+// GetLifecycleRules retrieves the rules of the primary bucket.
+func (m *MirroredBucket) GetLifecycleRules(ctx context.Context) ([]LifecycleRule, error) {
+	return m.primary.GetLifecycleRules(ctx)
+}
+
+// StartResumableUpload starts a resumable upload on the primary bucket.
+func (m *MirroredBucket) StartResumableUpload(ctx context.Context, objectName string) (UploadSession, error) {
+	return m.primary.StartResumableUpload(ctx, objectName)
+}
+
+// UploadPart uploads one part of a resumable upload to the primary bucket.
+func (m *MirroredBucket) UploadPart(ctx context.Context, session UploadSession, partNum int, reader io.Reader, size int64) error {
+	return m.primary.UploadPart(ctx, session, partNum, reader, size)
+}
+
+// CompleteResumable completes the resumable upload on the primary bucket, then copies the
+// object to the secondary bucket according to the mirror mode.
+func (m *MirroredBucket) CompleteResumable(ctx context.Context, session UploadSession) error {
+	if err := m.primary.CompleteResumable(ctx, session); err != nil {
+		return err
+	}
+	objectName := session.ObjectName
+	copyObject := func(ctx context.Context) error {
+		stats, err := m.primary.Stats(ctx, objectName)
+		if err != nil {
+			return err
+		}
+		reader, err := m.primary.GetObject(ctx, objectName)
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		return m.secondary.PutObject(ctx, objectName, reader, stats.Size)
+	}
+	return m.mirror(ctx, objectName, copyObject, func() {})
+}
+
+// AbortResumable aborts the resumable upload on the primary bucket.
+func (m *MirroredBucket) AbortResumable(ctx context.Context, session UploadSession) error {
+	return m.primary.AbortResumable(ctx, session)
+}
+
+// mirror replicates a write to the secondary bucket with write, synchronously or in the
+// background depending on the mode, then calls release. In the background, the write is
+// queued behind the running replication of objectName, replacing the one already queued.
+func (m *MirroredBucket) mirror(ctx context.Context, objectName string, write func(context.Context) error, release func()) error {
+	if m.mode != MirrorAsync {
+		defer release()
+		if err := write(ctx); err != nil {
+			return fmt.Errorf("%w: mirror %q: %v", ErrFailedToUpload, objectName, err)
+		}
+		return nil
+	}
+
+	next := &replication{write: write, release: release}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.running[objectName]; ok {
+		// Replace the write waiting behind the running replication, if any.
+		if superseded := m.running[objectName]; superseded != nil {
+			superseded.release()
+		}
+		m.running[objectName] = next
+		return nil
+	}
+	m.running[objectName] = nil
+	m.wg.Add(1)
+	go m.replicate(objectName, next)
+	return nil
+}
+
+// replicate runs r, then the writes of objectName queued behind it, until none is left.
+func (m *MirroredBucket) replicate(objectName string, r *replication) {
+	defer m.wg.Done()
+	for r != nil {
+		m.run(objectName, r)
+
+		m.mu.Lock()
+		r = m.running[objectName]
+		if r == nil {
+			delete(m.running, objectName)
+		} else {
+			m.running[objectName] = nil
+		}
+		m.mu.Unlock()
+	}
+}
+
+// run replicates r, retrying failures until the attempts are exhausted or the bucket is
+// closed, then releases it.
+func (m *MirroredBucket) run(objectName string, r *replication) {
+	defer r.release()
+	strategy := m.backoff()
+	var err error
+	for attempt := 1; attempt <= m.attempts; attempt++ {
+		if err = m.attempt(r); err == nil {
+			return
+		}
+		if attempt == m.attempts {
+			break
+		}
+		timer := time.NewTimer(strategy.Next(attempt))
+		select {
+		case <-timer.C:
+		case <-m.ctx.Done():
+			timer.Stop()
+		}
+		if m.ctx.Err() != nil {
+			err = m.ctx.Err()
+			break
+		}
+	}
+	m.mu.Lock()
+	onError := m.onError
+	m.mu.Unlock()
+	if onError != nil {
+		onError(objectName, fmt.Errorf("%w: mirror %q: %v", ErrFailedToUpload, objectName, err))
+	}
+}
+
+// attempt runs the write of r once, bounded by the attempt timeout.
+func (m *MirroredBucket) attempt(r *replication) error {
+	if err := m.ctx.Err(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(m.ctx, m.attemptTimeout)
+	defer cancel()
+	return r.write(ctx)
+}
@@ -0,0 +1,273 @@
+package bucket_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/backoff"
+	"github.com/zeroxsolutions/barbatos/bucket"
+	"github.com/zeroxsolutions/barbatos/bucket/fs"
+)
+
+// recordingBucket is a bucket.Bucket recording the data of its uploads. The first fails
+// uploads fail, and uploads block while gate is set and open.
+type recordingBucket struct {
+	bucket.Bucket
+
+	mu    sync.Mutex
+	fails int
+	gate  chan struct{}
+	// started receives the data of each upload when it starts, if set.
+	started chan string
+	puts    []string
+}
+
+func (r *recordingBucket) PutObject(ctx context.Context, objectName string, reader io.Reader, readerLen int64) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	gate, started := r.gate, r.started
+	r.mu.Unlock()
+	if started != nil {
+		started <- string(data)
+	}
+	if gate != nil {
+		<-gate
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.puts = append(r.puts, string(data))
+	if r.fails > 0 {
+		r.fails--
+		return errors.New("secondary down")
+	}
+	return r.Bucket.PutObject(ctx, objectName, strings.NewReader(string(data)), int64(len(data)))
+}
+
+func (r *recordingBucket) uploads() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.puts...)
+}
+
+// hangingBucket is a bucket.Bucket whose uploads block until their context is done.
+type hangingBucket struct {
+	bucket.Bucket
+}
+
+func (hangingBucket) PutObject(ctx context.Context, objectName string, reader io.Reader, readerLen int64) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// fastMirrorBackoff is a MirrorOption retrying replications without delay.
+var fastMirrorBackoff = bucket.WithMirrorBackoff(func() backoff.Strategy { return backoff.NewConstant(time.Millisecond) })
+
+func TestMirroredBucketSync(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		fails   int
+		wantErr error
+	}{
+		{name: "replicated", fails: 0},
+		{name: "secondary failure", fails: 1, wantErr: bucket.ErrFailedToUpload},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := fs.NewBucket(t.TempDir())
+			secondary := &recordingBucket{Bucket: fs.NewBucket(t.TempDir()), fails: tt.fails}
+			m := bucket.NewMirroredBucket(primary, secondary, bucket.MirrorSync)
+
+			err := m.PutObject(ctx, "report.csv", strings.NewReader("hello"), -1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("PutObject: err = %v, want %v", err, tt.wantErr)
+			}
+			if got := readObject(t, primary, "report.csv"); got != "hello" {
+				t.Errorf("primary object = %q, want hello", got)
+			}
+			if tt.wantErr == nil {
+				if got := readObject(t, secondary, "report.csv"); got != "hello" {
+					t.Errorf("secondary object = %q, want hello", got)
+				}
+			}
+		})
+	}
+}
+
+func TestMirroredBucketFailover(t *testing.T) {
+	ctx := context.Background()
+	primary, secondary := fs.NewBucket(t.TempDir()), fs.NewBucket(t.TempDir())
+	m := bucket.NewMirroredBucket(primary, secondary, bucket.MirrorSync)
+	if err := secondary.PutObject(ctx, "only-secondary", strings.NewReader("x"), 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readObject(t, m, "only-secondary"); got != "x" {
+		t.Errorf("GetObject = %q, want x", got)
+	}
+	if _, err := m.GetObject(ctx, "missing"); !errors.Is(err, bucket.ErrNotFound) {
+		t.Errorf("GetObject of missing object: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMirroredBucketAsyncRetries(t *testing.T) {
+	tests := []struct {
+		name    string
+		fails   int
+		wantErr bool
+	}{
+		{name: "first attempt", fails: 0},
+		{name: "after retries", fails: bucket.DefaultMirrorAttempts - 1},
+		{name: "attempts exhausted", fails: bucket.DefaultMirrorAttempts, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			secondary := &recordingBucket{Bucket: fs.NewBucket(t.TempDir()), fails: tt.fails}
+			m := bucket.NewMirroredBucket(fs.NewBucket(t.TempDir()), secondary, bucket.MirrorAsync, fastMirrorBackoff)
+			var failed []string
+			m.OnError(func(objectName string, err error) {
+				if !errors.Is(err, bucket.ErrFailedToUpload) {
+					t.Errorf("OnError: err = %v, want ErrFailedToUpload", err)
+				}
+				failed = append(failed, objectName)
+			})
+
+			if err := m.PutObject(ctx, "a", strings.NewReader("async"), 5); err != nil {
+				t.Fatal(err)
+			}
+			m.Wait()
+
+			if tt.wantErr {
+				if len(failed) != 1 || failed[0] != "a" {
+					t.Errorf("OnError calls = %v, want [a]", failed)
+				}
+				return
+			}
+			if len(failed) != 0 {
+				t.Errorf("OnError calls = %v, want none", failed)
+			}
+			if got := readObject(t, secondary, "a"); got != "async" {
+				t.Errorf("secondary object = %q, want async", got)
+			}
+		})
+	}
+}
+
+func TestMirroredBucketAsyncAttemptsBelowOne(t *testing.T) {
+	for _, attempts := range []int{0, -1} {
+		secondary := &recordingBucket{Bucket: fs.NewBucket(t.TempDir())}
+		m := bucket.NewMirroredBucket(fs.NewBucket(t.TempDir()), secondary, bucket.MirrorAsync,
+			fastMirrorBackoff, bucket.WithMirrorAttempts(attempts))
+		m.OnError(func(objectName string, err error) { t.Errorf("attempts %d: OnError: %v", attempts, err) })
+
+		if err := m.PutObject(context.Background(), "a", strings.NewReader("async"), 5); err != nil {
+			t.Fatal(err)
+		}
+		m.Wait()
+		if got := readObject(t, secondary, "a"); got != "async" {
+			t.Errorf("attempts %d: secondary object = %q, want async", attempts, got)
+		}
+	}
+}
+
+func TestMirroredBucketAsyncHungSecondary(t *testing.T) {
+	m := bucket.NewMirroredBucket(fs.NewBucket(t.TempDir()), hangingBucket{}, bucket.MirrorAsync,
+		fastMirrorBackoff, bucket.WithMirrorAttempts(2), bucket.WithMirrorAttemptTimeout(10*time.Millisecond))
+	failed := make(chan error, 1)
+	m.OnError(func(objectName string, err error) { failed <- err })
+
+	if err := m.PutObject(context.Background(), "a", strings.NewReader("x"), 1); err != nil {
+		t.Fatal(err)
+	}
+	m.Wait()
+	if err := <-failed; !errors.Is(err, bucket.ErrFailedToUpload) {
+		t.Errorf("OnError: err = %v, want ErrFailedToUpload", err)
+	}
+}
+
+func TestMirroredBucketClose(t *testing.T) {
+	// The replication waits an hour between attempts unless it is canceled.
+	started := make(chan string, 1)
+	secondary := &recordingBucket{Bucket: fs.NewBucket(t.TempDir()), fails: 1, started: started}
+	m := bucket.NewMirroredBucket(fs.NewBucket(t.TempDir()), secondary, bucket.MirrorAsync,
+		bucket.WithMirrorBackoff(func() backoff.Strategy { return backoff.NewConstant(time.Hour) }))
+	failed := make(chan error, 2)
+	m.OnError(func(objectName string, err error) { failed <- err })
+
+	ctx := context.Background()
+	if err := m.PutObject(ctx, "a", strings.NewReader("x"), 1); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-failed; !errors.Is(err, bucket.ErrFailedToUpload) {
+		t.Errorf("OnError: err = %v, want ErrFailedToUpload", err)
+	}
+
+	// Writes after Close are not replicated.
+	if err := m.PutObject(ctx, "b", strings.NewReader("y"), 1); err != nil {
+		t.Fatal(err)
+	}
+	m.Wait()
+	if err := <-failed; !errors.Is(err, bucket.ErrFailedToUpload) {
+		t.Errorf("OnError after Close: err = %v, want ErrFailedToUpload", err)
+	}
+	if got := secondary.uploads(); len(got) != 1 {
+		t.Errorf("uploads = %v, want the single failed one", got)
+	}
+}
+
+func TestMirroredBucketAsyncOrder(t *testing.T) {
+	ctx := context.Background()
+	gate := make(chan struct{})
+	started := make(chan string, 10)
+	primary := fs.NewBucket(t.TempDir())
+	secondary := &recordingBucket{Bucket: fs.NewBucket(t.TempDir()), gate: gate, started: started}
+	m := bucket.NewMirroredBucket(primary, secondary, bucket.MirrorAsync)
+
+	put := func(objectName, data string) {
+		t.Helper()
+		if err := m.PutObject(ctx, objectName, strings.NewReader(data), int64(len(data))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	put("a", "v1")
+	if got := <-started; got != "v1" {
+		t.Fatalf("first replication = %q, want v1", got)
+	}
+	// v2 and v3 are written while v1 is being replicated: v2 is superseded by v3.
+	put("a", "v2")
+	put("a", "v3")
+	// Other objects are replicated independently.
+	put("b", "other")
+	if got := <-started; got != "other" {
+		t.Fatalf("replication of b = %q, want other", got)
+	}
+	close(gate)
+	m.Wait()
+
+	if got := readObject(t, secondary, "a"); got != "v3" {
+		t.Errorf("secondary object = %q, want v3", got)
+	}
+	var replicated []string
+	for _, data := range secondary.uploads() {
+		if data != "other" {
+			replicated = append(replicated, data)
+		}
+	}
+	if strings.Join(replicated, ",") != "v1,v3" {
+		t.Errorf("replications of a = %v, want [v1 v3]", replicated)
+	}
+}
@@ -0,0 +1,116 @@
+package bucket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ObjectsError aggregates the per-object errors of a batch operation such as GetObjects.
+// It maps each failed object name to the error encountered for it.
+type ObjectsError struct {
+	// Errors maps the name of every failed object to its error.
+	Errors map[string]error
+}
+
+// Error returns a summary of all per-object errors, ordered by object name.
+func (e *ObjectsError) Error() string {
+	names := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s: %v", name, e.Errors[name]))
+	}
+	return fmt.Sprintf("bucket: %d object(s) failed: %s", len(names), strings.Join(parts, "; "))
+}
+
+// Is reports whether any of the per-object errors matches target, so that
+// errors.Is(err, ErrNotFound) can be used to detect missing objects in a batch.
+func (e *ObjectsError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetObjects downloads several objects concurrently, using at most concurrency
+// simultaneous GetObject calls (a value below 1 is treated as 1).
+//
+// Objects that do not exist are reported per name: the returned map holds readers for
+// every object that was found, and the returned *ObjectsError lists the missing ones.
+// If any object fails for another reason, all readers that were already opened are
+// closed and only the *ObjectsError is returned, so nothing is leaked.
+// The caller is responsible for closing every returned reader.
+//
+//	readers, err := bucket.GetObjects(ctx, b, []string{"a.txt", "b.txt"}, 4)
+//	var objectsErr *bucket.ObjectsError
+//	if errors.As(err, &objectsErr) && readers != nil {
+//		// some objects are missing; readers holds the others
+//	}
+func GetObjects(ctx context.Context, b Bucket, names []string, concurrency int) (map[string]io.ReadCloser, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		readers = make(map[string]io.ReadCloser, len(names))
+		errs    = make(map[string]error)
+		sem     = make(chan struct{}, concurrency)
+		seen    = make(map[string]struct{}, len(names))
+	)
+	for _, name := range names {
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(name string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			var (
+				reader io.ReadCloser
+				err    = ctx.Err()
+			)
+			if err == nil {
+				reader, err = b.GetObject(ctx, name)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[name] = err
+				return
+			}
+			readers[name] = reader
+		}(name)
+	}
+	wg.Wait()
+
+	if len(errs) == 0 {
+		return readers, nil
+	}
+	for _, err := range errs {
+		if !errors.Is(err, ErrNotFound) {
+			for _, reader := range readers {
+				_ = reader.Close()
+			}
+			return nil, &ObjectsError{Errors: errs}
+		}
+	}
+	return readers, &ObjectsError{Errors: errs}
+}
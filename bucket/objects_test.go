@@ -0,0 +1,201 @@
+package bucket_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/bucket"
+)
+
+var errBackend = errors.New("backend failure")
+
+// trackingBucket is a bucket.Bucket failing GetObject for the names in failing, tracking
+// the readers it opens, the maximum number of concurrent GetObject calls, and the number of
+// Stats calls.
+type trackingBucket struct {
+	bucket.Bucket
+	failing map[string]error
+
+	mu      sync.Mutex
+	active  int
+	peak    int
+	readers []*trackedReader
+	stats   int
+}
+
+type trackedReader struct {
+	io.ReadCloser
+	closed bool
+}
+
+func (r *trackedReader) Close() error {
+	r.closed = true
+	return r.ReadCloser.Close()
+}
+
+func (b *trackingBucket) Stats(ctx context.Context, objectName string) (*bucket.Stats, error) {
+	b.mu.Lock()
+	b.stats++
+	b.mu.Unlock()
+	return b.Bucket.Stats(ctx, objectName)
+}
+
+func (b *trackingBucket) GetObject(ctx context.Context, objectName string) (io.ReadCloser, error) {
+	b.mu.Lock()
+	b.active++
+	if b.active > b.peak {
+		b.peak = b.active
+	}
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		b.active--
+		b.mu.Unlock()
+	}()
+	time.Sleep(time.Millisecond)
+
+	if err := b.failing[objectName]; err != nil {
+		return nil, err
+	}
+	reader, err := b.Bucket.GetObject(ctx, objectName)
+	if err != nil {
+		return nil, err
+	}
+	tracked := &trackedReader{ReadCloser: reader}
+	b.mu.Lock()
+	b.readers = append(b.readers, tracked)
+	b.mu.Unlock()
+	return tracked, nil
+}
+
+// newTrackingBucket creates a trackingBucket holding objects, each containing its name.
+func newTrackingBucket(t *testing.T, objects ...string) *trackingBucket {
+	t.Helper()
+	b := &trackingBucket{Bucket: newMemBucket(), failing: make(map[string]error)}
+	for _, name := range objects {
+		if err := b.PutObject(context.Background(), name, strings.NewReader(name), int64(len(name))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return b
+}
+
+func TestGetObjects(t *testing.T) {
+	tests := []struct {
+		name        string
+		names       []string
+		failing     map[string]error
+		wantReaders []string
+		wantFailed  []string
+		wantErr     error
+		wantClosed  bool
+	}{
+		{
+			name:        "all found",
+			names:       []string{"a", "b", "c", "a"},
+			wantReaders: []string{"a", "b", "c"},
+		},
+		{
+			name:        "missing objects",
+			names:       []string{"a", "missing", "b"},
+			wantReaders: []string{"a", "b"},
+			wantFailed:  []string{"missing"},
+			wantErr:     bucket.ErrNotFound,
+		},
+		{
+			name:       "other failure",
+			names:      []string{"a", "b", "c"},
+			failing:    map[string]error{"c": errBackend},
+			wantFailed: []string{"c"},
+			wantErr:    errBackend,
+			wantClosed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTrackingBucket(t, "a", "b", "c")
+			for name, err := range tt.failing {
+				b.failing[name] = err
+			}
+
+			readers, err := bucket.GetObjects(context.Background(), b, tt.names, 2)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			var objectsErr *bucket.ObjectsError
+			if tt.wantErr != nil {
+				if !errors.As(err, &objectsErr) {
+					t.Fatalf("err = %T, want *ObjectsError", err)
+				}
+				var failed []string
+				for name := range objectsErr.Errors {
+					failed = append(failed, name)
+				}
+				sort.Strings(failed)
+				if strings.Join(failed, ",") != strings.Join(tt.wantFailed, ",") {
+					t.Errorf("failed objects = %v, want %v", failed, tt.wantFailed)
+				}
+			}
+
+			var got []string
+			for name, reader := range readers {
+				data, err := io.ReadAll(reader)
+				if err != nil || string(data) != name {
+					t.Errorf("object %q = %q, %v", name, data, err)
+				}
+				_ = reader.Close()
+				got = append(got, name)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.wantReaders, ",") {
+				t.Errorf("readers = %v, want %v", got, tt.wantReaders)
+			}
+			if tt.wantClosed {
+				for _, reader := range b.readers {
+					if !reader.closed {
+						t.Error("reader left open after a failure")
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestGetObjectsConcurrency(t *testing.T) {
+	names := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	tests := []struct {
+		concurrency int
+		wantPeak    int
+	}{
+		{0, 1},
+		{1, 1},
+		{3, 3},
+	}
+	for _, tt := range tests {
+		b := newTrackingBucket(t, names...)
+		readers, err := bucket.GetObjects(context.Background(), b, names, tt.concurrency)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, reader := range readers {
+			_ = reader.Close()
+		}
+		if b.peak > tt.wantPeak {
+			t.Errorf("concurrency %d: %d concurrent GetObject calls, want at most %d", tt.concurrency, b.peak, tt.wantPeak)
+		}
+	}
+}
+
+func TestObjectsErrorMessage(t *testing.T) {
+	err := &bucket.ObjectsError{Errors: map[string]error{"b": errBackend, "a": bucket.ErrNotFound}}
+	want := "bucket: 2 object(s) failed: a: bucket: not found; b: backend failure"
+	if err.Error() != want {
+		t.Errorf("Error = %q, want %q", err.Error(), want)
+	}
+}
@@ -4,12 +4,21 @@ import "time"
 
 // Stats represents the metadata of an object in the storage bucket.
 // It contains information about the total number of objects, the size of the object,
-// the content type of the object, and the last modified time of the object.
+// the content type and encoding of the object, its entity tag, the last modified time of the object, and its storage class.
 type Stats struct {
 	// Size represents the size of the object in the storage bucket.
 	Size int64 `json:"size" yaml:"size"`
 	// ContentType represents the content type of the object in the storage bucket.
 	ContentType string `json:"contentType" yaml:"contentType"`
+	// ContentEncoding represents the content encoding of the object (e.g. gzip), if any.
+	// It is empty for backends that do not store content encodings.
+	ContentEncoding string `json:"contentEncoding,omitempty" yaml:"contentEncoding,omitempty"`
+	// ETag is an opaque identifier of the object's content that changes whenever the object is
+	// rewritten. It is empty for backends that do not compute entity tags.
+	ETag string `json:"etag,omitempty" yaml:"etag,omitempty"`
 	// LastModified represents the last modified time of the object in the storage bucket.
 	LastModified time.Time `json:"lastModified" yaml:"lastModified"`
+	// StorageClass represents the storage class of the object (e.g. STANDARD, GLACIER).
+	// It is empty for backends that have no concept of storage classes.
+	StorageClass string `json:"storageClass,omitempty" yaml:"storageClass,omitempty"`
 }
@@ -0,0 +1,69 @@
+package bucket_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/bucket"
+)
+
+func TestStatsJSON(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		stats    bucket.Stats
+		contains []string
+		omits    []string
+	}{
+		{
+			name:     "storage class",
+			stats:    bucket.Stats{Size: 3, ContentType: "text/plain", LastModified: modified, StorageClass: "GLACIER"},
+			contains: []string{`"storageClass":"GLACIER"`},
+		},
+		{
+			name:  "no storage class",
+			stats: bucket.Stats{Size: 3, ContentType: "text/plain", LastModified: modified},
+			omits: []string{"storageClass"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.stats)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			for _, s := range tt.contains {
+				if !strings.Contains(string(data), s) {
+					t.Errorf("%s does not contain %s", data, s)
+				}
+			}
+			for _, s := range tt.omits {
+				if strings.Contains(string(data), s) {
+					t.Errorf("%s contains %s", data, s)
+				}
+			}
+			var got bucket.Stats
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.stats) {
+				t.Errorf("round trip = %+v, want %+v", got, tt.stats)
+			}
+		})
+	}
+}
+
+// TestStatsYAMLTags checks that every field is tagged with the same YAML name and options
+// as its JSON name, since the root module does not depend on a YAML encoder.
+func TestStatsYAMLTags(t *testing.T) {
+	typ := reflect.TypeOf(bucket.Stats{})
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if got, want := field.Tag.Get("yaml"), field.Tag.Get("json"); got != want {
+			t.Errorf("field %s: yaml tag %q, want %q", field.Name, got, want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package bucket
+
+import (
+	"context"
+	"io"
+	"strings"
+)
+
+// SubBucket is a Bucket view scoped to the objects of another Bucket whose names start with
+// a prefix, giving services sharing a bucket isolated folders. Object names passed to the
+// view are relative to the prefix, and names returned by it have the prefix stripped.
+type SubBucket struct {
+	parent Bucket
+	prefix string
+}
+
+var _ Bucket = (*SubBucket)(nil)
+
+// Sub returns a view of b in which every object name is transparently prefixed with
+// prefix + "/". Leading and trailing slashes of prefix are ignored, and an empty prefix
+// returns b itself. Sub views compose: Sub(Sub(b, "a"), "b") is equivalent to Sub(b, "a/b").
+//
+//	invoices := bucket.Sub(b, "billing/invoices")
+//	err := invoices.PutObject(ctx, "2024/001.pdf", reader, size) // writes billing/invoices/2024/001.pdf
+func Sub(b Bucket, prefix string) Bucket {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return b
+	}
+	if sub, ok := b.(*SubBucket); ok {
+		return &SubBucket{parent: sub.parent, prefix: sub.prefix + prefix + "/"}
+	}
+	return &SubBucket{parent: b, prefix: prefix + "/"}
+}
+
+// Prefix returns the prefix, including its trailing slash, prepended to object names by the view.
+func (s *SubBucket) Prefix() string {
+	return s.prefix
+}
+
+// PutObject uploads the object under the prefix.
+func (s *SubBucket) PutObject(ctx context.Context, objectName string, reader io.Reader, readerLen int64) error {
+	return s.parent.PutObject(ctx, s.prefix+objectName, reader, readerLen)
+}
+
+// GetObject downloads the object under the prefix.
+func (s *SubBucket) GetObject(ctx context.Context, objectName string) (io.ReadCloser, error) {
+	return s.parent.GetObject(ctx, s.prefix+objectName)
+}
+
+// Stats retrieves the metadata of the object under the prefix.
+func (s *SubBucket) Stats(ctx context.Context, objectName string) (*Stats, error) {
+	return s.parent.Stats(ctx, s.prefix+objectName)
+}
+
+// ListObjectVersions retrieves the versions of the object under the prefix.
+func (s *SubBucket) ListObjectVersions(ctx context.Context, objectName string) ([]ObjectVersion, error) {
+	return s.parent.ListObjectVersions(ctx, s.prefix+objectName)
+}
+
+// GetObjectVersion downloads a version of the object under the prefix.
+func (s *SubBucket) GetObjectVersion(ctx context.Context, objectName, versionID string) (io.ReadCloser, error) {
+	return s.parent.GetObjectVersion(ctx, s.prefix+objectName, versionID)
+}
+
+// SetLifecycleRule sets the rule on the parent bucket, restricted to the objects of the view.
+func (s *SubBucket) SetLifecycleRule(ctx context.Context, rule LifecycleRule) error {
+	rule.Prefix = s.prefix + rule.Prefix
+	return s.parent.SetLifecycleRule(ctx, rule)
+}
+
+// GetLifecycleRules retrieves the rules of the parent bucket that only apply to objects of
+// the view, with the prefix stripped from their own prefix.
+func (s *SubBucket) GetLifecycleRules(ctx context.Context) ([]LifecycleRule, error) {
+	rules, err := s.parent.GetLifecycleRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	scoped := make([]LifecycleRule, 0, len(rules))
+	for _, rule := range rules {
+		if strings.HasPrefix(rule.Prefix, s.prefix) {
+			rule.Prefix = strings.TrimPrefix(rule.Prefix, s.prefix)
+			scoped = append(scoped, rule)
+		}
+	}
+	return scoped, nil
+}
+
+// StartResumableUpload starts a resumable upload of the object under the prefix. The
+// returned session names the object relative to the view.
+func (s *SubBucket) StartResumableUpload(ctx context.Context, objectName string) (UploadSession, error) {
+	session, err := s.parent.StartResumableUpload(ctx, s.prefix+objectName)
+	if err != nil {
+		return UploadSession{}, err
+	}
+	session.ObjectName = strings.TrimPrefix(session.ObjectName, s.prefix)
+	return session, nil
+}
+
+// UploadPart uploads one part of a resumable upload started on the view.
+func (s *SubBucket) UploadPart(ctx context.Context, session UploadSession, partNum int, reader io.Reader, size int64) error {
+	return s.parent.UploadPart(ctx, s.parentSession(session), partNum, reader, size)
+}
+
+// CompleteResumable completes a resumable upload started on the view.
+func (s *SubBucket) CompleteResumable(ctx context.Context, session UploadSession) error {
+	return s.parent.CompleteResumable(ctx, s.parentSession(session))
+}
+
+// AbortResumable aborts a resumable upload started on the view.
+func (s *SubBucket) AbortResumable(ctx context.Context, session UploadSession) error {
+	return s.parent.AbortResumable(ctx, s.parentSession(session))
+}
+
+// parentSession returns session with its object name translated to the parent bucket.
+func (s *SubBucket) parentSession(session UploadSession) UploadSession {
+	session.ObjectName = s.prefix + session.ObjectName
+	return session
+}
@@ -0,0 +1,113 @@
+package bucket_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zeroxsolutions/barbatos/bucket"
+	"github.com/zeroxsolutions/barbatos/bucket/fs"
+)
+
+// ruleBucket is a bucket.Bucket storing its lifecycle rules in memory.
+type ruleBucket struct {
+	bucket.Bucket
+	rules []bucket.LifecycleRule
+}
+
+func (b *ruleBucket) SetLifecycleRule(ctx context.Context, rule bucket.LifecycleRule) error {
+	b.rules = append(b.rules, rule)
+	return nil
+}
+
+func (b *ruleBucket) GetLifecycleRules(ctx context.Context) ([]bucket.LifecycleRule, error) {
+	return b.rules, nil
+}
+
+func TestSub(t *testing.T) {
+	parent := fs.NewBucket(t.TempDir())
+	tests := []struct {
+		name       string
+		view       bucket.Bucket
+		wantPrefix string
+	}{
+		{"prefix", bucket.Sub(parent, "billing"), "billing/"},
+		{"slashes trimmed", bucket.Sub(parent, "/billing/invoices/"), "billing/invoices/"},
+		{"composed", bucket.Sub(bucket.Sub(parent, "billing"), "invoices"), "billing/invoices/"},
+		{"empty prefix", bucket.Sub(parent, "/"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if sub, ok := tt.view.(*bucket.SubBucket); ok {
+				if sub.Prefix() != tt.wantPrefix {
+					t.Errorf("Prefix = %q, want %q", sub.Prefix(), tt.wantPrefix)
+				}
+			} else if tt.view != parent || tt.wantPrefix != "" {
+				t.Fatalf("Sub = %T, want a SubBucket", tt.view)
+			}
+
+			if err := tt.view.PutObject(ctx, "2024/001.pdf", strings.NewReader(tt.name), int64(len(tt.name))); err != nil {
+				t.Fatal(err)
+			}
+			if got := readObject(t, parent, tt.wantPrefix+"2024/001.pdf"); got != tt.name {
+				t.Errorf("parent object = %q, want %q", got, tt.name)
+			}
+			if got := readObject(t, tt.view, "2024/001.pdf"); got != tt.name {
+				t.Errorf("view object = %q, want %q", got, tt.name)
+			}
+			stats, err := tt.view.Stats(ctx, "2024/001.pdf")
+			if err != nil || stats.Size != int64(len(tt.name)) {
+				t.Errorf("Stats = %+v, %v", stats, err)
+			}
+		})
+	}
+}
+
+func TestSubResumableUpload(t *testing.T) {
+	ctx := context.Background()
+	parent := fs.NewBucket(t.TempDir())
+	view := bucket.Sub(parent, "uploads")
+
+	session, err := view.StartResumableUpload(ctx, "video.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.ObjectName != "video.mp4" {
+		t.Errorf("session object = %q, want it relative to the view", session.ObjectName)
+	}
+	for i, part := range []string{"ab", "cd"} {
+		if err := view.UploadPart(ctx, session, i+1, strings.NewReader(part), 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := view.CompleteResumable(ctx, session); err != nil {
+		t.Fatal(err)
+	}
+	if got := readObject(t, parent, "uploads/video.mp4"); got != "abcd" {
+		t.Errorf("object = %q, want abcd", got)
+	}
+}
+
+func TestSubLifecycleRules(t *testing.T) {
+	ctx := context.Background()
+	parent := &ruleBucket{rules: []bucket.LifecycleRule{
+		{ID: "global", ExpireAfterDays: 365},
+		{ID: "other", Prefix: "reports/", ExpireAfterDays: 7},
+	}}
+	view := bucket.Sub(parent, "billing")
+	if err := view.SetLifecycleRule(ctx, bucket.LifecycleRule{ID: "drafts", Prefix: "drafts/", ExpireAfterDays: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if got := parent.rules[2].Prefix; got != "billing/drafts/" {
+		t.Errorf("parent rule prefix = %q, want billing/drafts/", got)
+	}
+
+	rules, err := view.GetLifecycleRules(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 1 || rules[0].ID != "drafts" || rules[0].Prefix != "drafts/" {
+		t.Errorf("rules = %+v, want only drafts with its prefix stripped", rules)
+	}
+}
@@ -0,0 +1,11 @@
+package bucket
+
+// UploadSession identifies a resumable upload started with Bucket.StartResumableUpload.
+// It holds no connection state and can be serialized (e.g. as JSON) and reloaded later,
+// possibly by another process, to upload the remaining parts and complete the upload.
+type UploadSession struct {
+	// ID is the backend identifier of the upload.
+	ID string `json:"id" yaml:"id"`
+	// ObjectName is the name of the object created when the upload is completed.
+	ObjectName string `json:"objectName" yaml:"objectName"`
+}
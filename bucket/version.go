@@ -0,0 +1,15 @@
+package bucket
+
+import "time"
+
+// ObjectVersion represents one version of an object in a versioned storage bucket.
+type ObjectVersion struct {
+	// VersionID identifies the version of the object.
+	VersionID string `json:"versionId" yaml:"versionId"`
+	// Size represents the size of this version of the object.
+	Size int64 `json:"size" yaml:"size"`
+	// LastModified represents the time at which this version was written.
+	LastModified time.Time `json:"lastModified" yaml:"lastModified"`
+	// IsLatest reports whether this version is the current version of the object.
+	IsLatest bool `json:"isLatest" yaml:"isLatest"`
+}
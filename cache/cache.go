@@ -14,6 +14,12 @@ type Cache interface {
 	// It returns true if the connection is active, and false otherwise.
 	IsConnected(ctx context.Context) bool
 
+	// CheckConnection performs a lightweight round-trip to the cache system (e.g. a PING),
+	// bounded by the context deadline, and returns the underlying error if it fails.
+	// Unlike IsConnected, which may report a cached state, it always probes the server,
+	// which makes it suitable for readiness checks.
+	CheckConnection(ctx context.Context) error
+
 	// Keys returns a list of keys in the cache system that match the given pattern.
 	// The pattern allows for wildcard searches (e.g., "*"), and the function
 	// returns the matching keys and any error encountered during the operation.
@@ -33,6 +39,18 @@ type Cache interface {
 	// duration, the value will be automatically removed from the cache.
 	SetWithExpiration(ctx context.Context, key string, value interface{}, expiration time.Duration) error
 
+	// SetNX stores a value in the cache system with the specified key only if the key
+	// does not already exist, setting the given expiration time (0 means no expiration).
+	// It returns true if the value was stored, and false if the key already existed.
+	// This operation is atomic and can be used as a simple distributed lock.
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+
+	// CompareAndSwap stores newValue under key, with the given expiration (0 means no expiration),
+	// only if the current value of key equals oldValue. It returns true if the value was swapped,
+	// and false if the key is missing or holds another value. The comparison and the write are
+	// atomic (e.g. a Redis Lua script), so concurrent swaps from the same value have one winner.
+	CompareAndSwap(ctx context.Context, key, oldValue, newValue string, expiration time.Duration) (bool, error)
+
 	// Del deletes the specified keys from the cache system. If the operation fails,
 	// it returns an error. It accepts multiple keys as variadic arguments.
 	Del(ctx context.Context, keys ...string) error
@@ -42,6 +60,16 @@ type Cache interface {
 	// of keys that share a common prefix or pattern.
 	DelWithPattern(ctx context.Context, pattern string) error
 
+	// DelWithPatternCount deletes all keys that match the given pattern from the cache system,
+	// like DelWithPattern, and returns the number of keys actually deleted. Implementations
+	// should accumulate the count while scanning and deleting matching keys in batches.
+	DelWithPatternCount(ctx context.Context, pattern string) (int64, error)
+
+	// Pipeline calls fn with a Pipe to queue several commands, then executes them in a single
+	// round-trip once fn returns. The queued commands are applied atomically: either all of them
+	// or none. If fn returns an error, nothing is executed and the error is returned.
+	Pipeline(ctx context.Context, fn func(p Pipe) error) error
+
 	// Close closes the connection to the cache system. This should be called
 	// when the cache client is no longer needed to release any resources held by it.
 	Close() error
@@ -7,3 +7,12 @@ import "errors"
 // a requested key exists in the cache but its associated value is nil, which
 // may imply that the key is present but uninitialized or cleared.
 var ErrCacheNil = errors.New("cache: nil")
+
+// ErrCacheDecode represents the error returned when a cached value cannot be decoded
+// into the requested type. This usually indicates that the stored data is corrupted
+// or was written with an incompatible format.
+var ErrCacheDecode = errors.New("cache: decode failed")
+
+// ErrCacheClosed represents the error returned when an operation is attempted on a cache
+// that has been closed.
+var ErrCacheClosed = errors.New("cache: closed")
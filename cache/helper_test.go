@@ -0,0 +1,200 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/cache"
+)
+
+// testClock is a clock only moving when advanced, for the options setting the clock of a
+// cache or helper.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newTestClock() *testClock {
+	return &testClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+// Now returns the current time of the clock.
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// mapCache is a map-backed cache.Cache honoring expirations, used by the tests of the
+// package. Values are stored formatted with fmt.Sprint; the methods it does not implement
+// panic through the nil embedded Cache.
+type mapCache struct {
+	cache.Cache
+
+	mu      sync.Mutex
+	values  map[string]string
+	expires map[string]time.Time
+	down    error
+	// skew is added to the wall clock, so that tests can expire keys without sleeping.
+	skew time.Duration
+}
+
+func newMapCache() *mapCache {
+	return &mapCache{values: make(map[string]string), expires: make(map[string]time.Time)}
+}
+
+// advance moves the clock of the cache forward by d.
+func (c *mapCache) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.skew += d
+}
+
+// now returns the current time of the cache. c.mu must be held.
+func (c *mapCache) now() time.Time {
+	return time.Now().Add(c.skew)
+}
+
+// expire removes the expired keys. c.mu must be held.
+func (c *mapCache) expire() {
+	now := c.now()
+	for key, expires := range c.expires {
+		if !now.Before(expires) {
+			delete(c.values, key)
+			delete(c.expires, key)
+		}
+	}
+}
+
+// store sets key to value with the given expiration. c.mu must be held.
+func (c *mapCache) store(key string, value interface{}, expiration time.Duration) {
+	c.values[key] = fmt.Sprint(value)
+	delete(c.expires, key)
+	if expiration > 0 {
+		c.expires[key] = c.now().Add(expiration)
+	}
+}
+
+func (c *mapCache) Get(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire()
+	value, ok := c.values[key]
+	if !ok {
+		return "", cache.ErrCacheNil
+	}
+	return value, nil
+}
+
+func (c *mapCache) Set(ctx context.Context, key string, value interface{}) error {
+	return c.SetWithExpiration(ctx, key, value, 0)
+}
+
+func (c *mapCache) Keys(ctx context.Context, pattern string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire()
+	var keys []string
+	for key := range c.values {
+		if ok, _ := path.Match(pattern, key); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (c *mapCache) Del(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.values, key)
+		delete(c.expires, key)
+	}
+	return nil
+}
+
+func (c *mapCache) DelWithPatternCount(ctx context.Context, pattern string) (int64, error) {
+	keys, _ := c.Keys(ctx, pattern)
+	return int64(len(keys)), c.Del(ctx, keys...)
+}
+
+func (c *mapCache) SetWithExpiration(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store(key, value, expiration)
+	return nil
+}
+
+func (c *mapCache) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire()
+	if _, ok := c.values[key]; ok {
+		return false, nil
+	}
+	c.store(key, value, expiration)
+	return true, nil
+}
+
+func (c *mapCache) CompareAndSwap(ctx context.Context, key, oldValue, newValue string, expiration time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire()
+	if current, ok := c.values[key]; !ok || current != oldValue {
+		return false, nil
+	}
+	c.store(key, newValue, expiration)
+	return true, nil
+}
+
+// Pipeline runs fn with a Pipe queuing commands, then applies them in order.
+func (c *mapCache) Pipeline(ctx context.Context, fn func(p cache.Pipe) error) error {
+	p := &mapPipe{cache: c}
+	if err := fn(p); err != nil {
+		return err
+	}
+	for _, command := range p.commands {
+		command(ctx)
+	}
+	return nil
+}
+
+// mapPipe is the Pipe of a mapCache.
+type mapPipe struct {
+	cache    *mapCache
+	commands []func(ctx context.Context)
+}
+
+func (p *mapPipe) Set(key string, value interface{}, expiration time.Duration) {
+	p.commands = append(p.commands, func(ctx context.Context) { _ = p.cache.Set(ctx, key, value) })
+}
+
+func (p *mapPipe) Del(keys ...string) {
+	p.commands = append(p.commands, func(ctx context.Context) { _ = p.cache.Del(ctx, keys...) })
+}
+
+func (p *mapPipe) Incr(key string) {
+	p.commands = append(p.commands, func(ctx context.Context) {
+		value, _ := p.cache.Get(ctx, key)
+		n, _ := strconv.ParseInt(value, 10, 64)
+		_ = p.cache.Set(ctx, key, n+1)
+	})
+}
+
+// CheckConnection returns down, so tests can simulate an unreachable cache.
+func (c *mapCache) CheckConnection(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.down
+}
@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// IdempotencyLockSuffix is appended to the key passed to Do to build the key of the lock
+// serializing concurrent first calls.
+const IdempotencyLockSuffix = ":lock"
+
+// DefaultIdempotencyLockTTL is the default expiration of the lock taken by Do.
+const DefaultIdempotencyLockTTL = 30 * time.Second
+
+// DefaultIdempotencyPollInterval is the default interval at which Do checks for the result of
+// a call in progress elsewhere.
+const DefaultIdempotencyPollInterval = 50 * time.Millisecond
+
+// idempotencyReleased is the value a lock holder swaps its token for before deleting the
+// lock, so that the deletion cannot remove a lock taken by another caller in the meantime.
+const idempotencyReleased = "released"
+
+// IdempotencyOption configures a call to Do.
+type IdempotencyOption func(*idempotencyConfig)
+
+// idempotencyConfig holds the settings of a call to Do.
+type idempotencyConfig struct {
+	lockTTL      time.Duration
+	pollInterval time.Duration
+}
+
+// WithIdempotencyLockTTL sets the expiration of the lock taken while fn runs, so a crashed
+// holder releases the key after d. It should exceed the longest run of fn: once it expires,
+// another caller may run fn again. It defaults to DefaultIdempotencyLockTTL.
+func WithIdempotencyLockTTL(d time.Duration) IdempotencyOption {
+	return func(c *idempotencyConfig) {
+		c.lockTTL = d
+	}
+}
+
+// WithIdempotencyPollInterval sets the interval at which Do checks for the result of a call
+// in progress elsewhere. It defaults to DefaultIdempotencyPollInterval.
+func WithIdempotencyPollInterval(d time.Duration) IdempotencyOption {
+	return func(c *idempotencyConfig) {
+		c.pollInterval = d
+	}
+}
+
+// Do runs fn at most once per key within ttl and returns its result, e.g. to make POST
+// handlers idempotent.
+//
+// On the first call for a key, fn is run and its result is cached under key for ttl.
+// Subsequent calls within ttl return the cached result without running fn. Concurrent
+// first calls, in this process or others sharing the cache, are serialized by a lock
+// taken with SetNX: only the lock holder runs fn while the others wait, polling at the poll
+// interval, until the result is available or ctx is done. The lock holds a random token
+// identifying its holder and expires after the lock TTL, so a crashed holder only blocks the
+// key until then.
+//
+// If fn returns an error, nothing is cached, the lock is released, and the error is
+// returned, so the next call runs fn again.
+//
+// Example:
+//
+//	body, err := cache.Do(ctx, c, "idempotency:"+requestKey, 24*time.Hour, func() ([]byte, error) {
+//		return json.Marshal(createOrder(ctx, req))
+//	})
+func Do(ctx context.Context, c Cache, key string, ttl time.Duration, fn func() ([]byte, error), opts ...IdempotencyOption) ([]byte, error) {
+	config := idempotencyConfig{lockTTL: DefaultIdempotencyLockTTL, pollInterval: DefaultIdempotencyPollInterval}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	lockTTL, pollInterval := config.lockTTL, config.pollInterval
+
+	lockKey := key + IdempotencyLockSuffix
+	token, err := idempotencyToken()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		value, err := c.Get(ctx, key)
+		if err == nil {
+			return []byte(value), nil
+		}
+		if !errors.Is(err, ErrCacheNil) {
+			return nil, err
+		}
+
+		locked, err := c.SetNX(ctx, lockKey, token, lockTTL)
+		if err != nil {
+			return nil, err
+		}
+		if locked {
+			defer releaseIdempotencyLock(c, lockKey, token, lockTTL)
+			return runIdempotent(ctx, c, key, ttl, fn)
+		}
+
+		timer := time.NewTimer(pollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// idempotencyToken returns a random token identifying the holder of a lock.
+func idempotencyToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// runIdempotent runs fn and caches its result, unless the result is already cached.
+func runIdempotent(ctx context.Context, c Cache, key string, ttl time.Duration, fn func() ([]byte, error)) ([]byte, error) {
+	// The result may have been stored between the lookup and the lock acquisition.
+	value, err := c.Get(ctx, key)
+	if err == nil {
+		return []byte(value), nil
+	}
+	if !errors.Is(err, ErrCacheNil) {
+		return nil, err
+	}
+
+	result, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.SetWithExpiration(ctx, key, string(result), ttl); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// releaseIdempotencyLock deletes the lock if it is still held with token. It does not use the
+// caller's context, which may be cancelled by then, and leaves the lock to expire if it fails.
+func releaseIdempotencyLock(c Cache, lockKey, token string, lockTTL time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), lockTTL)
+	defer cancel()
+	released, err := c.CompareAndSwap(ctx, lockKey, token, idempotencyReleased, lockTTL)
+	if err == nil && released {
+		_ = c.Del(ctx, lockKey)
+	}
+}
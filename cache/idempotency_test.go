@@ -0,0 +1,163 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/cache"
+)
+
+// fastPoll is an IdempotencyOption polling every millisecond.
+var fastPoll = cache.WithIdempotencyPollInterval(time.Millisecond)
+
+func TestIdempotencyDo(t *testing.T) {
+	errCall := errors.New("call failed")
+	tests := []struct {
+		name      string
+		results   []error
+		wantCalls int32
+		wantErrs  []error
+	}{
+		{"result cached", []error{nil, nil, nil}, 1, []error{nil, nil, nil}},
+		{"error not cached", []error{errCall, nil, nil}, 2, []error{errCall, nil, nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := newMapCache()
+			var calls int32
+			for i, want := range tt.wantErrs {
+				result := tt.results[i]
+				got, err := cache.Do(ctx, c, "req", time.Hour, func() ([]byte, error) {
+					atomic.AddInt32(&calls, 1)
+					if result != nil {
+						return nil, result
+					}
+					return []byte("created"), nil
+				}, fastPoll)
+				if !errors.Is(err, want) {
+					t.Fatalf("call %d: err = %v, want %v", i, err, want)
+				}
+				if err == nil && string(got) != "created" {
+					t.Errorf("call %d: result = %q, want created", i, got)
+				}
+				// The lock is released after every call.
+				if _, err := c.Get(ctx, "req"+cache.IdempotencyLockSuffix); !errors.Is(err, cache.ErrCacheNil) {
+					t.Errorf("call %d: lock left behind, err = %v", i, err)
+				}
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("fn ran %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestIdempotencyDoConcurrent(t *testing.T) {
+	ctx := context.Background()
+	c := newMapCache()
+
+	var calls int32
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	errs := make([]error, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := cache.Do(ctx, c, "req", time.Hour, func() ([]byte, error) {
+				atomic.AddInt32(&calls, 1)
+				time.Sleep(10 * time.Millisecond)
+				return []byte("created"), nil
+			}, fastPoll)
+			results[i], errs[i] = string(result), err
+		}(i)
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("fn ran %d times, want 1", calls)
+	}
+	for i := range results {
+		if errs[i] != nil || results[i] != "created" {
+			t.Errorf("call %d = %q, %v, want created, nil", i, results[i], errs[i])
+		}
+	}
+}
+
+func TestIdempotencyDoWaitCancelled(t *testing.T) {
+	c := newMapCache()
+	// Another caller holds the lock and never stores a result.
+	if _, err := c.SetNX(context.Background(), "req"+cache.IdempotencyLockSuffix, "1", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := cache.Do(ctx, c, "req", time.Hour, func() ([]byte, error) {
+		t.Error("fn ran while the lock was held")
+		return nil, nil
+	}, fastPoll)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestIdempotencyDoLockExpired(t *testing.T) {
+	ctx := context.Background()
+	c := newMapCache()
+	options := []cache.IdempotencyOption{cache.WithIdempotencyLockTTL(20 * time.Millisecond), fastPoll}
+
+	// The lock of a crashed caller expires after the lock TTL, not after the result TTL.
+	if _, err := c.SetNX(ctx, "req"+cache.IdempotencyLockSuffix, "crashed", 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	result, err := cache.Do(ctx, c, "req", time.Hour, func() ([]byte, error) {
+		return []byte("created"), nil
+	}, options...)
+	if err != nil || string(result) != "created" {
+		t.Errorf("Do = %q, %v, want created, nil", result, err)
+	}
+}
+
+func TestIdempotencyDoReleasesOwnLockOnly(t *testing.T) {
+	ctx := context.Background()
+	c := newMapCache()
+	lockKey := "req" + cache.IdempotencyLockSuffix
+	options := []cache.IdempotencyOption{cache.WithIdempotencyLockTTL(20 * time.Millisecond)}
+
+	_, err := cache.Do(ctx, c, "req", time.Hour, func() ([]byte, error) {
+		// The lock expires while fn runs and another caller takes it.
+		time.Sleep(30 * time.Millisecond)
+		if _, err := c.SetNX(ctx, lockKey, "other", time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		return []byte("created"), nil
+	}, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := c.Get(ctx, lockKey); err != nil || got != "other" {
+		t.Errorf("lock = %q, %v, want the lock of the other caller", got, err)
+	}
+}
+
+func TestIdempotencyDoReleasesAfterCancel(t *testing.T) {
+	c := newMapCache()
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := cache.Do(ctx, c, "req", time.Hour, func() ([]byte, error) {
+		cancel()
+		return nil, errors.New("call failed")
+	})
+	if err == nil {
+		t.Fatal("Do succeeded, want the error of fn")
+	}
+	// The lock is released even though the caller's context is cancelled.
+	if _, err := c.Get(context.Background(), "req"+cache.IdempotencyLockSuffix); !errors.Is(err, cache.ErrCacheNil) {
+		t.Errorf("lock left behind, err = %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// GetInto retrieves the value associated with the given key from the cache system
+// and decodes it into dest, which must be a non-nil pointer. The value is decoded with the
+// Unmarshaller the cache is configured with if it implements ValueDecoder, and with
+// encoding/json otherwise. It returns ErrCacheNil if the key is missing, any error returned
+// by the cache, or an error wrapping ErrCacheDecode if the stored value cannot be decoded
+// into dest.
+//
+// Example:
+//
+//	var user User
+//	if err := cache.GetInto(ctx, c, "user:1", &user); err != nil {
+//		// handle err
+//	}
+func GetInto(ctx context.Context, c Cache, key string, dest interface{}) error {
+	return GetIntoWith(ctx, c, key, dest, nil)
+}
+
+// GetIntoWith is like GetInto but decodes the value with unmarshal. A nil unmarshal decodes
+// it as GetInto does.
+//
+//	err := cache.GetIntoWith(ctx, c, "user:1", &user, jsoniter.Unmarshal)
+func GetIntoWith(ctx context.Context, c Cache, key string, dest interface{}, unmarshal Unmarshaller) error {
+	if unmarshal == nil {
+		unmarshal = json.Unmarshal
+		if decoder, ok := c.(ValueDecoder); ok {
+			unmarshal = decoder.DecodeValue
+		}
+	}
+	value, err := c.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := unmarshal([]byte(value), dest); err != nil {
+		return fmt.Errorf("%w: key %q: %v", ErrCacheDecode, key, err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zeroxsolutions/barbatos/cache"
+)
+
+type testUser struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestGetInto(t *testing.T) {
+	ctx := context.Background()
+	c := newMapCache()
+	if err := c.Set(ctx, "user:1", `{"name":"alice","age":30}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set(ctx, "user:2", "not json"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		want    testUser
+		wantErr error
+	}{
+		{name: "decoded", key: "user:1", want: testUser{Name: "alice", Age: 30}},
+		{name: "missing key", key: "user:3", wantErr: cache.ErrCacheNil},
+		{name: "invalid value", key: "user:2", wantErr: cache.ErrCacheDecode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got testUser
+			err := cache.GetInto(ctx, c, tt.key, &got)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetIntoWith(t *testing.T) {
+	ctx := context.Background()
+	c := newMapCache()
+	if err := c.Set(ctx, "name", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	errCustom := errors.New("custom decoder")
+
+	tests := []struct {
+		name      string
+		unmarshal cache.Unmarshaller
+		want      string
+		wantErr   error
+	}{
+		{
+			name: "custom unmarshaller",
+			unmarshal: func(data []byte, v interface{}) error {
+				*v.(*string) = "decoded:" + string(data)
+				return nil
+			},
+			want: "decoded:alice",
+		},
+		{
+			name:      "custom unmarshaller error",
+			unmarshal: func(data []byte, v interface{}) error { return errCustom },
+			wantErr:   cache.ErrCacheDecode,
+		},
+		{
+			name:    "nil falls back to JSON",
+			wantErr: cache.ErrCacheDecode,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			err := cache.GetIntoWith(ctx, c, "name", &got, tt.unmarshal)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// decodingCache is a mapCache implementing cache.ValueDecoder.
+type decodingCache struct {
+	*mapCache
+}
+
+func (decodingCache) DecodeValue(data []byte, v interface{}) error {
+	*v.(*string) = "decoded:" + string(data)
+	return nil
+}
+
+func TestGetIntoValueDecoder(t *testing.T) {
+	ctx := context.Background()
+	c := decodingCache{newMapCache()}
+	if err := c.Set(ctx, "name", "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	var got string
+	if err := cache.GetInto(ctx, c, "name", &got); err != nil || got != "decoded:alice" {
+		t.Errorf("GetInto = %q, %v, want the value decoded by the cache", got, err)
+	}
+	// An explicit Unmarshaller takes precedence over the one of the cache.
+	err := cache.GetIntoWith(ctx, c, "name", &got, func(data []byte, v interface{}) error {
+		*v.(*string) = "explicit:" + string(data)
+		return nil
+	})
+	if err != nil || got != "explicit:alice" {
+		t.Errorf("GetIntoWith = %q, %v, want the value decoded by the explicit Unmarshaller", got, err)
+	}
+}
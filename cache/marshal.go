@@ -0,0 +1,38 @@
+package cache
+
+import "encoding/json"
+
+// Marshaller encodes a value into bytes stored in the cache system.
+// It has the signature of json.Marshal, so alternative encoders such as jsoniter can be used.
+type Marshaller func(v interface{}) ([]byte, error)
+
+// Unmarshaller decodes bytes read from the cache system into v, which must be a pointer.
+// It has the signature of json.Unmarshal.
+type Unmarshaller func(data []byte, v interface{}) error
+
+// ValueDecoder is an optional interface implemented by the Cache implementations accepting an
+// Unmarshaller option, so that GetInto decodes their values the way they were configured to.
+type ValueDecoder interface {
+	// DecodeValue decodes data, a value read from the cache, into v, which must be a pointer.
+	DecodeValue(data []byte, v interface{}) error
+}
+
+// EncodeValue returns the string stored by Set for value. Strings and byte slices are
+// stored as-is; any other value is encoded with marshal, or encoding/json if nil.
+// Cache implementations accepting a Marshaller option use it to encode values in Set.
+func EncodeValue(value interface{}, marshal Marshaller) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	}
+	if marshal == nil {
+		marshal = json.Marshal
+	}
+	data, err := marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
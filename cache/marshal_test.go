@@ -0,0 +1,91 @@
+package cache_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zeroxsolutions/barbatos/cache"
+	"github.com/zeroxsolutions/barbatos/cache/memory"
+)
+
+// upperMarshaller encodes values as upper-case strings, to tell it apart from JSON.
+func upperMarshaller(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	return []byte(strings.ToUpper(string(data))), err
+}
+
+func TestEncodeValue(t *testing.T) {
+	errMarshal := errors.New("marshal failed")
+	tests := []struct {
+		name    string
+		value   interface{}
+		marshal cache.Marshaller
+		want    string
+		wantErr error
+	}{
+		{"string as-is", `{"a":1}`, nil, `{"a":1}`, nil},
+		{"bytes as-is", []byte("raw"), upperMarshaller, "raw", nil},
+		{"default marshaller", map[string]string{"name": "alice"}, nil, `{"name":"alice"}`, nil},
+		{"number", 42, nil, "42", nil},
+		{"custom marshaller", map[string]string{"name": "alice"}, upperMarshaller, `{"NAME":"ALICE"}`, nil},
+		{"marshal error", 1, func(interface{}) ([]byte, error) { return nil, errMarshal }, "", errMarshal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cache.EncodeValue(tt.value, tt.marshal)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("EncodeValue = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLRUWithMarshaller(t *testing.T) {
+	ctx := context.Background()
+	c := memory.NewLRU(0, memory.WithMarshaller(upperMarshaller))
+	if err := c.Set(ctx, "user", map[string]string{"name": "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := c.Get(ctx, "user"); err != nil || got != `{"NAME":"ALICE"}` {
+		t.Errorf("Get = %q, %v, want the custom encoding", got, err)
+	}
+}
+
+// lowerUnmarshaller decodes the values encoded by upperMarshaller.
+func lowerUnmarshaller(data []byte, v interface{}) error {
+	return json.Unmarshal([]byte(strings.ToLower(string(data))), v)
+}
+
+func TestLRUWithUnmarshaller(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []memory.Option
+		want string
+	}{
+		{"default JSON", nil, "alice"},
+		{"custom codec", []memory.Option{memory.WithMarshaller(upperMarshaller), memory.WithUnmarshaller(lowerUnmarshaller)}, "alice"},
+		{"custom marshaller only", []memory.Option{memory.WithMarshaller(upperMarshaller)}, "ALICE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := memory.NewLRU(0, tt.opts...)
+			if err := c.Set(ctx, "user", testUser{Name: "alice"}); err != nil {
+				t.Fatal(err)
+			}
+			var got testUser
+			if err := cache.GetInto(ctx, c, "user", &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Name != tt.want {
+				t.Errorf("Name = %q, want %q", got.Name, tt.want)
+			}
+		})
+	}
+}
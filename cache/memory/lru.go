@@ -0,0 +1,480 @@
+// Package memory provides an in-process cache.Cache implementation.
+// It is intended for single-instance deployments, local development and tests where
+// running a cache server such as Redis is not practical.
+package memory
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/cache"
+)
+
+// entry is a value stored in an LRU cache.
+type entry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+}
+
+// expired reports whether the entry has expired at now.
+func (e *entry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// Stats holds the counters of an LRU cache.
+type Stats struct {
+	// Hits is the number of Get calls that found a live entry.
+	Hits uint64
+	// Misses is the number of Get calls that found no entry or an expired one.
+	Misses uint64
+	// Evictions is the number of live entries removed to respect the size limit.
+	Evictions uint64
+	// Entries is the current number of entries, including expired ones not yet removed.
+	Entries int
+}
+
+// Option configures an LRU cache.
+type Option func(*LRU)
+
+// WithMarshaller sets the Marshaller used to encode values that are neither strings nor
+// byte slices. It defaults to encoding/json.
+func WithMarshaller(marshal cache.Marshaller) Option {
+	return func(c *LRU) {
+		c.marshal = marshal
+	}
+}
+
+// WithUnmarshaller sets the Unmarshaller used by cache.GetInto to decode the values of the
+// cache, which should match the Marshaller set with WithMarshaller. It defaults to
+// encoding/json.
+func WithUnmarshaller(unmarshal cache.Unmarshaller) Option {
+	return func(c *LRU) {
+		c.unmarshal = unmarshal
+	}
+}
+
+// WithDefaultTTL sets the expiration applied by Set, so that keys stored without an explicit
+// expiration do not live forever. SetWithExpiration always uses its own expiration. A d of 0,
+// the default, means Set stores entries without expiration.
+func WithDefaultTTL(d time.Duration) Option {
+	return func(c *LRU) {
+		c.defaultTTL = d
+	}
+}
+
+// WithExpiryJitter adds a random offset in [0, max) to the expiration of every entry stored
+// with one, so keys written together with the same TTL do not all expire at once and cause
+// a burst of reloads. Entries stored without expiration are not affected.
+func WithExpiryJitter(max time.Duration) Option {
+	return func(c *LRU) {
+		c.jitter = max
+	}
+}
+
+// WithRandSource sets the source of the expiry jitter, e.g. a seeded source to make the
+// offsets deterministic in tests. It defaults to a source seeded with the current time.
+func WithRandSource(src rand.Source) Option {
+	return func(c *LRU) {
+		c.random = rand.New(src)
+	}
+}
+
+// WithClock sets the function returning the current time, against which expirations are set
+// and checked, e.g. a fake clock advanced by tests instead of sleeping. It defaults to
+// time.Now.
+//
+//	c := memory.NewLRU(0, memory.WithClock(func() time.Time { return now }))
+func WithClock(now func() time.Time) Option {
+	return func(c *LRU) {
+		c.now = now
+	}
+}
+
+// LRU is a cache.Cache that keeps at most a fixed number of entries in memory and evicts
+// the least recently used entry when the limit is exceeded. Get and Set mark an entry as
+// recently used; Keys and pattern deletions do not.
+//
+// Expired entries are removed lazily when they are accessed, or evicted first as they age
+// towards the back of the list. LRU is safe for concurrent use.
+type LRU struct {
+	maxEntries int
+	marshal    cache.Marshaller
+	unmarshal  cache.Unmarshaller
+	defaultTTL time.Duration
+	jitter     time.Duration
+	random     *rand.Rand
+	now        func() time.Time
+
+	mu     sync.Mutex
+	items  map[string]*list.Element
+	order  *list.List
+	closed bool
+	stats  Stats
+}
+
+var (
+	_ cache.Cache        = (*LRU)(nil)
+	_ cache.ValueDecoder = (*LRU)(nil)
+)
+
+// NewLRU creates an LRU cache holding at most maxEntries entries.
+// A maxEntries of 0 or less means no limit.
+//
+//	c := memory.NewLRU(10000)
+//	err := c.SetWithExpiration(ctx, "session:42", session, time.Hour)
+func NewLRU(maxEntries int, opts ...Option) *LRU {
+	c := &LRU{
+		maxEntries: maxEntries,
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.jitter > 0 && c.random == nil {
+		c.random = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return c
+}
+
+// DecodeValue decodes data into v with the Unmarshaller set by WithUnmarshaller. It
+// implements cache.ValueDecoder.
+func (c *LRU) DecodeValue(data []byte, v interface{}) error {
+	if c.unmarshal == nil {
+		return json.Unmarshal(data, v)
+	}
+	return c.unmarshal(data, v)
+}
+
+// Stats returns a snapshot of the cache counters.
+func (c *LRU) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = c.order.Len()
+	return stats
+}
+
+// IsConnected reports whether the cache has not been closed.
+func (c *LRU) IsConnected(ctx context.Context) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.closed
+}
+
+// CheckConnection returns cache.ErrCacheClosed if the cache has been closed.
+func (c *LRU) CheckConnection(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+	return nil
+}
+
+// Keys returns the keys of live entries matching the Redis-style glob pattern, in no
+// particular order.
+func (c *LRU) Keys(ctx context.Context, pattern string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil, cache.ErrCacheClosed
+	}
+	now := c.now()
+	var keys []string
+	for key, elem := range c.items {
+		if !elem.Value.(*entry).expired(now) && match(pattern, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// Get returns the value stored under key and marks it as recently used.
+// It returns cache.ErrCacheNil if the key does not exist or has expired.
+func (c *LRU) Get(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return "", cache.ErrCacheClosed
+	}
+	e, ok := c.lookup(key, c.now())
+	if !ok {
+		c.stats.Misses++
+		return "", cache.ErrCacheNil
+	}
+	c.stats.Hits++
+	c.order.MoveToFront(c.items[key])
+	return e.value, nil
+}
+
+// Set stores value under key, expiring after the default TTL set by WithDefaultTTL, or
+// without expiration if there is none.
+func (c *LRU) Set(ctx context.Context, key string, value interface{}) error {
+	return c.SetWithExpiration(ctx, key, value, c.defaultTTL)
+}
+
+// SetWithExpiration stores value under key, expiring after expiration (0 means no
+// expiration), and evicts the least recently used entries if the limit is exceeded.
+func (c *LRU) SetWithExpiration(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	encoded, err := cache.EncodeValue(value, c.marshal)
+	if err != nil {
+		return fmt.Errorf("memory: encode %q: %w", key, err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+	c.set(key, encoded, c.expiresAt(c.now(), expiration))
+	return nil
+}
+
+// SetNX stores value under key only if no live entry exists for key.
+func (c *LRU) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	encoded, err := cache.EncodeValue(value, c.marshal)
+	if err != nil {
+		return false, fmt.Errorf("memory: encode %q: %w", key, err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false, cache.ErrCacheClosed
+	}
+	now := c.now()
+	if _, ok := c.lookup(key, now); ok {
+		return false, nil
+	}
+	c.set(key, encoded, c.expiresAt(now, expiration))
+	return true, nil
+}
+
+// CompareAndSwap stores newValue under key only if a live entry holds oldValue, under the
+// cache lock. A successful swap marks the entry as recently used.
+func (c *LRU) CompareAndSwap(ctx context.Context, key, oldValue, newValue string, expiration time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false, cache.ErrCacheClosed
+	}
+	now := c.now()
+	e, ok := c.lookup(key, now)
+	if !ok || e.value != oldValue {
+		return false, nil
+	}
+	c.set(key, newValue, c.expiresAt(now, expiration))
+	return true, nil
+}
+
+// Del deletes the given keys. Missing keys are ignored.
+func (c *LRU) Del(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+	for _, key := range keys {
+		c.remove(key)
+	}
+	return nil
+}
+
+// DelWithPattern deletes the entries whose keys match the Redis-style glob pattern.
+func (c *LRU) DelWithPattern(ctx context.Context, pattern string) error {
+	_, err := c.DelWithPatternCount(ctx, pattern)
+	return err
+}
+
+// DelWithPatternCount deletes the live entries whose keys match the Redis-style glob
+// pattern and returns how many were deleted. Matching expired entries are removed too,
+// but not counted.
+func (c *LRU) DelWithPatternCount(ctx context.Context, pattern string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return 0, cache.ErrCacheClosed
+	}
+	now := c.now()
+	var deleted int64
+	for key, elem := range c.items {
+		if !match(pattern, key) {
+			continue
+		}
+		if !elem.Value.(*entry).expired(now) {
+			deleted++
+		}
+		c.remove(key)
+	}
+	return deleted, nil
+}
+
+// Pipeline calls fn to queue commands, then applies them under a single lock. The commands
+// are validated before any of them is applied, so a failing Incr leaves the cache unchanged.
+func (c *LRU) Pipeline(ctx context.Context, fn func(p cache.Pipe) error) error {
+	p := &pipe{marshal: c.marshal}
+	if err := fn(p); err != nil {
+		return err
+	}
+	if p.err != nil {
+		return p.err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return cache.ErrCacheClosed
+	}
+	now := c.now()
+	if err := c.validate(p.ops, now); err != nil {
+		return err
+	}
+	for _, op := range p.ops {
+		switch op.kind {
+		case opSet:
+			c.set(op.keys[0], op.value, c.expiresAt(now, op.expiration))
+		case opDel:
+			for _, key := range op.keys {
+				c.remove(key)
+			}
+		case opIncr:
+			// validate guarantees the increment succeeds.
+			_ = c.incr(op.keys[0], now)
+		}
+	}
+	return nil
+}
+
+// Close removes all entries; subsequent operations return cache.ErrCacheClosed.
+func (c *LRU) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+	return nil
+}
+
+// lookup returns the live entry stored under key, removing it if it has expired.
+// It does not change the recency of the entry. c.mu must be held.
+func (c *LRU) lookup(key string, now time.Time) (*entry, bool) {
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := elem.Value.(*entry)
+	if e.expired(now) {
+		c.remove(key)
+		return nil, false
+	}
+	return e, true
+}
+
+// set stores value under key as the most recently used entry and evicts entries from the
+// back of the list while the limit is exceeded. c.mu must be held.
+func (c *LRU) set(key, value string, expiresAt time.Time) {
+	if elem, ok := c.items[key]; ok {
+		e := elem.Value.(*entry)
+		e.value, e.expiresAt = value, expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.items[key] = c.order.PushFront(&entry{key: key, value: value, expiresAt: expiresAt})
+
+	if c.maxEntries <= 0 {
+		return
+	}
+	now := c.now()
+	for c.order.Len() > c.maxEntries {
+		e := c.order.Back().Value.(*entry)
+		if !e.expired(now) {
+			c.stats.Evictions++
+		}
+		c.remove(e.key)
+	}
+}
+
+// incr increments the integer value stored under key, keeping its expiration.
+// A missing key is treated as 0. c.mu must be held.
+func (c *LRU) incr(key string, now time.Time) error {
+	e, ok := c.lookup(key, now)
+	if !ok {
+		c.set(key, "1", time.Time{})
+		return nil
+	}
+	n, err := strconv.ParseInt(e.value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("memory: incr %q: value is not an integer", key)
+	}
+	c.set(key, strconv.FormatInt(n+1, 10), e.expiresAt)
+	return nil
+}
+
+// validate checks that ops can be applied, simulating their effect on the values of the
+// incremented keys. c.mu must be held.
+func (c *LRU) validate(ops []op, now time.Time) error {
+	values := make(map[string]*string)
+	current := func(key string) *string {
+		if v, ok := values[key]; ok {
+			return v
+		}
+		if e, ok := c.items[key]; ok && !e.Value.(*entry).expired(now) {
+			return &e.Value.(*entry).value
+		}
+		return nil
+	}
+	for _, op := range ops {
+		switch op.kind {
+		case opSet:
+			value := op.value
+			values[op.keys[0]] = &value
+		case opDel:
+			for _, key := range op.keys {
+				values[key] = nil
+			}
+		case opIncr:
+			key := op.keys[0]
+			var n int64
+			if v := current(key); v != nil {
+				parsed, err := strconv.ParseInt(*v, 10, 64)
+				if err != nil {
+					return fmt.Errorf("memory: incr %q: value is not an integer", key)
+				}
+				n = parsed
+			}
+			value := strconv.FormatInt(n+1, 10)
+			values[key] = &value
+		}
+	}
+	return nil
+}
+
+// remove deletes the entry stored under key, if any. c.mu must be held.
+func (c *LRU) remove(key string) {
+	if elem, ok := c.items[key]; ok {
+		c.order.Remove(elem)
+		delete(c.items, key)
+	}
+}
+
+// expiresAt returns the expiration time of an entry stored at now, including the expiry
+// jitter, or the zero time if expiration is 0 or less. It must be called with c.mu held.
+func (c *LRU) expiresAt(now time.Time, expiration time.Duration) time.Time {
+	if expiration <= 0 {
+		return time.Time{}
+	}
+	if c.jitter > 0 {
+		expiration += time.Duration(c.random.Int63n(int64(c.jitter)))
+	}
+	return now.Add(expiration)
+}
@@ -0,0 +1,538 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/cache"
+)
+
+// newTestLRU creates an LRU holding the given keys, each with its own key as value.
+func newTestLRU(t *testing.T, keys ...string) *LRU {
+	t.Helper()
+	c := NewLRU(0)
+	for _, key := range keys {
+		if err := c.Set(context.Background(), key, key); err != nil {
+			t.Fatalf("Set(%q): %v", key, err)
+		}
+	}
+	return c
+}
+
+// fakeClock is a clock for WithClock that only moves when advanced.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+// Now returns the current time of the clock.
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newClockedLRU creates an LRU reading the time from a fake clock, holding the given keys.
+func newClockedLRU(t *testing.T, keys ...string) (*LRU, *fakeClock) {
+	t.Helper()
+	clock := newFakeClock()
+	c := NewLRU(0, WithClock(clock.Now))
+	for _, key := range keys {
+		if err := c.Set(context.Background(), key, key); err != nil {
+			t.Fatalf("Set(%q): %v", key, err)
+		}
+	}
+	return c, clock
+}
+
+// liveKeys returns the sorted keys of the live entries of c.
+func liveKeys(t *testing.T, c *LRU) []string {
+	t.Helper()
+	keys, err := c.Keys(context.Background(), "*")
+	if err != nil {
+		t.Fatalf("Keys: %v", err)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestLRUDelWithPatternCount(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		wantCount int64
+		wantLeft  []string
+	}{
+		{"user:*", 3, []string{"org:1"}},
+		{"user:[12]", 2, []string{"org:1", "user:10"}},
+		{"user:?", 2, []string{"org:1", "user:10"}},
+		{"*", 4, nil},
+		{"session:*", 0, []string{"org:1", "user:1", "user:10", "user:2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			c := newTestLRU(t, "user:1", "user:2", "user:10", "org:1")
+			n, err := c.DelWithPatternCount(context.Background(), tt.pattern)
+			if err != nil {
+				t.Fatalf("DelWithPatternCount: %v", err)
+			}
+			if n != tt.wantCount {
+				t.Errorf("count = %d, want %d", n, tt.wantCount)
+			}
+			if got := liveKeys(t, c); fmt.Sprint(got) != fmt.Sprint(tt.wantLeft) {
+				t.Errorf("keys left = %v, want %v", got, tt.wantLeft)
+			}
+		})
+	}
+}
+
+func TestLRUDelWithPatternCountExpired(t *testing.T) {
+	ctx := context.Background()
+	c, clock := newClockedLRU(t, "user:1")
+	if err := c.SetWithExpiration(ctx, "user:2", "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Minute)
+
+	// The expired entry is removed but not counted.
+	if n, err := c.DelWithPatternCount(ctx, "user:*"); err != nil || n != 1 {
+		t.Errorf("DelWithPatternCount = %d, %v, want 1, nil", n, err)
+	}
+	if got := c.Stats().Entries; got != 0 {
+		t.Errorf("entries = %d, want 0", got)
+	}
+}
+
+func TestLRUDelWithPatternCountClosed(t *testing.T) {
+	c := newTestLRU(t, "user:1")
+	_ = c.Close()
+	if _, err := c.DelWithPatternCount(context.Background(), "*"); !errors.Is(err, cache.ErrCacheClosed) {
+		t.Errorf("err = %v, want ErrCacheClosed", err)
+	}
+}
+
+func TestLRUCheckConnection(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		close   bool
+		wantErr error
+	}{
+		{"open", context.Background(), false, nil},
+		{"closed", context.Background(), true, cache.ErrCacheClosed},
+		{"context cancelled", cancelled, false, context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewLRU(0)
+			if tt.close {
+				_ = c.Close()
+			}
+			if err := c.CheckConnection(tt.ctx); !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got := c.IsConnected(tt.ctx); got == tt.close {
+				t.Errorf("IsConnected = %v, want %v", got, !tt.close)
+			}
+		})
+	}
+}
+
+func TestLRUEviction(t *testing.T) {
+	tests := []struct {
+		name     string
+		touch    []string
+		set      string
+		wantKeys []string
+	}{
+		{"least recently set", nil, "d", []string{"b", "c", "d"}},
+		{"get marks recent", []string{"a"}, "d", []string{"a", "c", "d"}},
+		{"overwrite does not evict", nil, "b", []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := NewLRU(3)
+			for _, key := range []string{"a", "b", "c"} {
+				if err := c.Set(ctx, key, key); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, key := range tt.touch {
+				if _, err := c.Get(ctx, key); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := c.Set(ctx, tt.set, tt.set); err != nil {
+				t.Fatal(err)
+			}
+			if got := liveKeys(t, c); fmt.Sprint(got) != fmt.Sprint(tt.wantKeys) {
+				t.Errorf("keys = %v, want %v", got, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestLRUStats(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	c := NewLRU(2, WithClock(clock.Now))
+	steps := []func() error{
+		func() error { return c.Set(ctx, "a", "a") },
+		func() error { return c.SetWithExpiration(ctx, "b", "b", time.Minute) },
+		// Evicts "a", the least recently used live entry.
+		func() error { return c.Set(ctx, "c", "c") },
+		func() error { clock.Advance(time.Minute); return nil },
+		// Evicts the expired "b", which is not counted as an eviction.
+		func() error { return c.Set(ctx, "d", "d") },
+		func() error { _, err := c.Get(ctx, "c"); return err },
+		func() error { _, err := c.Get(ctx, "d"); return err },
+		func() error { _, err := c.Get(ctx, "a"); return ignoreNil(err) },
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+	}
+
+	want := Stats{Hits: 2, Misses: 1, Evictions: 1, Entries: 2}
+	if got := c.Stats(); got != want {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+}
+
+// ignoreNil returns nil for cache.ErrCacheNil and err otherwise.
+func ignoreNil(err error) error {
+	if errors.Is(err, cache.ErrCacheNil) {
+		return nil
+	}
+	return err
+}
+
+func TestLRUExpiration(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	c := NewLRU(0, WithClock(clock.Now))
+	if err := c.SetWithExpiration(ctx, "short", "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetWithExpiration(ctx, "long", "v", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetWithExpiration(ctx, "forever", "v", 0); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Minute)
+
+	tests := []struct {
+		key     string
+		wantErr error
+	}{
+		{"short", cache.ErrCacheNil},
+		{"long", nil},
+		{"forever", nil},
+		{"missing", cache.ErrCacheNil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if _, err := c.Get(ctx, tt.key); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Get = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if got := liveKeys(t, c); fmt.Sprint(got) != "[forever long]" {
+		t.Errorf("keys = %v, want [forever long]", got)
+	}
+}
+
+func TestLRUSetNX(t *testing.T) {
+	ctx := context.Background()
+	c, clock := newClockedLRU(t, "taken")
+	if err := c.SetWithExpiration(ctx, "expired", "old", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Minute)
+
+	tests := []struct {
+		key       string
+		wantSet   bool
+		wantValue string
+	}{
+		{"taken", false, "taken"},
+		{"expired", true, "new"},
+		{"free", true, "new"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			set, err := c.SetNX(ctx, tt.key, "new", 0)
+			if err != nil || set != tt.wantSet {
+				t.Fatalf("SetNX = %v, %v, want %v, nil", set, err, tt.wantSet)
+			}
+			if got, _ := c.Get(ctx, tt.key); got != tt.wantValue {
+				t.Errorf("value = %q, want %q", got, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestLRUEncoding(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(0)
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"string", "text", "text"},
+		{"bytes", []byte("raw"), "raw"},
+		{"struct", struct {
+			ID int `json:"id"`
+		}{ID: 7}, `{"id":7}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := c.Set(ctx, tt.name, tt.value); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := c.Get(ctx, tt.name); err != nil || got != tt.want {
+				t.Errorf("Get = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+	if err := c.Set(ctx, "chan", make(chan int)); err == nil {
+		t.Error("storing an unencodable value succeeded")
+	}
+}
+
+func TestLRUClosed(t *testing.T) {
+	ctx := context.Background()
+	c := newTestLRU(t, "a")
+	_ = c.Close()
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{"Get", func() error { _, err := c.Get(ctx, "a"); return err }},
+		{"Set", func() error { return c.Set(ctx, "a", "v") }},
+		{"SetNX", func() error { _, err := c.SetNX(ctx, "a", "v", 0); return err }},
+		{"Del", func() error { return c.Del(ctx, "a") }},
+		{"Keys", func() error { _, err := c.Keys(ctx, "*"); return err }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, cache.ErrCacheClosed) {
+				t.Errorf("err = %v, want ErrCacheClosed", err)
+			}
+		})
+	}
+	if got := c.Stats().Entries; got != 0 {
+		t.Errorf("entries after Close = %d, want 0", got)
+	}
+}
+
+func TestLRUCompareAndSwap(t *testing.T) {
+	tests := []struct {
+		name      string
+		key       string
+		oldValue  string
+		wantSwap  bool
+		wantValue string
+	}{
+		{"matching value", "k", "v1", true, "v2"},
+		{"stale value", "k", "other", false, "v1"},
+		{"missing key", "missing", "", false, ""},
+		{"expired key", "expired", "v1", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c, clock := newClockedLRU(t)
+			if err := c.Set(ctx, "k", "v1"); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.SetWithExpiration(ctx, "expired", "v1", time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			clock.Advance(time.Minute)
+
+			swapped, err := c.CompareAndSwap(ctx, tt.key, tt.oldValue, "v2", 0)
+			if err != nil || swapped != tt.wantSwap {
+				t.Fatalf("CompareAndSwap = %v, %v, want %v, nil", swapped, err, tt.wantSwap)
+			}
+			if got, _ := c.Get(ctx, tt.key); got != tt.wantValue {
+				t.Errorf("value = %q, want %q", got, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestLRUCompareAndSwapConcurrent(t *testing.T) {
+	ctx := context.Background()
+	c := newTestLRU(t, "k")
+	results := make(chan bool)
+	for i := 0; i < 20; i++ {
+		go func(i int) {
+			swapped, _ := c.CompareAndSwap(ctx, "k", "k", fmt.Sprint(i), 0)
+			results <- swapped
+		}(i)
+	}
+	wins := 0
+	for i := 0; i < 20; i++ {
+		if <-results {
+			wins++
+		}
+	}
+	if wins != 1 {
+		t.Errorf("%d swaps succeeded, want exactly 1", wins)
+	}
+}
+
+func TestLRUCompareAndSwapClosed(t *testing.T) {
+	c := newTestLRU(t, "k")
+	_ = c.Close()
+	if _, err := c.CompareAndSwap(context.Background(), "k", "k", "v", 0); !errors.Is(err, cache.ErrCacheClosed) {
+		t.Errorf("err = %v, want ErrCacheClosed", err)
+	}
+}
+
+// expiryOf returns the expiration time of the entry stored under key.
+func expiryOf(t *testing.T, c *LRU, key string) time.Time {
+	t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.items[key]
+	if !ok {
+		t.Fatalf("no entry for %q", key)
+	}
+	return elem.Value.(*entry).expiresAt
+}
+
+func TestLRUExpiryJitter(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		expiration  time.Duration
+		wantMaxSkew time.Duration
+		wantSpread  bool
+	}{
+		{"without jitter", nil, time.Hour, 0, false},
+		{"with jitter", []Option{WithExpiryJitter(time.Minute)}, time.Hour, time.Minute, true},
+		{"seeded jitter", []Option{WithExpiryJitter(time.Minute), WithRandSource(rand.NewSource(1))}, time.Hour, time.Minute, true},
+		{"no expiration", []Option{WithExpiryJitter(time.Minute)}, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := NewLRU(0, tt.opts...)
+			offsets := make(map[time.Duration]bool)
+			for i := 0; i < 20; i++ {
+				key := fmt.Sprint(i)
+				before := time.Now()
+				if err := c.SetWithExpiration(ctx, key, "v", tt.expiration); err != nil {
+					t.Fatal(err)
+				}
+				after := time.Now()
+				expiresAt := expiryOf(t, c, key)
+				if tt.expiration == 0 {
+					if !expiresAt.IsZero() {
+						t.Fatalf("entry without expiration expires at %v", expiresAt)
+					}
+					continue
+				}
+				if expiresAt.Before(before.Add(tt.expiration)) || !expiresAt.Before(after.Add(tt.expiration+tt.wantMaxSkew+time.Millisecond)) {
+					t.Fatalf("expiration = %v, want within [%v, %v)", expiresAt.Sub(before), tt.expiration, tt.expiration+tt.wantMaxSkew)
+				}
+				offsets[expiresAt.Sub(before).Truncate(time.Second)] = true
+			}
+			if spread := len(offsets) > 1; spread != tt.wantSpread {
+				t.Errorf("distinct expirations = %d, want spread %v", len(offsets), tt.wantSpread)
+			}
+		})
+	}
+}
+
+func TestLRUExpiryJitterSeeded(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	c := NewLRU(0, WithExpiryJitter(time.Minute), WithRandSource(rand.NewSource(42)), WithClock(clock.Now))
+	expected := rand.New(rand.NewSource(42))
+	for i := 0; i < 5; i++ {
+		if err := c.SetWithExpiration(ctx, "k", "v", time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		offset := time.Duration(expected.Int63n(int64(time.Minute)))
+		if got := expiryOf(t, c, "k").Sub(clock.Now()); got != time.Hour+offset {
+			t.Errorf("write %d: expiration = %v, want 1h + %v", i, got, offset)
+		}
+	}
+}
+
+func TestLRUDefaultTTL(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		set        func(c *LRU) error
+		wantExpiry time.Duration
+	}{
+		{"Set without default", nil, func(c *LRU) error { return c.Set(context.Background(), "k", "v") }, 0},
+		{"Set with default", []Option{WithDefaultTTL(time.Hour)}, func(c *LRU) error {
+			return c.Set(context.Background(), "k", "v")
+		}, time.Hour},
+		{"explicit expiration kept", []Option{WithDefaultTTL(time.Hour)}, func(c *LRU) error {
+			return c.SetWithExpiration(context.Background(), "k", "v", time.Minute)
+		}, time.Minute},
+		{"explicit no expiration kept", []Option{WithDefaultTTL(time.Hour)}, func(c *LRU) error {
+			return c.SetWithExpiration(context.Background(), "k", "v", 0)
+		}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			c := NewLRU(0, append(tt.opts, WithClock(clock.Now))...)
+			if err := tt.set(c); err != nil {
+				t.Fatal(err)
+			}
+			expiresAt := expiryOf(t, c, "k")
+			if tt.wantExpiry == 0 {
+				if !expiresAt.IsZero() {
+					t.Errorf("expires at %v, want no expiration", expiresAt)
+				}
+				return
+			}
+			if got := expiresAt.Sub(clock.Now()); got != tt.wantExpiry {
+				t.Errorf("expiration = %v, want %v", got, tt.wantExpiry)
+			}
+		})
+	}
+}
+
+func TestLRUDefaultTTLExpires(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	c := NewLRU(0, WithDefaultTTL(time.Minute), WithClock(clock.Now))
+	if err := c.Set(ctx, "k", "v"); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Minute)
+	if _, err := c.Get(ctx, "k"); !errors.Is(err, cache.ErrCacheNil) {
+		t.Errorf("Get = %v, want ErrCacheNil", err)
+	}
+}
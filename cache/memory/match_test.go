@@ -0,0 +1,39 @@
+package memory
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		{"*", "", true},
+		{"*", "user:1", true},
+		{"user:*", "user:1", true},
+		{"user:*", "org:1", false},
+		{"*:1", "user:1", true},
+		{"u**1", "user:1", true},
+		{"user:?", "user:1", true},
+		{"user:?", "user:10", false},
+		{"user:?", "user:", false},
+		{"user:[12]", "user:2", true},
+		{"user:[12]", "user:3", false},
+		{"user:[a-c]", "user:b", true},
+		{"user:[a-c]", "user:d", false},
+		{"user:[^a]", "user:b", true},
+		{"user:[^a]", "user:a", false},
+		{"user:[12]", "user:", false},
+		{`user:\*`, "user:*", true},
+		{`user:\*`, "user:1", false},
+		{"user:1", "user:1", true},
+		{"user:1", "user:10", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.key, func(t *testing.T) {
+			if got := match(tt.pattern, tt.key); got != tt.want {
+				t.Errorf("match(%q, %q) = %v, want %v", tt.pattern, tt.key, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/cache"
+)
+
+// opKind identifies a command queued on a pipe.
+type opKind int
+
+const (
+	opSet opKind = iota
+	opDel
+	opIncr
+)
+
+// op is a command queued on a pipe.
+type op struct {
+	kind       opKind
+	keys       []string
+	value      string
+	expiration time.Duration
+}
+
+// pipe is the cache.Pipe passed to the function of LRU.Pipeline.
+// Values are encoded when queued; the first encoding error fails the pipeline.
+type pipe struct {
+	marshal cache.Marshaller
+	ops     []op
+	err     error
+}
+
+// Set queues storing value under key.
+func (p *pipe) Set(key string, value interface{}, expiration time.Duration) {
+	encoded, err := cache.EncodeValue(value, p.marshal)
+	if err != nil {
+		if p.err == nil {
+			p.err = fmt.Errorf("memory: encode %q: %w", key, err)
+		}
+		return
+	}
+	p.ops = append(p.ops, op{kind: opSet, keys: []string{key}, value: encoded, expiration: expiration})
+}
+
+// Del queues the deletion of keys.
+func (p *pipe) Del(keys ...string) {
+	p.ops = append(p.ops, op{kind: opDel, keys: append([]string(nil), keys...)})
+}
+
+// Incr queues incrementing the integer value stored under key.
+func (p *pipe) Incr(key string) {
+	p.ops = append(p.ops, op{kind: opIncr, keys: []string{key}})
+}
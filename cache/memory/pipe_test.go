@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/cache"
+)
+
+func TestLRUPipeline(t *testing.T) {
+	errAbort := errors.New("abort")
+	tests := []struct {
+		name       string
+		queue      func(p cache.Pipe) error
+		wantErr    bool
+		wantValues map[string]string
+	}{
+		{
+			name: "set, del and incr",
+			queue: func(p cache.Pipe) error {
+				p.Set("a", "1", 0)
+				p.Del("b")
+				p.Incr("n")
+				return nil
+			},
+			wantValues: map[string]string{"a": "1", "b": "", "n": "6"},
+		},
+		{
+			name: "incr missing key",
+			queue: func(p cache.Pipe) error {
+				p.Incr("missing")
+				p.Incr("missing")
+				return nil
+			},
+			wantValues: map[string]string{"missing": "2"},
+		},
+		{
+			name: "incr after del",
+			queue: func(p cache.Pipe) error {
+				p.Del("n")
+				p.Incr("n")
+				return nil
+			},
+			wantValues: map[string]string{"n": "1"},
+		},
+		{
+			name: "incr of a value set in the pipeline",
+			queue: func(p cache.Pipe) error {
+				p.Set("b", 41, 0)
+				p.Incr("b")
+				return nil
+			},
+			wantValues: map[string]string{"b": "42"},
+		},
+		{
+			name: "failing incr applies nothing",
+			queue: func(p cache.Pipe) error {
+				p.Set("a", "changed", 0)
+				p.Incr("b")
+				return nil
+			},
+			wantErr:    true,
+			wantValues: map[string]string{"a": "", "b": "text", "n": "5"},
+		},
+		{
+			name: "function error applies nothing",
+			queue: func(p cache.Pipe) error {
+				p.Set("a", "changed", 0)
+				return errAbort
+			},
+			wantErr:    true,
+			wantValues: map[string]string{"a": ""},
+		},
+		{
+			name: "encoding error applies nothing",
+			queue: func(p cache.Pipe) error {
+				p.Set("a", "changed", 0)
+				p.Set("c", make(chan int), 0)
+				return nil
+			},
+			wantErr:    true,
+			wantValues: map[string]string{"a": "", "c": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := NewLRU(0)
+			for key, value := range map[string]string{"b": "text", "n": "5"} {
+				if err := c.Set(ctx, key, value); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := c.Pipeline(ctx, tt.queue); (err != nil) != tt.wantErr {
+				t.Fatalf("Pipeline: err = %v, want error %v", err, tt.wantErr)
+			}
+			for key, want := range tt.wantValues {
+				got, err := c.Get(ctx, key)
+				if want == "" {
+					if !errors.Is(err, cache.ErrCacheNil) {
+						t.Errorf("Get(%q) = %q, %v, want ErrCacheNil", key, got, err)
+					}
+					continue
+				}
+				if err != nil || got != want {
+					t.Errorf("Get(%q) = %q, %v, want %q", key, got, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLRUPipelineIncrKeepsExpiration(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	c := NewLRU(0, WithClock(clock.Now))
+	if err := c.SetWithExpiration(ctx, "n", 1, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Pipeline(ctx, func(p cache.Pipe) error { p.Incr("n"); return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := c.Get(ctx, "n"); err != nil || got != "2" {
+		t.Fatalf("Get = %q, %v, want 2", got, err)
+	}
+	clock.Advance(time.Minute)
+	if _, err := c.Get(ctx, "n"); !errors.Is(err, cache.ErrCacheNil) {
+		t.Errorf("incremented entry did not expire: err = %v", err)
+	}
+}
+
+func TestLRUPipelineClosed(t *testing.T) {
+	c := NewLRU(0)
+	_ = c.Close()
+	err := c.Pipeline(context.Background(), func(p cache.Pipe) error {
+		p.Set("a", "1", 0)
+		return nil
+	})
+	if !errors.Is(err, cache.ErrCacheClosed) {
+		t.Errorf("err = %v, want ErrCacheClosed", err)
+	}
+}
@@ -0,0 +1,73 @@
+package cache_test
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/zeroxsolutions/barbatos/cache"
+)
+
+// notifyingCache is a cache.Cache implementing cache.Notifier whose connection state is
+// flipped by the test with setConnected.
+type notifyingCache struct {
+	cache.Cache
+
+	mu        sync.Mutex
+	connected bool
+	callbacks []func(connected bool)
+}
+
+func (c *notifyingCache) OnStateChange(fn func(connected bool)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.callbacks = append(c.callbacks, fn)
+}
+
+// setConnected changes the connection state and notifies the registered callbacks when it
+// differs from the current one.
+func (c *notifyingCache) setConnected(connected bool) {
+	c.mu.Lock()
+	if c.connected == connected {
+		c.mu.Unlock()
+		return
+	}
+	c.connected = connected
+	callbacks := append([]func(bool){}, c.callbacks...)
+	c.mu.Unlock()
+	for _, fn := range callbacks {
+		fn(connected)
+	}
+}
+
+func TestNotifier(t *testing.T) {
+	tests := []struct {
+		name  string
+		flips []bool
+		want  []bool
+	}{
+		{name: "connect", flips: []bool{true}, want: []bool{true}},
+		{name: "connect then lose", flips: []bool{true, false}, want: []bool{true, false}},
+		{name: "repeated states", flips: []bool{true, true, false, false, true}, want: []bool{true, false, true}},
+		{name: "no transition", flips: []bool{false}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c cache.Cache = &notifyingCache{}
+			notifier, ok := c.(cache.Notifier)
+			if !ok {
+				t.Fatal("cache does not implement cache.Notifier")
+			}
+			var got []bool
+			notifier.OnStateChange(func(connected bool) {
+				got = append(got, connected)
+			})
+			for _, connected := range tt.flips {
+				c.(*notifyingCache).setConnected(connected)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("callback states = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package cache
+
+import "time"
+
+// Pipe queues cache commands to be executed together by Cache.Pipeline.
+// Commands are only recorded when called; they are sent in a single round-trip and
+// applied atomically (e.g. with Redis MULTI/EXEC) once the pipeline function returns.
+type Pipe interface {
+	// Set queues storing value under key with the given expiration (0 means no expiration).
+	Set(key string, value interface{}, expiration time.Duration)
+
+	// Del queues the deletion of the specified keys.
+	Del(keys ...string)
+
+	// Incr queues incrementing the integer value stored under key by one.
+	// A missing key is treated as 0 before the increment.
+	Incr(key string)
+}
+
+// serializedPipe is a Pipe decorator that applies a KeySerializer to every key.
+type serializedPipe struct {
+	pipe  Pipe
+	cache *SerializedKeyCache
+}
+
+// Set serializes key and delegates to the underlying Pipe.
+func (p *serializedPipe) Set(key string, value interface{}, expiration time.Duration) {
+	p.pipe.Set(p.cache.serializer(key), value, expiration)
+}
+
+// Del serializes keys and delegates to the underlying Pipe.
+func (p *serializedPipe) Del(keys ...string) {
+	stored := make([]string, len(keys))
+	for i, key := range keys {
+		stored[i] = p.cache.serializer(key)
+	}
+	p.pipe.Del(stored...)
+}
+
+// Incr serializes key and delegates to the underlying Pipe.
+func (p *serializedPipe) Incr(key string) {
+	p.pipe.Incr(p.cache.serializer(key))
+}
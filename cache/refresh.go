@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRefreshTimeout is the default bound of the loader calls made by
+// Refresher.GetWithRefresh.
+const DefaultRefreshTimeout = 30 * time.Second
+
+// refreshCall is an in-flight load shared by the GetWithRefresh calls for the same key.
+type refreshCall struct {
+	done  chan struct{}
+	value string
+	err   error
+}
+
+// Refresher serves the values of a Cache while refreshing them in the background
+// (stale-while-revalidate). Loads are single-flighted per key among the calls made through
+// the same Refresher, so a Refresher should be shared by every caller reading the same keys.
+type Refresher struct {
+	cache   Cache
+	timeout time.Duration
+	now     func() time.Time
+
+	mu    sync.Mutex
+	calls map[string]*refreshCall
+}
+
+// RefresherOption configures a Refresher.
+type RefresherOption func(*Refresher)
+
+// WithRefreshTimeout sets the bound of the loader calls. Loads are detached from the caller's
+// context, since a background refresh outlives the call that triggered it. It defaults to
+// DefaultRefreshTimeout.
+func WithRefreshTimeout(d time.Duration) RefresherOption {
+	return func(r *Refresher) {
+		r.timeout = d
+	}
+}
+
+// WithRefreshClock sets the function returning the current time, against which the load time
+// of the values is recorded and their age computed, e.g. a fake clock advanced by tests. It
+// defaults to time.Now.
+func WithRefreshClock(now func() time.Time) RefresherOption {
+	return func(r *Refresher) {
+		r.now = now
+	}
+}
+
+// NewRefresher creates a Refresher reading and writing the values of c.
+//
+//	profiles := cache.NewRefresher(c)
+func NewRefresher(c Cache, opts ...RefresherOption) *Refresher {
+	r := &Refresher{cache: c, timeout: DefaultRefreshTimeout, now: time.Now, calls: make(map[string]*refreshCall)}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// GetWithRefresh returns the value cached under key, serving stale values while they are
+// refreshed in the background:
+//
+//   - a value stored less than softTTL ago is returned as is;
+//   - an older value is returned immediately, and loader is run in the background to replace it;
+//   - if there is no value, or it was stored hardTTL ago or more, loader is run and the call
+//     blocks until it returns or ctx is done.
+//
+// Values are stored with their load time and expire from the cache after hardTTL, so keys used
+// with GetWithRefresh must not be read or written with the Cache methods. Concurrent calls for
+// the same key share a single loader call. A failed background refresh is dropped and the
+// stale value keeps being served until it reaches hardTTL; a failed blocking load returns the
+// loader's error.
+//
+//	profile, err := profiles.GetWithRefresh(ctx, "profile:"+id, time.Minute, time.Hour,
+//		func(ctx context.Context) (string, error) {
+//			return loadProfileJSON(ctx, id)
+//		})
+func (r *Refresher) GetWithRefresh(ctx context.Context, key string, softTTL, hardTTL time.Duration, loader func(ctx context.Context) (string, error)) (string, error) {
+	stored, err := r.cache.Get(ctx, key)
+	if err != nil && !errors.Is(err, ErrCacheNil) {
+		return "", err
+	}
+	if err == nil {
+		if loadedAt, value, ok := decodeRefreshed(stored); ok {
+			age := r.now().Sub(loadedAt)
+			if age < hardTTL {
+				if age >= softTTL {
+					r.startLoad(key, hardTTL, loader)
+				}
+				return value, nil
+			}
+		}
+	}
+
+	call := r.startLoad(key, hardTTL, loader)
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-call.done:
+		return call.value, call.err
+	}
+}
+
+// startLoad starts loading key with loader and storing the result in the cache, or joins the
+// load of key in flight.
+func (r *Refresher) startLoad(key string, hardTTL time.Duration, loader func(ctx context.Context) (string, error)) *refreshCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if call, ok := r.calls[key]; ok {
+		return call
+	}
+	call := &refreshCall{done: make(chan struct{})}
+	r.calls[key] = call
+
+	go func() {
+		defer func() {
+			r.mu.Lock()
+			delete(r.calls, key)
+			r.mu.Unlock()
+			close(call.done)
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+		defer cancel()
+		loadedAt := r.now()
+		value, err := loader(ctx)
+		if err != nil {
+			call.err = err
+			return
+		}
+		call.value = value
+		call.err = r.cache.SetWithExpiration(ctx, key, encodeRefreshed(loadedAt, value), hardTTL)
+	}()
+	return call
+}
+
+// encodeRefreshed prefixes value with its load time.
+func encodeRefreshed(loadedAt time.Time, value string) string {
+	return strconv.FormatInt(loadedAt.UnixNano(), 10) + ":" + value
+}
+
+// decodeRefreshed splits a value stored by GetWithRefresh into its load time and value.
+// It reports false if stored was not written by GetWithRefresh.
+func decodeRefreshed(stored string) (time.Time, string, bool) {
+	prefix, value, ok := strings.Cut(stored, ":")
+	if !ok {
+		return time.Time{}, "", false
+	}
+	nanos, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return time.Unix(0, nanos), value, true
+}
@@ -0,0 +1,221 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/cache"
+	"github.com/zeroxsolutions/barbatos/cache/memory"
+)
+
+var errLoad = errors.New("load failed")
+
+// countingLoader returns a loader returning "v<n>" on its n-th call, or the error of that
+// call in errs if any.
+func countingLoader(calls *int32, errs map[int32]error) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		n := atomic.AddInt32(calls, 1)
+		if err := errs[n]; err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("v%d", n), nil
+	}
+}
+
+// waitCalls waits until calls reaches want, letting a background refresh run.
+func waitCalls(t *testing.T, calls *int32, want int32) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(calls) < want {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d loader calls, want %d", atomic.LoadInt32(calls), want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// storeSignalingCache is a Cache signaling on stored after each SetWithExpiration, so tests
+// can wait for a background refresh to store its value.
+type storeSignalingCache struct {
+	cache.Cache
+	stored chan struct{}
+}
+
+func (c storeSignalingCache) SetWithExpiration(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	err := c.Cache.SetWithExpiration(ctx, key, value, expiration)
+	c.stored <- struct{}{}
+	return err
+}
+
+func TestRefresher(t *testing.T) {
+	const softTTL, hardTTL = time.Minute, time.Hour
+	tests := []struct {
+		name      string
+		errs      map[int32]error
+		advance   time.Duration
+		wantFirst string
+		wantNext  string
+		wantErr   error
+		wantCalls int32
+	}{
+		{"fresh value", nil, softTTL - time.Second, "v1", "v1", nil, 1},
+		{"stale value refreshed", nil, softTTL, "v1", "v2", nil, 2},
+		{"failed refresh keeps stale value", map[int32]error{2: errLoad}, softTTL, "v1", "v1", nil, 2},
+		{"expired value reloaded", nil, hardTTL, "v2", "v2", nil, 2},
+		{"failed blocking load", map[int32]error{2: errLoad}, hardTTL, "", "", errLoad, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			clock, m := newTestClock(), newMapCache()
+			c := storeSignalingCache{Cache: m, stored: make(chan struct{}, 4)}
+			r := cache.NewRefresher(c, cache.WithRefreshClock(clock.Now))
+			var calls int32
+			loader := countingLoader(&calls, tt.errs)
+			if got, err := r.GetWithRefresh(ctx, "k", softTTL, hardTTL, loader); err != nil || got != "v1" {
+				t.Fatalf("initial load = %q, %v, want v1, nil", got, err)
+			}
+			clock.Advance(tt.advance)
+			m.advance(tt.advance)
+
+			got, err := r.GetWithRefresh(ctx, "k", softTTL, hardTTL, loader)
+			if !errors.Is(err, tt.wantErr) || got != tt.wantFirst {
+				t.Fatalf("GetWithRefresh = %q, %v, want %q, %v", got, err, tt.wantFirst, tt.wantErr)
+			}
+			waitCalls(t, &calls, tt.wantCalls)
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("loader calls = %d, want %d", got, tt.wantCalls)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			// Wait until every successful load, including a background refresh, has stored its value.
+			for i := int32(0); i < tt.wantCalls-int32(len(tt.errs)); i++ {
+				select {
+				case <-c.stored:
+				case <-time.After(time.Second):
+					t.Fatalf("%d values stored, want %d", i, tt.wantCalls-int32(len(tt.errs)))
+				}
+			}
+			if got, err := r.GetWithRefresh(ctx, "k", softTTL, hardTTL, loader); err != nil || got != tt.wantNext {
+				t.Errorf("next GetWithRefresh = %q, %v, want %q, nil", got, err, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestRefresherSingleFlight(t *testing.T) {
+	ctx := context.Background()
+	c := memory.NewLRU(0)
+	r := cache.NewRefresher(c)
+	gate := make(chan struct{})
+	var calls int32
+	loader := func(ctx context.Context) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		<-gate
+		return "v", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan string, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := r.GetWithRefresh(ctx, "k", time.Minute, time.Hour, loader)
+			if err != nil {
+				t.Error(err)
+			}
+			results <- value
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(gate)
+	wg.Wait()
+	close(results)
+
+	for value := range results {
+		if value != "v" {
+			t.Errorf("value = %q, want v", value)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("loader calls = %d, want 1", got)
+	}
+}
+
+func TestRefresherCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := memory.NewLRU(0)
+	r := cache.NewRefresher(c)
+	gate := make(chan struct{})
+	defer close(gate)
+	loader := func(ctx context.Context) (string, error) {
+		<-gate
+		return "v", nil
+	}
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := r.GetWithRefresh(ctx, "k", time.Minute, time.Hour, loader); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestRefresherTimeout(t *testing.T) {
+	r := cache.NewRefresher(memory.NewLRU(0), cache.WithRefreshTimeout(time.Millisecond))
+	loader := func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	if _, err := r.GetWithRefresh(context.Background(), "k", time.Minute, time.Hour, loader); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestRefresherForeignValue(t *testing.T) {
+	ctx := context.Background()
+	c := memory.NewLRU(0)
+	r := cache.NewRefresher(c)
+	// A value not written by GetWithRefresh is treated as missing.
+	if err := c.Set(ctx, "k", "plain"); err != nil {
+		t.Fatal(err)
+	}
+	var calls int32
+	got, err := r.GetWithRefresh(ctx, "k", time.Minute, time.Hour, countingLoader(&calls, nil))
+	if err != nil || got != "v1" {
+		t.Errorf("GetWithRefresh = %q, %v, want v1, nil", got, err)
+	}
+}
+
+func TestRefresherClosedCache(t *testing.T) {
+	c := memory.NewLRU(0)
+	r := cache.NewRefresher(c)
+	_ = c.Close()
+	var calls int32
+	_, err := r.GetWithRefresh(context.Background(), "k", time.Minute, time.Hour, countingLoader(&calls, nil))
+	if !errors.Is(err, cache.ErrCacheClosed) || calls != 0 {
+		t.Errorf("err = %v after %d loads, want ErrCacheClosed without loading", err, calls)
+	}
+}
+
+// taggedCache is a Cache value that is not comparable, since it holds a slice.
+type taggedCache struct {
+	cache.Cache
+	tags []string
+}
+
+func TestRefresherNonComparableCache(t *testing.T) {
+	r := cache.NewRefresher(taggedCache{Cache: memory.NewLRU(0), tags: []string{"profiles"}})
+	var calls int32
+	got, err := r.GetWithRefresh(context.Background(), "k", time.Minute, time.Hour, countingLoader(&calls, nil))
+	if err != nil || got != "v1" {
+		t.Errorf("GetWithRefresh = %q, %v, want v1, nil", got, err)
+	}
+}
@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// KeySerializer transforms a caller-facing key into the key actually stored in the cache system.
+// It can be used to enforce key conventions, such as hashing long keys or keys containing
+// sensitive data. A KeySerializer must be deterministic.
+type KeySerializer func(key string) string
+
+// IdentityKeySerializer is the default KeySerializer. It returns keys unchanged.
+func IdentityKeySerializer(key string) string {
+	return key
+}
+
+// SHA256KeySerializer returns a KeySerializer that replaces keys longer than maxLen bytes
+// with the hex-encoded SHA-256 digest of the key. Shorter keys are returned unchanged.
+// A maxLen of 0 hashes every key, which is useful for keys containing personal data.
+func SHA256KeySerializer(maxLen int) KeySerializer {
+	return func(key string) string {
+		if maxLen > 0 && len(key) <= maxLen {
+			return key
+		}
+		sum := sha256.Sum256([]byte(key))
+		return hex.EncodeToString(sum[:])
+	}
+}
+
+// SerializedKeyCache is a Cache decorator that applies a KeySerializer to every key
+// before delegating to the underlying Cache.
+//
+// Patterns passed to Keys and DelWithPattern are matched against the stored (serialized)
+// keys, since a serializer such as a hash cannot be applied to a pattern, and Keys returns
+// the stored keys: a serializer cannot be reversed, and remembering every original key would
+// grow without bound in a long-running process. Stored keys that the serializer changed must
+// therefore not be passed back to Get or Del; delete them with DelWithPattern instead.
+type SerializedKeyCache struct {
+	cache      Cache
+	serializer KeySerializer
+}
+
+// NewSerializedKeyCache creates a SerializedKeyCache that wraps c and applies serializer
+// to every key. A nil serializer defaults to IdentityKeySerializer.
+//
+//	c = cache.NewSerializedKeyCache(c, cache.SHA256KeySerializer(128))
+func NewSerializedKeyCache(c Cache, serializer KeySerializer) *SerializedKeyCache {
+	if serializer == nil {
+		serializer = IdentityKeySerializer
+	}
+	return &SerializedKeyCache{cache: c, serializer: serializer}
+}
+
+// IsConnected delegates to the underlying Cache.
+func (s *SerializedKeyCache) IsConnected(ctx context.Context) bool {
+	return s.cache.IsConnected(ctx)
+}
+
+// CheckConnection delegates to the underlying Cache.
+func (s *SerializedKeyCache) CheckConnection(ctx context.Context) error {
+	return s.cache.CheckConnection(ctx)
+}
+
+// Keys delegates to the underlying Cache. It returns the stored keys matching pattern.
+func (s *SerializedKeyCache) Keys(ctx context.Context, pattern string) ([]string, error) {
+	return s.cache.Keys(ctx, pattern)
+}
+
+// Get serializes key and delegates to the underlying Cache.
+func (s *SerializedKeyCache) Get(ctx context.Context, key string) (string, error) {
+	return s.cache.Get(ctx, s.serializer(key))
+}
+
+// Set serializes key and delegates to the underlying Cache.
+func (s *SerializedKeyCache) Set(ctx context.Context, key string, value interface{}) error {
+	return s.cache.Set(ctx, s.serializer(key), value)
+}
+
+// SetWithExpiration serializes key and delegates to the underlying Cache.
+func (s *SerializedKeyCache) SetWithExpiration(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	return s.cache.SetWithExpiration(ctx, s.serializer(key), value, expiration)
+}
+
+// SetNX serializes key and delegates to the underlying Cache.
+func (s *SerializedKeyCache) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return s.cache.SetNX(ctx, s.serializer(key), value, expiration)
+}
+
+// CompareAndSwap serializes key and delegates to the underlying Cache.
+func (s *SerializedKeyCache) CompareAndSwap(ctx context.Context, key, oldValue, newValue string, expiration time.Duration) (bool, error) {
+	return s.cache.CompareAndSwap(ctx, s.serializer(key), oldValue, newValue, expiration)
+}
+
+// Del serializes keys and delegates to the underlying Cache.
+func (s *SerializedKeyCache) Del(ctx context.Context, keys ...string) error {
+	stored := make([]string, len(keys))
+	for i, key := range keys {
+		stored[i] = s.serializer(key)
+	}
+	return s.cache.Del(ctx, stored...)
+}
+
+// DelWithPattern delegates to the underlying Cache. The pattern is matched against stored keys.
+func (s *SerializedKeyCache) DelWithPattern(ctx context.Context, pattern string) error {
+	return s.cache.DelWithPattern(ctx, pattern)
+}
+
+// DelWithPatternCount delegates to the underlying Cache. The pattern is matched against stored keys.
+func (s *SerializedKeyCache) DelWithPatternCount(ctx context.Context, pattern string) (int64, error) {
+	return s.cache.DelWithPatternCount(ctx, pattern)
+}
+
+// Pipeline delegates to the underlying Cache, serializing the keys of the queued commands.
+func (s *SerializedKeyCache) Pipeline(ctx context.Context, fn func(p Pipe) error) error {
+	return s.cache.Pipeline(ctx, func(p Pipe) error {
+		return fn(&serializedPipe{pipe: p, cache: s})
+	})
+}
+
+// Close delegates to the underlying Cache.
+func (s *SerializedKeyCache) Close() error {
+	return s.cache.Close()
+}
@@ -0,0 +1,164 @@
+package cache_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/cache"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestKeySerializers(t *testing.T) {
+	long := strings.Repeat("k", 20)
+	tests := []struct {
+		name       string
+		serializer cache.KeySerializer
+		key        string
+		want       string
+	}{
+		{"identity", cache.IdentityKeySerializer, "user:1", "user:1"},
+		{"sha256 short key", cache.SHA256KeySerializer(10), "user:1", "user:1"},
+		{"sha256 key at limit", cache.SHA256KeySerializer(6), "user:1", "user:1"},
+		{"sha256 long key", cache.SHA256KeySerializer(10), long, sha256Hex(long)},
+		{"sha256 every key", cache.SHA256KeySerializer(0), "user:1", sha256Hex("user:1")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.serializer(tt.key); got != tt.want {
+				t.Errorf("serializer(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSerializedKeyCache(t *testing.T) {
+	ctx := context.Background()
+	lru := newMapCache()
+	c := cache.NewSerializedKeyCache(lru, cache.SHA256KeySerializer(0))
+
+	if err := c.Set(ctx, "user:1", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := c.SetNX(ctx, "user:2", "bob", time.Minute); err != nil || !ok {
+		t.Fatalf("SetNX = %v, %v, want true, nil", ok, err)
+	}
+
+	// The underlying cache only sees the serialized keys.
+	if got, err := lru.Get(ctx, sha256Hex("user:1")); err != nil || got != "alice" {
+		t.Fatalf("underlying Get = %q, %v, want alice, nil", got, err)
+	}
+	if got, err := c.Get(ctx, "user:1"); err != nil || got != "alice" {
+		t.Fatalf("Get = %q, %v, want alice, nil", got, err)
+	}
+	if ok, err := c.CompareAndSwap(ctx, "user:2", "bob", "carol", 0); err != nil || !ok {
+		t.Fatalf("CompareAndSwap = %v, %v, want true, nil", ok, err)
+	}
+
+	// Keys returns the stored keys.
+	keys, err := c.Keys(ctx, "*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(keys)
+	want := []string{sha256Hex("user:1"), sha256Hex("user:2")}
+	sort.Strings(want)
+	if strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Errorf("Keys = %v, want %v", keys, want)
+	}
+
+	if err := c.Del(ctx, "user:1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(ctx, "user:1"); !errors.Is(err, cache.ErrCacheNil) {
+		t.Errorf("Get after Del: err = %v, want ErrCacheNil", err)
+	}
+	if got, err := c.Get(ctx, "user:2"); err != nil || got != "carol" {
+		t.Errorf("Get = %q, %v, want carol, nil", got, err)
+	}
+}
+
+func TestSerializedKeyCacheDelWithPatternCount(t *testing.T) {
+	ctx := context.Background()
+	c := cache.NewSerializedKeyCache(newMapCache(), func(key string) string { return "app:" + key })
+	for _, key := range []string{"user:1", "user:2", "order:1"} {
+		if err := c.Set(ctx, key, "x"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Patterns match stored keys.
+	tests := []struct {
+		pattern string
+		want    int64
+	}{
+		{"user:*", 0},
+		{"app:user:*", 2},
+		{"app:*", 1},
+	}
+	for _, tt := range tests {
+		n, err := c.DelWithPatternCount(ctx, tt.pattern)
+		if err != nil || n != tt.want {
+			t.Errorf("DelWithPatternCount(%q) = %d, %v, want %d, nil", tt.pattern, n, err, tt.want)
+		}
+	}
+}
+
+func TestSerializedKeyCachePipeline(t *testing.T) {
+	ctx := context.Background()
+	lru := newMapCache()
+	c := cache.NewSerializedKeyCache(lru, func(key string) string { return "app:" + key })
+
+	err := c.Pipeline(ctx, func(p cache.Pipe) error {
+		p.Set("hits", 1, 0)
+		p.Incr("hits")
+		p.Set("stale", "x", 0)
+		p.Del("stale")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := lru.Get(ctx, "app:hits"); err != nil || got != "2" {
+		t.Errorf("underlying Get = %q, %v, want 2, nil", got, err)
+	}
+	if _, err := lru.Get(ctx, "app:stale"); !errors.Is(err, cache.ErrCacheNil) {
+		t.Errorf("underlying Get of deleted key: err = %v, want ErrCacheNil", err)
+	}
+
+	// Patterns match stored keys.
+	n, err := c.DelWithPatternCount(ctx, "app:*")
+	if err != nil || n != 1 {
+		t.Errorf("DelWithPatternCount = %d, %v, want 1, nil", n, err)
+	}
+}
+
+func TestSerializedKeyCacheCheckConnection(t *testing.T) {
+	errDown := errors.New("connection refused")
+	tests := []struct {
+		name string
+		down error
+	}{
+		{"reachable", nil},
+		{"unreachable", errDown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			underlying := newMapCache()
+			underlying.down = tt.down
+			c := cache.NewSerializedKeyCache(underlying, nil)
+			if err := c.CheckConnection(context.Background()); !errors.Is(err, tt.down) {
+				t.Errorf("CheckConnection = %v, want %v", err, tt.down)
+			}
+		})
+	}
+}
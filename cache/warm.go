@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultWarmConcurrency is the default maximum number of concurrent writes issued by Warm.
+const DefaultWarmConcurrency = 8
+
+// WarmOption configures a Warm call.
+type WarmOption func(*warmConfig)
+
+// warmConfig holds the settings of a Warm call.
+type warmConfig struct {
+	concurrency int
+}
+
+// WithWarmConcurrency sets the maximum number of concurrent writes issued by Warm.
+// It defaults to DefaultWarmConcurrency; values below 1 mean 1.
+func WithWarmConcurrency(n int) WarmOption {
+	return func(c *warmConfig) {
+		c.concurrency = n
+	}
+}
+
+// WarmEntry is a value to preload into the cache system with Warm.
+type WarmEntry struct {
+	// Value is the value to store.
+	Value interface{}
+	// TTL is the expiration of the entry; 0 means no expiration.
+	TTL time.Duration
+}
+
+// Warm preloads entries into c, e.g. right after a deploy to avoid a stampede of cache
+// misses on the database. Entries are written concurrently, with at most the number of writes
+// set by WithWarmConcurrency in flight, using SetNX so that keys which already exist are left
+// untouched.
+//
+// Warm writes every entry it can and returns the first error encountered, annotated with
+// its key. If ctx is done, the remaining entries are skipped and ctx.Err() is returned.
+//
+//	err := cache.Warm(ctx, c, map[string]cache.WarmEntry{
+//		"config:features": {Value: features, TTL: time.Hour},
+//	})
+func Warm(ctx context.Context, c Cache, entries map[string]WarmEntry, opts ...WarmOption) error {
+	config := warmConfig{concurrency: DefaultWarmConcurrency}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	concurrency := config.concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		sem      = make(chan struct{}, concurrency)
+	)
+	setErr := func(err error) {
+		mu.Lock()
+		if firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+	}
+
+	for key, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			setErr(err)
+			break
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(key string, entry WarmEntry) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if _, err := c.SetNX(ctx, key, entry.Value, entry.TTL); err != nil {
+				setErr(fmt.Errorf("cache: warm key %q: %w", key, err))
+			}
+		}(key, entry)
+	}
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,130 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/cache"
+)
+
+var errWrite = errors.New("write failed")
+
+// warmCache is an in-memory cache whose SetNX fails for the keys in failing, tracking the
+// maximum number of concurrent SetNX calls.
+type warmCache struct {
+	*mapCache
+	failing map[string]bool
+
+	mu     sync.Mutex
+	active int
+	peak   int
+}
+
+func (c *warmCache) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	c.mu.Lock()
+	c.active++
+	if c.active > c.peak {
+		c.peak = c.active
+	}
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.active--
+		c.mu.Unlock()
+	}()
+	time.Sleep(time.Millisecond)
+
+	if c.failing[key] {
+		return false, errWrite
+	}
+	return c.mapCache.SetNX(ctx, key, value, expiration)
+}
+
+func TestWarm(t *testing.T) {
+	ctx := context.Background()
+	c := &warmCache{mapCache: newMapCache(), failing: map[string]bool{"broken": true}}
+	if err := c.mapCache.Set(ctx, "existing", "kept"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := map[string]cache.WarmEntry{
+		"existing": {Value: "overwritten"},
+		"broken":   {Value: "x"},
+		"short":    {Value: "s", TTL: time.Minute},
+	}
+	for i := 0; i < 20; i++ {
+		entries[fmt.Sprint("key", i)] = cache.WarmEntry{Value: i}
+	}
+
+	err := cache.Warm(ctx, c, entries)
+	if !errors.Is(err, errWrite) {
+		t.Fatalf("err = %v, want %v", err, errWrite)
+	}
+	if !strings.HasPrefix(err.Error(), `cache: warm key "broken"`) {
+		t.Errorf("err = %v, want it annotated with the key", err)
+	}
+	if c.peak > cache.DefaultWarmConcurrency {
+		t.Errorf("peak concurrency = %d, want at most %d", c.peak, cache.DefaultWarmConcurrency)
+	}
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"existing", "kept"},
+		{"key0", "0"},
+		{"key19", "19"},
+		{"short", "s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got, err := c.Get(ctx, tt.key); err != nil || got != tt.want {
+				t.Errorf("Get = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+	c.advance(time.Minute)
+	if _, err := c.Get(ctx, "short"); !errors.Is(err, cache.ErrCacheNil) {
+		t.Errorf("entry with TTL did not expire: err = %v", err)
+	}
+}
+
+func TestWarmConcurrency(t *testing.T) {
+	for _, concurrency := range []int{0, 1, 3} {
+		t.Run(fmt.Sprint(concurrency), func(t *testing.T) {
+			c := &warmCache{mapCache: newMapCache()}
+			entries := make(map[string]cache.WarmEntry)
+			for i := 0; i < 10; i++ {
+				entries[fmt.Sprint("key", i)] = cache.WarmEntry{Value: i}
+			}
+			if err := cache.Warm(context.Background(), c, entries, cache.WithWarmConcurrency(concurrency)); err != nil {
+				t.Fatal(err)
+			}
+			want := concurrency
+			if want < 1 {
+				want = 1
+			}
+			if c.peak > want {
+				t.Errorf("peak concurrency = %d, want at most %d", c.peak, want)
+			}
+		})
+	}
+}
+
+func TestWarmCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c := &warmCache{mapCache: newMapCache()}
+	err := cache.Warm(ctx, c, map[string]cache.WarmEntry{"a": {Value: 1}, "b": {Value: 2}})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if keys, _ := c.Keys(context.Background(), "*"); len(keys) != 0 {
+		t.Errorf("keys written after cancellation: %v", keys)
+	}
+}
@@ -0,0 +1,286 @@
+package log
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy defines what an AsyncLogger does when its queue is full.
+type OverflowPolicy int
+
+const (
+	// BlockOnFull makes the logging call wait until the queue has room.
+	// No entry is lost, but a slow underlying Logger slows down the caller.
+	BlockOnFull OverflowPolicy = iota
+	// DropOnFull discards the entry and counts it, so the caller is never slowed down.
+	DropOnFull
+)
+
+// DefaultAsyncQueueSize is the queue size used by NewAsyncLogger when size is below 1.
+const DefaultAsyncQueueSize = 1024
+
+// DefaultAsyncFlushTimeout is the default bound of a flush of an AsyncLogger.
+const DefaultAsyncFlushTimeout = 5 * time.Second
+
+// AsyncOption configures an AsyncLogger.
+type AsyncOption func(*AsyncLogger)
+
+// WithFlushTimeout sets how long Flush, and the Panic* and Fatal* calls flushing the queue,
+// wait for the queued entries to be written. It defaults to DefaultAsyncFlushTimeout.
+func WithFlushTimeout(d time.Duration) AsyncOption {
+	return func(l *AsyncLogger) {
+		l.flushTimeout = d
+	}
+}
+
+// AsyncLogger is a Logger wrapper that enqueues entries to a bounded queue drained by a
+// background goroutine delegating to the underlying Logger, taking logging cost off the
+// hot path. Entries are written in the order they were enqueued.
+//
+// Panic* and Fatal* entries are not queued: the queue is flushed and the entry is written
+// synchronously, so the panic happens in the caller's goroutine and nothing is lost before
+// the program exits. The flush is bounded by the flush timeout, so a stalled underlying
+// Logger delays these calls by at most that timeout instead of preventing the exit. Entries logged after Close are dropped and counted.
+//
+// The args and keysValues slices are copied when an entry is queued, but the values they hold
+// are written later from the background goroutine: pointers, maps and slices passed as values
+// must not be mutated after the logging call.
+type AsyncLogger struct {
+	// dropped is accessed atomically and kept first so that it is 64-bit aligned on 32-bit
+	// platforms.
+	dropped int64
+
+	logger       Logger
+	policy       OverflowPolicy
+	flushTimeout time.Duration
+	queue        chan func()
+	done         chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewAsyncLogger creates an AsyncLogger delegating to logger with a queue of size entries
+// (DefaultAsyncQueueSize if size is below 1), configured by opts, and starts its background
+// goroutine. Close must be called to write the remaining entries and stop the goroutine.
+//
+//	logger := log.NewAsyncLogger(base, 4096, log.DropOnFull)
+//	defer logger.Close()
+func NewAsyncLogger(logger Logger, size int, policy OverflowPolicy, opts ...AsyncOption) *AsyncLogger {
+	if size < 1 {
+		size = DefaultAsyncQueueSize
+	}
+	l := &AsyncLogger{
+		logger:       logger,
+		policy:       policy,
+		flushTimeout: DefaultAsyncFlushTimeout,
+		queue:        make(chan func(), size),
+		done:         make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	go l.run()
+	return l
+}
+
+// run writes the queued entries until the queue is closed.
+func (l *AsyncLogger) run() {
+	defer close(l.done)
+	for write := range l.queue {
+		write()
+	}
+}
+
+// enqueue queues write according to the overflow policy, or drops it if the logger is closed.
+func (l *AsyncLogger) enqueue(write func()) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.closed {
+		atomic.AddInt64(&l.dropped, 1)
+		return
+	}
+	if l.policy == DropOnFull {
+		select {
+		case l.queue <- write:
+		default:
+			atomic.AddInt64(&l.dropped, 1)
+		}
+		return
+	}
+	l.queue <- write
+}
+
+// copyArgs returns a copy of args, so that a queued entry does not share the backing array
+// of a variadic slice the caller may reuse.
+func copyArgs(args []interface{}) []interface{} {
+	return append([]interface{}(nil), args...)
+}
+
+// Flush blocks until every entry enqueued before the call has been written, or until the
+// flush timeout has passed, and reports whether the entries were written in time.
+func (l *AsyncLogger) Flush() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), l.flushTimeout)
+	defer cancel()
+	return l.flush(ctx)
+}
+
+// flush waits until every entry enqueued before the call has been written, and reports
+// whether they were before ctx is done.
+func (l *AsyncLogger) flush(ctx context.Context) bool {
+	l.mu.RLock()
+	if l.closed {
+		l.mu.RUnlock()
+		return true
+	}
+	flushed := make(chan struct{})
+	select {
+	case l.queue <- func() { close(flushed) }:
+	case <-ctx.Done():
+		l.mu.RUnlock()
+		return false
+	}
+	l.mu.RUnlock()
+
+	select {
+	case <-flushed:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Close writes the remaining entries and stops the background goroutine.
+// It does not close the underlying Logger. Calling Close more than once is a no-op.
+func (l *AsyncLogger) Close() error {
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		close(l.queue)
+	}
+	l.mu.Unlock()
+	<-l.done
+	return nil
+}
+
+// Dropped returns the number of entries discarded because the queue was full or the
+// logger was closed.
+func (l *AsyncLogger) Dropped() int64 {
+	return atomic.LoadInt64(&l.dropped)
+}
+
+// Enabled reports whether the underlying Logger emits entries of the given level.
+// It lets callers skip enqueueing entries that would be discarded anyway.
+func (l *AsyncLogger) Enabled(level Level) bool {
+	return Enabled(l.logger, level)
+}
+
+// Debug enqueues the entry for the underlying Logger.
+func (l *AsyncLogger) Debug(args ...interface{}) {
+	args = copyArgs(args)
+	l.enqueue(func() { l.logger.Debug(args...) })
+}
+
+// Debugf enqueues the entry for the underlying Logger.
+func (l *AsyncLogger) Debugf(template string, args ...interface{}) {
+	args = copyArgs(args)
+	l.enqueue(func() { l.logger.Debugf(template, args...) })
+}
+
+// Debugw enqueues the entry for the underlying Logger.
+func (l *AsyncLogger) Debugw(msg string, keysValues ...interface{}) {
+	keysValues = copyArgs(keysValues)
+	l.enqueue(func() { l.logger.Debugw(msg, keysValues...) })
+}
+
+// Info enqueues the entry for the underlying Logger.
+func (l *AsyncLogger) Info(args ...interface{}) {
+	args = copyArgs(args)
+	l.enqueue(func() { l.logger.Info(args...) })
+}
+
+// Infof enqueues the entry for the underlying Logger.
+func (l *AsyncLogger) Infof(template string, args ...interface{}) {
+	args = copyArgs(args)
+	l.enqueue(func() { l.logger.Infof(template, args...) })
+}
+
+// Infow enqueues the entry for the underlying Logger.
+func (l *AsyncLogger) Infow(msg string, keysValues ...interface{}) {
+	keysValues = copyArgs(keysValues)
+	l.enqueue(func() { l.logger.Infow(msg, keysValues...) })
+}
+
+// Warn enqueues the entry for the underlying Logger.
+func (l *AsyncLogger) Warn(args ...interface{}) {
+	args = copyArgs(args)
+	l.enqueue(func() { l.logger.Warn(args...) })
+}
+
+// Warnf enqueues the entry for the underlying Logger.
+func (l *AsyncLogger) Warnf(template string, args ...interface{}) {
+	args = copyArgs(args)
+	l.enqueue(func() { l.logger.Warnf(template, args...) })
+}
+
+// Warnw enqueues the entry for the underlying Logger.
+func (l *AsyncLogger) Warnw(msg string, keysValues ...interface{}) {
+	keysValues = copyArgs(keysValues)
+	l.enqueue(func() { l.logger.Warnw(msg, keysValues...) })
+}
+
+// Error enqueues the entry for the underlying Logger.
+func (l *AsyncLogger) Error(args ...interface{}) {
+	args = copyArgs(args)
+	l.enqueue(func() { l.logger.Error(args...) })
+}
+
+// Errorf enqueues the entry for the underlying Logger.
+func (l *AsyncLogger) Errorf(template string, args ...interface{}) {
+	args = copyArgs(args)
+	l.enqueue(func() { l.logger.Errorf(template, args...) })
+}
+
+// Errorw enqueues the entry for the underlying Logger.
+func (l *AsyncLogger) Errorw(msg string, keysValues ...interface{}) {
+	keysValues = copyArgs(keysValues)
+	l.enqueue(func() { l.logger.Errorw(msg, keysValues...) })
+}
+
+// Panic flushes the queue and delegates to the underlying Logger synchronously.
+func (l *AsyncLogger) Panic(args ...interface{}) {
+	l.Flush()
+	l.logger.Panic(args...)
+}
+
+// Panicf flushes the queue and delegates to the underlying Logger synchronously.
+func (l *AsyncLogger) Panicf(template string, args ...interface{}) {
+	l.Flush()
+	l.logger.Panicf(template, args...)
+}
+
+// Panicw flushes the queue and delegates to the underlying Logger synchronously.
+func (l *AsyncLogger) Panicw(msg string, keysValues ...interface{}) {
+	l.Flush()
+	l.logger.Panicw(msg, keysValues...)
+}
+
+// Fatal flushes the queue and delegates to the underlying Logger synchronously.
+func (l *AsyncLogger) Fatal(args ...interface{}) {
+	l.Flush()
+	l.logger.Fatal(args...)
+}
+
+// Fatalf flushes the queue and delegates to the underlying Logger synchronously.
+func (l *AsyncLogger) Fatalf(template string, args ...interface{}) {
+	l.Flush()
+	l.logger.Fatalf(template, args...)
+}
+
+// Fatalw flushes the queue and delegates to the underlying Logger synchronously.
+func (l *AsyncLogger) Fatalw(msg string, keysValues ...interface{}) {
+	l.Flush()
+	l.logger.Fatalw(msg, keysValues...)
+}
@@ -0,0 +1,272 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitDequeued waits until the background goroutine of l has taken every queued entry.
+func waitDequeued(t *testing.T, l *AsyncLogger) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for len(l.queue) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d entries still queued", len(l.queue))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAsyncLoggerOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		size   int
+		policy OverflowPolicy
+	}{
+		{"block on full", 4, BlockOnFull},
+		{"default size", 0, BlockOnFull},
+		{"drop on full with room", 1000, DropOnFull},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recordingLogger{}
+			l := NewAsyncLogger(rec, tt.size, tt.policy)
+			for i := 0; i < 100; i++ {
+				l.Infow(strconv.Itoa(i), "i", i)
+			}
+			l.Flush()
+
+			msgs := rec.messages()
+			if len(msgs) != 100 {
+				t.Fatalf("got %d entries, want 100", len(msgs))
+			}
+			for i, msg := range msgs {
+				if msg != strconv.Itoa(i) {
+					t.Fatalf("entry %d = %q, want %d", i, msg, i)
+				}
+			}
+			if err := l.Close(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestAsyncLoggerFlushOnClose(t *testing.T) {
+	gate := make(chan struct{})
+	rec := &recordingLogger{gate: gate}
+	l := NewAsyncLogger(rec, 16, BlockOnFull)
+	for i := 0; i < 10; i++ {
+		l.Info(i)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		_ = l.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+		t.Fatal("Close returned before the queued entries were written")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(gate)
+	<-closed
+
+	if got := len(rec.messages()); got != 10 {
+		t.Errorf("got %d entries, want 10", got)
+	}
+	// Closing again is a no-op, and entries logged after Close are dropped.
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("late")
+	l.Flush()
+	if got := l.Dropped(); got != 1 {
+		t.Errorf("Dropped = %d, want 1", got)
+	}
+}
+
+func TestAsyncLoggerOverflow(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      OverflowPolicy
+		wantDropped int64
+	}{
+		{"drop on full", DropOnFull, 8},
+		{"block on full", BlockOnFull, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gate := make(chan struct{})
+			rec := &recordingLogger{gate: gate}
+			l := NewAsyncLogger(rec, 2, tt.policy)
+
+			// The first entry is taken off the queue and blocks the background goroutine,
+			// leaving room for 2 entries.
+			l.Info("first")
+			waitDequeued(t, l)
+
+			logged := make(chan struct{})
+			go func() {
+				for i := 0; i < 10; i++ {
+					l.Info(i)
+				}
+				close(logged)
+			}()
+			if tt.policy == BlockOnFull {
+				select {
+				case <-logged:
+					t.Fatal("logging did not block on a full queue")
+				case <-time.After(20 * time.Millisecond):
+				}
+				close(gate)
+				<-logged
+			} else {
+				<-logged
+				close(gate)
+			}
+			if err := l.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := l.Dropped(); got != tt.wantDropped {
+				t.Errorf("Dropped = %d, want %d", got, tt.wantDropped)
+			}
+			if got, want := int64(len(rec.messages())), 11-tt.wantDropped; got != want {
+				t.Errorf("got %d entries, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestAsyncLoggerConcurrent(t *testing.T) {
+	rec := &recordingLogger{}
+	l := NewAsyncLogger(rec, 8, BlockOnFull)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				l.Infof("%d:%d", g, i)
+			}
+		}(g)
+	}
+	wg.Wait()
+	_ = l.Close()
+
+	// Entries of each goroutine keep their relative order.
+	next := make(map[string]int)
+	for _, msg := range rec.messages() {
+		var g, i int
+		if _, err := fmt.Sscanf(msg, "%d:%d", &g, &i); err != nil {
+			t.Fatalf("unexpected entry %q", msg)
+		}
+		key := strconv.Itoa(g)
+		if i != next[key] {
+			t.Fatalf("goroutine %d: entry %d written before entry %d", g, i, next[key])
+		}
+		next[key]++
+	}
+	if len(next) != 8 {
+		t.Errorf("entries from %d goroutines, want 8", len(next))
+	}
+}
+
+func TestAsyncLoggerPanicFlushes(t *testing.T) {
+	rec := &recordingLogger{}
+	l := NewAsyncLogger(rec, 16, BlockOnFull)
+	defer l.Close()
+	l.Info("before")
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Panic did not panic")
+			}
+		}()
+		l.Panic("boom")
+	}()
+
+	if got := fmt.Sprint(rec.messages()); got != "[before boom]" {
+		t.Errorf("entries = %v, want [before boom]", got)
+	}
+}
+
+func TestAsyncLoggerCopiesArgs(t *testing.T) {
+	gate := make(chan struct{})
+	rec := &recordingLogger{gate: gate}
+	l := NewAsyncLogger(rec, 4, BlockOnFull)
+
+	// The caller reuses its slices while the entries are still queued.
+	args := []interface{}{"a", 1}
+	keysValues := []interface{}{"k", "v"}
+	l.Info(args...)
+	l.Infof("%v %v", args...)
+	l.Infow("m", keysValues...)
+	args[0], args[1] = "b", 2
+	keysValues[1] = "changed"
+	close(gate)
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := rec.recorded()
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if entries[0].msg != "a1" || entries[1].msg != "a 1" {
+		t.Errorf("messages = %q, %q, want %q, %q", entries[0].msg, entries[1].msg, "a1", "a 1")
+	}
+	if got := entries[2].keysValues; len(got) != 2 || got[1] != "v" {
+		t.Errorf("keysValues = %v, want [k v]", got)
+	}
+}
+
+// fatalLogger is a recordingLogger signalling its Fatal calls on called without recording
+// them, so they are not held by the gate.
+type fatalLogger struct {
+	*recordingLogger
+	called chan struct{}
+}
+
+func (l *fatalLogger) Fatal(args ...interface{}) { close(l.called) }
+
+func TestAsyncLoggerFatalFlushTimeout(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy OverflowPolicy
+	}{
+		{"block on full", BlockOnFull},
+		{"drop on full", DropOnFull},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gate := make(chan struct{})
+			base := &fatalLogger{recordingLogger: &recordingLogger{gate: gate}, called: make(chan struct{})}
+			l := NewAsyncLogger(base, 1, tt.policy, WithFlushTimeout(20*time.Millisecond))
+			defer l.Close()
+			defer close(gate)
+
+			// The first entry stalls the underlying Logger and the second one fills the queue.
+			l.Info("stalled")
+			waitDequeued(t, l)
+			l.Info("queued")
+
+			go l.Fatal("boom")
+			select {
+			case <-base.called:
+			case <-time.After(time.Second):
+				t.Fatal("Fatal did not reach the underlying Logger with a stalled queue")
+			}
+			if l.Flush() {
+				t.Error("Flush reported success with a stalled underlying Logger")
+			}
+		})
+	}
+}
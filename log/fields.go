@@ -0,0 +1,30 @@
+package log
+
+// MissingKey is the synthetic key under which a dangling value is logged when
+// an odd number of keysValues is passed to a structured logging method.
+const MissingKey = "MISSING"
+
+// ErrorKey is the key of the field added to a structured log entry when its
+// keysValues could not be paired correctly.
+const ErrorKey = "LOGGER_ERROR"
+
+// oddKeysValuesMessage is the value of the ErrorKey field for an odd number of keysValues.
+const oddKeysValuesMessage = "odd number of keysValues"
+
+// NormalizeKeysValues returns keysValues unchanged when they form complete key-value pairs.
+// On an odd count, the dangling last element is logged as the value of MissingKey and an
+// ErrorKey field describing the mistake is appended, so no data is lost and nothing panics.
+// Logger adapters call it before converting keysValues to the fields of their backend.
+func NormalizeKeysValues(keysValues []interface{}) []interface{} {
+	if len(keysValues)%2 == 0 {
+		return keysValues
+	}
+	last := len(keysValues) - 1
+	normalized := make([]interface{}, 0, len(keysValues)+3)
+	normalized = append(normalized, keysValues[:last]...)
+	return append(normalized, MissingKey, keysValues[last], ErrorKey, oddKeysValuesMessage)
+}
+
+// CallerKey is the key of the field holding the source location (file:line) of the logging
+// call, added by adapters configured to report callers.
+const CallerKey = "caller"
@@ -0,0 +1,35 @@
+package log
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNormalizeKeysValues(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []interface{}
+		want []interface{}
+	}{
+		{"empty", nil, nil},
+		{"pairs", []interface{}{"user", "alice", "attempts", 3}, []interface{}{"user", "alice", "attempts", 3}},
+		{"single value", []interface{}{"alice"}, []interface{}{MissingKey, "alice", ErrorKey, oddKeysValuesMessage}},
+		{
+			"dangling value",
+			[]interface{}{"user", "alice", 42},
+			[]interface{}{"user", "alice", MissingKey, 42, ErrorKey, oddKeysValuesMessage},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := append([]interface{}(nil), tt.in...)
+			got := NormalizeKeysValues(in)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("NormalizeKeysValues = %v, want %v", got, tt.want)
+			}
+			if fmt.Sprint(in) != fmt.Sprint(tt.in) {
+				t.Errorf("input modified: %v, want %v", in, tt.in)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+)
+
+// entry is a log entry recorded by recordingLogger.
+type entry struct {
+	level      Level
+	msg        string
+	keysValues []interface{}
+}
+
+// recordingLogger is a Logger recording its entries, used by the tests of the package.
+// Panic* entries are recorded before panicking; Fatal* entries are only recorded.
+type recordingLogger struct {
+	// gate, if set, blocks every write until it is closed.
+	gate chan struct{}
+
+	mu      sync.Mutex
+	entries []entry
+}
+
+func (r *recordingLogger) record(level Level, msg string, keysValues []interface{}) {
+	if r.gate != nil {
+		<-r.gate
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry{level: level, msg: msg, keysValues: keysValues})
+}
+
+// recorded returns a copy of the recorded entries.
+func (r *recordingLogger) recorded() []entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]entry(nil), r.entries...)
+}
+
+// messages returns the messages of the recorded entries.
+func (r *recordingLogger) messages() []string {
+	entries := r.recorded()
+	msgs := make([]string, len(entries))
+	for i, e := range entries {
+		msgs[i] = e.msg
+	}
+	return msgs
+}
+
+func (r *recordingLogger) Debug(args ...interface{}) { r.record(DebugLevel, fmt.Sprint(args...), nil) }
+func (r *recordingLogger) Debugf(template string, args ...interface{}) {
+	r.record(DebugLevel, fmt.Sprintf(template, args...), nil)
+}
+func (r *recordingLogger) Debugw(msg string, keysValues ...interface{}) {
+	r.record(DebugLevel, msg, keysValues)
+}
+func (r *recordingLogger) Info(args ...interface{}) { r.record(InfoLevel, fmt.Sprint(args...), nil) }
+func (r *recordingLogger) Infof(template string, args ...interface{}) {
+	r.record(InfoLevel, fmt.Sprintf(template, args...), nil)
+}
+func (r *recordingLogger) Infow(msg string, keysValues ...interface{}) {
+	r.record(InfoLevel, msg, keysValues)
+}
+func (r *recordingLogger) Warn(args ...interface{}) { r.record(WarnLevel, fmt.Sprint(args...), nil) }
+func (r *recordingLogger) Warnf(template string, args ...interface{}) {
+	r.record(WarnLevel, fmt.Sprintf(template, args...), nil)
+}
+func (r *recordingLogger) Warnw(msg string, keysValues ...interface{}) {
+	r.record(WarnLevel, msg, keysValues)
+}
+func (r *recordingLogger) Error(args ...interface{}) { r.record(ErrorLevel, fmt.Sprint(args...), nil) }
+func (r *recordingLogger) Errorf(template string, args ...interface{}) {
+	r.record(ErrorLevel, fmt.Sprintf(template, args...), nil)
+}
+func (r *recordingLogger) Errorw(msg string, keysValues ...interface{}) {
+	r.record(ErrorLevel, msg, keysValues)
+}
+func (r *recordingLogger) Panic(args ...interface{}) {
+	msg := fmt.Sprint(args...)
+	r.record(PanicLevel, msg, nil)
+	panic(msg)
+}
+func (r *recordingLogger) Panicf(template string, args ...interface{}) {
+	msg := fmt.Sprintf(template, args...)
+	r.record(PanicLevel, msg, nil)
+	panic(msg)
+}
+func (r *recordingLogger) Panicw(msg string, keysValues ...interface{}) {
+	r.record(PanicLevel, msg, keysValues)
+	panic(msg)
+}
+func (r *recordingLogger) Fatal(args ...interface{}) { r.record(FatalLevel, fmt.Sprint(args...), nil) }
+func (r *recordingLogger) Fatalf(template string, args ...interface{}) {
+	r.record(FatalLevel, fmt.Sprintf(template, args...), nil)
+}
+func (r *recordingLogger) Fatalw(msg string, keysValues ...interface{}) {
+	r.record(FatalLevel, msg, keysValues)
+}
@@ -0,0 +1,60 @@
+package log
+
+// Level represents the severity of a log entry, ordered from least to most severe.
+type Level int8
+
+const (
+	// DebugLevel is used for detailed troubleshooting information.
+	DebugLevel Level = iota
+	// InfoLevel is used for general runtime information.
+	InfoLevel
+	// WarnLevel is used for situations that might lead to errors.
+	WarnLevel
+	// ErrorLevel is used for handled runtime errors.
+	ErrorLevel
+	// PanicLevel is used for entries that are followed by a panic.
+	PanicLevel
+	// FatalLevel is used for entries that are followed by program termination.
+	FatalLevel
+)
+
+// String returns the lower-case name of the level, e.g. "debug".
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	case PanicLevel:
+		return "panic"
+	case FatalLevel:
+		return "fatal"
+	default:
+		return "unknown"
+	}
+}
+
+// LevelEnabler is an optional interface implemented by loggers that can report whether
+// entries of a given level would be emitted. It lets callers skip building expensive
+// structured fields for entries that would be discarded anyway.
+type LevelEnabler interface {
+	// Enabled reports whether entries of the given level are emitted by the logger.
+	Enabled(level Level) bool
+}
+
+// Enabled reports whether logger emits entries of the given level.
+// Loggers that do not implement LevelEnabler are assumed to emit every level.
+//
+//	if log.Enabled(logger, log.DebugLevel) {
+//		logger.Debugw("state", "snapshot", expensiveSnapshot())
+//	}
+func Enabled(logger Logger, level Level) bool {
+	if enabler, ok := logger.(LevelEnabler); ok {
+		return enabler.Enabled(level)
+	}
+	return true
+}
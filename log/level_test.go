@@ -0,0 +1,57 @@
+package log
+
+import "testing"
+
+func TestLevelString(t *testing.T) {
+	tests := []struct {
+		level Level
+		want  string
+	}{
+		{DebugLevel, "debug"},
+		{InfoLevel, "info"},
+		{WarnLevel, "warn"},
+		{ErrorLevel, "error"},
+		{PanicLevel, "panic"},
+		{FatalLevel, "fatal"},
+		{Level(42), "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.level.String(); got != tt.want {
+				t.Errorf("String = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// minLevelLogger is a recordingLogger implementing LevelEnabler.
+type minLevelLogger struct {
+	recordingLogger
+	min Level
+}
+
+func (l *minLevelLogger) Enabled(level Level) bool {
+	return level >= l.min
+}
+
+func TestEnabled(t *testing.T) {
+	tests := []struct {
+		name   string
+		logger Logger
+		level  Level
+		want   bool
+	}{
+		{"without LevelEnabler", &recordingLogger{}, DebugLevel, true},
+		{"below minimum", &minLevelLogger{min: WarnLevel}, InfoLevel, false},
+		{"at minimum", &minLevelLogger{min: WarnLevel}, WarnLevel, true},
+		{"above minimum", &minLevelLogger{min: WarnLevel}, FatalLevel, true},
+		{"through wrapper", NewRedactingLogger(&minLevelLogger{min: ErrorLevel}, "password"), WarnLevel, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Enabled(tt.logger, tt.level); got != tt.want {
+				t.Errorf("Enabled = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/zeroxsolutions/barbatos/log/logruslog
+
+go 1.18
+
+require (
+	github.com/sirupsen/logrus v1.9.3
+	github.com/zeroxsolutions/barbatos v0.0.0-00010101000000-000000000000
+)
+
+require golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 // indirect
+
+replace github.com/zeroxsolutions/barbatos => ../../
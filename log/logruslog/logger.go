@@ -0,0 +1,198 @@
+// Package logruslog provides a log.Logger implementation backed by sirupsen/logrus.
+// It lets services standardized on logrus satisfy the Logger interface without
+// swapping their logging library.
+package logruslog
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	"github.com/zeroxsolutions/barbatos/log"
+)
+
+// LogrusLogger is a log.Logger that writes through a logrus entry.
+// Structured `*w` calls map their key-value pairs to logrus.Fields, formatted `*f` calls
+// are formatted with fmt.Sprintf semantics, `Panic*` calls logrus Panic and `Fatal*`
+// calls logrus Fatal, which exits the program through the logrus exit handler.
+//
+// With the WithCaller option, every entry also carries the file and line of the logging call
+// under log.CallerKey.
+type LogrusLogger struct {
+	entry      *logrus.Entry
+	caller     bool
+	callerSkip int
+}
+
+// Option configures a LogrusLogger.
+type Option func(*LogrusLogger)
+
+// WithCaller adds the source location of the logging call, as "dir/file.go:line", to every
+// entry under log.CallerKey. skip is the number of additional stack frames to skip, so that
+// helpers wrapping the logger can report their own caller instead of themselves: with a skip
+// of 0 the caller of the LogrusLogger method is reported, with 1 the caller of the function
+// calling it, and so on.
+//
+//	logger := logruslog.NewLogrusLogger(logrus.StandardLogger(), logruslog.WithCaller(0))
+func WithCaller(skip int) Option {
+	return func(l *LogrusLogger) {
+		l.caller = true
+		l.callerSkip = skip
+	}
+}
+
+var (
+	_ log.Logger       = (*LogrusLogger)(nil)
+	_ log.LevelEnabler = (*LogrusLogger)(nil)
+)
+
+// NewLogrusLogger creates a LogrusLogger that writes to logger.
+//
+//	logger := logruslog.NewLogrusLogger(logrus.StandardLogger())
+//	logger.Infow("user created", "id", id)
+func NewLogrusLogger(logger *logrus.Logger, opts ...Option) *LogrusLogger {
+	return NewLogrusEntryLogger(logrus.NewEntry(logger), opts...)
+}
+
+// NewLogrusEntryLogger creates a LogrusLogger that writes to entry, keeping the
+// fields already attached to it.
+func NewLogrusEntryLogger(entry *logrus.Entry, opts ...Option) *LogrusLogger {
+	l := &LogrusLogger{entry: entry}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// at returns the entry to log through at level, with the caller field if configured and level
+// is enabled, so that disabled calls skip the stack walk. It must be called directly from the
+// exported logging methods, which determines the stack depth it skips.
+func (l *LogrusLogger) at(level logrus.Level) *logrus.Entry {
+	if !l.caller || !l.entry.Logger.IsLevelEnabled(level) {
+		return l.entry
+	}
+	_, file, line, ok := runtime.Caller(2 + l.callerSkip)
+	if !ok {
+		return l.entry
+	}
+	file = filepath.Join(filepath.Base(filepath.Dir(file)), filepath.Base(file))
+	return l.entry.WithField(log.CallerKey, filepath.ToSlash(file)+":"+strconv.Itoa(line))
+}
+
+// fields converts keysValues, normalized with log.NormalizeKeysValues, to logrus.Fields.
+// Non-string keys are formatted with fmt.Sprint.
+func fields(keysValues []interface{}) logrus.Fields {
+	keysValues = log.NormalizeKeysValues(keysValues)
+	result := make(logrus.Fields, len(keysValues)/2)
+	for i := 0; i+1 < len(keysValues); i += 2 {
+		key, ok := keysValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysValues[i])
+		}
+		result[key] = keysValues[i+1]
+	}
+	return result
+}
+
+// Enabled reports whether the underlying logrus logger emits entries at level.
+func (l *LogrusLogger) Enabled(level log.Level) bool {
+	return l.entry.Logger.IsLevelEnabled(toLogrusLevel(level))
+}
+
+// toLogrusLevel maps a log.Level to the corresponding logrus level.
+func toLogrusLevel(level log.Level) logrus.Level {
+	switch level {
+	case log.DebugLevel:
+		return logrus.DebugLevel
+	case log.InfoLevel:
+		return logrus.InfoLevel
+	case log.WarnLevel:
+		return logrus.WarnLevel
+	case log.ErrorLevel:
+		return logrus.ErrorLevel
+	case log.PanicLevel:
+		return logrus.PanicLevel
+	default:
+		return logrus.FatalLevel
+	}
+}
+
+// Debug logs args at debug level.
+func (l *LogrusLogger) Debug(args ...interface{}) { l.at(logrus.DebugLevel).Debug(args...) }
+
+// Debugf logs a formatted message at debug level.
+func (l *LogrusLogger) Debugf(template string, args ...interface{}) {
+	l.at(logrus.DebugLevel).Debugf(template, args...)
+}
+
+// Debugw logs msg with the key-value pairs as fields at debug level.
+func (l *LogrusLogger) Debugw(msg string, keysValues ...interface{}) {
+	l.at(logrus.DebugLevel).WithFields(fields(keysValues)).Debug(msg)
+}
+
+// Info logs args at info level.
+func (l *LogrusLogger) Info(args ...interface{}) { l.at(logrus.InfoLevel).Info(args...) }
+
+// Infof logs a formatted message at info level.
+func (l *LogrusLogger) Infof(template string, args ...interface{}) {
+	l.at(logrus.InfoLevel).Infof(template, args...)
+}
+
+// Infow logs msg with the key-value pairs as fields at info level.
+func (l *LogrusLogger) Infow(msg string, keysValues ...interface{}) {
+	l.at(logrus.InfoLevel).WithFields(fields(keysValues)).Info(msg)
+}
+
+// Warn logs args at warning level.
+func (l *LogrusLogger) Warn(args ...interface{}) { l.at(logrus.WarnLevel).Warn(args...) }
+
+// Warnf logs a formatted message at warning level.
+func (l *LogrusLogger) Warnf(template string, args ...interface{}) {
+	l.at(logrus.WarnLevel).Warnf(template, args...)
+}
+
+// Warnw logs msg with the key-value pairs as fields at warning level.
+func (l *LogrusLogger) Warnw(msg string, keysValues ...interface{}) {
+	l.at(logrus.WarnLevel).WithFields(fields(keysValues)).Warn(msg)
+}
+
+// Error logs args at error level.
+func (l *LogrusLogger) Error(args ...interface{}) { l.at(logrus.ErrorLevel).Error(args...) }
+
+// Errorf logs a formatted message at error level.
+func (l *LogrusLogger) Errorf(template string, args ...interface{}) {
+	l.at(logrus.ErrorLevel).Errorf(template, args...)
+}
+
+// Errorw logs msg with the key-value pairs as fields at error level.
+func (l *LogrusLogger) Errorw(msg string, keysValues ...interface{}) {
+	l.at(logrus.ErrorLevel).WithFields(fields(keysValues)).Error(msg)
+}
+
+// Panic logs args at panic level and panics.
+func (l *LogrusLogger) Panic(args ...interface{}) { l.at(logrus.PanicLevel).Panic(args...) }
+
+// Panicf logs a formatted message at panic level and panics.
+func (l *LogrusLogger) Panicf(template string, args ...interface{}) {
+	l.at(logrus.PanicLevel).Panicf(template, args...)
+}
+
+// Panicw logs msg with the key-value pairs as fields at panic level and panics.
+func (l *LogrusLogger) Panicw(msg string, keysValues ...interface{}) {
+	l.at(logrus.PanicLevel).WithFields(fields(keysValues)).Panic(msg)
+}
+
+// Fatal logs args at fatal level and exits.
+func (l *LogrusLogger) Fatal(args ...interface{}) { l.at(logrus.FatalLevel).Fatal(args...) }
+
+// Fatalf logs a formatted message at fatal level and exits.
+func (l *LogrusLogger) Fatalf(template string, args ...interface{}) {
+	l.at(logrus.FatalLevel).Fatalf(template, args...)
+}
+
+// Fatalw logs msg with the key-value pairs as fields at fatal level and exits.
+func (l *LogrusLogger) Fatalw(msg string, keysValues ...interface{}) {
+	l.at(logrus.FatalLevel).WithFields(fields(keysValues)).Fatal(msg)
+}
@@ -0,0 +1,184 @@
+package logruslog
+
+import (
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/zeroxsolutions/barbatos/log"
+)
+
+// newTestLogger returns a LogrusLogger logging every level to a test hook, whose Fatal*
+// calls record the exit code instead of exiting.
+func newTestLogger(opts ...Option) (*LogrusLogger, *test.Hook, *int) {
+	logger, hook := test.NewNullLogger()
+	logger.SetLevel(logrus.DebugLevel)
+	exitCode := -1
+	logger.ExitFunc = func(code int) { exitCode = code }
+	return NewLogrusLogger(logger, opts...), hook, &exitCode
+}
+
+func TestLogrusLogger(t *testing.T) {
+	tests := []struct {
+		name       string
+		log        func(l *LogrusLogger)
+		wantLevel  logrus.Level
+		wantMsg    string
+		wantFields logrus.Fields
+	}{
+		{"Debug", func(l *LogrusLogger) { l.Debug("a", 1) }, logrus.DebugLevel, "a1", logrus.Fields{}},
+		{"Infof", func(l *LogrusLogger) { l.Infof("user %d", 42) }, logrus.InfoLevel, "user 42", logrus.Fields{}},
+		{"Warnw", func(l *LogrusLogger) { l.Warnw("retry", "attempt", 2) }, logrus.WarnLevel, "retry", logrus.Fields{"attempt": 2}},
+		{"Errorw", func(l *LogrusLogger) { l.Errorw("failed", "id", "x", "code", 500) }, logrus.ErrorLevel, "failed", logrus.Fields{"id": "x", "code": 500}},
+		{"non-string key", func(l *LogrusLogger) { l.Infow("m", 7, "v") }, logrus.InfoLevel, "m", logrus.Fields{"7": "v"}},
+		{"odd keysValues", func(l *LogrusLogger) { l.Infow("m", "k", "v", "dangling") }, logrus.InfoLevel, "m", logrus.Fields{
+			"k":            "v",
+			log.MissingKey: "dangling",
+			log.ErrorKey:   "odd number of keysValues",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, hook, _ := newTestLogger()
+			tt.log(l)
+
+			entries := hook.AllEntries()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			entry := entries[0]
+			if entry.Level != tt.wantLevel || entry.Message != tt.wantMsg {
+				t.Errorf("entry = %v %q, want %v %q", entry.Level, entry.Message, tt.wantLevel, tt.wantMsg)
+			}
+			if len(entry.Data) != len(tt.wantFields) {
+				t.Errorf("fields = %v, want %v", entry.Data, tt.wantFields)
+			}
+			for key, want := range tt.wantFields {
+				if got := entry.Data[key]; got != want {
+					t.Errorf("field %q = %v, want %v", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLogrusEntryLoggerKeepsFields(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	l := NewLogrusEntryLogger(logger.WithField("service", "billing"))
+	l.Infow("started", "port", 8080)
+
+	entry := hook.LastEntry()
+	if entry == nil {
+		t.Fatal("no entry")
+	}
+	if entry.Data["service"] != "billing" || entry.Data["port"] != 8080 {
+		t.Errorf("fields = %v, want service and port", entry.Data)
+	}
+}
+
+func TestLogrusLoggerPanicAndFatal(t *testing.T) {
+	l, hook, exitCode := newTestLogger()
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Panicw did not panic")
+			}
+		}()
+		l.Panicw("boom", "k", "v")
+	}()
+	if entry := hook.LastEntry(); entry == nil || entry.Level != logrus.PanicLevel || entry.Data["k"] != "v" {
+		t.Errorf("panic entry = %v", entry)
+	}
+
+	l.Fatalf("fatal %s", "error")
+	if entry := hook.LastEntry(); entry == nil || entry.Level != logrus.FatalLevel || entry.Message != "fatal error" {
+		t.Errorf("fatal entry = %v", entry)
+	}
+	if *exitCode != 1 {
+		t.Errorf("exit code = %d, want 1", *exitCode)
+	}
+}
+
+func TestLogrusLoggerEnabled(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	logger.SetLevel(logrus.WarnLevel)
+	l := NewLogrusLogger(logger)
+	tests := []struct {
+		level log.Level
+		want  bool
+	}{
+		{log.DebugLevel, false},
+		{log.InfoLevel, false},
+		{log.WarnLevel, true},
+		{log.ErrorLevel, true},
+		{log.PanicLevel, true},
+		{log.FatalLevel, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			if got := log.Enabled(l, tt.level); got != tt.want {
+				t.Errorf("Enabled = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// logVia logs through l from a helper, as the skip of WithCaller is meant for.
+func logVia(l *LogrusLogger) { l.Info("via helper") }
+
+func TestLogrusLoggerWithCaller(t *testing.T) {
+	// line returns the location of its caller, as reported under log.CallerKey.
+	line := func() string {
+		_, _, n, _ := runtime.Caller(1)
+		return "logruslog/logger_test.go:" + strconv.Itoa(n)
+	}
+	tests := []struct {
+		name string
+		opts []Option
+		log  func(l *LogrusLogger) string
+	}{
+		{"without caller", nil, func(l *LogrusLogger) string { l.Info("m"); return "" }},
+		{"Debug", []Option{WithCaller(0)}, func(l *LogrusLogger) string { l.Debug("m"); return line() }},
+		{"Infof", []Option{WithCaller(0)}, func(l *LogrusLogger) string { l.Infof("m %d", 1); return line() }},
+		{"Warnw", []Option{WithCaller(0)}, func(l *LogrusLogger) string { l.Warnw("m", "k", "v"); return line() }},
+		{"Errorw", []Option{WithCaller(0)}, func(l *LogrusLogger) string { l.Errorw("m"); return line() }},
+		{"helper skipped", []Option{WithCaller(1)}, func(l *LogrusLogger) string { logVia(l); return line() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, hook, _ := newTestLogger(tt.opts...)
+			want := tt.log(l)
+
+			entry := hook.LastEntry()
+			if entry == nil {
+				t.Fatal("no entry")
+			}
+			got, ok := entry.Data[log.CallerKey]
+			if want == "" {
+				if ok {
+					t.Errorf("caller = %v, want none", got)
+				}
+				return
+			}
+			if got != want {
+				t.Errorf("caller = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestLogrusLoggerWithCallerDisabledLevel(t *testing.T) {
+	l, hook, _ := newTestLogger(WithCaller(0))
+	l.entry.Logger.SetLevel(logrus.InfoLevel)
+
+	// A disabled call must not look up its caller, which would allocate the caller field.
+	allocs := testing.AllocsPerRun(100, func() { l.Debugf("m") })
+	if allocs != 0 {
+		t.Errorf("disabled Debugf allocated %v times, want 0", allocs)
+	}
+	if entries := hook.AllEntries(); len(entries) != 0 {
+		t.Errorf("got %d entries, want 0", len(entries))
+	}
+}
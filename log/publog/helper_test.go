@@ -0,0 +1,79 @@
+package publog
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/zeroxsolutions/barbatos/log"
+)
+
+// recordingLogger is a log.Logger recording the messages of its entries, used by the tests
+// of the package. Panic* entries are recorded before panicking; Fatal* entries are only
+// recorded.
+type recordingLogger struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (r *recordingLogger) record(msg string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, msg)
+}
+
+// recorded returns a copy of the recorded messages.
+func (r *recordingLogger) recorded() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.entries...)
+}
+
+func (r *recordingLogger) Debug(args ...interface{}) { r.record(fmt.Sprint(args...)) }
+func (r *recordingLogger) Debugf(template string, args ...interface{}) {
+	r.record(fmt.Sprintf(template, args...))
+}
+func (r *recordingLogger) Debugw(msg string, keysValues ...interface{}) { r.record(msg) }
+func (r *recordingLogger) Info(args ...interface{})                     { r.record(fmt.Sprint(args...)) }
+func (r *recordingLogger) Infof(template string, args ...interface{}) {
+	r.record(fmt.Sprintf(template, args...))
+}
+func (r *recordingLogger) Infow(msg string, keysValues ...interface{}) { r.record(msg) }
+func (r *recordingLogger) Warn(args ...interface{})                    { r.record(fmt.Sprint(args...)) }
+func (r *recordingLogger) Warnf(template string, args ...interface{}) {
+	r.record(fmt.Sprintf(template, args...))
+}
+func (r *recordingLogger) Warnw(msg string, keysValues ...interface{}) { r.record(msg) }
+func (r *recordingLogger) Error(args ...interface{})                   { r.record(fmt.Sprint(args...)) }
+func (r *recordingLogger) Errorf(template string, args ...interface{}) {
+	r.record(fmt.Sprintf(template, args...))
+}
+func (r *recordingLogger) Errorw(msg string, keysValues ...interface{}) { r.record(msg) }
+func (r *recordingLogger) Panic(args ...interface{}) {
+	msg := fmt.Sprint(args...)
+	r.record(msg)
+	panic(msg)
+}
+func (r *recordingLogger) Panicf(template string, args ...interface{}) {
+	msg := fmt.Sprintf(template, args...)
+	r.record(msg)
+	panic(msg)
+}
+func (r *recordingLogger) Panicw(msg string, keysValues ...interface{}) {
+	r.record(msg)
+	panic(msg)
+}
+func (r *recordingLogger) Fatal(args ...interface{}) { r.record(fmt.Sprint(args...)) }
+func (r *recordingLogger) Fatalf(template string, args ...interface{}) {
+	r.record(fmt.Sprintf(template, args...))
+}
+func (r *recordingLogger) Fatalw(msg string, keysValues ...interface{}) { r.record(msg) }
+
+// minLevelLogger is a recordingLogger implementing log.LevelEnabler.
+type minLevelLogger struct {
+	recordingLogger
+	min log.Level
+}
+
+func (l *minLevelLogger) Enabled(level log.Level) bool {
+	return level >= l.min
+}
@@ -0,0 +1,329 @@
+// Package publog provides a log.Logger wrapper publishing error entries to a pub-sub topic.
+// It lives apart from package log so that loggers do not depend on package pubsub.
+package publog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/log"
+	"github.com/zeroxsolutions/barbatos/pubsub"
+)
+
+// DefaultPublishQueueSize is the number of entries a PublishErrorLogger buffers while they
+// wait to be published.
+const DefaultPublishQueueSize = 256
+
+// DefaultPublishTimeout is the default bound of each publish issued by a PublishErrorLogger.
+const DefaultPublishTimeout = 5 * time.Second
+
+// PublishedEntry is the JSON payload published by a PublishErrorLogger for every entry at
+// error level or above.
+type PublishedEntry struct {
+	// Level is the name of the level of the entry, e.g. "error".
+	Level string `json:"level"`
+	// Message is the message of the entry.
+	Message string `json:"message"`
+	// Fields holds the key-value pairs of structured entries. Errors are stored as their
+	// message. If the entry cannot be encoded as JSON, every value is formatted with
+	// fmt.Sprint instead.
+	Fields map[string]interface{} `json:"fields,omitempty"`
+	// Timestamp is the time at which the entry was logged.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// PublishErrorLogger is a log.Logger wrapper that delegates every entry to the underlying Logger
+// and additionally publishes Error*, Panic*, and Fatal* entries, as JSON PublishedEntry
+// payloads, to a pub-sub topic feeding an alerting pipeline.
+//
+// Publishing is best-effort and never blocks the logging call: error entries are queued and
+// published by a background goroutine, and entries that do not fit in the queue or fail to
+// publish are counted by Failed. Panic* and Fatal* entries are not queued: since the program
+// is about to stop, the queue is flushed and the entry is published synchronously before
+// delegating. Flushing and publishing share a single publish timeout, so a stalled publisher
+// delays these calls by at most that timeout; an entry that cannot be published in time is
+// counted by Failed.
+type PublishErrorLogger struct {
+	// failed is accessed atomically and kept first so that it is 64-bit aligned on 32-bit
+	// platforms.
+	failed int64
+
+	logger  log.Logger
+	pub     pubsub.Publisher
+	topic   string
+	timeout time.Duration
+	queue   chan func()
+	done    chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// Option configures a PublishErrorLogger.
+type Option func(*PublishErrorLogger)
+
+// WithPublishTimeout sets the bound of each publish. It defaults to DefaultPublishTimeout.
+func WithPublishTimeout(d time.Duration) Option {
+	return func(l *PublishErrorLogger) {
+		l.timeout = d
+	}
+}
+
+// NewPublishErrorLogger creates a PublishErrorLogger delegating to logger and publishing
+// error entries to topic with pub, and starts its background goroutine. Close must be
+// called to publish the remaining entries and stop the goroutine.
+//
+//	logger := publog.NewPublishErrorLogger(base, publisher, "alerts.errors")
+//	defer logger.Close()
+func NewPublishErrorLogger(logger log.Logger, pub pubsub.Publisher, topic string, opts ...Option) *PublishErrorLogger {
+	l := &PublishErrorLogger{
+		logger:  logger,
+		pub:     pub,
+		topic:   topic,
+		timeout: DefaultPublishTimeout,
+		queue:   make(chan func(), DefaultPublishQueueSize),
+		done:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	go l.run()
+	return l
+}
+
+// run publishes the queued entries until the queue is closed.
+func (l *PublishErrorLogger) run() {
+	defer close(l.done)
+	for publish := range l.queue {
+		publish()
+	}
+}
+
+// publish publishes payload within the publish timeout, counting a failure.
+func (l *PublishErrorLogger) publish(payload []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), l.timeout)
+	defer cancel()
+	l.publishContext(ctx, payload)
+}
+
+// publishContext publishes payload, counting a failure.
+func (l *PublishErrorLogger) publishContext(ctx context.Context, payload []byte) {
+	if err := l.pub.Publish(ctx, l.topic, payload); err != nil {
+		atomic.AddInt64(&l.failed, 1)
+	}
+}
+
+// enqueue queues the entry for publishing, or counts it as failed if the queue is full or
+// the logger is closed.
+func (l *PublishErrorLogger) enqueue(level log.Level, msg string, keysValues []interface{}) {
+	payload, ok := l.encode(level, msg, keysValues)
+	if !ok {
+		return
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.closed {
+		atomic.AddInt64(&l.failed, 1)
+		return
+	}
+	select {
+	case l.queue <- func() { l.publish(payload) }:
+	default:
+		atomic.AddInt64(&l.failed, 1)
+	}
+}
+
+// publishNow flushes the queue and publishes the entry synchronously, both within the
+// publish timeout. The entry is counted as failed if the queue is not flushed in time.
+func (l *PublishErrorLogger) publishNow(level log.Level, msg string, keysValues []interface{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), l.timeout)
+	defer cancel()
+	if !l.flush(ctx) {
+		atomic.AddInt64(&l.failed, 1)
+		return
+	}
+	if payload, ok := l.encode(level, msg, keysValues); ok {
+		l.publishContext(ctx, payload)
+	}
+}
+
+// encode returns the JSON payload of the entry, counting a failure if it cannot be encoded.
+func (l *PublishErrorLogger) encode(level log.Level, msg string, keysValues []interface{}) ([]byte, bool) {
+	entry := PublishedEntry{Level: level.String(), Message: msg, Timestamp: time.Now()}
+	keysValues = log.NormalizeKeysValues(keysValues)
+	if len(keysValues) > 0 {
+		entry.Fields = make(map[string]interface{}, len(keysValues)/2)
+		for i := 0; i+1 < len(keysValues); i += 2 {
+			value := keysValues[i+1]
+			if err, ok := value.(error); ok {
+				value = err.Error()
+			}
+			entry.Fields[fmt.Sprint(keysValues[i])] = value
+		}
+	}
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		// Some value cannot be encoded: fall back to the formatted values.
+		for key, value := range entry.Fields {
+			entry.Fields[key] = fmt.Sprint(value)
+		}
+		payload, err = json.Marshal(entry)
+	}
+	if err != nil {
+		atomic.AddInt64(&l.failed, 1)
+		return nil, false
+	}
+	return payload, true
+}
+
+// Flush blocks until every entry queued before the call has been published.
+func (l *PublishErrorLogger) Flush() {
+	l.flush(context.Background())
+}
+
+// flush waits until every entry queued before the call has been published, and reports
+// whether they were before ctx is done.
+func (l *PublishErrorLogger) flush(ctx context.Context) bool {
+	l.mu.RLock()
+	if l.closed {
+		l.mu.RUnlock()
+		return true
+	}
+	flushed := make(chan struct{})
+	select {
+	case l.queue <- func() { close(flushed) }:
+	case <-ctx.Done():
+		l.mu.RUnlock()
+		return false
+	}
+	l.mu.RUnlock()
+
+	select {
+	case <-flushed:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Close publishes the remaining entries and stops the background goroutine.
+// It closes neither the underlying Logger nor the Publisher. Calling Close more than once
+// is a no-op.
+func (l *PublishErrorLogger) Close() error {
+	l.mu.Lock()
+	if !l.closed {
+		l.closed = true
+		close(l.queue)
+	}
+	l.mu.Unlock()
+	<-l.done
+	return nil
+}
+
+// Failed returns the number of entries that could not be published, because the queue was
+// full or could not be flushed in time, the logger was closed, or publishing failed.
+func (l *PublishErrorLogger) Failed() int64 {
+	return atomic.LoadInt64(&l.failed)
+}
+
+// Enabled reports whether the underlying Logger emits entries of the given level.
+func (l *PublishErrorLogger) Enabled(level log.Level) bool {
+	return log.Enabled(l.logger, level)
+}
+
+// Debug delegates to the underlying Logger.
+func (l *PublishErrorLogger) Debug(args ...interface{}) { l.logger.Debug(args...) }
+
+// Debugf delegates to the underlying Logger.
+func (l *PublishErrorLogger) Debugf(template string, args ...interface{}) {
+	l.logger.Debugf(template, args...)
+}
+
+// Debugw delegates to the underlying Logger.
+func (l *PublishErrorLogger) Debugw(msg string, keysValues ...interface{}) {
+	l.logger.Debugw(msg, keysValues...)
+}
+
+// Info delegates to the underlying Logger.
+func (l *PublishErrorLogger) Info(args ...interface{}) { l.logger.Info(args...) }
+
+// Infof delegates to the underlying Logger.
+func (l *PublishErrorLogger) Infof(template string, args ...interface{}) {
+	l.logger.Infof(template, args...)
+}
+
+// Infow delegates to the underlying Logger.
+func (l *PublishErrorLogger) Infow(msg string, keysValues ...interface{}) {
+	l.logger.Infow(msg, keysValues...)
+}
+
+// Warn delegates to the underlying Logger.
+func (l *PublishErrorLogger) Warn(args ...interface{}) { l.logger.Warn(args...) }
+
+// Warnf delegates to the underlying Logger.
+func (l *PublishErrorLogger) Warnf(template string, args ...interface{}) {
+	l.logger.Warnf(template, args...)
+}
+
+// Warnw delegates to the underlying Logger.
+func (l *PublishErrorLogger) Warnw(msg string, keysValues ...interface{}) {
+	l.logger.Warnw(msg, keysValues...)
+}
+
+// Error delegates to the underlying Logger and queues the entry for publishing.
+func (l *PublishErrorLogger) Error(args ...interface{}) {
+	l.logger.Error(args...)
+	l.enqueue(log.ErrorLevel, fmt.Sprint(args...), nil)
+}
+
+// Errorf delegates to the underlying Logger and queues the entry for publishing.
+func (l *PublishErrorLogger) Errorf(template string, args ...interface{}) {
+	l.logger.Errorf(template, args...)
+	l.enqueue(log.ErrorLevel, fmt.Sprintf(template, args...), nil)
+}
+
+// Errorw delegates to the underlying Logger and queues the entry for publishing.
+func (l *PublishErrorLogger) Errorw(msg string, keysValues ...interface{}) {
+	l.logger.Errorw(msg, keysValues...)
+	l.enqueue(log.ErrorLevel, msg, keysValues)
+}
+
+// Panic flushes the queue, publishes the entry, then delegates to the underlying Logger.
+func (l *PublishErrorLogger) Panic(args ...interface{}) {
+	l.publishNow(log.PanicLevel, fmt.Sprint(args...), nil)
+	l.logger.Panic(args...)
+}
+
+// Panicf flushes the queue, publishes the entry, then delegates to the underlying Logger.
+func (l *PublishErrorLogger) Panicf(template string, args ...interface{}) {
+	l.publishNow(log.PanicLevel, fmt.Sprintf(template, args...), nil)
+	l.logger.Panicf(template, args...)
+}
+
+// Panicw flushes the queue, publishes the entry, then delegates to the underlying Logger.
+func (l *PublishErrorLogger) Panicw(msg string, keysValues ...interface{}) {
+	l.publishNow(log.PanicLevel, msg, keysValues)
+	l.logger.Panicw(msg, keysValues...)
+}
+
+// Fatal flushes the queue, publishes the entry, then delegates to the underlying Logger.
+func (l *PublishErrorLogger) Fatal(args ...interface{}) {
+	l.publishNow(log.FatalLevel, fmt.Sprint(args...), nil)
+	l.logger.Fatal(args...)
+}
+
+// Fatalf flushes the queue, publishes the entry, then delegates to the underlying Logger.
+func (l *PublishErrorLogger) Fatalf(template string, args ...interface{}) {
+	l.publishNow(log.FatalLevel, fmt.Sprintf(template, args...), nil)
+	l.logger.Fatalf(template, args...)
+}
+
+// Fatalw flushes the queue, publishes the entry, then delegates to the underlying Logger.
+func (l *PublishErrorLogger) Fatalw(msg string, keysValues ...interface{}) {
+	l.publishNow(log.FatalLevel, msg, keysValues)
+	l.logger.Fatalw(msg, keysValues...)
+}
@@ -0,0 +1,244 @@
+package publog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/log"
+)
+
+var errPublish = errors.New("publish failed")
+
+// recordingPublisher is a pubsub.Publisher recording the published payloads. Publishing
+// fails with err if set, and blocks while gate is set and open.
+type recordingPublisher struct {
+	gate chan struct{}
+	err  error
+
+	mu       sync.Mutex
+	topics   []string
+	payloads [][]byte
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, topic string, messages ...[]byte) error {
+	if p.gate != nil {
+		<-p.gate
+	}
+	if p.err != nil {
+		return p.err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, msg := range messages {
+		p.topics = append(p.topics, topic)
+		p.payloads = append(p.payloads, msg)
+	}
+	return nil
+}
+
+func (p *recordingPublisher) PublishWithPriority(ctx context.Context, topic string, priority uint8, msg []byte) error {
+	return p.Publish(ctx, topic, msg)
+}
+
+func (p *recordingPublisher) IsConnected(ctx context.Context) bool      { return true }
+func (p *recordingPublisher) CheckConnection(ctx context.Context) error { return nil }
+func (p *recordingPublisher) Close() error                              { return nil }
+
+// entries decodes the published payloads.
+func (p *recordingPublisher) entries(t *testing.T) []PublishedEntry {
+	t.Helper()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	entries := make([]PublishedEntry, len(p.payloads))
+	for i, payload := range p.payloads {
+		if err := json.Unmarshal(payload, &entries[i]); err != nil {
+			t.Fatalf("payload %s: %v", payload, err)
+		}
+	}
+	return entries
+}
+
+func TestPublishErrorLogger(t *testing.T) {
+	tests := []struct {
+		name       string
+		log        func(l *PublishErrorLogger)
+		wantLevel  string
+		wantMsg    string
+		wantFields map[string]interface{}
+	}{
+		{"Debug", func(l *PublishErrorLogger) { l.Debugw("m", "k", "v") }, "", "", nil},
+		{"Info", func(l *PublishErrorLogger) { l.Infof("m %d", 1) }, "", "", nil},
+		{"Warn", func(l *PublishErrorLogger) { l.Warn("m") }, "", "", nil},
+		{"Error", func(l *PublishErrorLogger) { l.Error("failed ", 42) }, "error", "failed 42", nil},
+		{"Errorf", func(l *PublishErrorLogger) { l.Errorf("failed %s", "x") }, "error", "failed x", nil},
+		{"Errorw", func(l *PublishErrorLogger) { l.Errorw("failed", "id", "x", "code", 500) }, "error", "failed",
+			map[string]interface{}{"id": "x", "code": float64(500)}},
+		{"error value", func(l *PublishErrorLogger) { l.Errorw("failed", "err", errPublish) }, "error", "failed",
+			map[string]interface{}{"err": "publish failed"}},
+		{"unencodable value", func(l *PublishErrorLogger) { l.Errorw("failed", "c", complex(1, 2), "code", 500) }, "error", "failed",
+			map[string]interface{}{"c": "(1+2i)", "code": "500"}},
+		{"odd keysValues", func(l *PublishErrorLogger) { l.Errorw("failed", "dangling") }, "error", "failed",
+			map[string]interface{}{log.MissingKey: "dangling", log.ErrorKey: "odd number of keysValues"}},
+		{"Fatalw", func(l *PublishErrorLogger) { l.Fatalw("down", "k", "v") }, "fatal", "down",
+			map[string]interface{}{"k": "v"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recordingLogger{}
+			pub := &recordingPublisher{}
+			l := NewPublishErrorLogger(rec, pub, "alerts")
+			tt.log(l)
+			if err := l.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := len(rec.recorded()); got != 1 {
+				t.Errorf("delegated %d entries, want 1", got)
+			}
+			entries := pub.entries(t)
+			if tt.wantLevel == "" {
+				if len(entries) != 0 {
+					t.Errorf("published %+v, want nothing", entries)
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("published %d entries, want 1", len(entries))
+			}
+			e := entries[0]
+			if e.Level != tt.wantLevel || e.Message != tt.wantMsg || e.Timestamp.IsZero() || pub.topics[0] != "alerts" {
+				t.Errorf("published %+v on %s, want %s %q", e, pub.topics[0], tt.wantLevel, tt.wantMsg)
+			}
+			if len(e.Fields) != len(tt.wantFields) {
+				t.Errorf("fields = %v, want %v", e.Fields, tt.wantFields)
+			}
+			for key, want := range tt.wantFields {
+				if got := e.Fields[key]; got != want {
+					t.Errorf("field %q = %v, want %v", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPublishErrorLoggerPanic(t *testing.T) {
+	rec := &recordingLogger{}
+	pub := &recordingPublisher{}
+	l := NewPublishErrorLogger(rec, pub, "alerts")
+	defer l.Close()
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Panicw did not panic")
+			}
+		}()
+		l.Panicw("boom", "k", "v")
+	}()
+
+	// The entry is published synchronously, before the delegated call panics.
+	if entries := pub.entries(t); len(entries) != 1 || entries[0].Level != "panic" || entries[0].Message != "boom" {
+		t.Errorf("published %+v, want the panic entry", entries)
+	}
+}
+
+func TestPublishErrorLoggerFlushesBeforeFatal(t *testing.T) {
+	gate := make(chan struct{})
+	pub := &recordingPublisher{gate: gate}
+	l := NewPublishErrorLogger(&recordingLogger{}, pub, "alerts")
+	defer l.Close()
+	l.Error("first")
+	l.Error("second")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Fatal("down")
+	}()
+	close(gate)
+	<-done
+
+	// The queued entries are published before the fatal one.
+	entries := pub.entries(t)
+	if len(entries) != 3 || entries[0].Message != "first" || entries[1].Message != "second" || entries[2].Message != "down" {
+		t.Errorf("published %+v, want first, second, down", entries)
+	}
+}
+
+func TestPublishErrorLoggerStalledPublisher(t *testing.T) {
+	gate := make(chan struct{})
+	rec := &recordingLogger{}
+	l := NewPublishErrorLogger(rec, &recordingPublisher{gate: gate}, "alerts", WithPublishTimeout(50*time.Millisecond))
+	defer func() {
+		close(gate)
+		_ = l.Close()
+	}()
+	for i := 0; i < DefaultPublishQueueSize+1; i++ {
+		l.Error(i)
+	}
+	failed := l.Failed()
+
+	start := time.Now()
+	l.Fatal("down")
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Fatal took %v with a stalled publisher, want about the publish timeout", elapsed)
+	}
+	if got := rec.recorded(); got[len(got)-1] != "down" {
+		t.Errorf("last delegated entry = %q, want down", got[len(got)-1])
+	}
+	if got := l.Failed(); got != failed+1 {
+		t.Errorf("Failed = %d, want %d", got, failed+1)
+	}
+}
+
+func TestPublishErrorLoggerFailures(t *testing.T) {
+	t.Run("publish failure", func(t *testing.T) {
+		l := NewPublishErrorLogger(&recordingLogger{}, &recordingPublisher{err: errPublish}, "alerts")
+		l.Error("a")
+		l.Fatal("b")
+		_ = l.Close()
+		if got := l.Failed(); got != 2 {
+			t.Errorf("Failed = %d, want 2", got)
+		}
+	})
+
+	t.Run("full queue", func(t *testing.T) {
+		gate := make(chan struct{})
+		rec := &recordingLogger{}
+		l := NewPublishErrorLogger(rec, &recordingPublisher{gate: gate}, "alerts")
+		// The background goroutine takes at most one entry while the publisher is blocked.
+		total := DefaultPublishQueueSize + 10
+		for i := 0; i < total; i++ {
+			l.Error(i)
+		}
+		if got := l.Failed(); got < 9 {
+			t.Errorf("Failed = %d, want at least 9", got)
+		}
+		if got := len(rec.recorded()); got != total {
+			t.Errorf("delegated %d entries, want %d", got, total)
+		}
+		close(gate)
+		_ = l.Close()
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		l := NewPublishErrorLogger(&recordingLogger{}, &recordingPublisher{}, "alerts")
+		_ = l.Close()
+		_ = l.Close()
+		l.Error("late")
+		if got := l.Failed(); got != 1 {
+			t.Errorf("Failed = %d, want 1", got)
+		}
+	})
+}
+
+func TestPublishErrorLoggerEnabled(t *testing.T) {
+	l := NewPublishErrorLogger(&minLevelLogger{min: log.WarnLevel}, &recordingPublisher{}, "alerts")
+	defer l.Close()
+	if l.Enabled(log.InfoLevel) || !l.Enabled(log.ErrorLevel) {
+		t.Error("Enabled does not follow the underlying Logger")
+	}
+}
@@ -0,0 +1,135 @@
+package log
+
+import "strings"
+
+// RedactedValue is the placeholder that replaces the value of a sensitive key
+// in structured log entries emitted through a RedactingLogger.
+const RedactedValue = "***"
+
+// RedactingLogger is a Logger wrapper that masks the values of configured sensitive keys
+// (e.g. "password", "token", "authorization") in structured logs before delegating to the
+// underlying Logger. Key matching is case-insensitive. Unstructured and formatted logs
+// are passed through unchanged.
+//
+// A structured call with an odd number of keysValues does not panic: the dangling value
+// is logged under MissingKey and an ErrorKey field reports the mistake.
+type RedactingLogger struct {
+	logger Logger
+	keys   map[string]struct{}
+}
+
+// NewRedactingLogger creates a RedactingLogger that delegates to the given logger and redacts
+// the values of the provided sensitive keys in the `*w` logging methods.
+//
+//	logger := log.NewRedactingLogger(base, "password", "token", "authorization")
+//	logger.Infow("login", "user", "alice", "password", "hunter2") // password=***
+func NewRedactingLogger(logger Logger, keys ...string) *RedactingLogger {
+	sensitive := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		sensitive[strings.ToLower(key)] = struct{}{}
+	}
+	return &RedactingLogger{logger: logger, keys: sensitive}
+}
+
+// redact returns a copy of keysValues in which the value following every sensitive key
+// is replaced with RedactedValue. An odd number of keysValues is normalized first so the
+// dangling value is kept under MissingKey. The original slice is never modified.
+func (l *RedactingLogger) redact(keysValues []interface{}) []interface{} {
+	keysValues = NormalizeKeysValues(keysValues)
+	redacted := make([]interface{}, len(keysValues))
+	copy(redacted, keysValues)
+	for i := 0; i+1 < len(redacted); i += 2 {
+		key, ok := redacted[i].(string)
+		if !ok {
+			continue
+		}
+		if _, sensitive := l.keys[strings.ToLower(key)]; sensitive {
+			redacted[i+1] = RedactedValue
+		}
+	}
+	return redacted
+}
+
+// Debug delegates to the underlying Logger.
+func (l *RedactingLogger) Debug(args ...interface{}) { l.logger.Debug(args...) }
+
+// Debugf delegates to the underlying Logger.
+func (l *RedactingLogger) Debugf(template string, args ...interface{}) {
+	l.logger.Debugf(template, args...)
+}
+
+// Debugw redacts sensitive keys and delegates to the underlying Logger.
+func (l *RedactingLogger) Debugw(msg string, keysValues ...interface{}) {
+	l.logger.Debugw(msg, l.redact(keysValues)...)
+}
+
+// Info delegates to the underlying Logger.
+func (l *RedactingLogger) Info(args ...interface{}) { l.logger.Info(args...) }
+
+// Infof delegates to the underlying Logger.
+func (l *RedactingLogger) Infof(template string, args ...interface{}) {
+	l.logger.Infof(template, args...)
+}
+
+// Infow redacts sensitive keys and delegates to the underlying Logger.
+func (l *RedactingLogger) Infow(msg string, keysValues ...interface{}) {
+	l.logger.Infow(msg, l.redact(keysValues)...)
+}
+
+// Warn delegates to the underlying Logger.
+func (l *RedactingLogger) Warn(args ...interface{}) { l.logger.Warn(args...) }
+
+// Warnf delegates to the underlying Logger.
+func (l *RedactingLogger) Warnf(template string, args ...interface{}) {
+	l.logger.Warnf(template, args...)
+}
+
+// Warnw redacts sensitive keys and delegates to the underlying Logger.
+func (l *RedactingLogger) Warnw(msg string, keysValues ...interface{}) {
+	l.logger.Warnw(msg, l.redact(keysValues)...)
+}
+
+// Error delegates to the underlying Logger.
+func (l *RedactingLogger) Error(args ...interface{}) { l.logger.Error(args...) }
+
+// Errorf delegates to the underlying Logger.
+func (l *RedactingLogger) Errorf(template string, args ...interface{}) {
+	l.logger.Errorf(template, args...)
+}
+
+// Errorw redacts sensitive keys and delegates to the underlying Logger.
+func (l *RedactingLogger) Errorw(msg string, keysValues ...interface{}) {
+	l.logger.Errorw(msg, l.redact(keysValues)...)
+}
+
+// Panic delegates to the underlying Logger.
+func (l *RedactingLogger) Panic(args ...interface{}) { l.logger.Panic(args...) }
+
+// Panicf delegates to the underlying Logger.
+func (l *RedactingLogger) Panicf(template string, args ...interface{}) {
+	l.logger.Panicf(template, args...)
+}
+
+// Panicw redacts sensitive keys and delegates to the underlying Logger.
+func (l *RedactingLogger) Panicw(msg string, keysValues ...interface{}) {
+	l.logger.Panicw(msg, l.redact(keysValues)...)
+}
+
+// Fatal delegates to the underlying Logger.
+func (l *RedactingLogger) Fatal(args ...interface{}) { l.logger.Fatal(args...) }
+
+// Fatalf delegates to the underlying Logger.
+func (l *RedactingLogger) Fatalf(template string, args ...interface{}) {
+	l.logger.Fatalf(template, args...)
+}
+
+// Fatalw redacts sensitive keys and delegates to the underlying Logger.
+func (l *RedactingLogger) Fatalw(msg string, keysValues ...interface{}) {
+	l.logger.Fatalw(msg, l.redact(keysValues)...)
+}
+
+// Enabled reports whether the underlying Logger emits entries of the given level.
+// It implements LevelEnabler.
+func (l *RedactingLogger) Enabled(level Level) bool {
+	return Enabled(l.logger, level)
+}
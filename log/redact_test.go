@@ -0,0 +1,81 @@
+package log
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRedactingLogger(t *testing.T) {
+	tests := []struct {
+		name       string
+		log        func(l Logger)
+		level      Level
+		wantFields []interface{}
+	}{
+		{
+			name:       "sensitive key",
+			log:        func(l Logger) { l.Infow("login", "password", "hunter2") },
+			level:      InfoLevel,
+			wantFields: []interface{}{"password", RedactedValue},
+		},
+		{
+			name:       "case-insensitive key",
+			log:        func(l Logger) { l.Warnw("login", "Authorization", "Bearer abc") },
+			level:      WarnLevel,
+			wantFields: []interface{}{"Authorization", RedactedValue},
+		},
+		{
+			name:       "non-sensitive keys pass through",
+			log:        func(l Logger) { l.Errorw("login", "user", "alice", "attempts", 3) },
+			level:      ErrorLevel,
+			wantFields: []interface{}{"user", "alice", "attempts", 3},
+		},
+		{
+			name:       "mixed keys",
+			log:        func(l Logger) { l.Debugw("login", "user", "alice", "token", "t0k3n") },
+			level:      DebugLevel,
+			wantFields: []interface{}{"user", "alice", "token", RedactedValue},
+		},
+		{
+			name:       "odd keysValues",
+			log:        func(l Logger) { l.Infow("login", "password", "hunter2", "dangling") },
+			level:      InfoLevel,
+			wantFields: []interface{}{"password", RedactedValue, MissingKey, "dangling", ErrorKey, oddKeysValuesMessage},
+		},
+		{
+			name:  "unstructured logs unchanged",
+			log:   func(l Logger) { l.Infof("password=%s", "hunter2") },
+			level: InfoLevel,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recordingLogger{}
+			tt.log(NewRedactingLogger(rec, "password", "token", "authorization"))
+
+			entries := rec.recorded()
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			if entries[0].level != tt.level {
+				t.Errorf("level = %v, want %v", entries[0].level, tt.level)
+			}
+			if got, want := fmt.Sprint(entries[0].keysValues), fmt.Sprint(tt.wantFields); got != want {
+				t.Errorf("keysValues = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRedactingLoggerKeepsCallerSlice(t *testing.T) {
+	rec := &recordingLogger{}
+	keysValues := []interface{}{"password", "hunter2"}
+	NewRedactingLogger(rec, "password").Infow("login", keysValues...)
+
+	if keysValues[1] != "hunter2" {
+		t.Errorf("caller slice modified: %v", keysValues)
+	}
+	if msg := rec.messages(); len(msg) != 1 || msg[0] != "login" {
+		t.Errorf("messages = %v, want [login]", msg)
+	}
+}
@@ -0,0 +1,137 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/app"
+	"gorm.io/gorm"
+)
+
+// DefaultPingTimeout is the default bound of the ping issued by OrmApp.Run to verify the
+// database connection.
+const DefaultPingTimeout = 5 * time.Second
+
+// OrmAppOption configures an OrmApp.
+type OrmAppOption func(*OrmApp)
+
+// WithPingTimeout sets the bound of the ping issued by Run. It defaults to DefaultPingTimeout.
+func WithPingTimeout(d time.Duration) OrmAppOption {
+	return func(a *OrmApp) {
+		a.pingTimeout = d
+	}
+}
+
+// PoolConfig holds the connection pool settings applied by OrmApp to the underlying *sql.DB.
+// Zero values keep the database/sql defaults.
+type PoolConfig struct {
+	// MaxOpenConns is the maximum number of open connections to the database.
+	MaxOpenConns int `json:"maxOpenConns" yaml:"maxOpenConns"`
+	// MaxIdleConns is the maximum number of connections kept in the idle pool.
+	MaxIdleConns int `json:"maxIdleConns" yaml:"maxIdleConns"`
+	// ConnMaxLifetime is the maximum amount of time a connection may be reused.
+	ConnMaxLifetime time.Duration `json:"connMaxLifetime" yaml:"connMaxLifetime"`
+	// ConnMaxIdleTime is the maximum amount of time a connection may be idle.
+	ConnMaxIdleTime time.Duration `json:"connMaxIdleTime" yaml:"connMaxIdleTime"`
+}
+
+// OrmApp is an app.App that ties a database connection to the application lifecycle: Run
+// opens the connection, applies the pool settings, and pings the database, and Shutdown
+// closes the connection pool. The connection is available through DB once Run succeeded.
+type OrmApp struct {
+	dialector   gorm.Dialector
+	config      *gorm.Config
+	pool        PoolConfig
+	pingTimeout time.Duration
+
+	mu sync.RWMutex
+	db *gorm.DB
+}
+
+var _ app.App = (*OrmApp)(nil)
+
+// NewOrmApp creates an OrmApp connecting with dialector and config (a default gorm.Config
+// if nil) and applying pool to the connection pool.
+//
+//	database := orm.NewOrmApp(mysql.Open(dsn), orm.PoolConfig{MaxOpenConns: 20, ConnMaxLifetime: time.Hour}, nil)
+//	if err := database.Run(); err != nil {
+//		return err
+//	}
+//	defer database.Shutdown()
+//	db := database.DB()
+func NewOrmApp(dialector gorm.Dialector, pool PoolConfig, config *gorm.Config, opts ...OrmAppOption) *OrmApp {
+	if config == nil {
+		config = &gorm.Config{}
+	}
+	a := &OrmApp{dialector: dialector, config: config, pool: pool, pingTimeout: DefaultPingTimeout}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// DB returns the database connection, or nil if the OrmApp is not running.
+func (a *OrmApp) DB() *gorm.DB {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.db
+}
+
+// Run opens the database connection, applies the pool settings, and pings the database
+// within the ping timeout. On failure, the connection is closed and the error is returned.
+// Calling Run while the OrmApp is running is a no-op.
+func (a *OrmApp) Run() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.db != nil {
+		return nil
+	}
+
+	db, err := gorm.Open(a.dialector, a.config)
+	if err != nil {
+		return fmt.Errorf("orm: open: %w", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("orm: open: %w", err)
+	}
+	if a.pool.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(a.pool.MaxOpenConns)
+	}
+	if a.pool.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(a.pool.MaxIdleConns)
+	}
+	if a.pool.ConnMaxLifetime > 0 {
+		sqlDB.SetConnMaxLifetime(a.pool.ConnMaxLifetime)
+	}
+	if a.pool.ConnMaxIdleTime > 0 {
+		sqlDB.SetConnMaxIdleTime(a.pool.ConnMaxIdleTime)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.pingTimeout)
+	defer cancel()
+	if err := sqlDB.PingContext(ctx); err != nil {
+		_ = sqlDB.Close()
+		return fmt.Errorf("orm: ping: %w", err)
+	}
+	a.db = db
+	return nil
+}
+
+// Shutdown closes the connection pool, waiting for in-use connections to be returned.
+// Calling Shutdown while the OrmApp is not running is a no-op.
+func (a *OrmApp) Shutdown() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.db == nil {
+		return nil
+	}
+	sqlDB, err := a.db.DB()
+	a.db = nil
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
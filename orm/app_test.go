@@ -0,0 +1,67 @@
+package orm
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestOrmApp(t *testing.T) {
+	a := NewOrmApp(sqlite.Open("file::memory:"), PoolConfig{
+		MaxOpenConns:    3,
+		MaxIdleConns:    2,
+		ConnMaxLifetime: time.Hour,
+		ConnMaxIdleTime: time.Minute,
+	}, &gorm.Config{Logger: logger.Discard})
+	if a.DB() != nil {
+		t.Fatal("DB is set before Run")
+	}
+
+	if err := a.Run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	db := a.DB()
+	if db == nil {
+		t.Fatal("DB is nil after Run")
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sqlDB.Stats().MaxOpenConnections; got != 3 {
+		t.Errorf("MaxOpenConnections = %d, want 3", got)
+	}
+	if err := a.Run(); err != nil || a.DB() != db {
+		t.Errorf("second Run = %v, want a no-op", err)
+	}
+
+	if err := a.Shutdown(); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if a.DB() != nil {
+		t.Error("DB is set after Shutdown")
+	}
+	if err := sqlDB.Ping(); err == nil {
+		t.Error("connection pool still open after Shutdown")
+	}
+	if err := a.Shutdown(); err != nil {
+		t.Errorf("second Shutdown = %v, want nil", err)
+	}
+}
+
+func TestOrmAppRunFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "app.db")
+	a := NewOrmApp(sqlite.Open(path), PoolConfig{}, &gorm.Config{Logger: logger.Discard})
+	err := a.Run()
+	if err == nil || !strings.HasPrefix(err.Error(), "orm: open:") {
+		t.Fatalf("err = %v, want an open error", err)
+	}
+	if a.DB() != nil {
+		t.Error("DB is set after a failed Run")
+	}
+}
@@ -38,7 +38,7 @@ type MModel struct {
 	// UpdatedAt stores the timestamp of the most recent modification to the record.
 	// This value is automatically updated to the current timestamp whenever the record is modified.
 	// It is indexed to allow efficient queries for recently updated records.
-	UpdatedAt time.Time `json:"updatedAt" gorm:"column:UPDATED_AT;type:datetime(6);default:CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6);index:IDX_UPDATED_AT"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"column:UPDATED_AT;type:datetime(6);default:CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6);index:IDX_UPDATED_AT;autoUpdateTime:false"`
 
 	// DeletedAt is used for soft deletion of records. When a record is soft-deleted,
 	// this field is populated with the deletion timestamp, but the record itself remains in the database.
@@ -48,9 +48,27 @@ type MModel struct {
 }
 
 // BeforeCreate is a GORM hook that runs before a new record is inserted into the database.
-// This function generates a new UUID for the ID field of the MModel struct.
-func (mModel *MModel) BeforeCreate(*gorm.DB) error {
-	mModel.ID = uuid.New().String()
+// This function generates a new UUID for the ID field of the MModel struct, unless the record
+// is inserted by ImportCreate with an ID, and sets the CreatedAt and UpdatedAt fields from
+// the package clock if they are not already set.
+func (mModel *MModel) BeforeCreate(tx *gorm.DB) error {
+	if mModel.ID == "" || !isImport(tx) {
+		mModel.ID = uuid.New().String()
+	}
+	now := Now()
+	if mModel.CreatedAt.IsZero() {
+		mModel.CreatedAt = now
+	}
+	if mModel.UpdatedAt.IsZero() {
+		mModel.UpdatedAt = now
+	}
+	return nil
+}
+
+// BeforeUpdate is a GORM hook that runs before a record is updated in the database.
+// This function sets the UPDATED_AT column from the package clock.
+func (mModel *MModel) BeforeUpdate(tx *gorm.DB) error {
+	tx.Statement.SetColumn("UpdatedAt", Now())
 	return nil
 }
 
@@ -75,7 +93,7 @@ type PModel struct {
 	// Like `CreatedAt`, it uses `timestamp(6)` for microsecond precision and is
 	// automatically updated whenever the record is modified. This field is indexed
 	// for optimized queries involving recently updated records.
-	UpdatedAt time.Time `json:"updatedAt" gorm:"column:UPDATED_AT;type:timestamp(6);default:CURRENT_TIMESTAMP(6);index"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"column:UPDATED_AT;type:timestamp(6);default:CURRENT_TIMESTAMP(6);index;autoUpdateTime:false"`
 
 	// DeletedAt is a field used for soft deletion of records. When a record is soft-deleted,
 	// this field stores the timestamp of deletion, while the record remains in the database.
@@ -84,3 +102,24 @@ type PModel struct {
 	// efficient filtering of active and deleted records.
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"column:DELETED_AT;type:timestamp(6);index"`
 }
+
+// BeforeCreate is a GORM hook that runs before a new record is inserted into the database.
+// This function sets the CreatedAt and UpdatedAt fields of the PModel struct from the
+// package clock if they are not already set. The ID is left to the database default.
+func (pModel *PModel) BeforeCreate(*gorm.DB) error {
+	now := Now()
+	if pModel.CreatedAt.IsZero() {
+		pModel.CreatedAt = now
+	}
+	if pModel.UpdatedAt.IsZero() {
+		pModel.UpdatedAt = now
+	}
+	return nil
+}
+
+// BeforeUpdate is a GORM hook that runs before a record is updated in the database.
+// This function sets the UPDATED_AT column from the package clock.
+func (pModel *PModel) BeforeUpdate(tx *gorm.DB) error {
+	tx.Statement.SetColumn("UpdatedAt", Now())
+	return nil
+}
@@ -0,0 +1,59 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BatchLoadByFK returns the rows of type T whose fkColumn is one of parentIDs, grouped by
+// foreign key, e.g. the orders of a page of customers. It is the building block of dataloaders
+// resolving the children of many parents without issuing one query per parent.
+//
+// The rows are loaded with one `fkColumn IN (?)` query per DefaultChunkSize distinct parent
+// IDs, so typically a single query. Every parent ID is a key of the returned map, with a nil
+// slice if it has no children. fkColumn may be a struct field name or a column name; unknown
+// names return an error wrapping ErrUnknownColumn. The soft-delete scoping of db is
+// preserved, so soft-deleted rows are excluded unless db is Unscoped.
+//
+//	ordersByCustomer, err := orm.BatchLoadByFK[Order](db, "CUSTOMER_ID", customerIDs)
+func BatchLoadByFK[T any](db *gorm.DB, fkColumn string, parentIDs []string) (map[string][]T, error) {
+	stmt := db.Session(&gorm.Session{NewDB: true}).Model(new(T)).Statement
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	field := stmt.Schema.LookUpField(fkColumn)
+	if field == nil || field.DBName == "" {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownColumn, fkColumn)
+	}
+
+	result := make(map[string][]T, len(parentIDs))
+	unique := make([]string, 0, len(parentIDs))
+	for _, id := range parentIDs {
+		if _, ok := result[id]; ok {
+			continue
+		}
+		result[id] = nil
+		unique = append(unique, id)
+	}
+
+	for start := 0; start < len(unique); start += DefaultChunkSize {
+		end := start + DefaultChunkSize
+		if end > len(unique) {
+			end = len(unique)
+		}
+		var chunk []T
+		// A fresh session keeps the conditions of db without accumulating earlier chunks.
+		if err := db.Session(&gorm.Session{}).Where(clause.Eq{Column: column(field.DBName), Value: unique[start:end]}).Find(&chunk).Error; err != nil {
+			return nil, err
+		}
+		for i := range chunk {
+			value, _ := field.ValueOf(stmt.Context, reflect.ValueOf(&chunk[i]).Elem())
+			fk := fmt.Sprint(reflect.Indirect(reflect.ValueOf(value)))
+			result[fk] = append(result[fk], chunk[i])
+		}
+	}
+	return result, nil
+}
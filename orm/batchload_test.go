@@ -0,0 +1,126 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/zeroxsolutions/barbatos/orm/ormtest"
+	"gorm.io/gorm"
+)
+
+// testOrder is a child of testUser used by the relation tests.
+type testOrder struct {
+	ID        string         `gorm:"column:ID;primaryKey"`
+	UserID    string         `gorm:"column:USER_ID"`
+	Status    string         `gorm:"column:STATUS"`
+	DeletedAt gorm.DeletedAt `gorm:"column:DELETED_AT"`
+}
+
+const testOrdersTable = `CREATE TABLE test_orders (ID varchar(36) PRIMARY KEY, USER_ID varchar(36), STATUS varchar(32), DELETED_AT datetime)`
+
+func TestBatchLoadByFK(t *testing.T) {
+	db := newTestDB(t, testOrdersTable)
+	orders := []testOrder{
+		{ID: "o1", UserID: "u1"},
+		{ID: "o2", UserID: "u1"},
+		{ID: "o3", UserID: "u2"},
+		{ID: "o4", UserID: "u3"},
+	}
+	if err := db.Create(&orders).Error; err != nil {
+		t.Fatalf("create orders: %v", err)
+	}
+	if err := db.Delete(&testOrder{}, "ID = ?", "o3").Error; err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	counter := ormtest.NewQueryCounter()
+	if err := db.Use(counter); err != nil {
+		t.Fatalf("use counter: %v", err)
+	}
+	byUser, err := BatchLoadByFK[testOrder](db, "UserID", []string{"u1", "u2", "u3", "u4", "u1"})
+	if err != nil {
+		t.Fatalf("BatchLoadByFK: %v", err)
+	}
+	ormtest.AssertMaxQueries(t, counter, 1)
+
+	want := map[string]int{"u1": 2, "u2": 0, "u3": 1, "u4": 0}
+	if len(byUser) != len(want) {
+		t.Fatalf("got %d parents, want %d", len(byUser), len(want))
+	}
+	for parent, count := range want {
+		children, ok := byUser[parent]
+		if !ok {
+			t.Errorf("parent %s missing from the result", parent)
+		}
+		if len(children) != count {
+			t.Errorf("parent %s: got %d children, want %d", parent, len(children), count)
+		}
+		for _, child := range children {
+			if child.UserID != parent {
+				t.Errorf("order %s grouped under %s, belongs to %s", child.ID, parent, child.UserID)
+			}
+		}
+	}
+
+	if _, err := BatchLoadByFK[testOrder](db, "NOPE", []string{"u1"}); !errors.Is(err, ErrUnknownColumn) {
+		t.Fatalf("unknown column: got %v, want ErrUnknownColumn", err)
+	}
+}
+
+func TestBatchLoadByFKSeveralChunks(t *testing.T) {
+	db := newTestDB(t, testOrdersTable)
+	const parents = DefaultChunkSize + 250
+	parentIDs := make([]string, parents)
+	orders := make([]testOrder, 0, parents)
+	for i := range parentIDs {
+		parentIDs[i] = fmt.Sprintf("u%04d", i)
+		orders = append(orders, testOrder{ID: fmt.Sprintf("o%04d", i), UserID: parentIDs[i]})
+	}
+	if err := db.CreateInBatches(&orders, 200).Error; err != nil {
+		t.Fatalf("create orders: %v", err)
+	}
+	if err := db.Delete(&testOrder{}, "ID = ?", "o0000").Error; err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		db   *gorm.DB
+		want int
+	}{
+		{"plain", db, parents - 1},
+		{"unscoped", db.Unscoped(), parents},
+		{"chained where", db.Where("STATUS = ?", ""), parents - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			byUser, err := BatchLoadByFK[testOrder](tt.db, "USER_ID", parentIDs)
+			if err != nil {
+				t.Fatalf("BatchLoadByFK: %v", err)
+			}
+			total := 0
+			for _, children := range byUser {
+				total += len(children)
+			}
+			if total != tt.want {
+				t.Fatalf("got %d children, want %d", total, tt.want)
+			}
+			if len(byUser[parentIDs[parents-1]]) != 1 {
+				t.Fatalf("last chunk parent has %d children, want 1", len(byUser[parentIDs[parents-1]]))
+			}
+		})
+	}
+}
+
+func TestBatchLoadByFKPostgres(t *testing.T) {
+	db, statements := newPostgresDryRunDB(t)
+	if _, err := BatchLoadByFK[testOrder](db, "USER_ID", []string{"u1", "u2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `SELECT * FROM "test_orders" WHERE "test_orders"."USER_ID" IN ($1,$2) AND "test_orders"."DELETED_AT" IS NULL`
+	if got := statements(); len(got) != 1 || got[0] != want {
+		t.Errorf("SQL = %q, want [%q]", got, want)
+	}
+}
@@ -0,0 +1,99 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UpdateIfChanged compares the provided columns with the current row of type T identified
+// by id and updates only the columns whose value differs, through UpdateColumns, so that
+// UPDATED_AT is bumped only when something actually changed. It reports whether a write
+// occurred, which lets callers skip downstream cache invalidation for no-op updates.
+//
+// Column names may be struct field names or column names; unknown names return an error
+// wrapping ErrUnknownColumn. Numeric values are compared after conversion to the field's type,
+// and times with time.Time.Equal. It returns ErrNotFound if no row matched the given ID.
+//
+//	changed, err := orm.UpdateIfChanged[User](db, id, map[string]interface{}{"NAME": name})
+func UpdateIfChanged[T any](db *gorm.DB, id string, changes map[string]interface{}) (bool, error) {
+	var current T
+	query := db.Model(new(T))
+	if err := query.Where(byID(id)).Take(&current).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, ErrNotFound
+		}
+		return false, err
+	}
+
+	rv := reflect.ValueOf(&current).Elem()
+	changed := make(map[string]interface{}, len(changes))
+	for column, value := range changes {
+		field := query.Statement.Schema.LookUpField(column)
+		if field == nil || field.DBName == "" {
+			return false, fmt.Errorf("%w: %q", ErrUnknownColumn, column)
+		}
+		currentValue, _ := field.ValueOf(db.Statement.Context, rv)
+		if !sameValue(currentValue, value) {
+			changed[field.DBName] = value
+		}
+	}
+	if len(changed) == 0 {
+		return false, nil
+	}
+	if err := UpdateColumns[T](db, id, changed); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// sameValue reports whether the requested value equals the current value of a field.
+func sameValue(current, requested interface{}) bool {
+	cv := reflect.ValueOf(current)
+	for cv.IsValid() && cv.Kind() == reflect.Ptr {
+		if cv.IsNil() {
+			// A nil pointer equals a nil requested value, whether untyped or a nil pointer.
+			cv = reflect.Value{}
+			break
+		}
+		cv = cv.Elem()
+	}
+	rv := reflect.ValueOf(requested)
+	for rv.IsValid() && rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return !cv.IsValid()
+		}
+		rv = rv.Elem()
+	}
+	if !cv.IsValid() || !rv.IsValid() {
+		return cv.IsValid() == rv.IsValid()
+	}
+
+	if ct, ok := cv.Interface().(time.Time); ok {
+		rt, ok := rv.Interface().(time.Time)
+		return ok && ct.Equal(rt)
+	}
+	if isNumber(cv.Kind()) && isNumber(rv.Kind()) && rv.Type().ConvertibleTo(cv.Type()) {
+		converted := rv.Convert(cv.Type())
+		// Reject lossy conversions, e.g. 1.5 requested for an integer field.
+		if !converted.Type().ConvertibleTo(rv.Type()) || converted.Convert(rv.Type()).Interface() != rv.Interface() {
+			return false
+		}
+		rv = converted
+	}
+	return reflect.DeepEqual(cv.Interface(), rv.Interface())
+}
+
+// isNumber reports whether kind is an integer or floating-point kind.
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package orm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestUpdateIfChanged(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	updated := created.Add(time.Hour)
+	tests := []struct {
+		name        string
+		changes     map[string]interface{}
+		wantChanged bool
+		wantName    string
+		wantActive  bool
+		wantErr     error
+	}{
+		{"same values", map[string]interface{}{"NAME": "alice", "IS_ACTIVE": true}, false, "alice", true, nil},
+		{"field names", map[string]interface{}{"Name": "alice"}, false, "alice", true, nil},
+		{"changed column", map[string]interface{}{"NAME": "alicia", "IS_ACTIVE": true}, true, "alicia", true, nil},
+		{"changed boolean", map[string]interface{}{"IsActive": false}, true, "alice", false, nil},
+		{"pointer value", map[string]interface{}{"NAME": strPtr("alice")}, false, "alice", true, nil},
+		{"unknown column", map[string]interface{}{"EMAIL": "a@example.com"}, false, "alice", true, ErrUnknownColumn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetClock(func() time.Time { return created })
+			defer SetClock(nil)
+			db := newTestDB(t, testUsersTable)
+			id := createUsers(t, db, "alice")[0]
+
+			SetClock(func() time.Time { return updated })
+			changed, err := UpdateIfChanged[testUser](db, id, tt.changes)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+
+			var user testUser
+			if err := db.Take(&user, "ID = ?", id).Error; err != nil {
+				t.Fatal(err)
+			}
+			if user.Name != tt.wantName || user.IsActive != tt.wantActive {
+				t.Errorf("user = %q active %v, want %q active %v", user.Name, user.IsActive, tt.wantName, tt.wantActive)
+			}
+			// UPDATED_AT is only bumped by an actual write.
+			wantUpdated := created
+			if tt.wantChanged {
+				wantUpdated = updated
+			}
+			if !user.UpdatedAt.Equal(wantUpdated) {
+				t.Errorf("UpdatedAt = %v, want %v", user.UpdatedAt, wantUpdated)
+			}
+		})
+	}
+}
+
+func TestUpdateIfChangedNotFound(t *testing.T) {
+	db := newTestDB(t, testUsersTable)
+	if _, err := UpdateIfChanged[testUser](db, "missing", map[string]interface{}{"NAME": "x"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestSameValue(t *testing.T) {
+	moment := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var nilTime *time.Time
+	tests := []struct {
+		name      string
+		current   interface{}
+		requested interface{}
+		want      bool
+	}{
+		{"equal strings", "a", "a", true},
+		{"different strings", "a", "b", false},
+		{"int and int64", int64(3), 3, true},
+		{"int and float", int64(3), 3.0, true},
+		{"lossy float", int64(1), 1.5, false},
+		{"uint and negative int", uint8(255), -1, false},
+		{"equal times in other zones", moment, moment.In(time.FixedZone("X", 3600)), true},
+		{"different times", moment, moment.Add(time.Second), false},
+		{"time and string", moment, "2024-01-01", false},
+		{"pointer and value", strPtr("a"), "a", true},
+		{"nil pointer and nil", nilTime, nil, true},
+		{"nil pointer and value", nilTime, moment, false},
+		{"nil pointers", nilTime, (*time.Time)(nil), true},
+		{"nil pointer and pointer", nilTime, &moment, false},
+		{"value and nil", "a", nil, false},
+		{"value and nil pointer", "a", (*string)(nil), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameValue(tt.current, tt.requested); got != tt.want {
+				t.Errorf("sameValue(%v, %v) = %v, want %v", tt.current, tt.requested, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateIfChangedPostgres(t *testing.T) {
+	db, statements := newPostgresDryRunDB(t)
+	_, _ = UpdateIfChanged[testUser](db, "u1", map[string]interface{}{"NAME": "bob"})
+
+	want := `SELECT * FROM "test_users" WHERE "test_users"."ID" = $1 AND "test_users"."DELETED_AT" IS NULL LIMIT $2`
+	if got := statements(); len(got) == 0 || got[0] != want {
+		t.Errorf("SQL = %q, want %q first", got, want)
+	}
+}
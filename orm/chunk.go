@@ -0,0 +1,47 @@
+package orm
+
+import "gorm.io/gorm"
+
+// DefaultChunkSize is the number of IDs per query used by FindByIDs when no valid chunk
+// size is given. It stays well below the placeholder limits of the supported drivers.
+const DefaultChunkSize = 1000
+
+// FindByIDs returns the rows of type T whose ID is in ids, issuing one `ID IN (?)` query
+// per chunk of at most chunkSize IDs so that large ID sets never exceed the placeholder
+// limits of the driver. A chunkSize below 1 defaults to DefaultChunkSize.
+//
+// Duplicate IDs are queried once, so every matching row is returned exactly once. Rows are
+// returned in chunk order but the order within a chunk is unspecified. The soft-delete
+// scoping of db is preserved, so soft-deleted rows are excluded unless db is Unscoped.
+//
+//	users, err := orm.FindByIDs[User](db, ids, 500)
+func FindByIDs[T any](db *gorm.DB, ids []string, chunkSize int) ([]T, error) {
+	if chunkSize < 1 {
+		chunkSize = DefaultChunkSize
+	}
+
+	unique := make([]string, 0, len(ids))
+	seen := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+
+	var result []T
+	for start := 0; start < len(unique); start += chunkSize {
+		end := start + chunkSize
+		if end > len(unique) {
+			end = len(unique)
+		}
+		var chunk []T
+		// A fresh session keeps the conditions of db without accumulating earlier chunks.
+		if err := db.Session(&gorm.Session{}).Where(byID(unique[start:end])).Find(&chunk).Error; err != nil {
+			return nil, err
+		}
+		result = append(result, chunk...)
+	}
+	return result, nil
+}
@@ -0,0 +1,59 @@
+package orm
+
+import (
+	"fmt"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestFindByIDs(t *testing.T) {
+	db := newTestDB(t, testUsersTable)
+	names := make([]string, 25)
+	for i := range names {
+		names[i] = fmt.Sprintf("user-%02d", i)
+	}
+	ids := createUsers(t, db, names...)
+	if err := db.Delete(&testUser{}, "ID = ?", ids[0]).Error; err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	withDuplicates := append(append([]string{}, ids...), ids[1], "missing")
+
+	tests := []struct {
+		name      string
+		db        *gorm.DB
+		chunkSize int
+		want      int
+	}{
+		{"single chunk", db, 0, 24},
+		{"several chunks", db, 10, 24},
+		{"unscoped several chunks", db.Unscoped(), 10, 25},
+		{"chained where several chunks", db.Where("NAME <> ?", "user-01"), 7, 23},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, err := FindByIDs[testUser](tt.db, withDuplicates, tt.chunkSize)
+			if err != nil {
+				t.Fatalf("FindByIDs: %v", err)
+			}
+			if len(users) != tt.want {
+				t.Fatalf("got %d users, want %d", len(users), tt.want)
+			}
+		})
+	}
+}
+
+func TestFindByIDsPostgres(t *testing.T) {
+	db, statements := newPostgresDryRunDB(t)
+	if _, err := FindByIDs[testUser](db, []string{"u1", "u2", "u3"}, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`SELECT * FROM "test_users" WHERE "test_users"."ID" IN ($1,$2) AND "test_users"."DELETED_AT" IS NULL`,
+		`SELECT * FROM "test_users" WHERE "test_users"."ID" IN ($1) AND "test_users"."DELETED_AT" IS NULL`,
+	}
+	if got := statements(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("SQL = %q, want %q", got, want)
+	}
+}
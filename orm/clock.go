@@ -0,0 +1,22 @@
+package orm
+
+import "time"
+
+// Now returns the current time used by the model hooks and helpers of this package
+// to populate `CreatedAt` and `UpdatedAt`. It defaults to time.Now and can be replaced
+// with SetClock to make time-dependent tests deterministic.
+var Now func() time.Time = time.Now
+
+// SetClock replaces the clock used by the model hooks and helpers of this package.
+// Passing nil restores the default wall clock. It is intended for tests and is not
+// safe to call concurrently with database operations.
+//
+//	frozen := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+//	orm.SetClock(func() time.Time { return frozen })
+//	defer orm.SetClock(nil)
+func SetClock(clock func() time.Time) {
+	if clock == nil {
+		clock = time.Now
+	}
+	Now = clock
+}
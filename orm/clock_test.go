@@ -0,0 +1,80 @@
+package orm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetClock(t *testing.T) {
+	frozen := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return frozen })
+	if got := Now(); !got.Equal(frozen) {
+		t.Errorf("Now = %v, want %v", got, frozen)
+	}
+	SetClock(nil)
+	if got := Now(); got.Equal(frozen) || time.Since(got) > time.Minute {
+		t.Errorf("Now after SetClock(nil) = %v, want the wall clock", got)
+	}
+}
+
+func TestClockTimestamps(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	updated := created.Add(time.Hour)
+	preset := created.Add(-24 * time.Hour)
+	defer SetClock(nil)
+
+	tests := []struct {
+		name        string
+		presetTimes bool
+		wantCreated time.Time
+	}{
+		{"clock timestamps", false, created},
+		{"preset timestamps kept", true, preset},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, testUsersTable)
+			SetClock(func() time.Time { return created })
+			user := testUser{Name: "alice"}
+			if tt.presetTimes {
+				user.CreatedAt, user.UpdatedAt = preset, preset
+			}
+			if err := db.Create(&user).Error; err != nil {
+				t.Fatalf("create: %v", err)
+			}
+
+			var stored testUser
+			if err := db.First(&stored, "ID = ?", user.ID).Error; err != nil {
+				t.Fatalf("find: %v", err)
+			}
+			if !stored.CreatedAt.Equal(tt.wantCreated) || !stored.UpdatedAt.Equal(tt.wantCreated) {
+				t.Errorf("after insert: CreatedAt = %v, UpdatedAt = %v, want %v", stored.CreatedAt, stored.UpdatedAt, tt.wantCreated)
+			}
+
+			SetClock(func() time.Time { return updated })
+			if err := db.Model(&stored).Update("NAME", "bob").Error; err != nil {
+				t.Fatalf("update: %v", err)
+			}
+			if err := db.First(&stored, "ID = ?", user.ID).Error; err != nil {
+				t.Fatalf("find: %v", err)
+			}
+			if !stored.CreatedAt.Equal(tt.wantCreated) || !stored.UpdatedAt.Equal(updated) {
+				t.Errorf("after update: CreatedAt = %v, UpdatedAt = %v, want %v, %v", stored.CreatedAt, stored.UpdatedAt, tt.wantCreated, updated)
+			}
+		})
+	}
+}
+
+func TestPModelClock(t *testing.T) {
+	frozen := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return frozen })
+	defer SetClock(nil)
+
+	var model PModel
+	if err := model.BeforeCreate(nil); err != nil {
+		t.Fatal(err)
+	}
+	if !model.CreatedAt.Equal(frozen) || !model.UpdatedAt.Equal(frozen) {
+		t.Errorf("CreatedAt = %v, UpdatedAt = %v, want %v", model.CreatedAt, model.UpdatedAt, frozen)
+	}
+}
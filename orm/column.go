@@ -0,0 +1,17 @@
+package orm
+
+import "gorm.io/gorm/clause"
+
+// column returns the named column of the current table as a clause.Column, which gorm quotes
+// for the dialect of the statement. Raw conditions such as "ID = ?" are left unquoted, and
+// PostgreSQL folds unquoted identifiers to lower case, so they miss the upper-case columns
+// declared by MModel and PModel.
+func column(name string) clause.Column {
+	return clause.Column{Table: clause.CurrentTable, Name: name}
+}
+
+// byID returns a condition matching the row whose ID primary key is id. If id is a slice,
+// such as a []string, the condition matches the rows whose ID is any of its elements.
+func byID(id interface{}) clause.Expression {
+	return clause.Eq{Column: column("ID"), Value: id}
+}
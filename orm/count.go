@@ -0,0 +1,28 @@
+package orm
+
+import "gorm.io/gorm"
+
+// CountActive returns the number of rows of type T matching conds, excluding soft-deleted rows.
+// The conditions are passed to Where, e.g. CountActive[User](db, "IS_ACTIVE = ?", true).
+func CountActive[T any](db *gorm.DB, conds ...interface{}) (int64, error) {
+	return count[T](db, conds)
+}
+
+// CountAll returns the number of rows of type T matching conds, including soft-deleted rows.
+// The conditions are passed to Where, e.g. CountAll[User](db, "IS_ACTIVE = ?", true).
+func CountAll[T any](db *gorm.DB, conds ...interface{}) (int64, error) {
+	return count[T](db.Unscoped(), conds)
+}
+
+// count counts the rows of type T matching conds using the scoping of db.
+func count[T any](db *gorm.DB, conds []interface{}) (int64, error) {
+	query := db.Model(new(T))
+	if len(conds) > 0 {
+		query = query.Where(conds[0], conds[1:]...)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
@@ -0,0 +1,43 @@
+package orm
+
+import "testing"
+
+func TestCount(t *testing.T) {
+	db := newTestDB(t, testUsersTable)
+	ids := createUsers(t, db, "alice", "bob", "carol", "dave")
+	if err := db.Model(&testUser{}).Where("ID = ?", ids[1]).Update("IS_ACTIVE", false).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&testUser{}, "ID IN ?", ids[2:]).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		count func() (int64, error)
+		want  int64
+	}{
+		{"active without conditions", func() (int64, error) { return CountActive[testUser](db) }, 2},
+		{"all without conditions", func() (int64, error) { return CountAll[testUser](db) }, 4},
+		{"active with condition", func() (int64, error) { return CountActive[testUser](db, "IS_ACTIVE = ?", true) }, 1},
+		{"all with condition", func() (int64, error) { return CountAll[testUser](db, "IS_ACTIVE = ?", true) }, 3},
+		{"all with several arguments", func() (int64, error) { return CountAll[testUser](db, "NAME IN (?, ?)", "alice", "dave") }, 2},
+		{"no match", func() (int64, error) { return CountActive[testUser](db, "NAME = ?", "erin") }, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.count()
+			if err != nil {
+				t.Fatalf("count: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("count = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	// The scoping of db is not changed by CountAll.
+	if got, err := CountActive[testUser](db); err != nil || got != 2 {
+		t.Errorf("CountActive after CountAll = %d, %v, want 2, nil", got, err)
+	}
+}
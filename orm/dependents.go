@@ -0,0 +1,87 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// CanDelete reports whether the record of type T identified by id has no active child rows
+// in the named relations, which must be has-one or has-many relationships of T. Soft-deleted
+// children are ignored, so they never block a delete. Unknown relations return an error
+// wrapping ErrUnknownRelation.
+//
+// Foreign key constraints cannot express this when children are soft-deleted, since their
+// rows still exist in the database.
+//
+//	ok, err := orm.CanDelete[Customer](db, id, "Orders", "Addresses")
+func CanDelete[T any](db *gorm.DB, id string, relations ...string) (bool, error) {
+	db = db.Session(&gorm.Session{NewDB: true})
+	stmt := db.Model(new(T)).Statement
+	if err := stmt.Parse(new(T)); err != nil {
+		return false, err
+	}
+	for _, name := range relations {
+		relation, ok := stmt.Schema.Relationships.Relations[name]
+		if !ok {
+			return false, fmt.Errorf("%w: %q on %s", ErrUnknownRelation, name, stmt.Schema.Name)
+		}
+		if relation.Type != schema.HasOne && relation.Type != schema.HasMany {
+			return false, fmt.Errorf("%w: %q on %s is not a has-one or has-many relation", ErrUnknownRelation, name, stmt.Schema.Name)
+		}
+
+		query := db.Model(reflect.New(relation.FieldSchema.ModelType).Interface())
+		for _, ref := range relation.References {
+			switch {
+			case ref.PrimaryValue != "":
+				// Polymorphic type column, e.g. OWNER_TYPE = 'customers'.
+				query = query.Where(clause.Eq{Column: column(ref.ForeignKey.DBName), Value: ref.PrimaryValue})
+			case ref.PrimaryKey.DBName == "ID":
+				query = query.Where(clause.Eq{Column: column(ref.ForeignKey.DBName), Value: id})
+			default:
+				parent := db.Unscoped().Model(new(T)).Select(ref.PrimaryKey.DBName).Where(byID(id))
+				query = query.Where(clause.Expr{SQL: "? IN (?)", Vars: []interface{}{column(ref.ForeignKey.DBName), parent}})
+			}
+		}
+		var children int64
+		if err := query.Count(&children).Error; err != nil {
+			return false, err
+		}
+		if children > 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// DeleteIfNoChildren deletes the record of type T identified by id, soft-deleting it if T
+// supports it, unless CanDelete finds active child rows in the named relations, in which case
+// it returns ErrHasDependents. The check and the delete run in one transaction. It returns
+// ErrNotFound if no live record matched the given ID.
+//
+//	err := orm.DeleteIfNoChildren[Customer](db, id, "Orders")
+//	if errors.Is(err, orm.ErrHasDependents) {
+//		return http.StatusConflict
+//	}
+func DeleteIfNoChildren[T any](db *gorm.DB, id string, relations ...string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		ok, err := CanDelete[T](tx, id, relations...)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrHasDependents
+		}
+		result := tx.Where(byID(id)).Delete(new(T))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
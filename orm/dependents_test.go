@@ -0,0 +1,129 @@
+package orm
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// testOwner has many testPets, has one testBadge, and belongs to a testUser.
+type testOwner struct {
+	MModel
+	Name     string     `gorm:"column:NAME"`
+	MentorID string     `gorm:"column:MENTOR_ID"`
+	Mentor   *testUser  `gorm:"foreignKey:MentorID"`
+	Pets     []testPet  `gorm:"foreignKey:OwnerID"`
+	Badge    *testBadge `gorm:"foreignKey:OwnerID"`
+}
+
+type testPet struct {
+	ID        int            `gorm:"column:ID;primaryKey"`
+	OwnerID   string         `gorm:"column:OWNER_ID"`
+	DeletedAt gorm.DeletedAt `gorm:"column:DELETED_AT"`
+}
+
+type testBadge struct {
+	ID      int    `gorm:"column:ID;primaryKey"`
+	OwnerID string `gorm:"column:OWNER_ID"`
+}
+
+// newDependentsDB creates the owner "lonely" without children, "busy" with an active pet and
+// a badge, and "former" whose only pet is soft-deleted.
+func newDependentsDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := newTestDB(t,
+		`CREATE TABLE test_owners (ID varchar(36) PRIMARY KEY, CREATED_AT datetime, UPDATED_AT datetime,
+			DELETED_AT datetime, NAME varchar(255), MENTOR_ID varchar(36))`,
+		"CREATE TABLE test_pets (ID integer PRIMARY KEY, OWNER_ID varchar(36), DELETED_AT datetime)",
+		"CREATE TABLE test_badges (ID integer PRIMARY KEY, OWNER_ID varchar(36))",
+	)
+	for _, name := range []string{"lonely", "busy", "former"} {
+		if err := ImportCreate(db, []testOwner{{MModel: MModel{ID: name}, Name: name}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pets := []testPet{{OwnerID: "busy"}, {OwnerID: "former"}}
+	if err := db.Create(&pets).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&pets[1]).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&testBadge{OwnerID: "busy"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestCanDelete(t *testing.T) {
+	tests := []struct {
+		name      string
+		id        string
+		relations []string
+		want      bool
+		wantErr   error
+	}{
+		{"no children", "lonely", []string{"Pets", "Badge"}, true, nil},
+		{"active has-many child", "busy", []string{"Pets"}, false, nil},
+		{"active has-one child", "busy", []string{"Badge"}, false, nil},
+		{"soft-deleted child", "former", []string{"Pets", "Badge"}, true, nil},
+		{"no relations", "busy", nil, true, nil},
+		{"unknown relation", "lonely", []string{"Cats"}, false, ErrUnknownRelation},
+		{"belongs-to relation", "lonely", []string{"Mentor"}, false, ErrUnknownRelation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newDependentsDB(t)
+			got, err := CanDelete[testOwner](db, tt.id, tt.relations...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CanDelete = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeleteIfNoChildren(t *testing.T) {
+	tests := []struct {
+		name        string
+		id          string
+		wantErr     error
+		wantDeleted bool
+	}{
+		{"no children", "lonely", nil, true},
+		{"soft-deleted child", "former", nil, true},
+		{"active children", "busy", ErrHasDependents, false},
+		{"missing record", "missing", ErrNotFound, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newDependentsDB(t)
+			err := DeleteIfNoChildren[testOwner](db, tt.id, "Pets", "Badge")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			var deleted int64
+			if err := db.Unscoped().Model(&testOwner{}).Where("ID = ? AND DELETED_AT IS NOT NULL", tt.id).Count(&deleted).Error; err != nil {
+				t.Fatal(err)
+			}
+			if (deleted == 1) != tt.wantDeleted {
+				t.Errorf("soft-deleted rows = %d, want deleted %v", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestCanDeletePostgres(t *testing.T) {
+	db, statements := newPostgresDryRunDB(t)
+	if _, err := CanDelete[testOwner](db, "busy", "Pets"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `SELECT count(*) FROM "test_pets" WHERE "test_pets"."OWNER_ID" = $1 AND "test_pets"."DELETED_AT" IS NULL`
+	if got := statements(); len(got) != 1 || got[0] != want {
+		t.Errorf("SQL = %q, want [%q]", got, want)
+	}
+}
@@ -0,0 +1,70 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ChangeValue holds the old and new value of a column that differs between two versions of a record.
+type ChangeValue struct {
+	// Old is the value of the column in the old version.
+	Old interface{} `json:"old"`
+	// New is the value of the column in the new version.
+	New interface{} `json:"new"`
+}
+
+// diffSchemas caches the schemas parsed by Diff.
+var diffSchemas sync.Map
+
+// Diff compares two versions of a record of the model T and returns the columns whose value
+// differs, keyed by column name, e.g. to build audit trails or change-event payloads.
+// Timestamp fields (CreatedAt, UpdatedAt, and fields with autoCreateTime or autoUpdateTime)
+// and the soft-delete field are ignored, as are associations. Times are compared with
+// time.Time.Equal. A nil version is treated as the zero value of T.
+//
+// Diff panics if T is not a valid GORM model, like a struct without fields.
+//
+//	changes := orm.Diff(&before, &after)
+//	// map[string]orm.ChangeValue{"NAME": {Old: "Ada", New: "Ada Lovelace"}}
+func Diff[T any](before, after *T) map[string]ChangeValue {
+	s, err := schema.Parse(new(T), &diffSchemas, schema.NamingStrategy{})
+	if err != nil {
+		panic(fmt.Sprintf("orm: diff %T: %v", *new(T), err))
+	}
+	if before == nil {
+		before = new(T)
+	}
+	if after == nil {
+		after = new(T)
+	}
+
+	ctx := context.Background()
+	beforeValue, afterValue := reflect.ValueOf(before).Elem(), reflect.ValueOf(after).Elem()
+	changes := make(map[string]ChangeValue)
+	for _, field := range s.Fields {
+		if field.DBName == "" || isTimestampField(field) {
+			continue
+		}
+		oldValue, _ := field.ValueOf(ctx, beforeValue)
+		newValue, _ := field.ValueOf(ctx, afterValue)
+		if !sameValue(oldValue, newValue) {
+			changes[field.DBName] = ChangeValue{Old: oldValue, New: newValue}
+		}
+	}
+	return changes
+}
+
+// isTimestampField reports whether field is a creation, update, or soft-delete timestamp.
+func isTimestampField(field *schema.Field) bool {
+	switch field.Name {
+	case "CreatedAt", "UpdatedAt", "DeletedAt":
+		return true
+	}
+	return field.AutoCreateTime > 0 || field.AutoUpdateTime > 0 ||
+		field.FieldType == reflect.TypeOf(gorm.DeletedAt{})
+}
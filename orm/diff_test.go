@@ -0,0 +1,92 @@
+package orm
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// testArticle is a model with a custom update timestamp and an association.
+type testArticle struct {
+	ID        string     `gorm:"column:ID;primaryKey"`
+	Title     string     `gorm:"column:TITLE"`
+	Views     int        `gorm:"column:VIEWS"`
+	Published *time.Time `gorm:"column:PUBLISHED"`
+	EditedAt  time.Time  `gorm:"column:EDITED_AT;autoUpdateTime"`
+	Author    *testUser  `gorm:"foreignKey:ID;references:ID"`
+}
+
+func TestDiff(t *testing.T) {
+	moment := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := moment.Add(time.Hour)
+	tests := []struct {
+		name   string
+		before *testArticle
+		after  *testArticle
+		want   map[string]ChangeValue
+	}{
+		{
+			name:   "identical",
+			before: &testArticle{ID: "1", Title: "a"},
+			after:  &testArticle{ID: "1", Title: "a"},
+			want:   map[string]ChangeValue{},
+		},
+		{
+			name:   "changed columns",
+			before: &testArticle{ID: "1", Title: "a", Views: 1},
+			after:  &testArticle{ID: "1", Title: "b", Views: 2},
+			want:   map[string]ChangeValue{"TITLE": {Old: "a", New: "b"}, "VIEWS": {Old: 1, New: 2}},
+		},
+		{
+			name:   "equal times in other zones",
+			before: &testArticle{Published: &moment},
+			after:  &testArticle{Published: timePtr(moment.In(time.FixedZone("X", 3600)))},
+			want:   map[string]ChangeValue{},
+		},
+		{
+			name:   "timestamps and associations ignored",
+			before: &testArticle{EditedAt: moment, Author: &testUser{Name: "a"}},
+			after:  &testArticle{EditedAt: later, Author: &testUser{Name: "b"}},
+			want:   map[string]ChangeValue{},
+		},
+		{
+			name:  "nil before",
+			after: &testArticle{ID: "1"},
+			want:  map[string]ChangeValue{"ID": {Old: "", New: "1"}},
+		},
+		{
+			name:   "nil after",
+			before: &testArticle{Views: 3},
+			want:   map[string]ChangeValue{"VIEWS": {Old: 3, New: 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Diff(tt.before, tt.after)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Diff = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffMModel(t *testing.T) {
+	before := &testUser{MModel: MModel{ID: "1", CreatedAt: time.Now()}, Name: "a", IsActive: true}
+	after := &testUser{MModel: MModel{ID: "1", UpdatedAt: time.Now()}, Name: "a", IsActive: false}
+	got := Diff(before, after)
+	want := map[string]ChangeValue{"IS_ACTIVE": {Old: true, New: false}}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Diff = %v, want %v", got, want)
+	}
+}
+
+func TestDiffInvalidModel(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Diff of an invalid model did not panic")
+		}
+	}()
+	Diff[int](nil, nil)
+}
+
+func timePtr(t time.Time) *time.Time { return &t }
@@ -0,0 +1,178 @@
+package orm
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// enumValues holds the allowed values of an enum type, in registration order.
+type enumValues struct {
+	values []string
+	set    map[string]struct{}
+}
+
+var (
+	enumsMu sync.RWMutex
+	enums   = make(map[reflect.Type]*enumValues)
+)
+
+// RegisterEnum sets the values allowed for the enum type T, replacing any previous
+// registration. It is meant to be called from the init function of the package declaring T.
+//
+//	type OrderStatus string
+//
+//	const (
+//		OrderPending OrderStatus = "pending"
+//		OrderPaid    OrderStatus = "paid"
+//	)
+//
+//	func init() {
+//		orm.RegisterEnum(OrderPending, OrderPaid)
+//	}
+func RegisterEnum[T ~string](values ...T) {
+	allowed := &enumValues{values: make([]string, 0, len(values)), set: make(map[string]struct{}, len(values))}
+	for _, value := range values {
+		if _, ok := allowed.set[string(value)]; ok {
+			continue
+		}
+		allowed.values = append(allowed.values, string(value))
+		allowed.set[string(value)] = struct{}{}
+	}
+	enumsMu.Lock()
+	enums[reflect.TypeOf(new(T)).Elem()] = allowed
+	enumsMu.Unlock()
+}
+
+// enumValuesOf returns the values registered for T, or nil if none were.
+func enumValuesOf[T ~string]() *enumValues {
+	enumsMu.RLock()
+	defer enumsMu.RUnlock()
+	return enums[reflect.TypeOf(new(T)).Elem()]
+}
+
+// Enum is a string column restricted to the values registered for T with RegisterEnum.
+// Writing a value that is not registered, or any value of an unregistered type, fails with
+// an error wrapping ErrInvalidEnum; so does decoding such a value from JSON. Values read from
+// the database are not checked, so rows written before a value was removed remain readable.
+//
+// AutoMigrate declares the column as ENUM('pending','paid') on MySQL and as a varchar with a
+// CHECK constraint on the allowed values on other databases.
+//
+//	type Order struct {
+//		MModel
+//		Status orm.Enum[OrderStatus] `json:"status" gorm:"column:STATUS;not null"`
+//	}
+//
+//	order.Status = orm.NewEnum(OrderPaid)
+type Enum[T ~string] struct {
+	value T
+}
+
+// NewEnum returns an Enum holding value. The value is validated when it is written.
+func NewEnum[T ~string](value T) Enum[T] {
+	return Enum[T]{value: value}
+}
+
+// Get returns the value of the enum.
+func (e Enum[T]) Get() T {
+	return e.value
+}
+
+// String returns the value of the enum as a string.
+func (e Enum[T]) String() string {
+	return string(e.value)
+}
+
+// Valid reports whether the value of the enum is registered for T.
+func (e Enum[T]) Valid() bool {
+	return e.validate() == nil
+}
+
+// validate returns an error wrapping ErrInvalidEnum if the value is not registered for T.
+func (e Enum[T]) validate() error {
+	allowed := enumValuesOf[T]()
+	if allowed == nil {
+		return fmt.Errorf("%w: no values registered for %T", ErrInvalidEnum, e.value)
+	}
+	if _, ok := allowed.set[string(e.value)]; !ok {
+		return fmt.Errorf("%w: %q is not a %T", ErrInvalidEnum, string(e.value), e.value)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer, validating the value before it is written.
+func (e Enum[T]) Value() (driver.Value, error) {
+	if err := e.validate(); err != nil {
+		return nil, err
+	}
+	return string(e.value), nil
+}
+
+// Scan implements sql.Scanner.
+func (e *Enum[T]) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		e.value = ""
+	case string:
+		e.value = T(v)
+	case []byte:
+		e.value = T(v)
+	default:
+		return fmt.Errorf("orm: cannot scan %T into %T", src, e.value)
+	}
+	return nil
+}
+
+// MarshalJSON encodes the enum as a JSON string.
+func (e Enum[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(string(e.value))
+}
+
+// UnmarshalJSON decodes a JSON string into the enum, rejecting values not registered for T.
+func (e *Enum[T]) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	decoded := Enum[T]{value: T(value)}
+	if err := decoded.validate(); err != nil {
+		return err
+	}
+	*e = decoded
+	return nil
+}
+
+// GormDataType returns the generic data type of the column.
+func (Enum[T]) GormDataType() string {
+	return "string"
+}
+
+// GormDBDataType returns the column type declared by migrations: a native ENUM on MySQL,
+// and a varchar constrained by a CHECK on the registered values elsewhere. It falls back to
+// a plain varchar if no values are registered for T.
+func (Enum[T]) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	allowed := enumValuesOf[T]()
+	if allowed == nil || len(allowed.values) == 0 {
+		return "varchar(255)"
+	}
+	quoted := make([]string, len(allowed.values))
+	size := 1
+	for i, value := range allowed.values {
+		quoted[i] = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+		if len(value) > size {
+			size = len(value)
+		}
+	}
+	if db.Dialector.Name() == "mysql" {
+		return "ENUM(" + strings.Join(quoted, ",") + ")"
+	}
+	return fmt.Sprintf("varchar(%d) CHECK (%s IN (%s))",
+		size, db.Statement.Quote(field.DBName), strings.Join(quoted, ","))
+}
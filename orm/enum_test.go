@@ -0,0 +1,177 @@
+package orm
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+type testStatus string
+
+const (
+	testPending testStatus = "pending"
+	testPaid    testStatus = "paid"
+)
+
+// testColor is an enum type without registered values.
+type testColor string
+
+func init() {
+	RegisterEnum(testPending, testPaid, testPending)
+}
+
+// testShipment is a model with an enum column.
+type testShipment struct {
+	ID     int              `gorm:"column:ID;primaryKey"`
+	Status Enum[testStatus] `json:"status" gorm:"column:STATUS;not null"`
+}
+
+func TestEnumValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		valid   bool
+		value   func() (interface{}, error)
+		wantErr error
+	}{
+		{"registered value", true, func() (interface{}, error) { return NewEnum(testPaid).Value() }, nil},
+		{"unregistered value", false, func() (interface{}, error) { return NewEnum(testStatus("lost")).Value() }, ErrInvalidEnum},
+		{"empty value", false, func() (interface{}, error) { return Enum[testStatus]{}.Value() }, ErrInvalidEnum},
+		{"unregistered type", false, func() (interface{}, error) { return NewEnum(testColor("red")).Value() }, ErrInvalidEnum},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := tt.value()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if (err == nil) != tt.valid || (tt.valid && value == nil) {
+				t.Errorf("Value = %v, %v, want valid %v", value, err, tt.valid)
+			}
+		})
+	}
+	if !NewEnum(testPending).Valid() || NewEnum(testColor("red")).Valid() {
+		t.Error("Valid does not match the registered values")
+	}
+}
+
+func TestEnumScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    testStatus
+		wantErr bool
+	}{
+		{"string", "paid", testPaid, false},
+		{"bytes", []byte("pending"), testPending, false},
+		{"unregistered value", "lost", "lost", false},
+		{"nil", nil, "", false},
+		{"unsupported type", 42, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e Enum[testStatus]
+			err := e.Scan(tt.src)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if e.Get() != tt.want || e.String() != string(tt.want) {
+				t.Errorf("enum = %q, want %q", e.Get(), tt.want)
+			}
+		})
+	}
+}
+
+func TestEnumJSON(t *testing.T) {
+	data, err := json.Marshal(testShipment{Status: NewEnum(testPaid)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"status":"paid"`) {
+		t.Errorf("JSON = %s, want the status as a string", data)
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		want    testStatus
+		wantErr error
+	}{
+		{"registered value", `{"status":"pending"}`, testPending, nil},
+		{"unregistered value", `{"status":"lost"}`, "", ErrInvalidEnum},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var shipment testShipment
+			err := json.Unmarshal([]byte(tt.data), &shipment)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if shipment.Status.Get() != tt.want {
+				t.Errorf("status = %q, want %q", shipment.Status.Get(), tt.want)
+			}
+		})
+	}
+	var shipment testShipment
+	if err := json.Unmarshal([]byte(`{"status":1}`), &shipment); err == nil {
+		t.Error("decoding a number succeeded")
+	}
+}
+
+func TestEnumColumn(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.AutoMigrate(&testShipment{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&testShipment{Status: NewEnum(testPaid)}).Error; err != nil {
+		t.Fatalf("create valid shipment: %v", err)
+	}
+	if err := db.Create(&testShipment{Status: NewEnum(testStatus("lost"))}).Error; !errors.Is(err, ErrInvalidEnum) {
+		t.Errorf("create invalid shipment: err = %v, want ErrInvalidEnum", err)
+	}
+	// The CHECK constraint also rejects values written without the Valuer.
+	if err := db.Exec("INSERT INTO test_shipments (STATUS) VALUES ('lost')").Error; err == nil {
+		t.Error("the column accepted an unregistered value")
+	}
+
+	var shipment testShipment
+	if err := db.Take(&shipment).Error; err != nil {
+		t.Fatal(err)
+	}
+	if shipment.Status.Get() != testPaid {
+		t.Errorf("status = %q, want paid", shipment.Status.Get())
+	}
+}
+
+func TestEnumDBDataType(t *testing.T) {
+	tests := []struct {
+		dialect string
+		enum    interface {
+			GormDBDataType(*gorm.DB, *schema.Field) string
+		}
+		want string
+	}{
+		{"mysql", Enum[testStatus]{}, "ENUM('pending','paid')"},
+		{"sqlite", Enum[testStatus]{}, "varchar(7) CHECK (`STATUS` IN ('pending','paid'))"},
+		{"sqlite", Enum[testColor]{}, "varchar(255)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect+"/"+tt.want, func(t *testing.T) {
+			db, err := gorm.Open(renamedDialector{Dialector: sqlite.Open("file::memory:"), name: tt.dialect}, &gorm.Config{Logger: logger.Discard})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if sqlDB, err := db.DB(); err == nil {
+				t.Cleanup(func() { _ = sqlDB.Close() })
+			}
+			if got := tt.enum.GormDBDataType(db, &schema.Field{DBName: "STATUS"}); got != tt.want {
+				t.Errorf("GormDBDataType = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
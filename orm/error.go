@@ -0,0 +1,39 @@
+package orm
+
+import "errors"
+
+// ErrNotFound represents the error returned when no record matches the given criteria.
+// This error is used to indicate that the targeted row does not exist or has been soft-deleted.
+var ErrNotFound = errors.New("orm: not found")
+
+// ErrUnknownColumn represents the error returned when a column name does not belong to the model's schema.
+// This error is used to reject unvalidated input before it reaches the generated SQL.
+var ErrUnknownColumn = errors.New("orm: unknown column")
+
+// ErrUnknownMigration represents the error returned when a migration version has not been registered.
+// This error is used to reject a rollback target that the Migrator does not know about.
+var ErrUnknownMigration = errors.New("orm: unknown migration")
+
+// ErrMissingTenant represents the error returned when a tenant-scoped model is queried without a tenant.
+// This error is used by the tenant plugin to fail closed instead of leaking rows across tenants.
+var ErrMissingTenant = errors.New("orm: missing tenant")
+
+// ErrTenantMismatch represents the error returned when a record is created with a tenant other than the one of its context.
+// This error is used by the tenant plugin to reject writes into another tenant instead of silently moving them.
+var ErrTenantMismatch = errors.New("orm: tenant mismatch")
+
+// ErrInvalidCursor represents the error returned when a pagination cursor cannot be decoded.
+// This error is used to reject cursors that were tampered with or not produced by EncodeCursor.
+var ErrInvalidCursor = errors.New("orm: invalid cursor")
+
+// ErrUnknownRelation represents the error returned when an association path does not match the model's relationships.
+// This error is used to reject preload paths with typos before they reach GORM.
+var ErrUnknownRelation = errors.New("orm: unknown relation")
+
+// ErrHasDependents represents the error returned when a record cannot be deleted because active child rows reference it.
+// This error is used by DeleteIfNoChildren to enforce referential integrity for soft-deleted schemas.
+var ErrHasDependents = errors.New("orm: has dependents")
+
+// ErrInvalidEnum represents the error returned when an Enum value is not one of the values registered for its type.
+// This error is used to reject bad enum data before it is written to the database.
+var ErrInvalidEnum = errors.New("orm: invalid enum value")
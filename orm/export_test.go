@@ -0,0 +1,129 @@
+package orm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestExportCSV(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	SetClock(func() time.Time { return created })
+	defer SetClock(nil)
+
+	db := newTestDB(t, testUsersTable)
+	ids := createUsers(t, db, "alice", `bob "the builder", jr`, "carol")
+	if err := db.Model(&testUser{}).Where("ID = ?", ids[1]).Update("IS_ACTIVE", false).Error; err != nil {
+		t.Fatal(err)
+	}
+	// Soft-delete with a known time.
+	if err := db.Model(&testUser{}).Where("ID = ?", ids[2]).Update("DELETED_AT", created).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		db   *gorm.DB
+		opts ExportOptions
+		want string
+	}{
+		{
+			name: "selected columns",
+			db:   db.Order("NAME"),
+			opts: ExportOptions{Columns: []string{"Name", "IS_ACTIVE", "CreatedAt"}},
+			want: "NAME,IS_ACTIVE,CREATED_AT\n" +
+				"alice,true,2024-01-02T03:04:05Z\n" +
+				`"bob ""the builder"", jr",false,2024-01-02T03:04:05Z` + "\n",
+		},
+		{
+			name: "custom headers",
+			db:   db.Order("NAME"),
+			opts: ExportOptions{Columns: []string{"NAME"}, Headers: []string{"Name"}},
+			want: "Name\nalice\n\"bob \"\"the builder\"\", jr\"\n",
+		},
+		{
+			name: "conditions apply",
+			db:   db.Where("IS_ACTIVE = ?", true),
+			opts: ExportOptions{Columns: []string{"NAME"}},
+			want: "NAME\nalice\n",
+		},
+		{
+			name: "soft-deleted rows with Unscoped",
+			db:   db.Unscoped().Where("NAME = ?", "carol"),
+			opts: ExportOptions{Columns: []string{"NAME", "DELETED_AT"}},
+			want: "NAME,DELETED_AT\ncarol,2024-01-02T03:04:05Z\n",
+		},
+		{
+			name: "NULL as empty field",
+			db:   db.Where("NAME = ?", "alice"),
+			opts: ExportOptions{Columns: []string{"NAME", "DELETED_AT"}},
+			want: "NAME,DELETED_AT\nalice,\n",
+		},
+		{
+			name: "no rows",
+			db:   db.Where("NAME = ?", "nobody"),
+			opts: ExportOptions{Columns: []string{"NAME"}},
+			want: "NAME\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := ExportCSV[testUser](context.Background(), tt.db, &buf, tt.opts); err != nil {
+				t.Fatalf("ExportCSV: %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("CSV =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExportCSVEveryColumn(t *testing.T) {
+	db := newTestDB(t, testUsersTable)
+	createUsers(t, db, "alice")
+
+	var buf bytes.Buffer
+	if err := ExportCSV[testUser](context.Background(), db, &buf, ExportOptions{}); err != nil {
+		t.Fatalf("ExportCSV: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	if want := "ID,CREATED_AT,UPDATED_AT,DELETED_AT,NAME,IS_ACTIVE"; lines[0] != want {
+		t.Errorf("header = %q, want %q", lines[0], want)
+	}
+	if fields := strings.Split(lines[1], ","); len(fields) != 6 || fields[4] != "alice" {
+		t.Errorf("record = %q", lines[1])
+	}
+}
+
+func TestExportCSVErrors(t *testing.T) {
+	db := newTestDB(t, testUsersTable)
+	tests := []struct {
+		name    string
+		opts    ExportOptions
+		wantErr error
+	}{
+		{"unknown column", ExportOptions{Columns: []string{"EMAIL"}}, ErrUnknownColumn},
+		{"header count mismatch", ExportOptions{Columns: []string{"NAME", "ID"}, Headers: []string{"Name"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := ExportCSV[testUser](context.Background(), db, &buf, tt.opts)
+			if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Fatalf("err = %v, want an error matching %v", err, tt.wantErr)
+			}
+			if buf.Len() != 0 {
+				t.Errorf("wrote %q before failing validation", buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// GetOrCreate returns the first record of type T matching the where conditions, or creates
+// one from defaults when none exists. The boolean result reports whether a record was created.
+//
+// Unlike GORM's FirstOrCreate, the record is created with a regular Create call, so the
+// BeforeCreate hooks of the base models always run and generate the ID. The lookup excludes
+// soft-deleted rows, so a soft-deleted match results in a new record being created.
+// The where values are copied onto the created record only for columns that are left at their
+// zero value in defaults.
+//
+// The lookup and the insert are not atomic, so concurrent calls may both try to create the
+// record. When the where columns are covered by a unique index, the losing insert fails with
+// a unique violation, as reported by IsUniqueViolation, and the record created by the winner
+// is looked up again and returned. Inside a PostgreSQL transaction the failed insert aborts
+// the transaction, so the unique violation is returned instead. Without a unique index, both
+// calls create a record.
+//
+//	user, created, err := orm.GetOrCreate(db, map[string]interface{}{"EMAIL": email}, &User{Name: name})
+func GetOrCreate[T any](db *gorm.DB, where map[string]interface{}, defaults *T) (*T, bool, error) {
+	var found T
+	err := db.Model(new(T)).Where(where).Take(&found).Error
+	if err == nil {
+		return &found, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	record := new(T)
+	if defaults != nil {
+		*record = *defaults
+	}
+	create := db.Session(&gorm.Session{NewDB: true}).Model(record)
+	if err := create.Statement.Parse(record); err != nil {
+		return nil, false, err
+	}
+	rv := reflect.ValueOf(record).Elem()
+	for column, value := range where {
+		field := create.Statement.Schema.LookUpField(column)
+		if field == nil {
+			return nil, false, fmt.Errorf("%w: %q", ErrUnknownColumn, column)
+		}
+		if _, zero := field.ValueOf(db.Statement.Context, rv); zero {
+			if err := field.Set(db.Statement.Context, rv, value); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	if err := create.Create(record).Error; err != nil {
+		if IsUniqueViolation(err) {
+			// Another caller created the record since the lookup.
+			if lookupErr := db.Model(new(T)).Where(where).Take(&found).Error; lookupErr == nil {
+				return &found, false, nil
+			}
+		}
+		return nil, false, err
+	}
+	return record, true, nil
+}
+
+// IsUniqueViolation reports whether err is the violation of a unique constraint. It
+// recognizes gorm.ErrDuplicatedKey, errors exposing a PostgreSQL SQLSTATE of 23505 (as
+// pgconn.PgError does), MySQL error 1062, and SQLite unique constraint failures.
+func IsUniqueViolation(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	var sqlState interface{ SQLState() string }
+	if errors.As(err, &sqlState) && sqlState.SQLState() == "23505" {
+		return true
+	}
+	// The MySQL and SQLite drivers expose their error codes only as struct fields, which
+	// cannot be matched without importing the drivers, so their messages are used.
+	message := err.Error()
+	return strings.Contains(message, "Error 1062") || strings.Contains(message, "UNIQUE constraint failed")
+}
@@ -0,0 +1,133 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestGetOrCreate(t *testing.T) {
+	db := newTestDB(t, testUsersTable)
+	ids := createUsers(t, db, "alice", "bob")
+	if err := db.Delete(&testUser{}, "ID = ?", ids[1]).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		where       map[string]interface{}
+		defaults    *testUser
+		wantCreated bool
+		wantID      string
+		wantName    string
+	}{
+		{"existing record", map[string]interface{}{"NAME": "alice"}, &testUser{IsActive: true}, false, ids[0], "alice"},
+		{"missing record", map[string]interface{}{"NAME": "carol"}, &testUser{IsActive: true}, true, "", "carol"},
+		{"soft-deleted record", map[string]interface{}{"NAME": "bob"}, &testUser{IsActive: true}, true, "", "bob"},
+		{"defaults win over where", map[string]interface{}{"NAME": "dave"}, &testUser{Name: "david", IsActive: true}, true, "", "david"},
+		{"nil defaults", map[string]interface{}{"NAME": "erin"}, nil, true, "", "erin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, created, err := GetOrCreate(db, tt.where, tt.defaults)
+			if err != nil {
+				t.Fatalf("GetOrCreate: %v", err)
+			}
+			if created != tt.wantCreated {
+				t.Errorf("created = %v, want %v", created, tt.wantCreated)
+			}
+			if tt.wantID != "" && user.ID != tt.wantID {
+				t.Errorf("ID = %q, want %q", user.ID, tt.wantID)
+			}
+			if created && (user.ID == "" || user.ID == ids[1]) {
+				t.Errorf("created record has ID %q, want a new ID", user.ID)
+			}
+			if user.Name != tt.wantName {
+				t.Errorf("Name = %q, want %q", user.Name, tt.wantName)
+			}
+		})
+	}
+
+	// A second call finds the record created by the first one.
+	first, _, err := GetOrCreate(db, map[string]interface{}{"NAME": "frank"}, &testUser{IsActive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, created, err := GetOrCreate(db, map[string]interface{}{"NAME": "frank"}, &testUser{IsActive: true})
+	if err != nil || created || second.ID != first.ID {
+		t.Errorf("second call = %q, %v, %v, want %q, false, nil", second.ID, created, err, first.ID)
+	}
+}
+
+func TestGetOrCreateUnknownColumn(t *testing.T) {
+	// The lookup already fails on the unknown column, so nothing is created.
+	db := newTestDB(t, testUsersTable)
+	_, created, err := GetOrCreate(db, map[string]interface{}{"EMAIL": "a@example.com"}, &testUser{Name: "alice"})
+	if err == nil || created {
+		t.Fatalf("GetOrCreate = %v, %v, want an error", created, err)
+	}
+	var count int64
+	if err := db.Model(&testUser{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("rows = %d, want 0", count)
+	}
+}
+
+func TestGetOrCreateConcurrentCreate(t *testing.T) {
+	db := newTestDB(t, testUsersTable, "CREATE UNIQUE INDEX UDX_test_users_NAME ON test_users (NAME)")
+	// Without the default transaction, the competing insert is committed on its own.
+	db = db.Session(&gorm.Session{SkipDefaultTransaction: true})
+	var competed bool
+	var competitorID string
+	err := db.Callback().Create().Before("gorm:create").Register("test:competitor", func(tx *gorm.DB) {
+		if competed {
+			return
+		}
+		competed = true
+		// Another caller creates the record between the lookup and the insert.
+		user := testUser{Name: "alice", IsActive: true}
+		if err := tx.Session(&gorm.Session{NewDB: true}).Create(&user).Error; err != nil {
+			_ = tx.AddError(err)
+			return
+		}
+		competitorID = user.ID
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, created, err := GetOrCreate(db, map[string]interface{}{"NAME": "alice"}, &testUser{IsActive: true})
+	if err != nil {
+		t.Fatalf("GetOrCreate: %v", err)
+	}
+	if created || user.ID != competitorID {
+		t.Errorf("GetOrCreate = %q, created %v, want the competing record %q", user.ID, created, competitorID)
+	}
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"duplicated key", gorm.ErrDuplicatedKey, true},
+		{"PostgreSQL unique violation", fmt.Errorf("insert: %w", sqlStateError("23505")), true},
+		{"PostgreSQL serialization failure", sqlStateError("40001"), false},
+		{"MySQL duplicate entry", errors.New("Error 1062 (23000): Duplicate entry 'a' for key 'EMAIL'"), true},
+		{"SQLite unique constraint", errors.New("constraint failed: UNIQUE constraint failed: users.EMAIL (2067)"), true},
+		{"record not found", gorm.ErrRecordNotFound, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsUniqueViolation(tt.err); got != tt.want {
+				t.Errorf("IsUniqueViolation = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
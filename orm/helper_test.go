@@ -0,0 +1,177 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
+)
+
+// testUser is the model used by the tests of the package.
+type testUser struct {
+	MModel
+	Name     string `json:"name" gorm:"column:NAME;type:varchar(255);not null"`
+	IsActive bool   `json:"isActive" gorm:"column:IS_ACTIVE;default:1"`
+}
+
+// testUsersTable creates the table of testUser. MModel declares MySQL column defaults that
+// SQLite cannot parse, so the tests create their tables with explicit DDL instead of
+// AutoMigrate.
+const testUsersTable = `CREATE TABLE test_users (
+	ID varchar(36) PRIMARY KEY,
+	CREATED_AT datetime,
+	UPDATED_AT datetime,
+	DELETED_AT datetime,
+	NAME varchar(255) NOT NULL,
+	IS_ACTIVE boolean DEFAULT 1
+)`
+
+// newTestDB opens a private in-memory SQLite database and runs the given DDL statements.
+func newTestDB(t *testing.T, ddl ...string) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("database handle: %v", err)
+	}
+	// Every connection to file::memory: opens a distinct database.
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	for _, statement := range ddl {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatalf("create schema: %v", err)
+		}
+	}
+	return db
+}
+
+// createUsers inserts users with the given names and returns their IDs.
+func createUsers(t *testing.T, db *gorm.DB, names ...string) []string {
+	t.Helper()
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		user := testUser{Name: name, IsActive: true}
+		if err := db.Create(&user).Error; err != nil {
+			t.Fatalf("create user %q: %v", name, err)
+		}
+		ids = append(ids, user.ID)
+	}
+	return ids
+}
+
+// postgresDialector is a connection-less gorm.Dialector quoting identifiers and binding
+// variables as PostgreSQL does, so that tests can assert the SQL built for PostgreSQL in
+// DryRun mode.
+type postgresDialector struct{}
+
+func (postgresDialector) Name() string { return "postgres" }
+
+func (postgresDialector) Initialize(db *gorm.DB) error {
+	callbacks.RegisterDefaultCallbacks(db, &callbacks.Config{})
+	db.ConnPool = dryRunPool{}
+	return nil
+}
+
+// errDryRun is returned by a dryRunPool asked to run a statement.
+var errDryRun = errors.New("statement run in DryRun mode")
+
+// dryRunPool is a gorm.ConnPool that runs no statement but supports transactions, so that
+// functions using transactions can be run in DryRun mode.
+type dryRunPool struct{}
+
+func (dryRunPool) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, errDryRun
+}
+
+func (dryRunPool) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	return nil, errDryRun
+}
+
+func (dryRunPool) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	return nil, errDryRun
+}
+
+func (dryRunPool) QueryRowContext(context.Context, string, ...interface{}) *sql.Row { return nil }
+
+func (dryRunPool) BeginTx(context.Context, *sql.TxOptions) (gorm.ConnPool, error) {
+	return &dryRunTx{}, nil
+}
+
+// dryRunTx is the transaction of a dryRunPool.
+type dryRunTx struct{ dryRunPool }
+
+func (*dryRunTx) Commit() error   { return nil }
+func (*dryRunTx) Rollback() error { return nil }
+
+func (d postgresDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return postgresMigrator{migrator.Migrator{Config: migrator.Config{DB: db, Dialector: d}}}
+}
+
+// postgresMigrator is the gorm.Migrator of a postgresDialector. A DryRun session has no
+// catalog to query, so it reports every index as missing.
+type postgresMigrator struct{ migrator.Migrator }
+
+func (postgresMigrator) HasIndex(interface{}, string) bool { return false }
+
+func (postgresDialector) DataTypeOf(*schema.Field) string { return "" }
+
+func (postgresDialector) DefaultValueOf(*schema.Field) clause.Expression {
+	return clause.Expr{SQL: "DEFAULT"}
+}
+
+func (postgresDialector) BindVarTo(writer clause.Writer, stmt *gorm.Statement, v interface{}) {
+	writer.WriteString("$" + strconv.Itoa(len(stmt.Vars)))
+}
+
+func (postgresDialector) QuoteTo(writer clause.Writer, str string) {
+	writer.WriteString(`"` + strings.ReplaceAll(str, ".", `"."`) + `"`)
+}
+
+func (postgresDialector) Explain(sql string, vars ...interface{}) string {
+	return logger.ExplainSQL(sql, nil, `'`, vars...)
+}
+
+// newPostgresDryRunDB opens a PostgreSQL DryRun session and returns it with a function
+// returning the SQL of the statements it built so far.
+func newPostgresDryRunDB(t *testing.T) (*gorm.DB, func() []string) {
+	t.Helper()
+	db, err := gorm.Open(postgresDialector{}, &gorm.Config{DryRun: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	var statements []string
+	record := func(tx *gorm.DB) { statements = append(statements, tx.Statement.SQL.String()) }
+	callback := db.Callback()
+	for name, err := range map[string]error{
+		"create": callback.Create().After("*").Register("test:record", record),
+		"query":  callback.Query().After("*").Register("test:record", record),
+		"update": callback.Update().After("*").Register("test:record", record),
+		"delete": callback.Delete().After("*").Register("test:record", record),
+		"raw":    callback.Raw().After("*").Register("test:record", record),
+	} {
+		if err != nil {
+			t.Fatalf("register %s callback: %v", name, err)
+		}
+	}
+	return db, func() []string { return statements }
+}
+
+// sqlStateError is an error exposing a SQLSTATE, as pgconn.PgError does.
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "sqlstate " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
@@ -0,0 +1,33 @@
+package orm
+
+import "gorm.io/gorm"
+
+// importSetting is the statement setting marking an insert issued by ImportCreate.
+const importSetting = "orm:import"
+
+// ImportCreate inserts records imported from another system, preserving the values they
+// already carry. Non-zero CreatedAt and UpdatedAt values are persisted verbatim instead of
+// being replaced by the clock or the column defaults, and the BeforeCreate hooks of MModel
+// and ULIDModel keep a non-empty ID instead of generating a new one. Records without an ID
+// or timestamps get them as with a regular Create, and all other hooks still run.
+//
+// Records are inserted in batches of DefaultChunkSize within a single transaction.
+//
+//	err := orm.ImportCreate(db, []User{
+//		{MModel: orm.MModel{ID: legacyID, CreatedAt: legacyCreatedAt}, Name: "Ada"},
+//	})
+func ImportCreate[T any](db *gorm.DB, records []T) error {
+	if len(records) == 0 {
+		return nil
+	}
+	return db.Set(importSetting, true).CreateInBatches(&records, DefaultChunkSize).Error
+}
+
+// isImport reports whether the statement of tx was issued by ImportCreate.
+func isImport(tx *gorm.DB) bool {
+	if tx == nil {
+		return false
+	}
+	imported, ok := tx.Get(importSetting)
+	return ok && imported == true
+}
@@ -0,0 +1,101 @@
+package orm
+
+import (
+	"testing"
+	"time"
+)
+
+func TestImportCreate(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	legacy := time.Date(2019, 3, 4, 5, 6, 7, 0, time.UTC)
+	tests := []struct {
+		name        string
+		record      testUser
+		wantID      string
+		wantCreated time.Time
+		wantUpdated time.Time
+	}{
+		{
+			name:        "preserved values",
+			record:      testUser{MModel: MModel{ID: "legacy-1", CreatedAt: legacy, UpdatedAt: legacy.Add(time.Hour)}, Name: "ada"},
+			wantID:      "legacy-1",
+			wantCreated: legacy,
+			wantUpdated: legacy.Add(time.Hour),
+		},
+		{
+			name:        "missing timestamps",
+			record:      testUser{MModel: MModel{ID: "legacy-2"}, Name: "ada"},
+			wantID:      "legacy-2",
+			wantCreated: now,
+			wantUpdated: now,
+		},
+		{
+			name:        "missing ID",
+			record:      testUser{MModel: MModel{CreatedAt: legacy}, Name: "ada"},
+			wantCreated: legacy,
+			wantUpdated: now,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetClock(func() time.Time { return now })
+			defer SetClock(nil)
+			db := newTestDB(t, testUsersTable)
+
+			if err := ImportCreate(db, []testUser{tt.record}); err != nil {
+				t.Fatalf("ImportCreate: %v", err)
+			}
+			var users []testUser
+			if err := db.Find(&users).Error; err != nil {
+				t.Fatal(err)
+			}
+			if len(users) != 1 {
+				t.Fatalf("imported %d users, want 1", len(users))
+			}
+			user := users[0]
+			if tt.wantID != "" && user.ID != tt.wantID {
+				t.Errorf("ID = %q, want %q", user.ID, tt.wantID)
+			}
+			if user.ID == "" {
+				t.Error("no ID generated")
+			}
+			if !user.CreatedAt.Equal(tt.wantCreated) || !user.UpdatedAt.Equal(tt.wantUpdated) {
+				t.Errorf("timestamps = %v, %v, want %v, %v", user.CreatedAt, user.UpdatedAt, tt.wantCreated, tt.wantUpdated)
+			}
+		})
+	}
+}
+
+func TestImportCreateULIDModel(t *testing.T) {
+	db := newTestDB(t, testEventsTable)
+	id := NewULID()
+	if err := ImportCreate(db, []testEvent{{ULIDModel: ULIDModel{ID: id}, Name: "imported"}}); err != nil {
+		t.Fatal(err)
+	}
+	var event testEvent
+	if err := db.Take(&event).Error; err != nil {
+		t.Fatal(err)
+	}
+	if event.ID != id {
+		t.Errorf("ID = %q, want %q", event.ID, id)
+	}
+}
+
+func TestCreateIgnoresGivenID(t *testing.T) {
+	db := newTestDB(t, testUsersTable)
+	user := testUser{MModel: MModel{ID: "given"}, Name: "ada"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	if user.ID == "given" {
+		t.Error("regular Create kept the given ID")
+	}
+}
+
+func TestImportCreateEmpty(t *testing.T) {
+	// Without records, nothing is inserted and the missing table is never queried.
+	db := newTestDB(t)
+	if err := ImportCreate[testUser](db, nil); err != nil {
+		t.Errorf("ImportCreate: %v", err)
+	}
+}
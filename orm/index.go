@@ -0,0 +1,69 @@
+package orm
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// NotDeleted returns the condition matching rows that have not been soft-deleted by the
+// base models, with the column quoted for the dialect of db. It is intended to be used
+// with PartialUniqueIndex and raw queries.
+func NotDeleted(db *gorm.DB) string {
+	return db.Statement.Quote("DELETED_AT") + " IS NULL"
+}
+
+// PartialUniqueIndex creates a unique index on the given columns of table that only
+// applies to rows matching the where condition. Combined with NotDeleted it
+// allows a unique value (e.g. an email) to be inserted again after the previous row
+// holding it has been soft-deleted.
+//
+// On PostgreSQL and SQLite a native partial index is created. MySQL has no partial
+// indexes, so a virtual generated column evaluating to 1 for matching rows and NULL
+// otherwise is added and included in the unique index; since NULLs never collide,
+// non-matching rows are effectively excluded.
+//
+// The index is named UDX_{table}_{columns} and the call is a no-op if it already exists.
+// The where condition is embedded as raw SQL and must never contain user input.
+//
+//	err := orm.PartialUniqueIndex(db, "USERS", []string{"EMAIL"}, orm.NotDeleted(db))
+func PartialUniqueIndex(db *gorm.DB, table string, cols []string, where string) error {
+	if len(cols) == 0 {
+		return fmt.Errorf("orm: partial unique index on %q requires at least one column", table)
+	}
+
+	name := "UDX_" + table + "_" + strings.Join(cols, "_")
+	if db.Migrator().HasIndex(table, name) {
+		return nil
+	}
+
+	quoted := make([]string, 0, len(cols)+1)
+	for _, col := range cols {
+		quoted = append(quoted, db.Statement.Quote(col))
+	}
+
+	switch db.Dialector.Name() {
+	case "mysql":
+		generated := name + "_ACTIVE"
+		if !db.Migrator().HasColumn(table, generated) {
+			err := db.Exec(fmt.Sprintf(
+				"ALTER TABLE %s ADD COLUMN %s TINYINT AS (IF(%s, 1, NULL)) VIRTUAL",
+				db.Statement.Quote(table), db.Statement.Quote(generated), where,
+			)).Error
+			if err != nil {
+				return err
+			}
+		}
+		quoted = append(quoted, db.Statement.Quote(generated))
+		return db.Exec(fmt.Sprintf(
+			"CREATE UNIQUE INDEX %s ON %s (%s)",
+			db.Statement.Quote(name), db.Statement.Quote(table), strings.Join(quoted, ", "),
+		)).Error
+	default:
+		return db.Exec(fmt.Sprintf(
+			"CREATE UNIQUE INDEX %s ON %s (%s) WHERE %s",
+			db.Statement.Quote(name), db.Statement.Quote(table), strings.Join(quoted, ", "), where,
+		)).Error
+	}
+}
@@ -0,0 +1,52 @@
+package orm
+
+import "testing"
+
+func TestPartialUniqueIndex(t *testing.T) {
+	db := newTestDB(t, testUsersTable)
+	for i := 0; i < 2; i++ {
+		// The second call finds the index and does nothing.
+		if err := PartialUniqueIndex(db, "test_users", []string{"NAME"}, NotDeleted(db)); err != nil {
+			t.Fatalf("PartialUniqueIndex call %d: %v", i+1, err)
+		}
+	}
+	if !db.Migrator().HasIndex("test_users", "UDX_test_users_NAME") {
+		t.Fatal("index UDX_test_users_NAME not created")
+	}
+
+	steps := []struct {
+		name    string
+		do      func() error
+		wantErr bool
+	}{
+		{"insert", func() error { return db.Create(&testUser{Name: "alice"}).Error }, false},
+		{"insert duplicate", func() error { return db.Create(&testUser{Name: "alice"}).Error }, true},
+		{"soft delete", func() error { return db.Where("NAME = ?", "alice").Delete(&testUser{}).Error }, false},
+		{"insert after soft delete", func() error { return db.Create(&testUser{Name: "alice"}).Error }, false},
+		{"insert duplicate again", func() error { return db.Create(&testUser{Name: "alice"}).Error }, true},
+	}
+	for _, step := range steps {
+		if err := step.do(); (err != nil) != step.wantErr {
+			t.Fatalf("%s: err = %v, want error %v", step.name, err, step.wantErr)
+		}
+	}
+}
+
+func TestPartialUniqueIndexWithoutColumns(t *testing.T) {
+	db := newTestDB(t, testUsersTable)
+	if err := PartialUniqueIndex(db, "test_users", nil, NotDeleted(db)); err == nil {
+		t.Error("PartialUniqueIndex without columns succeeded, want an error")
+	}
+}
+
+func TestPartialUniqueIndexPostgres(t *testing.T) {
+	db, statements := newPostgresDryRunDB(t)
+	if err := PartialUniqueIndex(db, "test_users", []string{"NAME"}, NotDeleted(db)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `CREATE UNIQUE INDEX "UDX_test_users_NAME" ON "test_users" ("NAME") WHERE "DELETED_AT" IS NULL`
+	if got := statements(); len(got) != 1 || got[0] != want {
+		t.Errorf("SQL = %q, want [%q]", got, want)
+	}
+}
@@ -0,0 +1,106 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/zeroxsolutions/barbatos/cache"
+	"gorm.io/gorm"
+)
+
+// CacheInvalidator is a GORM plugin deleting the cache keys related to records after
+// they are (soft) deleted, keeping caches coherent without manual invalidation calls.
+// The keys of each model type are derived by the function registered with InvalidateOnDelete.
+//
+// Example:
+//
+//	invalidator := orm.NewCacheInvalidator(c)
+//	orm.InvalidateOnDelete(invalidator, func(user *User) []string {
+//		return []string{"user:" + user.ID}
+//	})
+//	err := db.Use(invalidator)
+type CacheInvalidator struct {
+	cache cache.Cache
+
+	mu   sync.RWMutex
+	keys map[reflect.Type]func(record interface{}) []string
+}
+
+// NewCacheInvalidator creates a CacheInvalidator deleting keys from c.
+func NewCacheInvalidator(c cache.Cache) *CacheInvalidator {
+	return &CacheInvalidator{
+		cache: c,
+		keys:  make(map[reflect.Type]func(record interface{}) []string),
+	}
+}
+
+// InvalidateOnDelete registers keys as the function deriving the cache keys of a deleted
+// record of type T. It is called with the value passed to Delete, so conditions-only deletes
+// such as db.Where("NAME = ?", name).Delete(&User{}) see a record with zero-valued fields.
+func InvalidateOnDelete[T any](inv *CacheInvalidator, keys func(record *T) []string) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	inv.keys[reflect.TypeOf((*T)(nil)).Elem()] = func(record interface{}) []string {
+		return keys(record.(*T))
+	}
+}
+
+// Name returns the name of the plugin.
+func (inv *CacheInvalidator) Name() string {
+	return "orm:cache_invalidator"
+}
+
+// Initialize registers the invalidation callback after the delete transaction is committed.
+func (inv *CacheInvalidator) Initialize(db *gorm.DB) error {
+	return db.Callback().Delete().After("gorm:commit_or_rollback_transaction").
+		Register("orm:invalidate_cache", inv.afterDelete)
+}
+
+// afterDelete deletes the cache keys of the deleted records if the delete succeeded.
+// Keys are deleted once the statement's own transaction has committed. When the delete runs
+// inside WithTransaction, the deletion is deferred with RegisterAfterCommit until the outermost
+// transaction commits; inside other caller-managed transactions, whose commit GORM does not
+// expose, the keys are deleted right after the statement. A cache failure is added to the
+// statement's errors, unless the deletion is deferred.
+func (inv *CacheInvalidator) afterDelete(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+	inv.mu.RLock()
+	keysOf, ok := inv.keys[db.Statement.Schema.ModelType]
+	inv.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	var keys []string
+	value := db.Statement.ReflectValue
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			record := reflect.Indirect(value.Index(i))
+			if record.CanAddr() {
+				keys = append(keys, keysOf(record.Addr().Interface())...)
+			}
+		}
+	case reflect.Struct:
+		if value.CanAddr() {
+			keys = append(keys, keysOf(value.Addr().Interface())...)
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	ctx := db.Statement.Context
+	if _, deferred := ctx.Value(afterCommitKey{}).(*afterCommit); deferred {
+		RegisterAfterCommit(ctx, func() {
+			_ = inv.cache.Del(ctx, keys...)
+		})
+		return
+	}
+	if err := inv.cache.Del(ctx, keys...); err != nil {
+		_ = db.AddError(fmt.Errorf("orm: invalidate cache: %w", err))
+	}
+}
@@ -0,0 +1,173 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zeroxsolutions/barbatos/cache"
+	"gorm.io/gorm"
+)
+
+var errCacheDelete = errors.New("cache delete failed")
+
+// failingDelCache is an in-memory cache whose deletions fail with errCacheDelete.
+type failingDelCache struct {
+	*testCache
+}
+
+func (c failingDelCache) Del(context.Context, ...string) error {
+	return errCacheDelete
+}
+
+// newInvalidatedDB opens a database using a CacheInvalidator on c, deriving the key
+// "user:<ID>" of deleted users.
+func newInvalidatedDB(t *testing.T, c cache.Cache) *gorm.DB {
+	t.Helper()
+	db := newTestDB(t, testUsersTable)
+	invalidator := NewCacheInvalidator(c)
+	InvalidateOnDelete(invalidator, func(user *testUser) []string {
+		return []string{"user:" + user.ID}
+	})
+	if err := db.Use(invalidator); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestCacheInvalidator(t *testing.T) {
+	tests := []struct {
+		name     string
+		del      func(db *gorm.DB, ids []string) error
+		wantKept []int
+	}{
+		{
+			name: "single record",
+			del: func(db *gorm.DB, ids []string) error {
+				return db.Delete(&testUser{MModel: MModel{ID: ids[0]}}).Error
+			},
+			wantKept: []int{1},
+		},
+		{
+			name: "records slice",
+			del: func(db *gorm.DB, ids []string) error {
+				users := []testUser{{MModel: MModel{ID: ids[0]}}, {MModel: MModel{ID: ids[1]}}}
+				return db.Delete(&users).Error
+			},
+		},
+		// Conditions-only deletes see a zero-valued record, whose key is "user:".
+		{
+			name: "conditions only",
+			del: func(db *gorm.DB, ids []string) error {
+				return db.Where("NAME = ?", "alice").Delete(&testUser{}).Error
+			},
+			wantKept: []int{0, 1},
+		},
+		{
+			name: "other model",
+			del: func(db *gorm.DB, ids []string) error {
+				return db.Delete(&testEvent{}, "1 = 1").Error
+			},
+			wantKept: []int{0, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := newTestCache()
+			db := newInvalidatedDB(t, c)
+			if err := db.Exec(testEventsTable).Error; err != nil {
+				t.Fatal(err)
+			}
+			ids := createUsers(t, db, "alice", "bob")
+			for _, id := range ids {
+				if err := c.Set(ctx, "user:"+id, id); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := tt.del(db, ids); err != nil {
+				t.Fatalf("delete: %v", err)
+			}
+			for i, id := range ids {
+				kept := false
+				for _, k := range tt.wantKept {
+					kept = kept || k == i
+				}
+				if _, err := c.Get(ctx, "user:"+id); (err == nil) != kept {
+					t.Errorf("key of user %d: err = %v, want kept %v", i, err, kept)
+				}
+			}
+		})
+	}
+}
+
+func TestCacheInvalidatorFailures(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("cache failure", func(t *testing.T) {
+		db := newInvalidatedDB(t, failingDelCache{testCache: newTestCache()})
+		id := createUsers(t, db, "alice")[0]
+		err := db.Delete(&testUser{MModel: MModel{ID: id}}).Error
+		if !errors.Is(err, errCacheDelete) {
+			t.Errorf("err = %v, want %v", err, errCacheDelete)
+		}
+	})
+
+	t.Run("failed delete", func(t *testing.T) {
+		c := newTestCache()
+		db := newInvalidatedDB(t, c)
+		if err := c.Set(ctx, "user:1", "1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Exec("DROP TABLE test_users").Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Delete(&testUser{MModel: MModel{ID: "1"}}).Error; err == nil {
+			t.Fatal("delete succeeded without a table")
+		}
+		if _, err := c.Get(ctx, "user:1"); err != nil {
+			t.Errorf("key deleted after a failed delete: %v", err)
+		}
+	})
+}
+
+func TestCacheInvalidatorTransaction(t *testing.T) {
+	errRollback := errors.New("rollback")
+	tests := []struct {
+		name        string
+		err         error
+		wantDeleted bool
+	}{
+		{"committed", nil, true},
+		{"rolled back", errRollback, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := newTestCache()
+			db := newInvalidatedDB(t, c)
+			id := createUsers(t, db, "alice")[0]
+			if err := c.Set(ctx, "user:"+id, id); err != nil {
+				t.Fatal(err)
+			}
+
+			err := WithTransaction(ctx, db, func(ctx context.Context, tx *gorm.DB) error {
+				if err := tx.Delete(&testUser{MModel: MModel{ID: id}}).Error; err != nil {
+					return err
+				}
+				if _, err := c.Get(ctx, "user:"+id); err != nil {
+					t.Errorf("key deleted before the commit: %v", err)
+				}
+				return tt.err
+			})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			_, err = c.Get(ctx, "user:"+id)
+			if deleted := errors.Is(err, cache.ErrCacheNil); deleted != tt.wantDeleted {
+				t.Errorf("key deleted = %v (err %v), want %v", deleted, err, tt.wantDeleted)
+			}
+		})
+	}
+}
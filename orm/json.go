@@ -0,0 +1,73 @@
+package orm
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// jsonExtract is a clause.Expression that extracts the value at a path of a JSON column
+// as text, using the SQL syntax of the dialect the statement is built for.
+type jsonExtract struct {
+	column string
+	path   []string
+}
+
+// JSONExtract returns an expression selecting, as text, the value found at path inside
+// the JSON column. The path is a dot-separated list of object keys, e.g. "address.city".
+// The generated SQL depends on the dialect:
+//
+//   - MySQL: JSON_UNQUOTE(JSON_EXTRACT(column, '$.address.city'))
+//   - PostgreSQL: column #>> '{address,city}'
+//   - SQLite: json_extract(column, '$.address.city')
+//
+// Example:
+//
+//	db.Select("ID", orm.JSONExtract("PROFILE", "address.city")).Find(&rows)
+func JSONExtract(column, path string) clause.Expression {
+	return jsonExtract{column: column, path: strings.Split(path, ".")}
+}
+
+// Build writes the dialect-specific extraction SQL to builder.
+func (e jsonExtract) Build(builder clause.Builder) {
+	dialect := ""
+	if stmt, ok := builder.(*gorm.Statement); ok {
+		dialect = stmt.Dialector.Name()
+	}
+
+	switch dialect {
+	case "postgres":
+		builder.WriteQuoted(e.column)
+		builder.WriteString(" #>> ")
+		builder.AddVar(builder, "{"+strings.Join(e.path, ",")+"}")
+	case "sqlite":
+		builder.WriteString("json_extract(")
+		builder.WriteQuoted(e.column)
+		builder.WriteString(", ")
+		builder.AddVar(builder, "$."+strings.Join(e.path, "."))
+		builder.WriteString(")")
+	default:
+		builder.WriteString("JSON_UNQUOTE(JSON_EXTRACT(")
+		builder.WriteQuoted(e.column)
+		builder.WriteString(", ")
+		builder.AddVar(builder, "$."+strings.Join(e.path, "."))
+		builder.WriteString("))")
+	}
+}
+
+// JSONContains returns a GORM scope that filters rows whose JSON column holds value at path.
+// On MySQL and PostgreSQL the extracted value is text, so value is formatted with fmt.Sprint
+// before the comparison (e.g. true becomes "true" and 42 becomes "42"). On SQLite, where
+// json_extract returns native SQL values, value is compared as-is.
+//
+//	db.Scopes(orm.JSONContains("PROFILE", "address.city", "Hanoi")).Find(&users)
+func JSONContains(column, path string, value interface{}) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if _, ok := value.(string); !ok && db.Dialector.Name() != "sqlite" {
+			value = fmt.Sprint(value)
+		}
+		return db.Where(clause.Expr{SQL: "? = ?", Vars: []interface{}{JSONExtract(column, path), value}})
+	}
+}
@@ -0,0 +1,100 @@
+package orm
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testProfile is a model with a JSON column.
+type testProfile struct {
+	ID      int    `gorm:"column:ID;primaryKey"`
+	Profile string `gorm:"column:PROFILE"`
+}
+
+const testProfilesTable = `CREATE TABLE test_profiles (
+	ID integer PRIMARY KEY,
+	PROFILE text
+)`
+
+func TestJSONExtractSQL(t *testing.T) {
+	tests := []struct {
+		dialect string
+		want    string
+	}{
+		{"sqlite", "SELECT json_extract(`PROFILE`, \"$.address.city\") FROM `test_profiles`"},
+		{"postgres", "SELECT `PROFILE` #>> \"{address,city}\" FROM `test_profiles`"},
+		{"mysql", "SELECT JSON_UNQUOTE(JSON_EXTRACT(`PROFILE`, \"$.address.city\")) FROM `test_profiles`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			db, err := gorm.Open(renamedDialector{Dialector: sqlite.Open("file::memory:"), name: tt.dialect}, &gorm.Config{Logger: logger.Discard})
+			if err != nil {
+				t.Fatalf("open database: %v", err)
+			}
+			got := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				return tx.Model(&testProfile{}).Select("?", JSONExtract("PROFILE", "address.city")).Find(&[]string{})
+			})
+			if got != tt.want {
+				t.Errorf("SQL = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONContains(t *testing.T) {
+	db := newTestDB(t, testProfilesTable)
+	profiles := []testProfile{
+		{ID: 1, Profile: `{"address":{"city":"Hanoi"},"age":30,"admin":true}`},
+		{ID: 2, Profile: `{"address":{"city":"Paris"},"age":41,"admin":false}`},
+		{ID: 3, Profile: `{"age":30}`},
+	}
+	if err := db.Create(&profiles).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		value   interface{}
+		wantIDs []int
+	}{
+		{"nested string", "address.city", "Hanoi", []int{1}},
+		{"number", "age", 30, []int{1, 3}},
+		{"boolean", "admin", true, []int{1}},
+		{"no match", "address.city", "Rome", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []int
+			err := db.Model(&testProfile{}).Scopes(JSONContains("PROFILE", tt.path, tt.value)).Order("ID").Pluck("ID", &ids).Error
+			if err != nil {
+				t.Fatalf("query: %v", err)
+			}
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("IDs = %v, want %v", ids, tt.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Fatalf("IDs = %v, want %v", ids, tt.wantIDs)
+				}
+			}
+		})
+	}
+}
+
+func TestJSONExtractSelect(t *testing.T) {
+	db := newTestDB(t, testProfilesTable)
+	if err := db.Create(&testProfile{ID: 1, Profile: `{"address":{"city":"Hanoi"}}`}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var city string
+	if err := db.Model(&testProfile{}).Select("?", JSONExtract("PROFILE", "address.city")).Scan(&city).Error; err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if city != "Hanoi" {
+		t.Errorf("city = %q, want Hanoi", city)
+	}
+}
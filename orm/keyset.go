@@ -0,0 +1,127 @@
+package orm
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultPageSize is the number of rows returned by KeysetPage when no valid limit is given.
+const DefaultPageSize = 50
+
+// Cursor identifies the position after the last row of a page returned by KeysetPage.
+// The zero Cursor designates the start of the table when requesting a page, and the end of
+// the table when returned as the next cursor.
+type Cursor struct {
+	// CreatedAt is the creation time of the last row of the page.
+	CreatedAt time.Time `json:"c"`
+	// ID is the ID of the last row of the page.
+	ID string `json:"i"`
+}
+
+// IsZero reports whether c is the zero Cursor.
+func (c Cursor) IsZero() bool {
+	return c.CreatedAt.IsZero() && c.ID == ""
+}
+
+// EncodeCursor returns c as an opaque URL-safe string, suitable for API responses.
+func EncodeCursor(c Cursor) string {
+	if c.IsZero() {
+		return ""
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a string produced by EncodeCursor. An empty string decodes to the zero
+// Cursor. Malformed strings return an error wrapping ErrInvalidCursor.
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+	if s == "" {
+		return c, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Cursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	return c, nil
+}
+
+// KeysetPage returns up to limit rows of type T created after the position (afterCreatedAt,
+// afterID), ordered by CREATED_AT then ID as with OrderByOldest, and the cursor of the next
+// page. Unlike offset pagination, each page is a range scan on the (CREATED_AT, ID) order, so
+// its cost does not grow with the page number and rows never shift between pages; rows sharing
+// a timestamp are split by ID without gaps or duplicates. A zero afterCreatedAt and empty
+// afterID return the first page, and a zero next cursor means there are no more rows.
+// A limit below 1 defaults to DefaultPageSize. The conditions already on db are kept.
+//
+//	cursor, err := orm.DecodeCursor(req.Cursor)
+//	users, next, err := orm.KeysetPage[User](db, cursor.CreatedAt, cursor.ID, 20)
+//	resp.NextCursor = orm.EncodeCursor(next)
+func KeysetPage[T any](db *gorm.DB, afterCreatedAt time.Time, afterID string, limit int) ([]T, Cursor, error) {
+	if limit < 1 {
+		limit = DefaultPageSize
+	}
+	query := db.Model(new(T))
+	if !afterCreatedAt.IsZero() || afterID != "" {
+		// gorm wraps a condition containing OR in parentheses before joining it with the others.
+		createdAt, id := column("CREATED_AT"), column("ID")
+		query = query.Where(clause.Expr{
+			SQL:  "? > ? OR (? = ? AND ? > ?)",
+			Vars: []interface{}{createdAt, afterCreatedAt, createdAt, afterCreatedAt, id, afterID},
+		})
+	}
+
+	var rows []T
+	if err := query.Scopes(OrderByOldest()).Limit(limit + 1).Find(&rows).Error; err != nil {
+		return nil, Cursor{}, err
+	}
+	if len(rows) <= limit {
+		return rows, Cursor{}, nil
+	}
+	rows = rows[:limit]
+
+	next, err := cursorOf(db, &rows[limit-1])
+	if err != nil {
+		return nil, Cursor{}, err
+	}
+	return rows, next, nil
+}
+
+// cursorOf returns the Cursor positioned at row.
+func cursorOf[T any](db *gorm.DB, row *T) (Cursor, error) {
+	stmt := db.Session(&gorm.Session{NewDB: true}).Model(row).Statement
+	if err := stmt.Parse(row); err != nil {
+		return Cursor{}, err
+	}
+	createdAt, id := stmt.Schema.LookUpField("CREATED_AT"), stmt.Schema.LookUpField("ID")
+	if createdAt == nil {
+		return Cursor{}, fmt.Errorf("%w: %q", ErrUnknownColumn, "CREATED_AT")
+	}
+	if id == nil {
+		return Cursor{}, fmt.Errorf("%w: %q", ErrUnknownColumn, "ID")
+	}
+
+	rv := reflect.ValueOf(row).Elem()
+	var c Cursor
+	value, _ := createdAt.ValueOf(stmt.Context, rv)
+	switch v := value.(type) {
+	case time.Time:
+		c.CreatedAt = v
+	case *time.Time:
+		if v != nil {
+			c.CreatedAt = *v
+		}
+	}
+	value, _ = id.ValueOf(stmt.Context, rv)
+	c.ID = fmt.Sprint(value)
+	return c, nil
+}
@@ -0,0 +1,149 @@
+package orm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeysetPage(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		limit     int
+		active    bool
+		wantPages []string
+	}{
+		{"pages of three", 3, false, []string{"a,b,c", "d,e,f", "g"}},
+		{"exact pages", 7, false, []string{"a,b,c,d,e,f,g"}},
+		{"default limit", 0, false, []string{"a,b,c,d,e,f,g"}},
+		{"conditions kept", 2, true, []string{"a,c", "e,g"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, testUsersTable)
+			// b, c and d share a timestamp and are ordered by ID.
+			created := []struct {
+				id string
+				at time.Time
+			}{
+				{"f", base.Add(3 * time.Hour)},
+				{"c", base.Add(time.Hour)},
+				{"a", base},
+				{"e", base.Add(2 * time.Hour)},
+				{"d", base.Add(time.Hour)},
+				{"g", base.Add(4 * time.Hour)},
+				{"b", base.Add(time.Hour)},
+			}
+			users := make([]testUser, len(created))
+			for i, c := range created {
+				users[i] = testUser{MModel: MModel{ID: c.id, CreatedAt: c.at}, Name: c.id, IsActive: true}
+			}
+			if err := ImportCreate(db, users); err != nil {
+				t.Fatal(err)
+			}
+			if err := db.Model(&testUser{}).Where("ID IN ?", []string{"b", "d", "f"}).Update("IS_ACTIVE", false).Error; err != nil {
+				t.Fatal(err)
+			}
+			// Soft-deleted rows are skipped.
+			if err := ImportCreate(db, []testUser{{MModel: MModel{ID: "bb", CreatedAt: base.Add(time.Hour)}, Name: "bb"}}); err != nil {
+				t.Fatal(err)
+			}
+			if err := db.Delete(&testUser{}, "ID = ?", "bb").Error; err != nil {
+				t.Fatal(err)
+			}
+			query := db
+			if tt.active {
+				query = db.Where("IS_ACTIVE = ?", true)
+			}
+
+			var pages []string
+			token := ""
+			for len(pages) <= len(tt.wantPages) {
+				cursor, err := DecodeCursor(token)
+				if err != nil {
+					t.Fatal(err)
+				}
+				page, next, err := KeysetPage[testUser](query, cursor.CreatedAt, cursor.ID, tt.limit)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var names []string
+				for _, user := range page {
+					names = append(names, user.Name)
+				}
+				pages = append(pages, strings.Join(names, ","))
+				if next.IsZero() {
+					break
+				}
+				token = EncodeCursor(next)
+			}
+			if strings.Join(pages, "|") != strings.Join(tt.wantPages, "|") {
+				t.Errorf("pages = %v, want %v", pages, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestKeysetPageEmpty(t *testing.T) {
+	db := newTestDB(t, testUsersTable)
+	page, next, err := KeysetPage[testUser](db, time.Time{}, "", 10)
+	if err != nil || len(page) != 0 || !next.IsZero() {
+		t.Errorf("KeysetPage = %v, %v, %v, want no rows and a zero cursor", page, next, err)
+	}
+}
+
+func TestKeysetPagePostgres(t *testing.T) {
+	db, statements := newPostgresDryRunDB(t)
+	if _, _, err := KeysetPage[testUser](db, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), "u1", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `SELECT * FROM "test_users" WHERE ("test_users"."CREATED_AT" > $1 OR ("test_users"."CREATED_AT" = $2 AND "test_users"."ID" > $3)) ` +
+		`AND "test_users"."DELETED_AT" IS NULL ORDER BY "test_users"."CREATED_AT","test_users"."ID" LIMIT $4`
+	if got := statements(); len(got) != 1 || got[0] != want {
+		t.Errorf("SQL = %q, want [%q]", got, want)
+	}
+}
+
+func TestKeysetPageUnknownColumn(t *testing.T) {
+	db := newTestDB(t, "CREATE TABLE test_accounts (ID integer PRIMARY KEY, NAME varchar(255), CREATED_AT datetime)")
+	for i := 1; i <= 2; i++ {
+		if err := db.Exec("INSERT INTO test_accounts (ID, NAME, CREATED_AT) VALUES (?, 'x', ?)", i, time.Now()).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := KeysetPage[testAccount](db, time.Time{}, "", 1); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("err = %v, want ErrUnknownColumn", err)
+	}
+}
+
+func TestCursorEncoding(t *testing.T) {
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		token   string
+		want    Cursor
+		wantErr error
+	}{
+		{"empty", "", Cursor{}, nil},
+		{"round trip", EncodeCursor(Cursor{CreatedAt: at, ID: "u1"}), Cursor{CreatedAt: at, ID: "u1"}, nil},
+		{"not base64", "%%%", Cursor{}, ErrInvalidCursor},
+		{"not JSON", "bm90IGpzb24", Cursor{}, ErrInvalidCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeCursor(tt.token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !got.CreatedAt.Equal(tt.want.CreatedAt) || got.ID != tt.want.ID {
+				t.Errorf("cursor = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if got := EncodeCursor(Cursor{}); got != "" {
+		t.Errorf("EncodeCursor of the zero Cursor = %q, want empty", got)
+	}
+}
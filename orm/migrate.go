@@ -0,0 +1,167 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SchemaMigration records a migration applied by a Migrator.
+// It is stored in the schema_migrations table.
+type SchemaMigration struct {
+	// Version is the version of the applied migration.
+	Version string `json:"version" gorm:"column:VERSION;primaryKey;type:varchar(255);not null"`
+
+	// AppliedAt stores the timestamp indicating when the migration was applied.
+	AppliedAt time.Time `json:"appliedAt" gorm:"column:APPLIED_AT;not null"`
+}
+
+// TableName returns the name of the table tracking applied migrations.
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationStep is a function applying or reverting one schema migration.
+type MigrationStep func(tx *gorm.DB) error
+
+// migration is a registered pair of up and down steps.
+type migration struct {
+	version string
+	up      MigrationStep
+	down    MigrationStep
+}
+
+// Migrator runs versioned schema migrations in registration order and tracks the
+// applied versions in the schema_migrations table.
+//
+// Each migration runs in its own transaction together with the update of the tracking
+// table. Note that MySQL implicitly commits DDL statements, so a failing migration mixing
+// several DDL statements may be partially applied there.
+//
+// Example:
+//
+//	m := orm.NewMigrator()
+//	m.Register("0001_create_users", func(tx *gorm.DB) error {
+//		return tx.Migrator().CreateTable(&User{})
+//	}, func(tx *gorm.DB) error {
+//		return tx.Migrator().DropTable(&User{})
+//	})
+//	err := m.Up(ctx, db)
+type Migrator struct {
+	migrations []migration
+}
+
+// NewMigrator creates a Migrator with no registered migrations.
+func NewMigrator() *Migrator {
+	return &Migrator{}
+}
+
+// Register appends a migration identified by version. Migrations are applied in the
+// order they are registered and rolled back in reverse order. The down step may be nil
+// for irreversible migrations, in which case rolling them back fails.
+// It panics if version is empty or already registered, or if up is nil.
+func (m *Migrator) Register(version string, up, down MigrationStep) {
+	if version == "" {
+		panic("orm: empty migration version")
+	}
+	if up == nil {
+		panic(fmt.Sprintf("orm: nil up step for migration %q", version))
+	}
+	if m.index(version) >= 0 {
+		panic(fmt.Sprintf("orm: migration %q registered twice", version))
+	}
+	m.migrations = append(m.migrations, migration{version: version, up: up, down: down})
+}
+
+// Up applies every registered migration that has not been applied yet, in registration
+// order. It stops at the first failing migration. Calling Up again is a no-op.
+func (m *Migrator) Up(ctx context.Context, db *gorm.DB) error {
+	db = db.WithContext(ctx)
+	applied, err := m.applied(db)
+	if err != nil {
+		return err
+	}
+
+	for _, mig := range m.migrations {
+		if applied[mig.version] {
+			continue
+		}
+		mig := mig
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := mig.up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: mig.version, AppliedAt: Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("orm: migration %q up: %w", mig.version, err)
+		}
+	}
+	return nil
+}
+
+// Down rolls back, in reverse registration order, every applied migration registered
+// after the to version, leaving to itself applied. An empty to rolls back all migrations.
+// It returns ErrUnknownMigration if to is not registered.
+func (m *Migrator) Down(ctx context.Context, db *gorm.DB, to string) error {
+	stop := -1
+	if to != "" {
+		if stop = m.index(to); stop < 0 {
+			return fmt.Errorf("%w: %q", ErrUnknownMigration, to)
+		}
+	}
+
+	db = db.WithContext(ctx)
+	applied, err := m.applied(db)
+	if err != nil {
+		return err
+	}
+
+	for i := len(m.migrations) - 1; i > stop; i-- {
+		mig := m.migrations[i]
+		if !applied[mig.version] {
+			continue
+		}
+		if mig.down == nil {
+			return fmt.Errorf("orm: migration %q is irreversible", mig.version)
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := mig.down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{Version: mig.version}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("orm: migration %q down: %w", mig.version, err)
+		}
+	}
+	return nil
+}
+
+// index returns the position of version in the registered migrations, or -1.
+func (m *Migrator) index(version string) int {
+	for i, mig := range m.migrations {
+		if mig.version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// applied ensures the tracking table exists and returns the set of applied versions.
+func (m *Migrator) applied(db *gorm.DB) (map[string]bool, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, err
+	}
+	var versions []string
+	if err := db.Model(&SchemaMigration{}).Pluck("VERSION", &versions).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[string]bool, len(versions))
+	for _, version := range versions {
+		applied[version] = true
+	}
+	return applied, nil
+}
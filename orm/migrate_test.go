@@ -0,0 +1,184 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+var errMigration = errors.New("migration failed")
+
+// newTestMigrator registers a migration creating a table per name, logging the steps run.
+// The migration named by failing fails its up step after creating its table.
+func newTestMigrator(log *[]string, failing string, names ...string) *Migrator {
+	m := NewMigrator()
+	for _, name := range names {
+		name := name
+		m.Register(name, func(tx *gorm.DB) error {
+			*log = append(*log, "up "+name)
+			if err := tx.Exec("CREATE TABLE " + name + " (ID integer)").Error; err != nil {
+				return err
+			}
+			if name == failing {
+				return errMigration
+			}
+			return nil
+		}, func(tx *gorm.DB) error {
+			*log = append(*log, "down "+name)
+			return tx.Exec("DROP TABLE " + name).Error
+		})
+	}
+	return m
+}
+
+// appliedVersions returns the versions recorded in schema_migrations, if it exists.
+func appliedVersions(t *testing.T, db *gorm.DB) []string {
+	t.Helper()
+	var versions []string
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return versions
+	}
+	if err := db.Model(&SchemaMigration{}).Order("VERSION").Pluck("VERSION", &versions).Error; err != nil {
+		t.Fatal(err)
+	}
+	return versions
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name        string
+		run         func(m *Migrator, db *gorm.DB) error
+		wantErr     error
+		wantLog     string
+		wantApplied string
+	}{
+		{
+			name:        "up",
+			run:         func(m *Migrator, db *gorm.DB) error { return m.Up(ctx, db) },
+			wantLog:     "[up t1 up t2 up t3]",
+			wantApplied: "[t1 t2 t3]",
+		},
+		{
+			name: "up twice",
+			run: func(m *Migrator, db *gorm.DB) error {
+				if err := m.Up(ctx, db); err != nil {
+					return err
+				}
+				return m.Up(ctx, db)
+			},
+			wantLog:     "[up t1 up t2 up t3]",
+			wantApplied: "[t1 t2 t3]",
+		},
+		{
+			name: "down to version",
+			run: func(m *Migrator, db *gorm.DB) error {
+				if err := m.Up(ctx, db); err != nil {
+					return err
+				}
+				return m.Down(ctx, db, "t1")
+			},
+			wantLog:     "[up t1 up t2 up t3 down t3 down t2]",
+			wantApplied: "[t1]",
+		},
+		{
+			name: "down all",
+			run: func(m *Migrator, db *gorm.DB) error {
+				if err := m.Up(ctx, db); err != nil {
+					return err
+				}
+				return m.Down(ctx, db, "")
+			},
+			wantLog:     "[up t1 up t2 up t3 down t3 down t2 down t1]",
+			wantApplied: "[]",
+		},
+		{
+			name:        "down unknown version",
+			run:         func(m *Migrator, db *gorm.DB) error { return m.Down(ctx, db, "t9") },
+			wantErr:     ErrUnknownMigration,
+			wantLog:     "[]",
+			wantApplied: "[]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			var log []string
+			m := newTestMigrator(&log, "", "t1", "t2", "t3")
+			if err := tt.run(m, db); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got := fmt.Sprint(log); got != tt.wantLog {
+				t.Errorf("steps = %s, want %s", got, tt.wantLog)
+			}
+			if got := fmt.Sprint(appliedVersions(t, db)); got != tt.wantApplied {
+				t.Errorf("applied = %s, want %s", got, tt.wantApplied)
+			}
+		})
+	}
+}
+
+func TestMigratorUpFailure(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	var log []string
+	m := newTestMigrator(&log, "t2", "t1", "t2", "t3")
+
+	if err := m.Up(ctx, db); !errors.Is(err, errMigration) {
+		t.Fatalf("Up: err = %v, want %v", err, errMigration)
+	}
+	if got := fmt.Sprint(log); got != "[up t1 up t2]" {
+		t.Errorf("steps = %s, want [up t1 up t2]", got)
+	}
+	if got := fmt.Sprint(appliedVersions(t, db)); got != "[t1]" {
+		t.Errorf("applied = %s, want [t1]", got)
+	}
+	// The failing migration is rolled back with its transaction.
+	if db.Migrator().HasTable("t2") {
+		t.Error("table of the failed migration exists")
+	}
+}
+
+func TestMigratorIrreversible(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	m := NewMigrator()
+	m.Register("t1", func(tx *gorm.DB) error { return nil }, nil)
+	if err := m.Up(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Down(ctx, db, ""); err == nil {
+		t.Fatal("Down succeeded for an irreversible migration")
+	}
+	if got := fmt.Sprint(appliedVersions(t, db)); got != "[t1]" {
+		t.Errorf("applied = %s, want [t1]", got)
+	}
+}
+
+func TestMigratorRegisterPanics(t *testing.T) {
+	up := func(tx *gorm.DB) error { return nil }
+	tests := []struct {
+		name    string
+		version string
+		up      MigrationStep
+	}{
+		{"empty version", "", up},
+		{"nil up step", "t2", nil},
+		{"duplicate version", "t1", up},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMigrator()
+			m.Register("t1", up, nil)
+			defer func() {
+				if recover() == nil {
+					t.Error("Register did not panic")
+				}
+			}()
+			m.Register(tt.version, tt.up, nil)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package orm
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OrderByNewest returns a GORM scope that orders records from the most recently created to
+// the oldest, by CREATED_AT then ID descending. The ID tiebreaker makes the order deterministic
+// when several records share a timestamp, so pages do not overlap or skip records.
+//
+//	db.Scopes(orm.OrderByNewest()).Limit(20).Offset(40).Find(&users)
+func OrderByNewest() func(*gorm.DB) *gorm.DB {
+	return orderByCreated(true)
+}
+
+// OrderByOldest returns a GORM scope that orders records from the oldest to the most recently
+// created, by CREATED_AT then ID ascending, with the same stable tiebreak as OrderByNewest.
+//
+//	db.Scopes(orm.OrderByOldest()).Find(&users)
+func OrderByOldest() func(*gorm.DB) *gorm.DB {
+	return orderByCreated(false)
+}
+
+// orderByCreated returns a scope ordering by the CREATED_AT and ID columns of the current table.
+func orderByCreated(desc bool) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+			{Column: clause.Column{Table: clause.CurrentTable, Name: "CREATED_AT"}, Desc: desc},
+			{Column: clause.Column{Table: clause.CurrentTable, Name: "ID"}, Desc: desc},
+		}})
+	}
+}
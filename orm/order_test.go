@@ -0,0 +1,58 @@
+package orm
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestOrderByCreated(t *testing.T) {
+	tests := []struct {
+		name  string
+		scope func(*gorm.DB) *gorm.DB
+		want  string
+	}{
+		{"newest", OrderByNewest(), "d,c,b,a"},
+		{"oldest", OrderByOldest(), "a,b,c,d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, testUsersTable)
+			early := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			// b, c and d share a timestamp and are ordered by ID.
+			users := []testUser{
+				{MModel: MModel{ID: "4", CreatedAt: early.Add(time.Hour)}, Name: "d"},
+				{MModel: MModel{ID: "2", CreatedAt: early.Add(time.Hour)}, Name: "b"},
+				{MModel: MModel{ID: "1", CreatedAt: early}, Name: "a"},
+				{MModel: MModel{ID: "3", CreatedAt: early.Add(time.Hour)}, Name: "c"},
+			}
+			if err := ImportCreate(db, users); err != nil {
+				t.Fatal(err)
+			}
+
+			var found []testUser
+			if err := db.Scopes(tt.scope).Find(&found).Error; err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, user := range found {
+				names = append(names, user.Name)
+			}
+			if got := strings.Join(names, ","); got != tt.want {
+				t.Errorf("order = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderByCreatedQualifiesColumns(t *testing.T) {
+	db := newTestDB(t, testUsersTable)
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Scopes(OrderByNewest()).Find(&[]testUser{})
+	})
+	if !strings.Contains(sql, "ORDER BY `test_users`.`CREATED_AT` DESC,`test_users`.`ID` DESC") {
+		t.Errorf("SQL = %s, want the columns qualified with the table", sql)
+	}
+}
@@ -0,0 +1,102 @@
+// Package ormtest provides helpers for testing code built on the orm package.
+// It is intended to be imported from tests only.
+package ormtest
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// QueryCounter is a GORM plugin that counts the statements executed through the database
+// it is registered on. It is safe for concurrent use.
+//
+// Combined with AssertMaxQueries it protects against N+1 query regressions:
+//
+//	counter := ormtest.NewQueryCounter()
+//	if err := db.Use(counter); err != nil {
+//		t.Fatal(err)
+//	}
+//	listOrders(db)
+//	ormtest.AssertMaxQueries(t, counter, 2)
+type QueryCounter struct {
+	mu         sync.Mutex
+	count      int
+	statements []string
+}
+
+// NewQueryCounter creates a QueryCounter with a count of zero.
+func NewQueryCounter() *QueryCounter {
+	return &QueryCounter{}
+}
+
+// Name returns the name of the plugin.
+func (c *QueryCounter) Name() string {
+	return "ormtest:query_counter"
+}
+
+// Initialize registers the counting callbacks after every kind of statement.
+func (c *QueryCounter) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	for _, err := range []error{
+		callback.Create().After("*").Register("ormtest:count", c.record),
+		callback.Query().After("*").Register("ormtest:count", c.record),
+		callback.Update().After("*").Register("ormtest:count", c.record),
+		callback.Delete().After("*").Register("ormtest:count", c.record),
+		callback.Row().After("*").Register("ormtest:count", c.record),
+		callback.Raw().After("*").Register("ormtest:count", c.record),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// record counts the statement if it was actually built and sent to the database.
+func (c *QueryCounter) record(db *gorm.DB) {
+	sql := db.Statement.SQL.String()
+	if sql == "" || db.DryRun {
+		return
+	}
+	c.mu.Lock()
+	c.count++
+	c.statements = append(c.statements, sql)
+	c.mu.Unlock()
+}
+
+// Count returns the number of statements executed since the counter was created or reset.
+func (c *QueryCounter) Count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.count
+}
+
+// Statements returns the SQL of the statements executed since the counter was created
+// or reset, with placeholders instead of bound parameters.
+func (c *QueryCounter) Statements() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	statements := make([]string, len(c.statements))
+	copy(statements, c.statements)
+	return statements
+}
+
+// Reset sets the count back to zero.
+func (c *QueryCounter) Reset() {
+	c.mu.Lock()
+	c.count = 0
+	c.statements = nil
+	c.mu.Unlock()
+}
+
+// AssertMaxQueries fails the test if counter recorded more than n statements,
+// listing the executed statements to help locate the N+1 pattern.
+func AssertMaxQueries(t testing.TB, counter *QueryCounter, n int) {
+	t.Helper()
+	if count := counter.Count(); count > n {
+		t.Errorf("ormtest: executed %d queries, want at most %d:\n%s", count, n, "  "+strings.Join(counter.Statements(), "\n  "))
+	}
+}
@@ -0,0 +1,147 @@
+package ormtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type item struct {
+	ID   int    `gorm:"column:ID;primaryKey"`
+	Name string `gorm:"column:NAME"`
+}
+
+// newCountedDB opens an in-memory SQLite database with an items table and registers a
+// QueryCounter on it.
+func newCountedDB(t *testing.T) (*gorm.DB, *QueryCounter) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	if err := db.Exec("CREATE TABLE items (ID integer PRIMARY KEY, NAME text)").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	counter := NewQueryCounter()
+	if err := db.Use(counter); err != nil {
+		t.Fatalf("Use: %v", err)
+	}
+	return db, counter
+}
+
+func TestQueryCounter(t *testing.T) {
+	tests := []struct {
+		name      string
+		run       func(db *gorm.DB) error
+		wantCount int
+		wantSQL   []string
+	}{
+		{"create", func(db *gorm.DB) error { return db.Create(&item{ID: 1, Name: "a"}).Error }, 1, []string{"INSERT"}},
+		{"query", func(db *gorm.DB) error { return db.Find(&[]item{}).Error }, 1, []string{"SELECT"}},
+		{"update and delete", func(db *gorm.DB) error {
+			if err := db.Model(&item{}).Where("ID = ?", 1).Update("NAME", "b").Error; err != nil {
+				return err
+			}
+			return db.Delete(&item{}, 1).Error
+		}, 2, []string{"UPDATE", "DELETE"}},
+		{"raw and row", func(db *gorm.DB) error {
+			if err := db.Exec("DELETE FROM items").Error; err != nil {
+				return err
+			}
+			var n int
+			return db.Raw("SELECT COUNT(*) FROM items").Row().Scan(&n)
+		}, 2, []string{"DELETE", "SELECT"}},
+		{"dry run", func(db *gorm.DB) error {
+			return db.Session(&gorm.Session{DryRun: true}).Find(&[]item{}).Error
+		}, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, counter := newCountedDB(t)
+			if err := tt.run(db); err != nil {
+				t.Fatal(err)
+			}
+			if got := counter.Count(); got != tt.wantCount {
+				t.Errorf("Count = %d, want %d: %v", got, tt.wantCount, counter.Statements())
+			}
+			statements := counter.Statements()
+			if len(statements) != len(tt.wantSQL) {
+				t.Fatalf("Statements = %v, want %d", statements, len(tt.wantSQL))
+			}
+			for i, prefix := range tt.wantSQL {
+				if !strings.HasPrefix(statements[i], prefix) {
+					t.Errorf("statement %d = %q, want prefix %q", i, statements[i], prefix)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryCounterReset(t *testing.T) {
+	db, counter := newCountedDB(t)
+	if err := db.Find(&[]item{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	statements := counter.Statements()
+	counter.Reset()
+	if counter.Count() != 0 || len(counter.Statements()) != 0 {
+		t.Errorf("after Reset: Count = %d, Statements = %v", counter.Count(), counter.Statements())
+	}
+	// The statements returned before the reset are a copy.
+	if len(statements) != 1 {
+		t.Errorf("statements before Reset = %v", statements)
+	}
+}
+
+// recordingTB is a testing.TB recording the failures reported through Errorf.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertMaxQueries(t *testing.T) {
+	tests := []struct {
+		max      int
+		wantFail bool
+	}{
+		{3, false},
+		{2, false},
+		{1, true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.max), func(t *testing.T) {
+			db, counter := newCountedDB(t)
+			for i := 0; i < 2; i++ {
+				if err := db.Find(&[]item{}).Error; err != nil {
+					t.Fatal(err)
+				}
+			}
+			tb := &recordingTB{TB: t}
+			AssertMaxQueries(tb, counter, tt.max)
+			if failed := len(tb.errors) > 0; failed != tt.wantFail {
+				t.Fatalf("failed = %v, want %v", failed, tt.wantFail)
+			}
+			// The failure lists the executed statements.
+			if tt.wantFail && !strings.Contains(tb.errors[0], "SELECT * FROM `items`") {
+				t.Errorf("failure message %q does not list the statements", tb.errors[0])
+			}
+		})
+	}
+}
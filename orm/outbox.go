@@ -0,0 +1,96 @@
+package orm
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/pubsub"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// relayBatchSize is the number of outbox events loaded per query by Relay.
+const relayBatchSize = 100
+
+// OutboxEvent is a row of the transactional outbox table.
+// Events are written in the same transaction as the entity change that produced them
+// and are later published by Relay, so an event is emitted if and only if the change
+// is committed.
+type OutboxEvent struct {
+	// ID is the primary key of the event, represented as a ULID so that events sort
+	// in the order they were written.
+	ID string `json:"id" gorm:"column:ID;primaryKey;type:char(26);not null"`
+
+	// Topic is the pub-sub topic the event is published to.
+	Topic string `json:"topic" gorm:"column:TOPIC;type:varchar(255);not null"`
+
+	// Payload is the JSON-encoded event body.
+	Payload []byte `json:"payload" gorm:"column:PAYLOAD;not null"`
+
+	// CreatedAt stores the timestamp indicating when the event was written.
+	CreatedAt time.Time `json:"createdAt" gorm:"column:CREATED_AT;not null"`
+
+	// SentAt stores the timestamp indicating when the event was published.
+	// It is NULL for events that have not been published yet and is indexed
+	// so that pending events can be found efficiently.
+	SentAt *time.Time `json:"sentAt" gorm:"column:SENT_AT;index:IDX_OUTBOX_SENT_AT"`
+}
+
+// TableName returns the name of the outbox table.
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
+
+// EmitEvent writes an event with the JSON encoding of payload to the outbox table using tx.
+// It is meant to be called from GORM `AfterCreate`, `AfterUpdate`, and `AfterDelete` hooks,
+// whose tx argument is the transaction of the entity change, so the event row is committed
+// or rolled back together with the entity.
+//
+//	func (u *User) AfterCreate(tx *gorm.DB) error {
+//		return orm.EmitEvent(tx, "user.created", u)
+//	}
+func EmitEvent(tx *gorm.DB, topic string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	event := OutboxEvent{
+		ID:        NewULID(),
+		Topic:     topic,
+		Payload:   data,
+		CreatedAt: Now(),
+	}
+	return tx.Session(&gorm.Session{NewDB: true}).Create(&event).Error
+}
+
+// Relay publishes all pending outbox events to pub in the order they were written and
+// marks each one as sent once it has been published. It stops at the first publishing
+// error, leaving that event and the following ones pending for the next run, and returns
+// the error. Relay performs a single pass; run it periodically (e.g. on a ticker) to
+// keep the outbox drained.
+//
+// Delivery is at-least-once: if marking an event as sent fails after it was published,
+// or several relays run concurrently, the event may be published more than once.
+func Relay(ctx context.Context, db *gorm.DB, pub pubsub.Publisher) error {
+	db = db.WithContext(ctx)
+	for {
+		var events []OutboxEvent
+		if err := db.Where(clause.Eq{Column: column("SENT_AT"), Value: nil}).
+			Order(clause.OrderByColumn{Column: column("ID")}).Limit(relayBatchSize).Find(&events).Error; err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := pub.Publish(ctx, event.Topic, event.Payload); err != nil {
+				return err
+			}
+			err := db.Model(&OutboxEvent{}).Where(byID(event.ID)).Update("SENT_AT", Now()).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(events) < relayBatchSize {
+			return nil
+		}
+	}
+}
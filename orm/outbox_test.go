@@ -0,0 +1,177 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+const testOutboxTable = `CREATE TABLE outbox_events (
+	ID char(26) PRIMARY KEY,
+	TOPIC varchar(255) NOT NULL,
+	PAYLOAD blob NOT NULL,
+	CREATED_AT datetime NOT NULL,
+	SENT_AT datetime
+)`
+
+var errPublish = errors.New("publish failed")
+
+// recordingPublisher is a pubsub.Publisher recording the published messages.
+// It fails once failAfter messages have been published, if failAfter is positive.
+type recordingPublisher struct {
+	failAfter int
+	published []string
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, topic string, messages ...[]byte) error {
+	for _, msg := range messages {
+		if p.failAfter > 0 && len(p.published) >= p.failAfter {
+			return errPublish
+		}
+		p.published = append(p.published, topic+":"+string(msg))
+	}
+	return nil
+}
+
+func (p *recordingPublisher) PublishWithPriority(ctx context.Context, topic string, priority uint8, msg []byte) error {
+	return p.Publish(ctx, topic, msg)
+}
+
+func (p *recordingPublisher) IsConnected(ctx context.Context) bool      { return true }
+func (p *recordingPublisher) CheckConnection(ctx context.Context) error { return nil }
+func (p *recordingPublisher) Close() error                              { return nil }
+
+// pendingEvents returns the number of outbox events not sent yet.
+func pendingEvents(t *testing.T, db *gorm.DB) int64 {
+	t.Helper()
+	var n int64
+	if err := db.Model(&OutboxEvent{}).Where("SENT_AT IS NULL").Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestEmitEvent(t *testing.T) {
+	errRollback := errors.New("rollback")
+	tests := []struct {
+		name        string
+		txErr       error
+		wantPending int64
+	}{
+		{"committed", nil, 1},
+		{"rolled back", errRollback, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, testUsersTable, testOutboxTable)
+			err := db.Transaction(func(tx *gorm.DB) error {
+				user := testUser{Name: "alice", IsActive: true}
+				if err := tx.Create(&user).Error; err != nil {
+					return err
+				}
+				if err := EmitEvent(tx, "user.created", map[string]string{"name": user.Name}); err != nil {
+					return err
+				}
+				return tt.txErr
+			})
+			if !errors.Is(err, tt.txErr) {
+				t.Fatalf("Transaction: err = %v, want %v", err, tt.txErr)
+			}
+			if got := pendingEvents(t, db); got != tt.wantPending {
+				t.Errorf("pending events = %d, want %d", got, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestEmitEventInvalidPayload(t *testing.T) {
+	db := newTestDB(t, testOutboxTable)
+	if err := EmitEvent(db, "user.created", make(chan int)); err == nil {
+		t.Fatal("EmitEvent succeeded with an unencodable payload")
+	}
+	if got := pendingEvents(t, db); got != 0 {
+		t.Errorf("pending events = %d, want 0", got)
+	}
+}
+
+func TestRelay(t *testing.T) {
+	tests := []struct {
+		name          string
+		events        int
+		failAfter     int
+		wantErr       error
+		wantPublished int
+	}{
+		{"empty outbox", 0, 0, nil, 0},
+		{"single batch", 3, 0, nil, 3},
+		{"several batches", relayBatchSize + 5, 0, nil, relayBatchSize + 5},
+		{"publish failure", 5, 2, errPublish, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, testOutboxTable)
+			for i := 0; i < tt.events; i++ {
+				if err := EmitEvent(db, "counter", i); err != nil {
+					t.Fatalf("EmitEvent: %v", err)
+				}
+			}
+
+			pub := &recordingPublisher{failAfter: tt.failAfter}
+			if err := Relay(context.Background(), db, pub); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Relay: err = %v, want %v", err, tt.wantErr)
+			}
+			if len(pub.published) != tt.wantPublished {
+				t.Fatalf("published %d events, want %d", len(pub.published), tt.wantPublished)
+			}
+			// Events are published in the order they were written.
+			for i, msg := range pub.published {
+				if want := "counter:" + strconv.Itoa(i); msg != want {
+					t.Fatalf("event %d = %q, want %q", i, msg, want)
+				}
+			}
+			if got, want := pendingEvents(t, db), int64(tt.events-tt.wantPublished); got != want {
+				t.Errorf("pending events = %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestRelayResumesAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t, testOutboxTable)
+	for i := 0; i < 4; i++ {
+		if err := EmitEvent(db, "counter", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := Relay(ctx, db, &recordingPublisher{failAfter: 1}); !errors.Is(err, errPublish) {
+		t.Fatalf("first Relay: err = %v, want %v", err, errPublish)
+	}
+	pub := &recordingPublisher{}
+	if err := Relay(ctx, db, pub); err != nil {
+		t.Fatalf("second Relay: %v", err)
+	}
+	if got := fmt.Sprint(pub.published); got != "[counter:1 counter:2 counter:3]" {
+		t.Errorf("published = %v, want the events left pending", got)
+	}
+	if got := pendingEvents(t, db); got != 0 {
+		t.Errorf("pending events = %d, want 0", got)
+	}
+}
+
+func TestRelayPostgres(t *testing.T) {
+	db, statements := newPostgresDryRunDB(t)
+	if err := Relay(context.Background(), db, &recordingPublisher{}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `SELECT * FROM "outbox_events" WHERE "outbox_events"."SENT_AT" IS NULL ORDER BY "outbox_events"."ID" LIMIT $1`
+	if got := statements(); len(got) != 1 || got[0] != want {
+		t.Errorf("SQL = %q, want [%q]", got, want)
+	}
+}
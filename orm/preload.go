@@ -0,0 +1,74 @@
+package orm
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// PreloadAll returns db with the association paths of the model T preloaded. Paths use
+// GORM's dotted notation for nested associations (e.g. "Orders.Items") and are validated
+// against the relationships of T: unknown segments add an error wrapping ErrUnknownRelation
+// to the query instead of silently loading nothing. Each association is loaded with one
+// query per path segment, however many records are returned.
+//
+//	var users []User
+//	err := orm.PreloadAll[User](db, "Orders.Items", "Company").Find(&users).Error
+func PreloadAll[T any](db *gorm.DB, paths ...string) *gorm.DB {
+	stmt := db.Session(&gorm.Session{NewDB: true}).Model(new(T)).Statement
+	if err := stmt.Parse(new(T)); err != nil {
+		_ = db.AddError(err)
+		return db
+	}
+	for _, path := range paths {
+		if err := validateRelationPath(stmt.Schema, path); err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		db = db.Preload(path)
+	}
+	return db
+}
+
+// PreloadWith returns a GORM scope that preloads the association path with conditions,
+// which are passed to GORM's Preload (e.g. a where clause and its arguments, or a function
+// customizing the association query). The path is validated against the relationships of the
+// statement's model like PreloadAll.
+//
+//	err := db.Scopes(
+//		orm.PreloadWith("Orders", "STATUS = ?", "paid"),
+//		orm.PreloadWith("Orders.Items"),
+//	).Find(&users).Error
+func PreloadWith(path string, conds ...interface{}) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		model := db.Statement.Model
+		if model == nil {
+			model = db.Statement.Dest
+		}
+		if err := db.Statement.Parse(model); err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		if err := validateRelationPath(db.Statement.Schema, path); err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		return db.Preload(path, conds...)
+	}
+}
+
+// validateRelationPath checks that every segment of the dotted path names a relationship,
+// starting from s.
+func validateRelationPath(s *schema.Schema, path string) error {
+	current := s
+	for _, name := range strings.Split(path, ".") {
+		relation, ok := current.Relationships.Relations[name]
+		if !ok {
+			return fmt.Errorf("%w: %q on %s", ErrUnknownRelation, path, current.Name)
+		}
+		current = relation.FieldSchema
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package orm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// testBuyer, testPurchase and testLine form a two-level association tree.
+type testBuyer struct {
+	ID        int            `gorm:"column:ID;primaryKey"`
+	Name      string         `gorm:"column:NAME"`
+	Purchases []testPurchase `gorm:"foreignKey:BuyerID"`
+}
+
+type testPurchase struct {
+	ID      int        `gorm:"column:ID;primaryKey"`
+	BuyerID int        `gorm:"column:BUYER_ID"`
+	Status  string     `gorm:"column:STATUS"`
+	Lines   []testLine `gorm:"foreignKey:PurchaseID"`
+}
+
+type testLine struct {
+	ID         int    `gorm:"column:ID;primaryKey"`
+	PurchaseID int    `gorm:"column:PURCHASE_ID"`
+	Product    string `gorm:"column:PRODUCT"`
+}
+
+// newPreloadDB creates a buyer with a paid and a pending purchase of one line each.
+func newPreloadDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := newTestDB(t)
+	if err := db.AutoMigrate(&testBuyer{}, &testPurchase{}, &testLine{}); err != nil {
+		t.Fatal(err)
+	}
+	buyer := testBuyer{Name: "ada", Purchases: []testPurchase{
+		{Status: "paid", Lines: []testLine{{Product: "book"}}},
+		{Status: "pending", Lines: []testLine{{Product: "pen"}}},
+	}}
+	if err := db.Create(&buyer).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// describe summarizes the loaded associations of buyer, e.g. "paid[book] pending[pen]".
+func describe(buyer testBuyer) string {
+	var parts []string
+	for _, purchase := range buyer.Purchases {
+		var products []string
+		for _, line := range purchase.Lines {
+			products = append(products, line.Product)
+		}
+		parts = append(parts, purchase.Status+"["+strings.Join(products, ",")+"]")
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestPreloadAll(t *testing.T) {
+	tests := []struct {
+		name    string
+		paths   []string
+		want    string
+		wantErr error
+	}{
+		{"no paths", nil, "", nil},
+		{"one level", []string{"Purchases"}, "paid[] pending[]", nil},
+		{"nested", []string{"Purchases.Lines"}, "paid[book] pending[pen]", nil},
+		{"unknown relation", []string{"Orders"}, "", ErrUnknownRelation},
+		{"unknown nested relation", []string{"Purchases", "Purchases.Items"}, "", ErrUnknownRelation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newPreloadDB(t)
+			var buyers []testBuyer
+			err := PreloadAll[testBuyer](db, tt.paths...).Find(&buyers).Error
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(buyers) != 1 || describe(buyers[0]) != tt.want {
+				t.Errorf("buyers = %+v, want %q", buyers, tt.want)
+			}
+		})
+	}
+}
+
+func TestPreloadWith(t *testing.T) {
+	tests := []struct {
+		name    string
+		scopes  []func(*gorm.DB) *gorm.DB
+		want    string
+		wantErr error
+	}{
+		{"conditions", []func(*gorm.DB) *gorm.DB{PreloadWith("Purchases", "STATUS = ?", "paid")}, "paid[]", nil},
+		{"nested with conditions", []func(*gorm.DB) *gorm.DB{
+			PreloadWith("Purchases", "STATUS = ?", "pending"),
+			PreloadWith("Purchases.Lines"),
+		}, "pending[pen]", nil},
+		{"unknown relation", []func(*gorm.DB) *gorm.DB{PreloadWith("Purchases.Items")}, "", ErrUnknownRelation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newPreloadDB(t)
+			var buyers []testBuyer
+			err := db.Scopes(tt.scopes...).Find(&buyers).Error
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(buyers) != 1 || describe(buyers[0]) != tt.want {
+				t.Errorf("buyers = %+v, want %q", buyers, tt.want)
+			}
+		})
+	}
+}
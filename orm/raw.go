@@ -0,0 +1,25 @@
+package orm
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// RawInto runs the raw SQL query with its bound args and scans the resulting rows into a
+// slice of T, which may be a model embedding MModel or PModel or any projection struct whose
+// fields map to the selected columns.
+//
+// Raw queries bypass GORM's scopes: soft-deleted rows are not filtered out, so the query
+// must include a condition such as NotDeleted when they should be excluded.
+// Arguments are always bound as parameters and never interpolated into the SQL.
+//
+//	users, err := orm.RawInto[User](ctx, db,
+//		"SELECT * FROM USERS WHERE EMAIL LIKE ? AND "+orm.NotDeleted(db), "%@example.com")
+func RawInto[T any](ctx context.Context, db *gorm.DB, sql string, args ...interface{}) ([]T, error) {
+	var out []T
+	if err := db.WithContext(ctx).Raw(sql, args...).Scan(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,64 @@
+package orm
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRawInto(t *testing.T) {
+	db := newTestDB(t, testUsersTable)
+	ids := createUsers(t, db, "alice", "bob", "carol")
+	if err := db.Delete(&testUser{}, "ID = ?", ids[2]).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		sql       string
+		args      []interface{}
+		wantNames []string
+		wantErr   bool
+	}{
+		{"deleted rows included", "SELECT * FROM test_users", nil, []string{"alice", "bob", "carol"}, false},
+		{"not deleted condition", "SELECT * FROM test_users WHERE " + NotDeleted(db), nil, []string{"alice", "bob"}, false},
+		{"bound argument", "SELECT * FROM test_users WHERE NAME = ?", []interface{}{"bob"}, []string{"bob"}, false},
+		{"argument not interpolated", "SELECT * FROM test_users WHERE NAME = ?", []interface{}{"x' OR '1'='1"}, nil, false},
+		{"invalid query", "SELECT * FROM missing_table", nil, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, err := RawInto[testUser](context.Background(), db, tt.sql, tt.args...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			var names []string
+			for _, user := range users {
+				names = append(names, user.Name)
+			}
+			sort.Strings(names)
+			if strings.Join(names, ",") != strings.Join(tt.wantNames, ",") {
+				t.Errorf("names = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestRawIntoProjection(t *testing.T) {
+	db := newTestDB(t, testUsersTable)
+	createUsers(t, db, "alice", "bob")
+
+	type activeCount struct {
+		Active int `gorm:"column:ACTIVE"`
+		Total  int `gorm:"column:TOTAL"`
+	}
+	rows, err := RawInto[activeCount](context.Background(), db,
+		"SELECT SUM(IS_ACTIVE) AS ACTIVE, COUNT(*) AS TOTAL FROM test_users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].Active != 2 || rows[0].Total != 2 {
+		t.Errorf("rows = %+v, want one row with 2 active of 2", rows)
+	}
+}
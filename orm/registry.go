@@ -0,0 +1,55 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+var (
+	registryMu sync.Mutex
+	registry   []interface{}
+)
+
+// Register adds models to the package registry migrated by AutoMigrateAll. It is meant to
+// be called from the init function of the package declaring the models, so that the list
+// of migrated models stays in sync with their definitions. Registering the same model type
+// twice has no effect.
+//
+//	func init() {
+//		orm.Register(&User{}, &Order{})
+//	}
+func Register(models ...interface{}) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, model := range models {
+		registered := false
+		for _, existing := range registry {
+			if reflect.TypeOf(existing) == reflect.TypeOf(model) {
+				registered = true
+				break
+			}
+		}
+		if !registered {
+			registry = append(registry, model)
+		}
+	}
+}
+
+// AutoMigrateAll runs AutoMigrate for every registered model, one at a time in registration
+// order, and returns the first error encountered.
+func AutoMigrateAll(db *gorm.DB) error {
+	registryMu.Lock()
+	models := make([]interface{}, len(registry))
+	copy(models, registry)
+	registryMu.Unlock()
+
+	for _, model := range models {
+		if err := db.AutoMigrate(model); err != nil {
+			return fmt.Errorf("orm: migrate %T: %w", model, err)
+		}
+	}
+	return nil
+}
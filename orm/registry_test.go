@@ -0,0 +1,84 @@
+package orm
+
+import (
+	"strings"
+	"testing"
+)
+
+type testAccount struct {
+	ID   int    `gorm:"column:ID;primaryKey"`
+	Name string `gorm:"column:NAME"`
+}
+
+type testInvoice struct {
+	ID        int `gorm:"column:ID;primaryKey"`
+	AccountID int `gorm:"column:ACCOUNT_ID"`
+}
+
+// resetRegistry empties the model registry for the duration of the test.
+func resetRegistry(t *testing.T) {
+	t.Helper()
+	registryMu.Lock()
+	previous := registry
+	registry = nil
+	registryMu.Unlock()
+	t.Cleanup(func() {
+		registryMu.Lock()
+		registry = previous
+		registryMu.Unlock()
+	})
+}
+
+func TestRegister(t *testing.T) {
+	resetRegistry(t)
+	Register(&testAccount{}, &testInvoice{})
+	Register(&testAccount{})
+
+	if len(registry) != 2 {
+		t.Fatalf("registry holds %d models, want 2", len(registry))
+	}
+	if _, ok := registry[0].(*testAccount); !ok {
+		t.Errorf("first model = %T, want *testAccount", registry[0])
+	}
+	if _, ok := registry[1].(*testInvoice); !ok {
+		t.Errorf("second model = %T, want *testInvoice", registry[1])
+	}
+}
+
+func TestAutoMigrateAll(t *testing.T) {
+	tests := []struct {
+		name       string
+		models     []interface{}
+		ddl        []string
+		wantErr    string
+		wantTables []string
+	}{
+		{"no models", nil, nil, "", nil},
+		{"every model", []interface{}{&testAccount{}, &testInvoice{}}, nil, "", []string{"test_accounts", "test_invoices"}},
+		// A view named like the table of testInvoice makes its creation fail, and the
+		// following models are not migrated.
+		{"failing model", []interface{}{&testInvoice{}, &testAccount{}}, []string{"CREATE VIEW test_invoices AS SELECT 1"},
+			"orm: migrate *orm.testInvoice", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetRegistry(t)
+			Register(tt.models...)
+			db := newTestDB(t, tt.ddl...)
+
+			err := AutoMigrateAll(db)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("AutoMigrateAll: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.wantErr)) {
+				t.Fatalf("err = %v, want prefix %q", err, tt.wantErr)
+			}
+			for _, table := range []string{"test_accounts", "test_invoices"} {
+				want := strings.Contains(strings.Join(tt.wantTables, ","), table)
+				if got := db.Migrator().HasTable(table); got != want {
+					t.Errorf("table %s exists = %v, want %v", table, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package orm
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// UseReadWriteSplit registers GORM's dbresolver plugin on db so that writes are routed
+// to the sources and reads (SELECT queries) are routed to the replicas. When several
+// connections are given for either role, one is picked at random for each statement.
+//
+// Replicas are usually updated asynchronously, so a read issued right after a write may
+// not observe it yet (replica lag). Use the UsePrimary scope for read-after-write paths
+// that must see their own writes.
+//
+//	err := orm.UseReadWriteSplit(db,
+//		[]gorm.Dialector{mysql.Open(primaryDSN)},
+//		[]gorm.Dialector{mysql.Open(replicaDSN)},
+//	)
+func UseReadWriteSplit(db *gorm.DB, sources, replicas []gorm.Dialector) error {
+	return db.Use(dbresolver.Register(dbresolver.Config{
+		Sources:  sources,
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	}))
+}
+
+// UsePrimary returns a GORM scope that forces the statement to run on a source (primary)
+// connection, including reads. This is the way to read your own writes despite replica lag.
+//
+//	db.Scopes(orm.UsePrimary()).First(&user, "ID = ?", id)
+func UsePrimary() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Clauses(dbresolver.Write)
+	}
+}
+
+// UseReplica returns a GORM scope that forces the statement to run on a replica connection.
+//
+//	db.Scopes(orm.UseReplica()).Find(&users)
+func UseReplica() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Clauses(dbresolver.Read)
+	}
+}
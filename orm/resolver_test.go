@@ -0,0 +1,64 @@
+package orm
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openFileDB opens the SQLite database stored in path, creating it with the given DDL.
+func openFileDB(t *testing.T, path string, ddl ...string) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	for _, statement := range ddl {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatalf("create schema: %v", err)
+		}
+	}
+	return db
+}
+
+func TestUseReadWriteSplit(t *testing.T) {
+	dir := t.TempDir()
+	primary, replica := filepath.Join(dir, "primary.db"), filepath.Join(dir, "replica.db")
+	openFileDB(t, replica, testUsersTable)
+	db := openFileDB(t, primary, testUsersTable)
+
+	if err := UseReadWriteSplit(db, []gorm.Dialector{sqlite.Open(primary)}, []gorm.Dialector{sqlite.Open(replica)}); err != nil {
+		t.Fatalf("UseReadWriteSplit: %v", err)
+	}
+	// The write goes to the primary; the replica never receives it, like a lagging replica.
+	createUsers(t, db, "alice")
+
+	tests := []struct {
+		name  string
+		query *gorm.DB
+		want  int64
+	}{
+		{"reads use the replica", db, 0},
+		{"UseReplica", db.Scopes(UseReplica()), 0},
+		{"UsePrimary reads own writes", db.Scopes(UsePrimary()), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var count int64
+			if err := tt.query.Model(&testUser{}).Count(&count).Error; err != nil {
+				t.Fatalf("count: %v", err)
+			}
+			if count != tt.want {
+				t.Errorf("count = %d, want %d", count, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/backoff"
+	"gorm.io/gorm"
+)
+
+// RetryOption configures a WithRetryableTx call.
+type RetryOption func(*retryConfig)
+
+// retryConfig holds the settings of a WithRetryableTx call.
+type retryConfig struct {
+	strategy backoff.Strategy
+}
+
+// RetryBackoff sets the backoff strategy spacing the attempts of a WithRetryableTx call.
+// A stateful strategy must not be shared between concurrent calls. The default is an
+// exponential backoff from 20ms up to 1s with 20% jitter.
+func RetryBackoff(strategy backoff.Strategy) RetryOption {
+	return func(c *retryConfig) {
+		c.strategy = strategy
+	}
+}
+
+// retryableSQLStates lists the SQLSTATE codes of transient transaction failures:
+// serialization failure and deadlock detected.
+var retryableSQLStates = map[string]struct{}{
+	"40001": {},
+	"40P01": {},
+}
+
+// IsRetryableTxError reports whether err is a deadlock or serialization failure after
+// which the whole transaction can safely be retried. It recognizes errors exposing a
+// PostgreSQL SQLSTATE of 40001 or 40P01 (as pgconn.PgError does) and MySQL error 1213.
+func IsRetryableTxError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var sqlState interface{ SQLState() string }
+	if errors.As(err, &sqlState) {
+		if _, ok := retryableSQLStates[sqlState.SQLState()]; ok {
+			return true
+		}
+	}
+	// The MySQL driver exposes the error number only as a struct field, which cannot be
+	// matched without importing the driver, so its message ("Error 1213 ...") is used.
+	return strings.Contains(err.Error(), "Error 1213")
+}
+
+// WithRetryableTx runs fn in a transaction and retries the whole transaction, up to
+// maxAttempts attempts in total (a value below 1 is treated as 1), when it fails with a
+// deadlock or serialization failure as reported by IsRetryableTxError. Attempts are spaced
+// with the strategy set by RetryBackoff.
+//
+// Non-retryable errors are returned immediately. If ctx is done while waiting between
+// attempts, ctx.Err() is returned. Since fn may run several times, it must not have side
+// effects outside of the transaction.
+//
+//	err := orm.WithRetryableTx(ctx, db, func(tx *gorm.DB) error {
+//		return tx.Model(&account).Update("BALANCE", gorm.Expr("BALANCE - ?", amount)).Error
+//	}, 5)
+func WithRetryableTx(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error, maxAttempts int, opts ...RetryOption) error {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	config := retryConfig{strategy: backoff.NewExponential(20*time.Millisecond, time.Second, 0.2)}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	db = db.WithContext(ctx)
+	strategy := config.strategy
+	for attempt := 1; ; attempt++ {
+		err := db.Transaction(fn)
+		if err == nil || attempt >= maxAttempts || !IsRetryableTxError(err) {
+			return err
+		}
+
+		timer := time.NewTimer(strategy.Next(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/backoff"
+	"gorm.io/gorm"
+)
+
+func TestIsRetryableTxError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"serialization failure", sqlStateError("40001"), true},
+		{"deadlock detected", sqlStateError("40P01"), true},
+		{"wrapped SQLSTATE", fmt.Errorf("update: %w", sqlStateError("40001")), true},
+		{"unique violation", sqlStateError("23505"), false},
+		{"MySQL deadlock", errors.New("Error 1213 (40001): Deadlock found when trying to get lock"), true},
+		{"MySQL duplicate entry", errors.New("Error 1062 (23000): Duplicate entry"), false},
+		{"record not found", gorm.ErrRecordNotFound, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryableTxError(tt.err); got != tt.want {
+				t.Errorf("IsRetryableTxError = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithRetryableTx(t *testing.T) {
+	errPermanent := errors.New("permanent")
+	tests := []struct {
+		name         string
+		failures     []error
+		maxAttempts  int
+		wantErr      error
+		wantAttempts int
+		wantRows     int64
+	}{
+		{"success", nil, 3, nil, 1, 1},
+		{"retried until success", []error{sqlStateError("40001"), sqlStateError("40P01")}, 3, nil, 3, 1},
+		{"attempts exhausted", []error{sqlStateError("40001"), sqlStateError("40001")}, 2, sqlStateError("40001"), 2, 0},
+		{"permanent error", []error{errPermanent}, 3, errPermanent, 1, 0},
+		{"attempts below one", []error{sqlStateError("40001")}, 0, sqlStateError("40001"), 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, testUsersTable)
+			attempts := 0
+			err := WithRetryableTx(context.Background(), db, func(tx *gorm.DB) error {
+				attempts++
+				if err := tx.Create(&testUser{Name: "alice", IsActive: true}).Error; err != nil {
+					return err
+				}
+				if attempts <= len(tt.failures) {
+					return tt.failures[attempts-1]
+				}
+				return nil
+			}, tt.maxAttempts, RetryBackoff(backoff.NewConstant(time.Millisecond)))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			// Failed attempts are rolled back.
+			var rows int64
+			if err := db.Model(&testUser{}).Count(&rows).Error; err != nil {
+				t.Fatal(err)
+			}
+			if rows != tt.wantRows {
+				t.Errorf("rows = %d, want %d", rows, tt.wantRows)
+			}
+		})
+	}
+}
+
+func TestWithRetryableTxCancelled(t *testing.T) {
+	db := newTestDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	attempts := 0
+	err := WithRetryableTx(ctx, db, func(tx *gorm.DB) error {
+		attempts++
+		return sqlStateError("40001")
+	}, 5, RetryBackoff(backoff.NewConstant(time.Hour)))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
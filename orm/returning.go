@@ -0,0 +1,40 @@
+package orm
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateReturning inserts value and populates it with the given columns as stored by
+// the database, such as timestamps filled from column defaults. When no columns are
+// given, every column is returned.
+//
+// On dialects that support it (PostgreSQL and SQLite) this is done in a single round-trip
+// using a `RETURNING` clause. On other dialects, such as MySQL, the record is reloaded by
+// its primary key after the insert.
+//
+// The BeforeCreate hooks of MModel and PModel already set CreatedAt and UpdatedAt from the
+// package clock, so those columns are only worth returning for models whose timestamps are
+// left to column defaults.
+//
+//	user := User{Name: "alice"}
+//	err := orm.CreateReturning(db, &user, "ID", "CREATED_AT")
+func CreateReturning[T any](db *gorm.DB, value *T, columns ...string) error {
+	switch db.Dialector.Name() {
+	case "postgres", "sqlite":
+		returning := clause.Returning{}
+		for _, column := range columns {
+			returning.Columns = append(returning.Columns, clause.Column{Name: column})
+		}
+		return db.Clauses(returning).Create(value).Error
+	default:
+		if err := db.Create(value).Error; err != nil {
+			return err
+		}
+		reload := db.Session(&gorm.Session{NewDB: true})
+		if len(columns) > 0 {
+			reload = reload.Select(columns)
+		}
+		return reload.Take(value).Error
+	}
+}
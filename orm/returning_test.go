@@ -0,0 +1,100 @@
+package orm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testTicket is a model whose CODE and CREATED_AT columns are filled by database defaults.
+type testTicket struct {
+	ID        string    `gorm:"column:ID;primaryKey"`
+	Title     string    `gorm:"column:TITLE"`
+	Code      string    `gorm:"column:CODE;<-:false"`
+	CreatedAt time.Time `gorm:"column:CREATED_AT;<-:false"`
+}
+
+const testTicketsTable = `CREATE TABLE test_tickets (
+	ID varchar(36) PRIMARY KEY,
+	TITLE varchar(255),
+	CODE varchar(255) DEFAULT 'generated',
+	CREATED_AT datetime DEFAULT CURRENT_TIMESTAMP
+)`
+
+// renamedDialector reports another dialect name, to run the code paths of dialects without
+// RETURNING support against SQLite.
+type renamedDialector struct {
+	gorm.Dialector
+	name string
+}
+
+func (d renamedDialector) Name() string { return d.name }
+
+// countingLogger is a silent gorm logger recording the executed statements.
+type countingLogger struct {
+	logger.Interface
+	statements []string
+}
+
+func (l *countingLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	l.statements = append(l.statements, sql)
+}
+
+func TestCreateReturning(t *testing.T) {
+	tests := []struct {
+		name        string
+		dialect     string
+		columns     []string
+		wantQueries int
+	}{
+		{"returning clause", "", []string{"CODE", "CREATED_AT"}, 1},
+		{"returning every column", "", nil, 1},
+		{"reload", "mysql", []string{"CODE", "CREATED_AT"}, 2},
+		{"reload every column", "mysql", nil, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t)
+			if tt.dialect != "" {
+				var err error
+				db, err = gorm.Open(renamedDialector{Dialector: sqlite.Open("file::memory:"), name: tt.dialect}, &gorm.Config{Logger: logger.Discard})
+				if err != nil {
+					t.Fatalf("open database: %v", err)
+				}
+				sqlDB, err := db.DB()
+				if err != nil {
+					t.Fatal(err)
+				}
+				sqlDB.SetMaxOpenConns(1)
+				t.Cleanup(func() { _ = sqlDB.Close() })
+			}
+			if err := db.Exec(testTicketsTable).Error; err != nil {
+				t.Fatalf("create schema: %v", err)
+			}
+			counter := &countingLogger{Interface: logger.Discard}
+			db = db.Session(&gorm.Session{Logger: counter})
+
+			ticket := testTicket{ID: "t1", Title: "broken build"}
+			if err := CreateReturning(db, &ticket, tt.columns...); err != nil {
+				t.Fatalf("CreateReturning: %v", err)
+			}
+			if got := len(counter.statements); got != tt.wantQueries {
+				t.Errorf("executed %d statements, want %d: %q", got, tt.wantQueries, counter.statements)
+			}
+			if ticket.Code != "generated" {
+				t.Errorf("Code = %q, want the database default", ticket.Code)
+			}
+			if ticket.CreatedAt.IsZero() {
+				t.Error("CreatedAt not populated from the database default")
+			}
+			if ticket.ID != "t1" || ticket.Title != "broken build" {
+				t.Errorf("ticket = %+v, want the inserted values kept", ticket)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package orm
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Columns returns a GORM scope that restricts the query to the given columns.
+// Each name may be either a struct field name (e.g. "CreatedAt") or a column name
+// (e.g. "CREATED_AT") and is validated against the schema of the statement's model.
+// Unknown names add an error wrapping ErrUnknownColumn to the query instead of being
+// interpolated, so the scope is safe to use with user-provided column lists.
+//
+//	var users []User
+//	err := db.Scopes(orm.Columns("ID", "NAME")).Find(&users).Error
+func Columns(cols ...string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		model := db.Statement.Model
+		if model == nil {
+			model = db.Statement.Dest
+		}
+		if err := db.Statement.Parse(model); err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+
+		selected := make([]string, 0, len(cols))
+		for _, col := range cols {
+			field := db.Statement.Schema.LookUpField(col)
+			if field == nil || field.DBName == "" {
+				_ = db.AddError(fmt.Errorf("%w: %q", ErrUnknownColumn, col))
+				return db
+			}
+			selected = append(selected, field.DBName)
+		}
+		return db.Select(selected)
+	}
+}
+
+// SelectInto loads the given columns of the model T and scans them into a slice of
+// the projection type V. Columns are validated with the Columns scope, and fields of V
+// that are not selected are left at their zero value.
+//
+//	type UserSummary struct {
+//		ID   string `gorm:"column:ID"`
+//		Name string `gorm:"column:NAME"`
+//	}
+//	summaries, err := orm.SelectInto[User, UserSummary](db.Where("IS_ACTIVE = ?", true), "ID", "NAME")
+func SelectInto[T, V any](db *gorm.DB, cols ...string) ([]V, error) {
+	var out []V
+	if err := db.Model(new(T)).Scopes(Columns(cols...)).Find(&out).Error; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,68 @@
+package orm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestColumns(t *testing.T) {
+	db := newTestDB(t, testUsersTable)
+	createUsers(t, db, "alice")
+
+	tests := []struct {
+		name     string
+		cols     []string
+		wantName string
+		wantID   bool
+		wantErr  error
+	}{
+		{name: "column names", cols: []string{"ID", "NAME"}, wantName: "alice", wantID: true},
+		{name: "field names", cols: []string{"Name"}, wantName: "alice"},
+		{name: "unknown column", cols: []string{"NAME", "PASSWORD"}, wantErr: ErrUnknownColumn},
+		{name: "injection attempt", cols: []string{"NAME; DROP TABLE test_users"}, wantErr: ErrUnknownColumn},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var users []testUser
+			err := db.Scopes(Columns(tt.cols...)).Find(&users).Error
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if len(users) != 1 {
+				t.Fatalf("got %d users, want 1", len(users))
+			}
+			if users[0].Name != tt.wantName || (users[0].ID != "") != tt.wantID {
+				t.Errorf("user = {ID: %q, Name: %q}, want name %q, ID selected %v", users[0].ID, users[0].Name, tt.wantName, tt.wantID)
+			}
+			if !users[0].CreatedAt.IsZero() {
+				t.Errorf("CreatedAt = %v, want zero since it was not selected", users[0].CreatedAt)
+			}
+		})
+	}
+}
+
+func TestSelectInto(t *testing.T) {
+	type userSummary struct {
+		ID       string `gorm:"column:ID"`
+		Name     string `gorm:"column:NAME"`
+		IsActive bool   `gorm:"column:IS_ACTIVE"`
+	}
+	db := newTestDB(t, testUsersTable)
+	ids := createUsers(t, db, "alice", "bob")
+
+	summaries, err := SelectInto[testUser, userSummary](db.Where("NAME = ?", "bob"), "ID", "NAME")
+	if err != nil {
+		t.Fatalf("SelectInto: %v", err)
+	}
+	want := userSummary{ID: ids[1], Name: "bob"}
+	if len(summaries) != 1 || summaries[0] != want {
+		t.Errorf("summaries = %+v, want [%+v]", summaries, want)
+	}
+
+	if _, err := SelectInto[testUser, userSummary](db, "EMAIL"); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("err = %v, want ErrUnknownColumn", err)
+	}
+}
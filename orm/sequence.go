@@ -0,0 +1,135 @@
+package orm
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Sequence stores the last value issued for a named sequence by NextSeq.
+// The sequences table must be migrated before NextSeq is used, e.g. with db.AutoMigrate(&orm.Sequence{}).
+type Sequence struct {
+	// Name identifies the sequence, e.g. the table whose rows are numbered.
+	Name string `json:"name" gorm:"column:NAME;primaryKey;type:varchar(191);not null"`
+
+	// Value is the last number issued for the sequence, 0 if none has been issued yet.
+	Value uint64 `json:"value" gorm:"column:VALUE;not null;default:0"`
+}
+
+// TableName returns the name of the table storing the sequences.
+func (Sequence) TableName() string {
+	return "sequences"
+}
+
+// Sequenced is an embeddable model adding a human-friendly sequential number to a table,
+// alongside its UUID primary key. The number is assigned on creation by the plugin returned
+// by Sequencing, using a sequence named after the table.
+//
+//	type Invoice struct {
+//		orm.MModel
+//		orm.Sequenced
+//	}
+type Sequenced struct {
+	// SeqNo is the sequential number of the record, unique within its table.
+	SeqNo uint64 `json:"seqNo" gorm:"column:SEQ_NO;not null;uniqueIndex"`
+}
+
+// NextSeq atomically increments the named sequence and returns its new value, starting at 1.
+// When db is a transaction, the number is only consumed if the transaction commits, so
+// numbers stay gap-free; concurrent callers are serialized on the sequence row until then.
+//
+// PostgreSQL and SQLite increment the row with UPDATE ... RETURNING. Other dialects, such as
+// MySQL, lock the row with SELECT ... FOR UPDATE before updating it.
+func NextSeq(db *gorm.DB, name string) (uint64, error) {
+	db = db.Session(&gorm.Session{NewDB: true})
+	err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&Sequence{Name: name}).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var value uint64
+	switch db.Dialector.Name() {
+	case "postgres", "sqlite":
+		err = db.Raw(
+			`UPDATE sequences SET "VALUE" = "VALUE" + 1 WHERE "NAME" = ? RETURNING "VALUE"`, name,
+		).Scan(&value).Error
+	default:
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var sequence Sequence
+			byName := clause.Eq{Column: column("NAME"), Value: name}
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(byName).Take(&sequence).Error
+			if err != nil {
+				return err
+			}
+			value = sequence.Value + 1
+			return tx.Model(&sequence).Where(byName).Update("VALUE", value).Error
+		})
+	}
+	if err != nil {
+		return 0, err
+	}
+	return value, nil
+}
+
+// sequencing is the GORM plugin returned by Sequencing.
+type sequencing struct{}
+
+// Sequencing returns a GORM plugin assigning the SeqNo of models embedding Sequenced when it
+// is zero, using NextSeq with the table name as the sequence name. The number is drawn in
+// the transaction of the create statement.
+//
+// A plugin is used rather than a BeforeCreate hook on Sequenced, since two embedded structs
+// declaring BeforeCreate (such as MModel and Sequenced) would make the method ambiguous and
+// GORM would run neither.
+//
+//	err := db.Use(orm.Sequencing())
+func Sequencing() gorm.Plugin {
+	return sequencing{}
+}
+
+// Name returns the name of the plugin.
+func (sequencing) Name() string {
+	return "orm:sequencing"
+}
+
+// Initialize registers the callback assigning sequence numbers.
+func (p sequencing) Initialize(db *gorm.DB) error {
+	return db.Callback().Create().After("gorm:begin_transaction").Before("gorm:create").
+		Register("orm:assign_seq_no", p.assign)
+}
+
+// assign sets the SeqNo of every record being created whose SeqNo is zero.
+func (sequencing) assign(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+	field := db.Statement.Schema.LookUpField("SeqNo")
+	if field == nil {
+		return
+	}
+
+	assign := func(record reflect.Value) {
+		if _, zero := field.ValueOf(db.Statement.Context, record); !zero {
+			return
+		}
+		value, err := NextSeq(db, db.Statement.Table)
+		if err != nil {
+			_ = db.AddError(err)
+			return
+		}
+		if err := field.Set(db.Statement.Context, record, value); err != nil {
+			_ = db.AddError(err)
+		}
+	}
+
+	value := db.Statement.ReflectValue
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len() && db.Error == nil; i++ {
+			assign(reflect.Indirect(value.Index(i)))
+		}
+	case reflect.Struct:
+		assign(value)
+	}
+}
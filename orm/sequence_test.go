@@ -0,0 +1,173 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testNumbered is a model embedding Sequenced.
+type testNumbered struct {
+	MModel
+	Sequenced
+	Name string `gorm:"column:NAME"`
+}
+
+const testNumberedTable = `CREATE TABLE test_numbereds (
+	ID varchar(36) PRIMARY KEY,
+	CREATED_AT datetime,
+	UPDATED_AT datetime,
+	DELETED_AT datetime,
+	SEQ_NO integer NOT NULL UNIQUE,
+	NAME varchar(255)
+)`
+
+// newSequenceDB opens a database with the sequences table, reporting dialect as its name
+// if set.
+func newSequenceDB(t *testing.T, dialect string, ddl ...string) *gorm.DB {
+	t.Helper()
+	var db *gorm.DB
+	if dialect == "" {
+		db = newTestDB(t, ddl...)
+	} else {
+		var err error
+		db, err = gorm.Open(renamedDialector{Dialector: sqlite.Open("file::memory:"), name: dialect}, &gorm.Config{Logger: logger.Discard})
+		if err != nil {
+			t.Fatalf("open database: %v", err)
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sqlDB.SetMaxOpenConns(1)
+		t.Cleanup(func() { _ = sqlDB.Close() })
+		for _, statement := range ddl {
+			if err := db.Exec(statement).Error; err != nil {
+				t.Fatalf("create schema: %v", err)
+			}
+		}
+	}
+	if err := db.AutoMigrate(&Sequence{}); err != nil {
+		t.Fatalf("migrate sequences: %v", err)
+	}
+	return db
+}
+
+func TestNextSeq(t *testing.T) {
+	for _, dialect := range []string{"", "mysql"} {
+		name := dialect
+		if name == "" {
+			name = "returning"
+		}
+		t.Run(name, func(t *testing.T) {
+			db := newSequenceDB(t, dialect)
+			var got []uint64
+			for _, sequence := range []string{"invoices", "invoices", "orders", "invoices"} {
+				value, err := NextSeq(db, sequence)
+				if err != nil {
+					t.Fatalf("NextSeq(%q): %v", sequence, err)
+				}
+				got = append(got, value)
+			}
+			// Each sequence is numbered independently from 1.
+			if fmt.Sprint(got) != "[1 2 1 3]" {
+				t.Errorf("values = %v, want [1 2 1 3]", got)
+			}
+		})
+	}
+}
+
+func TestNextSeqLockingQuoted(t *testing.T) {
+	db := newSequenceDB(t, "mysql")
+	var statements []string
+	record := func(tx *gorm.DB) { statements = append(statements, tx.Statement.SQL.String()) }
+	if err := db.Callback().Query().After("*").Register("test:record", record); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Callback().Update().After("*").Register("test:record", record); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NextSeq(db, "invoices"); err != nil {
+		t.Fatal(err)
+	}
+	if len(statements) != 2 {
+		t.Fatalf("statements = %q, want the locking select and the update", statements)
+	}
+	for _, statement := range statements {
+		if !strings.Contains(statement, "`sequences`.`NAME` = ") {
+			t.Errorf("statement %q does not match the quoted NAME column", statement)
+		}
+	}
+}
+
+func TestNextSeqRolledBack(t *testing.T) {
+	db := newSequenceDB(t, "")
+	if _, err := NextSeq(db, "invoices"); err != nil {
+		t.Fatal(err)
+	}
+	errRollback := errors.New("rollback")
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if value, err := NextSeq(tx, "invoices"); err != nil || value != 2 {
+			t.Errorf("NextSeq in transaction = %d, %v, want 2, nil", value, err)
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatal(err)
+	}
+	// The number drawn by the rolled back transaction is issued again.
+	if value, err := NextSeq(db, "invoices"); err != nil || value != 2 {
+		t.Errorf("NextSeq = %d, %v, want 2, nil", value, err)
+	}
+}
+
+func TestSequencing(t *testing.T) {
+	db := newSequenceDB(t, "", testNumberedTable, testUsersTable)
+	if err := db.Use(Sequencing()); err != nil {
+		t.Fatalf("Use: %v", err)
+	}
+
+	steps := []struct {
+		name string
+		rows []testNumbered
+	}{
+		{"single record", []testNumbered{{Name: "a"}}},
+		{"batch", []testNumbered{{Name: "b"}, {Name: "c"}}},
+		{"explicit number kept", []testNumbered{{Name: "d", Sequenced: Sequenced{SeqNo: 100}}}},
+		{"after explicit number", []testNumbered{{Name: "e"}}},
+	}
+	for _, step := range steps {
+		rows := step.rows
+		if err := db.Create(&rows).Error; err != nil {
+			t.Fatalf("%s: Create: %v", step.name, err)
+		}
+	}
+
+	var rows []testNumbered
+	if err := db.Order("NAME").Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, len(rows))
+	for i, row := range rows {
+		got[i] = fmt.Sprintf("%s=%d", row.Name, row.SeqNo)
+	}
+	if want := "[a=1 b=2 c=3 d=100 e=4]"; fmt.Sprint(got) != want {
+		t.Errorf("numbers = %v, want %s", got, want)
+	}
+
+	// Models without Sequenced are not numbered.
+	createUsers(t, db, "alice")
+	var count int64
+	if err := db.Model(&Sequence{}).Where("NAME = ?", "test_users").Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Error("a sequence was created for a model without Sequenced")
+	}
+}
@@ -0,0 +1,84 @@
+package orm
+
+import (
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/log"
+	"gorm.io/gorm"
+)
+
+// slowQueryStartKey is the statement instance key holding the start time of a statement.
+const slowQueryStartKey = "orm:slow_query_start"
+
+// slowQueryLogger is the GORM plugin returned by SlowQueryLogger.
+type slowQueryLogger struct {
+	logger    log.Logger
+	threshold time.Duration
+}
+
+// SlowQueryLogger returns a GORM plugin that times every statement and logs a warning
+// through logger when its execution takes longer than threshold. The warning carries
+// the SQL, the duration, and the number of rows affected.
+//
+// The SQL is logged with its placeholders and without the bound parameters, so values
+// such as credentials or personal data never reach the logs.
+//
+//	err := db.Use(orm.SlowQueryLogger(logger, 200*time.Millisecond))
+func SlowQueryLogger(logger log.Logger, threshold time.Duration) gorm.Plugin {
+	return &slowQueryLogger{logger: logger, threshold: threshold}
+}
+
+// Name returns the name of the plugin.
+func (p *slowQueryLogger) Name() string {
+	return "orm:slow_query_logger"
+}
+
+// Initialize registers the timing callbacks around every kind of statement.
+func (p *slowQueryLogger) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	for _, err := range []error{
+		callback.Create().Before("*").Register("orm:slow_query_start", p.start),
+		callback.Create().After("*").Register("orm:slow_query_end", p.end),
+		callback.Query().Before("*").Register("orm:slow_query_start", p.start),
+		callback.Query().After("*").Register("orm:slow_query_end", p.end),
+		callback.Update().Before("*").Register("orm:slow_query_start", p.start),
+		callback.Update().After("*").Register("orm:slow_query_end", p.end),
+		callback.Delete().Before("*").Register("orm:slow_query_start", p.start),
+		callback.Delete().After("*").Register("orm:slow_query_end", p.end),
+		callback.Row().Before("*").Register("orm:slow_query_start", p.start),
+		callback.Row().After("*").Register("orm:slow_query_end", p.end),
+		callback.Raw().Before("*").Register("orm:slow_query_start", p.start),
+		callback.Raw().After("*").Register("orm:slow_query_end", p.end),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// start records the start time of the statement.
+func (p *slowQueryLogger) start(db *gorm.DB) {
+	db.InstanceSet(slowQueryStartKey, time.Now())
+}
+
+// end logs the statement if it took longer than the threshold.
+func (p *slowQueryLogger) end(db *gorm.DB) {
+	value, ok := db.InstanceGet(slowQueryStartKey)
+	if !ok {
+		return
+	}
+	started, ok := value.(time.Time)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(started)
+	if elapsed <= p.threshold {
+		return
+	}
+	p.logger.Warnw("orm: slow query",
+		"sql", db.Statement.SQL.String(),
+		"duration", elapsed,
+		"rowsAffected", db.Statement.RowsAffected,
+	)
+}
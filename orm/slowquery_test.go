@@ -0,0 +1,88 @@
+package orm
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/log"
+)
+
+// warnLogger is a log.Logger recording the Warnw entries. Its other methods are not used
+// by the package and panic.
+type warnLogger struct {
+	log.Logger
+
+	mu      sync.Mutex
+	entries []map[string]interface{}
+}
+
+func (l *warnLogger) Warnw(msg string, keysValues ...interface{}) {
+	fields := map[string]interface{}{"msg": msg}
+	for i := 0; i+1 < len(keysValues); i += 2 {
+		fields[keysValues[i].(string)] = keysValues[i+1]
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, fields)
+}
+
+func TestSlowQueryLogger(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		wantLogs  bool
+	}{
+		{"slower than threshold", -1, true},
+		{"faster than threshold", time.Hour, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, testUsersTable)
+			logger := &warnLogger{}
+			if err := db.Use(SlowQueryLogger(logger, tt.threshold)); err != nil {
+				t.Fatalf("Use: %v", err)
+			}
+
+			createUsers(t, db, "alice")
+			var users []testUser
+			if err := db.Where("NAME = ?", "alice").Find(&users).Error; err != nil {
+				t.Fatal(err)
+			}
+			if err := db.Model(&testUser{}).Where("NAME = ?", "alice").Update("IS_ACTIVE", false).Error; err != nil {
+				t.Fatal(err)
+			}
+			if err := db.Exec("DELETE FROM test_users WHERE NAME = ?", "alice").Error; err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.wantLogs {
+				if len(logger.entries) != 0 {
+					t.Errorf("logged %d entries, want none", len(logger.entries))
+				}
+				return
+			}
+			wantSQL := []string{"INSERT INTO", "SELECT", "UPDATE", "DELETE FROM"}
+			if len(logger.entries) != len(wantSQL) {
+				t.Fatalf("logged %d entries, want %d", len(logger.entries), len(wantSQL))
+			}
+			for i, fields := range logger.entries {
+				sql, _ := fields["sql"].(string)
+				if !strings.HasPrefix(sql, wantSQL[i]) {
+					t.Errorf("entry %d: sql = %q, want prefix %q", i, sql, wantSQL[i])
+				}
+				// Bound parameters are not logged.
+				if strings.Contains(sql, "alice") {
+					t.Errorf("entry %d: sql %q contains a parameter", i, sql)
+				}
+				if _, ok := fields["duration"].(time.Duration); !ok {
+					t.Errorf("entry %d: duration = %v", i, fields["duration"])
+				}
+				if rows, ok := fields["rowsAffected"].(int64); !ok || rows != 1 {
+					t.Errorf("entry %d: rowsAffected = %v, want 1", i, fields["rowsAffected"])
+				}
+			}
+		})
+	}
+}
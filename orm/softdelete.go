@@ -0,0 +1,51 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// DeletedReason is an embeddable struct adding a DELETE_REASON column that records why a
+// record was soft-deleted by SoftDeleteWithReason.
+//
+//	type Invoice struct {
+//		MModel
+//		DeletedReason
+//		Number string `json:"number" gorm:"column:NUMBER;type:varchar(64);not null"`
+//	}
+type DeletedReason struct {
+	// DeleteReason holds the reason given when the record was soft-deleted.
+	DeleteReason string `json:"deleteReason,omitempty" gorm:"column:DELETE_REASON;type:varchar(255);default:NULL"`
+}
+
+// SoftDeleteWithReason soft-deletes the record of type T identified by id, setting DELETED_AT
+// from the package clock and DELETE_REASON to reason in a single statement. T must have both
+// columns, e.g. by embedding MModel and DeletedReason; otherwise an error wrapping
+// ErrUnknownColumn is returned. It returns ErrNotFound if no live record matched the given ID.
+//
+//	err := orm.SoftDeleteWithReason[Invoice](ctx, db, id, "duplicate of INV-2024-001")
+func SoftDeleteWithReason[T any](ctx context.Context, db *gorm.DB, id, reason string) error {
+	query := db.WithContext(ctx).Model(new(T))
+	if err := query.Statement.Parse(new(T)); err != nil {
+		return err
+	}
+	for _, column := range []string{"DELETED_AT", "DELETE_REASON"} {
+		if field := query.Statement.Schema.LookUpField(column); field == nil {
+			return fmt.Errorf("%w: %q", ErrUnknownColumn, column)
+		}
+	}
+
+	result := query.Where(byID(id)).UpdateColumns(map[string]interface{}{
+		"DELETED_AT":    Now(),
+		"DELETE_REASON": reason,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// testReasonedUser is a testUser recording why it was soft-deleted.
+type testReasonedUser struct {
+	MModel
+	DeletedReason
+	Name string `gorm:"column:NAME"`
+}
+
+func (testReasonedUser) TableName() string { return "test_users" }
+
+func TestSoftDeleteWithReason(t *testing.T) {
+	deletedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		id      func(ids []string) string
+		wantErr error
+	}{
+		{"live record", func(ids []string) string { return ids[0] }, nil},
+		{"already deleted", func(ids []string) string { return ids[1] }, ErrNotFound},
+		{"missing record", func([]string) string { return "missing" }, ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := newTestDB(t, testUsersTable, "ALTER TABLE test_users ADD COLUMN DELETE_REASON varchar(255)")
+			ids := createUsers(t, db, "alice", "bob")
+			if err := db.Delete(&testUser{}, "ID = ?", ids[1]).Error; err != nil {
+				t.Fatal(err)
+			}
+			SetClock(func() time.Time { return deletedAt })
+			defer SetClock(nil)
+
+			err := SoftDeleteWithReason[testReasonedUser](ctx, db, tt.id(ids), "duplicate")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			var user testReasonedUser
+			if err := db.Unscoped().Take(&user, "ID = ?", ids[0]).Error; err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != nil {
+				if user.DeletedAt.Valid || user.DeleteReason != "" {
+					t.Errorf("live user modified: %+v", user)
+				}
+				return
+			}
+			if !user.DeletedAt.Valid || !user.DeletedAt.Time.Equal(deletedAt) || user.DeleteReason != "duplicate" {
+				t.Errorf("deleted user = %v %q, want deleted at %v for duplicate", user.DeletedAt, user.DeleteReason, deletedAt)
+			}
+		})
+	}
+}
+
+func TestSoftDeleteWithReasonUnknownColumn(t *testing.T) {
+	db := newTestDB(t, testUsersTable)
+	id := createUsers(t, db, "alice")[0]
+	err := SoftDeleteWithReason[testUser](context.Background(), db, id, "duplicate")
+	if !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("err = %v, want ErrUnknownColumn", err)
+	}
+}
+
+func TestSoftDeleteWithReasonPostgres(t *testing.T) {
+	db, statements := newPostgresDryRunDB(t)
+	_ = SoftDeleteWithReason[testReasonedUser](context.Background(), db, "u1", "duplicate")
+
+	want := `UPDATE "test_users" SET "DELETED_AT"=$1,"DELETE_REASON"=$2 WHERE "test_users"."ID" = $3 AND "test_users"."DELETED_AT" IS NULL`
+	if got := statements(); len(got) != 1 || got[0] != want {
+		t.Errorf("SQL = %q, want [%q]", got, want)
+	}
+}
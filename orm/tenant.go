@@ -0,0 +1,162 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// tenantKey is the context key under which WithTenant stores the tenant ID.
+type tenantKey struct{}
+
+// crossTenantSetting is the statement setting marking a statement as cross-tenant.
+const crossTenantSetting = "orm:cross_tenant"
+
+// TenantModel is an embeddable model adding a tenant column to a table.
+// Models embedding it are scoped automatically by the plugin returned by TenantScoping.
+//
+//	type Invoice struct {
+//		orm.MModel
+//		orm.TenantModel
+//		Amount int64 `json:"amount" gorm:"column:AMOUNT;not null"`
+//	}
+type TenantModel struct {
+	// TenantID identifies the tenant owning the record.
+	// It is indexed since every query on the table filters on it.
+	TenantID string `json:"tenantId" gorm:"column:TENANT_ID;type:varchar(36);not null;index:IDX_TENANT_ID"`
+}
+
+// WithTenant returns a copy of ctx carrying the tenant ID used by the tenant plugin.
+//
+//	db.WithContext(orm.WithTenant(ctx, tenantID)).Find(&invoices)
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant ID stored in ctx by WithTenant.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(tenantKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
+
+// CrossTenant returns a GORM scope disabling tenant scoping for the statement.
+// It is an escape hatch for administrative queries spanning every tenant.
+//
+//	db.Scopes(orm.CrossTenant()).Find(&invoices)
+func CrossTenant() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Set(crossTenantSetting, true)
+	}
+}
+
+// tenantScoping is the GORM plugin returned by TenantScoping.
+type tenantScoping struct{}
+
+// TenantScoping returns a GORM plugin enforcing tenant isolation for models embedding
+// TenantModel, or declaring a TenantID field of their own mapped to any column. The tenant
+// is read from the statement context, as set by WithTenant: queries, updates, and deletes
+// get a condition on the tenant column, and created records without a TenantID get the
+// tenant of the context. Creating a record whose TenantID is set to another tenant fails
+// with ErrTenantMismatch.
+//
+// Statements on tenant-scoped models without a tenant in their context fail with
+// ErrMissingTenant, unless the CrossTenant scope is used. Models without a TenantID
+// field are not affected. Raw and Exec statements are never scoped: their SQL must filter
+// on the tenant itself.
+//
+//	err := db.Use(orm.TenantScoping())
+func TenantScoping() gorm.Plugin {
+	return tenantScoping{}
+}
+
+// Name returns the name of the plugin.
+func (tenantScoping) Name() string {
+	return "orm:tenant_scoping"
+}
+
+// Initialize registers the tenant callbacks.
+func (p tenantScoping) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	for _, err := range []error{
+		callback.Create().Before("gorm:create").Register("orm:tenant_create", p.create),
+		callback.Query().Before("gorm:query").Register("orm:tenant_query", p.scope),
+		callback.Update().Before("gorm:update").Register("orm:tenant_update", p.scope),
+		callback.Delete().Before("gorm:delete").Register("orm:tenant_delete", p.scope),
+		callback.Row().Before("gorm:row").Register("orm:tenant_row", p.scope),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tenant returns the TenantID field of the model and the tenant ID to apply to the
+// statement, and whether the statement is tenant-scoped at all. It adds ErrMissingTenant
+// to db if the tenant is required but missing.
+func (tenantScoping) tenant(db *gorm.DB) (*schema.Field, string, bool) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil, "", false
+	}
+	field := db.Statement.Schema.LookUpField("TenantID")
+	if field == nil {
+		return nil, "", false
+	}
+	if crossTenant, ok := db.Get(crossTenantSetting); ok && crossTenant == true {
+		return nil, "", false
+	}
+	tenantID, ok := TenantFromContext(db.Statement.Context)
+	if !ok {
+		_ = db.AddError(ErrMissingTenant)
+		return nil, "", false
+	}
+	return field, tenantID, true
+}
+
+// scope adds the tenant condition to the statement.
+func (p tenantScoping) scope(db *gorm.DB) {
+	field, tenantID, ok := p.tenant(db)
+	if !ok {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenantID},
+	}})
+}
+
+// create sets the tenant of every record being created, rejecting records set to another
+// tenant.
+func (p tenantScoping) create(db *gorm.DB) {
+	field, tenantID, ok := p.tenant(db)
+	if !ok {
+		return
+	}
+	set := func(record reflect.Value) error {
+		current, isZero := field.ValueOf(db.Statement.Context, record)
+		if !isZero {
+			if current != tenantID {
+				return fmt.Errorf("%w: record of tenant %v created in tenant %q", ErrTenantMismatch, current, tenantID)
+			}
+			return nil
+		}
+		return field.Set(db.Statement.Context, record, tenantID)
+	}
+	value := db.Statement.ReflectValue
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := set(reflect.Indirect(value.Index(i))); err != nil {
+				_ = db.AddError(err)
+				return
+			}
+		}
+	case reflect.Struct:
+		if err := set(value); err != nil {
+			_ = db.AddError(err)
+		}
+	}
+}
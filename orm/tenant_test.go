@@ -0,0 +1,228 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// testInvoiceRow is a tenant-scoped model.
+type testInvoiceRow struct {
+	MModel
+	TenantModel
+	Name string `gorm:"column:NAME"`
+}
+
+func (testInvoiceRow) TableName() string { return "test_invoice_rows" }
+
+const testInvoiceRowsTable = `CREATE TABLE test_invoice_rows (
+	ID varchar(36) PRIMARY KEY,
+	CREATED_AT datetime,
+	UPDATED_AT datetime,
+	DELETED_AT datetime,
+	TENANT_ID varchar(36) NOT NULL,
+	NAME varchar(255)
+)`
+
+// newTenantDB opens a database with tenant scoping holding the invoices a1, a2 and a3 of
+// tenant A and b1 of tenant B.
+func newTenantDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := newTestDB(t, testUsersTable, testInvoiceRowsTable)
+	if err := db.Use(TenantScoping()); err != nil {
+		t.Fatalf("Use: %v", err)
+	}
+	a := db.WithContext(WithTenant(context.Background(), "A"))
+	if err := a.Create(&testInvoiceRow{Name: "a1"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Create(&[]testInvoiceRow{{Name: "a2"}, {Name: "a3"}}).Error; err != nil {
+		t.Fatal(err)
+	}
+	// A record may carry the tenant of its context.
+	b := db.WithContext(WithTenant(context.Background(), "B"))
+	if err := b.Create(&testInvoiceRow{Name: "b1", TenantModel: TenantModel{TenantID: "B"}}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// invoiceNames returns the sorted names of the invoices visible through db.
+func invoiceNames(db *gorm.DB) (string, error) {
+	var invoices []testInvoiceRow
+	if err := db.Find(&invoices).Error; err != nil {
+		return "", err
+	}
+	names := make([]string, len(invoices))
+	for i, invoice := range invoices {
+		names[i] = invoice.TenantID + "/" + invoice.Name
+	}
+	sort.Strings(names)
+	return fmt.Sprint(names), nil
+}
+
+func TestTenantScopingQuery(t *testing.T) {
+	db := newTenantDB(t)
+	tests := []struct {
+		name    string
+		db      *gorm.DB
+		want    string
+		wantErr error
+	}{
+		{"tenant A", db.WithContext(WithTenant(context.Background(), "A")), "[A/a1 A/a2 A/a3]", nil},
+		{"tenant B", db.WithContext(WithTenant(context.Background(), "B")), "[B/b1]", nil},
+		{"unknown tenant", db.WithContext(WithTenant(context.Background(), "C")), "[]", nil},
+		{"missing tenant", db, "", ErrMissingTenant},
+		{"empty tenant", db.WithContext(WithTenant(context.Background(), "")), "", ErrMissingTenant},
+		{"cross-tenant", db.Scopes(CrossTenant()), "[A/a1 A/a2 A/a3 B/b1]", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := invoiceNames(tt.db)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("invoices = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTenantScopingWrites(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(b *gorm.DB) error
+		want string
+	}{
+		{"count", func(b *gorm.DB) error {
+			var n int64
+			if err := b.Model(&testInvoiceRow{}).Count(&n).Error; err != nil {
+				return err
+			}
+			if n != 1 {
+				return fmt.Errorf("count = %d, want 1", n)
+			}
+			return nil
+		}, "[A/a1 A/a2 A/a3 B/b1]"},
+		{"update", func(b *gorm.DB) error {
+			return b.Model(&testInvoiceRow{}).Where("NAME LIKE ?", "%1").Update("NAME", "renamed").Error
+		}, "[A/a1 A/a2 A/a3 B/renamed]"},
+		{"delete", func(b *gorm.DB) error {
+			return b.Where("1 = 1").Delete(&testInvoiceRow{}).Error
+		}, "[A/a1 A/a2 A/a3]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTenantDB(t)
+			if err := tt.run(db.WithContext(WithTenant(context.Background(), "B"))); err != nil {
+				t.Fatal(err)
+			}
+			got, err := invoiceNames(db.Scopes(CrossTenant()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("invoices = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTenantScopingMissingTenantWrites(t *testing.T) {
+	db := newTenantDB(t)
+	if err := db.Create(&testInvoiceRow{Name: "x"}).Error; !errors.Is(err, ErrMissingTenant) {
+		t.Errorf("Create: err = %v, want ErrMissingTenant", err)
+	}
+	if err := db.Where("1 = 1").Delete(&testInvoiceRow{}).Error; !errors.Is(err, ErrMissingTenant) {
+		t.Errorf("Delete: err = %v, want ErrMissingTenant", err)
+	}
+	if got, _ := invoiceNames(db.Scopes(CrossTenant())); got != "[A/a1 A/a2 A/a3 B/b1]" {
+		t.Errorf("invoices = %s, want them unchanged", got)
+	}
+}
+
+func TestTenantScopingMismatchingTenant(t *testing.T) {
+	db := newTenantDB(t)
+	b := db.WithContext(WithTenant(context.Background(), "B"))
+	tests := []struct {
+		name   string
+		record interface{}
+	}{
+		{"record", &testInvoiceRow{Name: "x", TenantModel: TenantModel{TenantID: "A"}}},
+		{"batch", &[]testInvoiceRow{{Name: "x"}, {Name: "y", TenantModel: TenantModel{TenantID: "A"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := b.Create(tt.record).Error; !errors.Is(err, ErrTenantMismatch) {
+				t.Errorf("err = %v, want ErrTenantMismatch", err)
+			}
+		})
+	}
+	if got, _ := invoiceNames(db.Scopes(CrossTenant())); got != "[A/a1 A/a2 A/a3 B/b1]" {
+		t.Errorf("invoices = %s, want them unchanged", got)
+	}
+}
+
+// testOrgRow is a tenant-scoped model mapping its TenantID to another column.
+type testOrgRow struct {
+	ID       string `gorm:"column:ID;primaryKey"`
+	TenantID string `gorm:"column:ORG_ID"`
+}
+
+func (testOrgRow) TableName() string { return "test_org_rows" }
+
+func TestTenantScopingCustomColumn(t *testing.T) {
+	db := newTestDB(t, "CREATE TABLE test_org_rows (ID varchar(36) PRIMARY KEY, ORG_ID varchar(36) NOT NULL)")
+	if err := db.Use(TenantScoping()); err != nil {
+		t.Fatalf("Use: %v", err)
+	}
+	for _, row := range []struct{ id, tenant string }{{"a1", "A"}, {"b1", "B"}} {
+		if err := db.WithContext(WithTenant(context.Background(), row.tenant)).Create(&testOrgRow{ID: row.id}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var rows []testOrgRow
+	if err := db.WithContext(WithTenant(context.Background(), "B")).Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].ID != "b1" || rows[0].TenantID != "B" {
+		t.Errorf("rows = %+v, want b1 of tenant B", rows)
+	}
+}
+
+func TestTenantScopingOtherModels(t *testing.T) {
+	db := newTenantDB(t)
+	// Models without a TenantID field need no tenant.
+	createUsers(t, db, "alice")
+	var users []testUser
+	if err := db.Find(&users).Error; err != nil || len(users) != 1 {
+		t.Errorf("Find = %d users, %v, want 1, nil", len(users), err)
+	}
+}
+
+func TestTenantFromContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		want   string
+		wantOK bool
+	}{
+		{"with tenant", WithTenant(context.Background(), "A"), "A", true},
+		{"without tenant", context.Background(), "", false},
+		{"empty tenant", WithTenant(context.Background(), ""), "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := TenantFromContext(tt.ctx)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("TenantFromContext = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
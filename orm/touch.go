@@ -0,0 +1,28 @@
+package orm
+
+import "gorm.io/gorm"
+
+// TouchParent sets the UPDATED_AT column of the row of type T identified by id to the
+// package clock's current time, without running hooks or modifying any other column.
+// It returns ErrNotFound if no row matched the given ID.
+//
+// GORM does not bump the parent's UpdatedAt when its associations are mutated, which makes
+// the parent's timestamp unreliable as a version for caching. Call TouchParent right after
+// such mutations, ideally in the same transaction:
+//
+//	err := db.Transaction(func(tx *gorm.DB) error {
+//		if err := tx.Model(&order).Association("Items").Replace(items); err != nil {
+//			return err
+//		}
+//		return orm.TouchParent[Order](tx, order.ID)
+//	})
+func TouchParent[T any](db *gorm.DB, id string) error {
+	result := db.Model(new(T)).Where(byID(id)).UpdateColumn("UPDATED_AT", Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
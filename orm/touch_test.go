@@ -0,0 +1,60 @@
+package orm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTouchParent(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	touched := created.Add(time.Hour)
+	SetClock(func() time.Time { return created })
+	defer SetClock(nil)
+
+	db := newTestDB(t, testUsersTable)
+	ids := createUsers(t, db, "alice", "bob", "carol")
+	if err := db.Delete(&testUser{}, "ID = ?", ids[2]).Error; err != nil {
+		t.Fatal(err)
+	}
+	SetClock(func() time.Time { return touched })
+
+	tests := []struct {
+		name    string
+		id      string
+		wantErr error
+	}{
+		{"existing parent", ids[0], nil},
+		{"missing parent", "missing", ErrNotFound},
+		{"soft-deleted parent", ids[2], ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := TouchParent[testUser](db, tt.id); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Only the touched parent has a new UPDATED_AT.
+	want := map[string]time.Time{ids[0]: touched, ids[1]: created, ids[2]: created}
+	var users []testUser
+	if err := db.Unscoped().Find(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, user := range users {
+		if !user.UpdatedAt.Equal(want[user.ID]) {
+			t.Errorf("%s: UpdatedAt = %v, want %v", user.Name, user.UpdatedAt, want[user.ID])
+		}
+	}
+}
+
+func TestTouchParentPostgres(t *testing.T) {
+	db, statements := newPostgresDryRunDB(t)
+	_ = TouchParent[testUser](db, "u1")
+
+	want := `UPDATE "test_users" SET "UPDATED_AT"=$1 WHERE "test_users"."ID" = $2 AND "test_users"."DELETED_AT" IS NULL`
+	if got := statements(); len(got) != 1 || got[0] != want {
+		t.Errorf("SQL = %q, want [%q]", got, want)
+	}
+}
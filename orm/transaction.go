@@ -0,0 +1,81 @@
+package orm
+
+import (
+	"context"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// afterCommitKey is the context key holding the after-commit callbacks of a WithTransaction call.
+type afterCommitKey struct{}
+
+// afterCommit collects the callbacks registered during a transaction.
+type afterCommit struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+// add appends fns to the callbacks.
+func (a *afterCommit) add(fns ...func()) {
+	a.mu.Lock()
+	a.fns = append(a.fns, fns...)
+	a.mu.Unlock()
+}
+
+// WithTransaction runs fn in a transaction and, once the transaction has committed, runs the
+// callbacks registered with RegisterAfterCommit during fn, in registration order. If fn
+// returns an error or panics, the transaction is rolled back and the callbacks are discarded.
+//
+// fn receives a context carrying the transaction's callbacks, which is also the context of
+// tx; it must be used for nested operations and for RegisterAfterCommit. WithTransaction calls
+// can be nested by passing tx: the nested transaction uses a savepoint, and its callbacks are
+// deferred until the outermost transaction commits, or discarded if the savepoint is rolled back.
+//
+//	err := orm.WithTransaction(ctx, db, func(ctx context.Context, tx *gorm.DB) error {
+//		if err := tx.Create(&order).Error; err != nil {
+//			return err
+//		}
+//		orm.RegisterAfterCommit(ctx, func() { publishOrderCreated(order) })
+//		return nil
+//	})
+func WithTransaction(ctx context.Context, db *gorm.DB, fn func(ctx context.Context, tx *gorm.DB) error) error {
+	parent, _ := ctx.Value(afterCommitKey{}).(*afterCommit)
+	callbacks := &afterCommit{}
+	ctx = context.WithValue(ctx, afterCommitKey{}, callbacks)
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(ctx, tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	callbacks.mu.Lock()
+	fns := callbacks.fns
+	callbacks.mu.Unlock()
+	if parent != nil {
+		parent.add(fns...)
+		return nil
+	}
+	for _, fn := range fns {
+		fn()
+	}
+	return nil
+}
+
+// RegisterAfterCommit registers fn to run after the transaction of the WithTransaction call
+// owning ctx commits, and to be discarded if it rolls back. This is the place for side effects
+// such as publishing events or busting caches, which must not happen for rolled-back changes.
+//
+// If ctx does not belong to a WithTransaction call, there is no transaction to wait for and fn
+// runs immediately. It reports whether fn was deferred.
+func RegisterAfterCommit(ctx context.Context, fn func()) bool {
+	callbacks, ok := ctx.Value(afterCommitKey{}).(*afterCommit)
+	if !ok {
+		fn()
+		return false
+	}
+	callbacks.add(fn)
+	return true
+}
@@ -0,0 +1,139 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestWithTransaction(t *testing.T) {
+	errRollback := errors.New("rollback")
+	tests := []struct {
+		name      string
+		fn        func(ctx context.Context, tx *gorm.DB, calls *[]string) error
+		wantErr   error
+		wantCalls []string
+		wantUsers int64
+	}{
+		{
+			name: "committed",
+			fn: func(ctx context.Context, tx *gorm.DB, calls *[]string) error {
+				RegisterAfterCommit(ctx, func() { *calls = append(*calls, "first") })
+				RegisterAfterCommit(ctx, func() { *calls = append(*calls, "second") })
+				return tx.Create(&testUser{Name: "alice"}).Error
+			},
+			wantCalls: []string{"first", "second"},
+			wantUsers: 1,
+		},
+		{
+			name: "rolled back",
+			fn: func(ctx context.Context, tx *gorm.DB, calls *[]string) error {
+				RegisterAfterCommit(ctx, func() { *calls = append(*calls, "first") })
+				if err := tx.Create(&testUser{Name: "alice"}).Error; err != nil {
+					return err
+				}
+				return errRollback
+			},
+			wantErr: errRollback,
+		},
+		{
+			name: "nested committed",
+			fn: func(ctx context.Context, tx *gorm.DB, calls *[]string) error {
+				RegisterAfterCommit(ctx, func() { *calls = append(*calls, "outer") })
+				err := WithTransaction(ctx, tx, func(ctx context.Context, tx *gorm.DB) error {
+					RegisterAfterCommit(ctx, func() { *calls = append(*calls, "inner") })
+					return tx.Create(&testUser{Name: "alice"}).Error
+				})
+				if len(*calls) != 0 {
+					t.Errorf("callbacks ran before the outermost commit: %v", *calls)
+				}
+				return err
+			},
+			wantCalls: []string{"outer", "inner"},
+			wantUsers: 1,
+		},
+		{
+			name: "nested rolled back",
+			fn: func(ctx context.Context, tx *gorm.DB, calls *[]string) error {
+				RegisterAfterCommit(ctx, func() { *calls = append(*calls, "outer") })
+				err := WithTransaction(ctx, tx, func(ctx context.Context, tx *gorm.DB) error {
+					RegisterAfterCommit(ctx, func() { *calls = append(*calls, "inner") })
+					if err := tx.Create(&testUser{Name: "bob"}).Error; err != nil {
+						return err
+					}
+					return errRollback
+				})
+				if !errors.Is(err, errRollback) {
+					t.Errorf("nested err = %v, want %v", err, errRollback)
+				}
+				return tx.Create(&testUser{Name: "alice"}).Error
+			},
+			wantCalls: []string{"outer"},
+			wantUsers: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, testUsersTable)
+			var calls []string
+			err := WithTransaction(context.Background(), db, func(ctx context.Context, tx *gorm.DB) error {
+				return tt.fn(ctx, tx, &calls)
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if strings.Join(calls, ",") != strings.Join(tt.wantCalls, ",") {
+				t.Errorf("callbacks = %v, want %v", calls, tt.wantCalls)
+			}
+			var users int64
+			if err := db.Model(&testUser{}).Count(&users).Error; err != nil {
+				t.Fatal(err)
+			}
+			if users != tt.wantUsers {
+				t.Errorf("users = %d, want %d", users, tt.wantUsers)
+			}
+		})
+	}
+}
+
+func TestWithTransactionPanic(t *testing.T) {
+	db := newTestDB(t, testUsersTable)
+	ran := false
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("panic not propagated")
+			}
+		}()
+		_ = WithTransaction(context.Background(), db, func(ctx context.Context, tx *gorm.DB) error {
+			RegisterAfterCommit(ctx, func() { ran = true })
+			if err := tx.Create(&testUser{Name: "alice"}).Error; err != nil {
+				return err
+			}
+			panic("boom")
+		})
+	}()
+	if ran {
+		t.Error("callback ran after a panic")
+	}
+	var users int64
+	if err := db.Model(&testUser{}).Count(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	if users != 0 {
+		t.Errorf("users = %d, want 0", users)
+	}
+}
+
+func TestRegisterAfterCommitOutsideTransaction(t *testing.T) {
+	ran := false
+	if deferred := RegisterAfterCommit(context.Background(), func() { ran = true }); deferred {
+		t.Error("RegisterAfterCommit deferred a callback without a transaction")
+	}
+	if !ran {
+		t.Error("callback did not run immediately")
+	}
+}
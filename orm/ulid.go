@@ -0,0 +1,141 @@
+package orm
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// crockfordAlphabet is the Crockford base32 alphabet used to encode ULIDs.
+// It excludes the letters I, L, O, and U to avoid ambiguity.
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ulidGenerator produces monotonic ULIDs. IDs generated within the same millisecond
+// reuse the previous entropy incremented by one, so they remain strictly increasing.
+type ulidGenerator struct {
+	mu      sync.Mutex
+	lastMs  uint64
+	entropy [10]byte
+}
+
+// defaultULIDGenerator is the process-wide generator used by NewULID.
+var defaultULIDGenerator = &ulidGenerator{}
+
+// NewULID returns a new 26-character ULID string. The first 10 characters encode the
+// current Unix time in milliseconds and the remaining 16 characters encode 80 bits of
+// randomness. IDs generated by the same process are lexicographically increasing,
+// even when several are created within the same millisecond.
+func NewULID() string {
+	return defaultULIDGenerator.next()
+}
+
+func (g *ulidGenerator) next() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(time.Now().UnixMilli())
+	if ms <= g.lastMs {
+		// Same (or an earlier, due to clock skew) millisecond: keep the previous timestamp
+		// and increment the entropy so that ordering is preserved.
+		ms = g.lastMs
+		if !incrementEntropy(&g.entropy) {
+			// The entropy overflowed; move on to the next millisecond with fresh entropy.
+			ms++
+			g.randomEntropy()
+		}
+	} else {
+		g.randomEntropy()
+	}
+	g.lastMs = ms
+
+	return encodeULID(ms, g.entropy)
+}
+
+func (g *ulidGenerator) randomEntropy() {
+	if _, err := rand.Read(g.entropy[:]); err != nil {
+		panic("orm: failed to read random entropy: " + err.Error())
+	}
+}
+
+// incrementEntropy adds one to the 80-bit big-endian entropy value.
+// It returns false if the value overflowed.
+func incrementEntropy(entropy *[10]byte) bool {
+	for i := len(entropy) - 1; i >= 0; i-- {
+		entropy[i]++
+		if entropy[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeULID encodes a 48-bit millisecond timestamp followed by 80 bits of entropy
+// as a 26-character Crockford base32 string.
+func encodeULID(ms uint64, entropy [10]byte) string {
+	hi := ms<<16 | uint64(binary.BigEndian.Uint16(entropy[0:2]))
+	lo := binary.BigEndian.Uint64(entropy[2:10])
+
+	var out [26]byte
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = crockfordAlphabet[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// ULIDModel is a base model that uses a ULID as its primary key.
+// ULIDs are time-sortable, so rows inserted later have greater IDs, which keeps
+// B-tree indexes append-friendly on high-write tables. Apart from the ID, it
+// provides the same timestamps and soft deletion support as `MModel`.
+//
+//	Event represents a high-write application model that embeds ULIDModel.
+//	type Event struct {
+//		ULIDModel
+//		Name string `json:"name" gorm:"column:NAME;type:varchar(255);not null"`
+//	}
+type ULIDModel struct {
+	// ID is the primary key for the record, represented as a 26-character ULID string.
+	// It is generated by the BeforeCreate hook and sorts lexicographically by creation time.
+	ID string `json:"id" gorm:"column:ID;primaryKey;type:char(26);not null"`
+
+	// CreatedAt stores the timestamp indicating when the record was created.
+	// It is indexed to optimize queries based on record creation time.
+	CreatedAt time.Time `json:"createdAt" gorm:"column:CREATED_AT;type:datetime(6);default:CURRENT_TIMESTAMP(6);index:IDX_CREATED_AT;<-:create"`
+
+	// UpdatedAt stores the timestamp of the most recent modification to the record.
+	// It is indexed to allow efficient queries for recently updated records.
+	UpdatedAt time.Time `json:"updatedAt" gorm:"column:UPDATED_AT;type:datetime(6);default:CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6);index:IDX_UPDATED_AT;autoUpdateTime:false"`
+
+	// DeletedAt is used for soft deletion of records.
+	// It is indexed to optimize filtering of active or soft-deleted records.
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"column:DELETED_AT;type:datetime(6);default:NULL;index:IDX_DELETED_AT"`
+}
+
+// BeforeCreate is a GORM hook that runs before a new record is inserted into the database.
+// This function generates a new monotonic ULID for the ID field of the ULIDModel struct, unless
+// the record is inserted by ImportCreate with an ID, and sets the CreatedAt and UpdatedAt
+// fields from the package clock if they are not already set.
+func (ulidModel *ULIDModel) BeforeCreate(tx *gorm.DB) error {
+	if ulidModel.ID == "" || !isImport(tx) {
+		ulidModel.ID = NewULID()
+	}
+	now := Now()
+	if ulidModel.CreatedAt.IsZero() {
+		ulidModel.CreatedAt = now
+	}
+	if ulidModel.UpdatedAt.IsZero() {
+		ulidModel.UpdatedAt = now
+	}
+	return nil
+}
+
+// BeforeUpdate is a GORM hook that runs before a record is updated in the database.
+// This function sets the UPDATED_AT column from the package clock.
+func (ulidModel *ULIDModel) BeforeUpdate(tx *gorm.DB) error {
+	tx.Statement.SetColumn("UpdatedAt", Now())
+	return nil
+}
@@ -0,0 +1,118 @@
+package orm
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEncodeULID(t *testing.T) {
+	tests := []struct {
+		name    string
+		ms      uint64
+		entropy [10]byte
+		want    string
+	}{
+		{"zero", 0, [10]byte{}, "00000000000000000000000000"},
+		{"timestamp", 1469918176385, [10]byte{}, "01ARYZ6S410000000000000000"},
+		{"entropy", 0, [10]byte{9: 1}, "00000000000000000000000001"},
+		{"max", 1<<48 - 1, [10]byte{255, 255, 255, 255, 255, 255, 255, 255, 255, 255}, "7ZZZZZZZZZZZZZZZZZZZZZZZZZ"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := encodeULID(tt.ms, tt.entropy); got != tt.want {
+				t.Errorf("encodeULID = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIncrementEntropy(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     [10]byte
+		want   [10]byte
+		wantOK bool
+	}{
+		{"simple", [10]byte{9: 1}, [10]byte{9: 2}, true},
+		{"carry", [10]byte{8: 0, 9: 255}, [10]byte{8: 1, 9: 0}, true},
+		{"overflow", [10]byte{255, 255, 255, 255, 255, 255, 255, 255, 255, 255}, [10]byte{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entropy := tt.in
+			if ok := incrementEntropy(&entropy); ok != tt.wantOK || entropy != tt.want {
+				t.Errorf("incrementEntropy = %v, %v, want %v, %v", entropy, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestNewULIDMonotonic(t *testing.T) {
+	ids := make([]string, 10000)
+	for i := range ids {
+		ids[i] = NewULID()
+	}
+	for i, id := range ids {
+		if len(id) != 26 {
+			t.Fatalf("ID %q has length %d, want 26", id, len(id))
+		}
+		if strings.Trim(id, crockfordAlphabet) != "" {
+			t.Fatalf("ID %q has characters outside the Crockford alphabet", id)
+		}
+		if i > 0 && id <= ids[i-1] {
+			t.Fatalf("ID %q is not greater than the previous ID %q", id, ids[i-1])
+		}
+	}
+}
+
+func TestULIDGeneratorClockSkew(t *testing.T) {
+	future := uint64(time.Now().Add(time.Hour).UnixMilli())
+	g := &ulidGenerator{lastMs: future, entropy: [10]byte{9: 7}}
+
+	if got, want := g.next(), encodeULID(future, [10]byte{9: 8}); got != want {
+		t.Errorf("next = %q, want %q", got, want)
+	}
+}
+
+// testEvent is a model embedding ULIDModel.
+type testEvent struct {
+	ULIDModel
+	Name string `gorm:"column:NAME"`
+}
+
+const testEventsTable = `CREATE TABLE test_events (
+	ID char(26) PRIMARY KEY,
+	CREATED_AT datetime,
+	UPDATED_AT datetime,
+	DELETED_AT datetime,
+	NAME varchar(255)
+)`
+
+func TestULIDModel(t *testing.T) {
+	db := newTestDB(t, testEventsTable)
+	var ids []string
+	for _, name := range []string{"a", "b", "c"} {
+		event := testEvent{Name: name}
+		event.ID = "ignored"
+		if err := db.Create(&event).Error; err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if len(event.ID) != 26 || event.CreatedAt.IsZero() || event.UpdatedAt.IsZero() {
+			t.Fatalf("event not initialized: %+v", event.ULIDModel)
+		}
+		ids = append(ids, event.ID)
+	}
+	if !sort.StringsAreSorted(ids) {
+		t.Errorf("IDs not in creation order: %v", ids)
+	}
+
+	var names []string
+	if err := db.Model(&testEvent{}).Order("ID").Pluck("NAME", &names).Error; err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(names, "") != "abc" {
+		t.Errorf("names ordered by ID = %v, want [a b c]", names)
+	}
+}
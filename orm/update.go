@@ -0,0 +1,32 @@
+package orm
+
+import "gorm.io/gorm"
+
+// UpdateColumns updates exactly the provided columns on the row of type T identified by id.
+// Unlike `Updates` with a struct, zero values such as `false`, `0`, and `""` are written
+// as-is, because the changes are applied from a map keyed by column name.
+// The UPDATED_AT column is bumped to the package clock's current time unless it is explicitly provided.
+// It returns ErrNotFound if no row matched the given ID.
+//
+//	err := orm.UpdateColumns[User](db, id, map[string]interface{}{
+//		"IS_ACTIVE": false,
+//		"NAME":      "",
+//	})
+func UpdateColumns[T any](db *gorm.DB, id string, columns map[string]interface{}) error {
+	values := make(map[string]interface{}, len(columns)+1)
+	for column, value := range columns {
+		values[column] = value
+	}
+	if _, ok := values["UPDATED_AT"]; !ok {
+		values["UPDATED_AT"] = Now()
+	}
+
+	result := db.Model(new(T)).Where(byID(id)).UpdateColumns(values)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
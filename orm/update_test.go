@@ -0,0 +1,102 @@
+package orm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestUpdateColumns(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	updated := created.Add(time.Hour)
+	explicit := created.Add(2 * time.Hour)
+
+	tests := []struct {
+		name        string
+		columns     map[string]interface{}
+		deleted     bool
+		wantErr     error
+		wantName    string
+		wantActive  bool
+		wantUpdated time.Time
+	}{
+		{
+			name:        "zero values",
+			columns:     map[string]interface{}{"IS_ACTIVE": false, "NAME": ""},
+			wantName:    "",
+			wantActive:  false,
+			wantUpdated: updated,
+		},
+		{
+			name:        "only given columns",
+			columns:     map[string]interface{}{"NAME": "bob"},
+			wantName:    "bob",
+			wantActive:  true,
+			wantUpdated: updated,
+		},
+		{
+			name:        "explicit UPDATED_AT",
+			columns:     map[string]interface{}{"NAME": "bob", "UPDATED_AT": explicit},
+			wantName:    "bob",
+			wantActive:  true,
+			wantUpdated: explicit,
+		},
+		{
+			name:    "soft-deleted row",
+			columns: map[string]interface{}{"NAME": "bob"},
+			deleted: true,
+			wantErr: ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetClock(func() time.Time { return created })
+			defer SetClock(nil)
+			db := newTestDB(t, testUsersTable)
+			id := createUsers(t, db, "alice")[0]
+			if tt.deleted {
+				if err := db.Delete(&testUser{}, "ID = ?", id).Error; err != nil {
+					t.Fatalf("delete: %v", err)
+				}
+			}
+
+			SetClock(func() time.Time { return updated })
+			err := UpdateColumns[testUser](db, id, tt.columns)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateColumns: err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			var user testUser
+			if err := db.First(&user, "ID = ?", id).Error; err != nil {
+				t.Fatalf("find: %v", err)
+			}
+			if user.Name != tt.wantName || user.IsActive != tt.wantActive {
+				t.Errorf("user = {%q, %v}, want {%q, %v}", user.Name, user.IsActive, tt.wantName, tt.wantActive)
+			}
+			if !user.UpdatedAt.Equal(tt.wantUpdated) {
+				t.Errorf("UpdatedAt = %v, want %v", user.UpdatedAt, tt.wantUpdated)
+			}
+		})
+	}
+}
+
+func TestUpdateColumnsMissingRow(t *testing.T) {
+	db := newTestDB(t, testUsersTable)
+	err := UpdateColumns[testUser](db, "missing", map[string]interface{}{"NAME": "bob"})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestUpdateColumnsPostgres(t *testing.T) {
+	db, statements := newPostgresDryRunDB(t)
+	_ = UpdateColumns[testUser](db, "u1", map[string]interface{}{"NAME": "bob"})
+
+	want := `UPDATE "test_users" SET "NAME"=$1,"UPDATED_AT"=$2 WHERE "test_users"."ID" = $3 AND "test_users"."DELETED_AT" IS NULL`
+	if got := statements(); len(got) != 1 || got[0] != want {
+		t.Errorf("SQL = %q, want [%q]", got, want)
+	}
+}
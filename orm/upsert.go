@@ -0,0 +1,143 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// UpsertResult reports how many rows a BulkUpsert inserted and updated.
+type UpsertResult struct {
+	// Inserted is the number of rows whose conflict key did not exist yet.
+	Inserted int64 `json:"inserted"`
+	// Updated is the number of existing rows overwritten by the upsert.
+	Updated int64 `json:"updated"`
+}
+
+// BulkUpsert inserts rows of type T, updating the existing row instead whenever a row
+// conflicts on conflictCols, which must be backed by a primary key or unique index. Column
+// names may be struct field names or column names; unknown names return an error wrapping
+// ErrUnknownColumn. Rows are written in batches of DefaultChunkSize.
+//
+// A conflicting row has its columns overwritten, except its primary key, its creation
+// timestamp, and its DELETED_AT column: UPDATED_AT is set from the package clock, and a
+// soft-deleted row is updated but stays soft-deleted rather than being resurrected.
+//
+// The counts are computed portably: within the same transaction as the upsert, the rows
+// matching the conflict keys are counted first, including soft-deleted ones since they
+// conflict too. Rows sharing a conflict key are counted once. Concurrent writers inserting
+// the same keys between the count and the upsert can skew the counts unless the transaction
+// runs at a serializable isolation level.
+//
+//	result, err := orm.BulkUpsert(db, []string{"EMAIL"}, users)
+//	log.Infow("users synced", "inserted", result.Inserted, "updated", result.Updated)
+func BulkUpsert[T any](db *gorm.DB, conflictCols []string, rows []T) (UpsertResult, error) {
+	if len(rows) == 0 {
+		return UpsertResult{}, nil
+	}
+	query := db.Model(new(T))
+	if err := query.Statement.Parse(new(T)); err != nil {
+		return UpsertResult{}, err
+	}
+	columns := make([]clause.Column, 0, len(conflictCols))
+	fields := make([]*schema.Field, 0, len(conflictCols))
+	names := make([]string, 0, len(conflictCols))
+	for _, col := range conflictCols {
+		field := query.Statement.Schema.LookUpField(col)
+		if field == nil || field.DBName == "" {
+			return UpsertResult{}, fmt.Errorf("%w: %q", ErrUnknownColumn, col)
+		}
+		columns = append(columns, clause.Column{Name: field.DBName})
+		fields = append(fields, field)
+		names = append(names, field.DBName)
+	}
+	if len(columns) == 0 {
+		return UpsertResult{}, fmt.Errorf("%w: no conflict column", ErrUnknownColumn)
+	}
+
+	ctx := db.Statement.Context
+	keys := make([][]interface{}, 0, len(rows))
+	seen := make(map[string]struct{}, len(rows))
+	for i := range rows {
+		rv := reflect.ValueOf(&rows[i]).Elem()
+		key := make([]interface{}, len(fields))
+		for j, field := range fields {
+			key[j], _ = field.ValueOf(ctx, rv)
+		}
+		id := fmt.Sprintf("%#v", key)
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		keys = append(keys, key)
+	}
+
+	var result UpsertResult
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var target interface{} = column(names[0])
+		if len(names) > 1 {
+			targets := make([]clause.Column, len(names))
+			for i, name := range names {
+				targets[i] = column(name)
+			}
+			target = targets
+		}
+		for start := 0; start < len(keys); start += DefaultChunkSize {
+			end := start + DefaultChunkSize
+			if end > len(keys) {
+				end = len(keys)
+			}
+			values := make([]interface{}, 0, end-start)
+			for _, key := range keys[start:end] {
+				if len(names) == 1 {
+					values = append(values, key[0])
+				} else {
+					values = append(values, key)
+				}
+			}
+			var existing int64
+			if err := tx.Model(new(T)).Unscoped().Where(clause.IN{Column: target, Values: values}).Count(&existing).Error; err != nil {
+				return err
+			}
+			result.Updated += existing
+		}
+		result.Inserted = int64(len(keys)) - result.Updated
+
+		return tx.Clauses(upsertClause(query.Statement.Schema, columns)).
+			CreateInBatches(&rows, DefaultChunkSize).Error
+	})
+	if err != nil {
+		return UpsertResult{}, err
+	}
+	return result, nil
+}
+
+// upsertClause returns the ON CONFLICT clause of BulkUpsert for the model described by sch.
+func upsertClause(sch *schema.Schema, columns []clause.Column) clause.OnConflict {
+	conflict := make(map[string]struct{}, len(columns))
+	for _, column := range columns {
+		conflict[column.Name] = struct{}{}
+	}
+	deletedAt := reflect.TypeOf(gorm.DeletedAt{})
+	updatedAt := sch.LookUpField("UpdatedAt")
+
+	var updates []string
+	for _, field := range sch.Fields {
+		if _, ok := conflict[field.DBName]; ok || field.DBName == "" || field.PrimaryKey ||
+			!field.Updatable || field.AutoCreateTime != 0 || field.FieldType == deletedAt || field == updatedAt {
+			continue
+		}
+		updates = append(updates, field.DBName)
+	}
+	set := clause.AssignmentColumns(updates)
+	if updatedAt != nil && updatedAt.DBName != "" {
+		set = append(set, clause.Assignment{Column: clause.Column{Name: updatedAt.DBName}, Value: Now()})
+	}
+	if len(set) == 0 {
+		return clause.OnConflict{Columns: columns, DoNothing: true}
+	}
+	return clause.OnConflict{Columns: columns, DoUpdates: set}
+}
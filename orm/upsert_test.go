@@ -0,0 +1,125 @@
+package orm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBulkUpsert(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	upserted := created.Add(time.Hour)
+	SetClock(func() time.Time { return created })
+	defer SetClock(nil)
+
+	db := newTestDB(t, testUsersTable, "CREATE UNIQUE INDEX IDX_TEST_USERS_NAME ON test_users (NAME)")
+	ids := createUsers(t, db, "alice", "bob")
+	// GORM inserts the column default instead of a false IsActive, so rows to be updated
+	// start inactive and the upsert activates them.
+	if err := db.Model(&testUser{}).Where("ID IN ?", ids).Update("IS_ACTIVE", false).Error; err != nil {
+		t.Fatalf("deactivate: %v", err)
+	}
+	if err := db.Delete(&testUser{}, "ID = ?", ids[1]).Error; err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	SetClock(func() time.Time { return upserted })
+	rows := []testUser{
+		{Name: "alice", IsActive: true},
+		{Name: "bob", IsActive: true},
+		{Name: "carol", IsActive: true},
+		{Name: "carol", IsActive: true},
+	}
+	result, err := BulkUpsert(db, []string{"Name"}, rows)
+	if err != nil {
+		t.Fatalf("BulkUpsert: %v", err)
+	}
+	if want := (UpsertResult{Inserted: 1, Updated: 2}); result != want {
+		t.Errorf("result = %+v, want %+v", result, want)
+	}
+
+	tests := []struct {
+		name        string
+		id          string
+		wantActive  bool
+		wantCreated time.Time
+		wantDeleted bool
+	}{
+		{name: "alice", id: ids[0], wantActive: true, wantCreated: created},
+		{name: "bob", id: ids[1], wantActive: true, wantCreated: created, wantDeleted: true},
+		{name: "carol", wantActive: true, wantCreated: upserted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var user testUser
+			if err := db.Unscoped().Where("NAME = ?", tt.name).First(&user).Error; err != nil {
+				t.Fatalf("find: %v", err)
+			}
+			if tt.id != "" && user.ID != tt.id {
+				t.Errorf("ID = %q, want %q", user.ID, tt.id)
+			}
+			if user.IsActive != tt.wantActive {
+				t.Errorf("IsActive = %v, want %v", user.IsActive, tt.wantActive)
+			}
+			if !user.CreatedAt.Equal(tt.wantCreated) {
+				t.Errorf("CreatedAt = %v, want %v", user.CreatedAt, tt.wantCreated)
+			}
+			if !user.UpdatedAt.Equal(upserted) {
+				t.Errorf("UpdatedAt = %v, want %v", user.UpdatedAt, upserted)
+			}
+			if user.DeletedAt.Valid != tt.wantDeleted {
+				t.Errorf("soft-deleted = %v, want %v", user.DeletedAt.Valid, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestBulkUpsertErrors(t *testing.T) {
+	db := newTestDB(t, testUsersTable)
+	rows := []testUser{{Name: "alice"}}
+	tests := []struct {
+		name    string
+		columns []string
+	}{
+		{"unknown column", []string{"EMAIL"}},
+		{"no column", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := BulkUpsert(db, tt.columns, rows); !errors.Is(err, ErrUnknownColumn) {
+				t.Errorf("err = %v, want ErrUnknownColumn", err)
+			}
+		})
+	}
+}
+
+func TestBulkUpsertPostgres(t *testing.T) {
+	tests := []struct {
+		name      string
+		conflicts []string
+		want      string
+	}{
+		{
+			"single column",
+			[]string{"NAME"},
+			`SELECT count(*) FROM "test_users" WHERE "test_users"."NAME" IN ($1,$2)`,
+		},
+		{
+			"composite key",
+			[]string{"NAME", "IS_ACTIVE"},
+			`SELECT count(*) FROM "test_users" WHERE ("test_users"."NAME","test_users"."IS_ACTIVE") IN (($1,$2),($3,$4))`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, statements := newPostgresDryRunDB(t)
+			rows := []testUser{{Name: "alice", IsActive: true}, {Name: "bob", IsActive: true}}
+			if _, err := BulkUpsert(db, tt.conflicts, rows); err != nil {
+				t.Fatal(err)
+			}
+			if got := statements(); len(got) == 0 || got[0] != tt.want {
+				t.Errorf("SQL = %q, want %q first", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package orm
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/cache"
+	"gorm.io/gorm"
+)
+
+// WriteThrough saves value to the database in a transaction and, only once the transaction
+// has committed, writes its JSON encoding to the cache under key. A ttl of 0 stores the
+// value without expiration.
+//
+// If the database write fails or the transaction is rolled back, the cache is left untouched.
+// If the cache write fails after the commit, the key is deleted on a best-effort basis so that
+// readers do not observe a stale value, and the cache error is returned.
+//
+// When ctx belongs to a WithTransaction call, the cache write is deferred with
+// RegisterAfterCommit until the outermost transaction commits, and discarded if it rolls
+// back; a failure of the deferred write is not reported, but the key is still deleted. Inside
+// other caller-managed transactions, whose commit GORM does not expose, the commit referred to
+// above is the savepoint of the nested transaction, not the outer one.
+//
+//	err := orm.WriteThrough(ctx, db, c, "user:"+user.ID, time.Hour, &user)
+func WriteThrough[T any](ctx context.Context, db *gorm.DB, c cache.Cache, key string, ttl time.Duration, value *T) error {
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.Save(value).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// The value is encoded right away, so that later changes to it are not cached.
+	data, err := json.Marshal(value)
+	if err != nil {
+		_ = c.Del(ctx, key)
+		return err
+	}
+	if _, deferred := ctx.Value(afterCommitKey{}).(*afterCommit); deferred {
+		RegisterAfterCommit(ctx, func() {
+			_ = writeCache(ctx, c, key, ttl, data)
+		})
+		return nil
+	}
+	return writeCache(ctx, c, key, ttl, data)
+}
+
+// writeCache stores data in the cache under key, deleting the key if the write fails.
+func writeCache(ctx context.Context, c cache.Cache, key string, ttl time.Duration, data []byte) error {
+	var err error
+	if ttl > 0 {
+		err = c.SetWithExpiration(ctx, key, string(data), ttl)
+	} else {
+		err = c.Set(ctx, key, string(data))
+	}
+	if err != nil {
+		_ = c.Del(ctx, key)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package orm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/cache"
+	"gorm.io/gorm"
+)
+
+var errCacheWrite = errors.New("cache write failed")
+
+// testCache is a map-backed cache recording the expiration of its writes, whose writes fail
+// with setErr when it is set. The methods it does not implement panic through the nil
+// embedded Cache.
+type testCache struct {
+	cache.Cache
+	setErr      error
+	expirations []time.Duration
+
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func newTestCache() *testCache {
+	return &testCache{values: make(map[string]string)}
+}
+
+func (c *testCache) Get(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.values[key]
+	if !ok {
+		return "", cache.ErrCacheNil
+	}
+	return value, nil
+}
+
+func (c *testCache) Set(ctx context.Context, key string, value interface{}) error {
+	return c.SetWithExpiration(ctx, key, value, 0)
+}
+
+func (c *testCache) SetWithExpiration(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	if c.setErr != nil {
+		return c.setErr
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expirations = append(c.expirations, expiration)
+	c.values[key] = fmt.Sprint(value)
+	return nil
+}
+
+func (c *testCache) Del(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.values, key)
+	}
+	return nil
+}
+
+func TestWriteThrough(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		setErr  error
+		wantErr error
+	}{
+		{"without expiration", 0, nil, nil},
+		{"with expiration", time.Hour, nil, nil},
+		{"cache failure", time.Hour, errCacheWrite, errCacheWrite},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := newTestDB(t, testUsersTable)
+			c := newTestCache()
+			c.values["user"] = "stale"
+			c.setErr = tt.setErr
+
+			user := testUser{Name: "alice", IsActive: true}
+			if err := WriteThrough(ctx, db, c, "user", tt.ttl, &user); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WriteThrough: err = %v, want %v", err, tt.wantErr)
+			}
+
+			// The row is committed even if the cache write fails.
+			var count int64
+			if err := db.Model(&testUser{}).Where("NAME = ?", "alice").Count(&count).Error; err != nil {
+				t.Fatal(err)
+			}
+			if count != 1 {
+				t.Errorf("saved rows = %d, want 1", count)
+			}
+
+			cached, err := c.Get(ctx, "user")
+			if tt.wantErr != nil {
+				// The stale value is deleted rather than left behind.
+				if !errors.Is(err, cache.ErrCacheNil) {
+					t.Errorf("Get: value %q, err = %v, want ErrCacheNil", cached, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			var got testUser
+			if err := json.Unmarshal([]byte(cached), &got); err != nil {
+				t.Fatalf("decode cached value: %v", err)
+			}
+			if got.ID != user.ID || got.Name != "alice" {
+				t.Errorf("cached user = %+v, want %+v", got, user)
+			}
+			if len(c.expirations) != 1 || c.expirations[0] != tt.ttl {
+				t.Errorf("expirations = %v, want [%v]", c.expirations, tt.ttl)
+			}
+		})
+	}
+}
+
+func TestWriteThroughDatabaseFailure(t *testing.T) {
+	ctx := context.Background()
+	// The table does not exist, so the save fails.
+	db := newTestDB(t)
+	c := newTestCache()
+	if err := c.Set(ctx, "user", "stale"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteThrough(ctx, db, c, "user", 0, &testUser{Name: "alice"}); err == nil {
+		t.Fatal("WriteThrough succeeded without a table")
+	}
+	if got, err := c.Get(ctx, "user"); err != nil || got != "stale" {
+		t.Errorf("Get = %q, %v, want the cache left untouched", got, err)
+	}
+}
+
+func TestWriteThroughInTransaction(t *testing.T) {
+	errRollback := errors.New("rollback")
+	tests := []struct {
+		name      string
+		err       error
+		wantValue bool
+	}{
+		{"committed", nil, true},
+		{"rolled back", errRollback, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestDB(t, testUsersTable)
+			c := newTestCache()
+			err := WithTransaction(context.Background(), db, func(ctx context.Context, tx *gorm.DB) error {
+				if err := WriteThrough(ctx, tx, c, "user", 0, &testUser{Name: "alice"}); err != nil {
+					return err
+				}
+				// The savepoint has committed, but the outer transaction has not.
+				if got, err := c.Get(ctx, "user"); !errors.Is(err, cache.ErrCacheNil) {
+					t.Errorf("Get before the commit = %q, %v, want ErrCacheNil", got, err)
+				}
+				return tt.err
+			})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("WithTransaction: err = %v, want %v", err, tt.err)
+			}
+
+			_, err = c.Get(context.Background(), "user")
+			if tt.wantValue && err != nil {
+				t.Errorf("Get after the commit: %v", err)
+			}
+			if !tt.wantValue && !errors.Is(err, cache.ErrCacheNil) {
+				t.Errorf("Get after the rollback: err = %v, want ErrCacheNil", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package pubsub
+
+import (
+	"context"
+	"time"
+)
+
+// ReceiveBatch groups the messages received from the subscriber into batches of up to
+// maxBatch messages (a value below 1 is treated as 1). A batch is emitted as soon as it is
+// full or, if maxWait is positive, once maxWait has elapsed since its first message,
+// whichever comes first.
+//
+// When ctx is cancelled or the subscriber's receiver channel is closed, the pending partial
+// batch is flushed and the returned channel is closed. The consumer must therefore keep
+// reading until the channel is closed. It returns an error if the receiver cannot be obtained.
+//
+// Example:
+//
+//	batches, err := pubsub.ReceiveBatch(ctx, subscriber, 500, time.Second)
+//	for batch := range batches {
+//		// write batch in bulk
+//	}
+func ReceiveBatch(ctx context.Context, s Subscriber, maxBatch int, maxWait time.Duration) (<-chan []Message, error) {
+	messages, err := s.Receiver(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if maxBatch < 1 {
+		maxBatch = 1
+	}
+
+	batches := make(chan []Message)
+	go func() {
+		defer close(batches)
+
+		var (
+			batch   = make([]Message, 0, maxBatch)
+			timer   *time.Timer
+			timeout <-chan time.Time
+		)
+		flush := func() {
+			if timer != nil {
+				timer.Stop()
+				timer, timeout = nil, nil
+			}
+			if len(batch) == 0 {
+				return
+			}
+			batches <- batch
+			batch = make([]Message, 0, maxBatch)
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				flush()
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					flush()
+					return
+				}
+				batch = append(batch, msg)
+				if len(batch) >= maxBatch {
+					flush()
+				} else if len(batch) == 1 && maxWait > 0 {
+					timer = time.NewTimer(maxWait)
+					timeout = timer.C
+				}
+			case <-timeout:
+				timer, timeout = nil, nil
+				flush()
+			}
+		}
+	}()
+	return batches, nil
+}
@@ -0,0 +1,130 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// batchSizes drains batches and returns the size of each batch, failing the test if the
+// channel is not closed within a second.
+func batchSizes(t *testing.T, batches <-chan []Message) []int {
+	t.Helper()
+	var sizes []int
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case batch, ok := <-batches:
+			if !ok {
+				return sizes
+			}
+			sizes = append(sizes, len(batch))
+		case <-timeout:
+			t.Fatalf("batches not closed, got sizes %v", sizes)
+		}
+	}
+}
+
+// sendMessages sends n messages numbered from 0 to ch.
+func sendMessages(ch chan<- Message, n int) {
+	for i := 0; i < n; i++ {
+		ch <- rawMessage{topic: "orders", data: []byte(strconv.Itoa(i))}
+	}
+}
+
+func TestReceiveBatchBySize(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxBatch  int
+		messages  int
+		wantSizes []int
+	}{
+		{"full batches", 3, 6, []int{3, 3}},
+		{"final partial batch on close", 3, 7, []int{3, 3, 1}},
+		{"maxBatch below 1", 0, 3, []int{1, 1, 1}},
+		{"no message", 3, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := &chanSubscriber{ch: make(chan Message)}
+			batches, err := ReceiveBatch(context.Background(), sub, tt.maxBatch, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			go func() {
+				sendMessages(sub.ch, tt.messages)
+				close(sub.ch)
+			}()
+			if got := batchSizes(t, batches); fmt.Sprint(got) != fmt.Sprint(tt.wantSizes) {
+				t.Errorf("batch sizes = %v, want %v", got, tt.wantSizes)
+			}
+		})
+	}
+}
+
+func TestReceiveBatchByTime(t *testing.T) {
+	sub := &chanSubscriber{ch: make(chan Message)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	const maxWait = 20 * time.Millisecond
+	batches, err := ReceiveBatch(ctx, sub, 100, maxWait)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for round := 0; round < 2; round++ {
+		start := time.Now()
+		sendMessages(sub.ch, 2)
+		select {
+		case batch := <-batches:
+			if len(batch) != 2 {
+				t.Errorf("round %d: batch size = %d, want 2", round, len(batch))
+			}
+			if elapsed := time.Since(start); elapsed < maxWait {
+				t.Errorf("round %d: batch emitted after %v, want at least %v", round, elapsed, maxWait)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("round %d: no batch emitted after maxWait", round)
+		}
+	}
+}
+
+func TestReceiveBatchFlushesOnCancel(t *testing.T) {
+	sub := &chanSubscriber{ch: make(chan Message)}
+	ctx, cancel := context.WithCancel(context.Background())
+	batches, err := ReceiveBatch(ctx, sub, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The receiver channel is unbuffered, so both messages are in the pending batch once
+	// the sends return.
+	sendMessages(sub.ch, 2)
+	cancel()
+
+	batch, ok := <-batches
+	if !ok {
+		t.Fatal("batches closed without flushing the partial batch")
+	}
+	for i, msg := range batch {
+		if got := string(msg.Data()); got != strconv.Itoa(i) {
+			t.Errorf("message %d = %q, want %d", i, got, i)
+		}
+	}
+	if len(batch) != 2 {
+		t.Errorf("batch size = %d, want 2", len(batch))
+	}
+	if sizes := batchSizes(t, batches); len(sizes) != 0 {
+		t.Errorf("batches after the final flush: %v", sizes)
+	}
+}
+
+func TestReceiveBatchReceiverError(t *testing.T) {
+	sub := &chanSubscriber{err: ErrConnectFailed}
+	if _, err := ReceiveBatch(context.Background(), sub, 10, time.Second); !errors.Is(err, ErrConnectFailed) {
+		t.Errorf("err = %v, want ErrConnectFailed", err)
+	}
+}
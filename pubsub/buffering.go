@@ -0,0 +1,305 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/cache"
+)
+
+// BufferKeyPrefix is the prefix of the cache keys storing the messages buffered by a BufferingPublisher.
+const BufferKeyPrefix = "pubsub:buffer:"
+
+// DefaultFlushInterval is the interval at which a BufferingPublisher retries buffered
+// messages when none is given.
+const DefaultFlushInterval = time.Second
+
+// bufferedBatch is a Publish call stored in the cache by a BufferingPublisher.
+type bufferedBatch struct {
+	Topic    string   `json:"topic"`
+	Messages [][]byte `json:"messages"`
+	// Priority is set for messages published with PublishWithPriority.
+	Priority *uint8 `json:"priority,omitempty"`
+}
+
+// BufferingPublisher is a Publisher decorator that provides store-and-forward delivery: when
+// publishing fails, e.g. because the broker is down, the messages are stored in a Cache and
+// a background flusher publishes them, in order, once the publisher reports being connected.
+//
+// Buffered messages survive restarts: a new BufferingPublisher with the same name resumes
+// delivering them. A name must be used by a single BufferingPublisher at a time. Entries are
+// stored without expiration, regardless of any default TTL of the cache, and the cache must
+// not evict the keys prefixed with BufferKeyPrefix: a buffered Publish call whose entry
+// disappears is lost, and is reported as dropped with ErrBufferedMessageLost. While
+// messages are buffered, new messages are buffered behind them, so that delivery order is
+// preserved; each Publish call is buffered and delivered as a whole.
+//
+// Buffered messages that can never be delivered, because they are lost, cannot be decoded, or
+// the underlying publisher returns ErrUnsupported for them, are dropped so that they do not block
+// the messages behind them; register an OnDrop callback to dead-letter them.
+type BufferingPublisher struct {
+	pub      Publisher
+	cache    cache.Cache
+	prefix   string
+	pattern  string
+	interval time.Duration
+
+	mu      sync.Mutex
+	queue   []string
+	lastSeq int64
+	dropped int64
+	onDrop  func(topic string, messages [][]byte, err error)
+
+	flushMu sync.Mutex
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+var _ Publisher = (*BufferingPublisher)(nil)
+
+// NewBufferingPublisher creates a BufferingPublisher delivering to pub and buffering in c
+// under keys prefixed with BufferKeyPrefix and name. It loads the messages left buffered by a
+// previous instance and starts a flusher that retries them every flushInterval, or
+// DefaultFlushInterval if flushInterval is not positive. Close stops the flusher.
+//
+//	pub, err := pubsub.NewBufferingPublisher(ctx, pub, c, "orders-api", 0)
+func NewBufferingPublisher(ctx context.Context, pub Publisher, c cache.Cache, name string, flushInterval time.Duration) (*BufferingPublisher, error) {
+	if flushInterval <= 0 {
+		flushInterval = DefaultFlushInterval
+	}
+	b := &BufferingPublisher{
+		pub:      pub,
+		cache:    c,
+		prefix:   BufferKeyPrefix + name + ":",
+		pattern:  BufferKeyPrefix + escapeGlob(name) + ":*",
+		interval: flushInterval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	keys, err := c.Keys(ctx, b.pattern)
+	if err != nil {
+		return nil, fmt.Errorf("pubsub: load buffered messages: %w", err)
+	}
+	// Keys end with a fixed-width sequence number, so they sort in buffering order.
+	sort.Strings(keys)
+	b.queue = keys
+
+	go b.run()
+	return b, nil
+}
+
+// Publish publishes messages to topic through the underlying publisher. If messages are
+// already buffered, or publishing fails, the messages are buffered instead and nil is
+// returned; an error is returned only if buffering fails too.
+func (b *BufferingPublisher) Publish(ctx context.Context, topic string, messages ...[]byte) error {
+	return b.publish(ctx, bufferedBatch{Topic: topic, Messages: messages})
+}
+
+// PublishWithPriority publishes msg to topic with a priority hint through the underlying
+// publisher, buffering it like Publish. Buffered messages keep their priority. ErrUnsupported
+// from the underlying publisher is returned as-is rather than buffered.
+func (b *BufferingPublisher) PublishWithPriority(ctx context.Context, topic string, priority uint8, msg []byte) error {
+	return b.publish(ctx, bufferedBatch{Topic: topic, Messages: [][]byte{msg}, Priority: &priority})
+}
+
+// publish delivers batch, or buffers it if messages are already buffered or delivery fails.
+func (b *BufferingPublisher) publish(ctx context.Context, batch bufferedBatch) error {
+	if b.Pending() == 0 {
+		err := b.send(ctx, batch)
+		if err == nil || errors.Is(err, ErrUnsupported) {
+			return err
+		}
+		if bufferErr := b.enqueue(ctx, batch); bufferErr != nil {
+			return fmt.Errorf("%w (buffering failed: %v)", err, bufferErr)
+		}
+		return nil
+	}
+	return b.enqueue(ctx, batch)
+}
+
+// send publishes batch through the underlying publisher.
+func (b *BufferingPublisher) send(ctx context.Context, batch bufferedBatch) error {
+	if batch.Priority != nil {
+		return b.pub.PublishWithPriority(ctx, batch.Topic, *batch.Priority, batch.Messages[0])
+	}
+	return b.pub.Publish(ctx, batch.Topic, batch.Messages...)
+}
+
+// OnDrop registers a callback invoked when Flush drops a buffered Publish call that fails
+// permanently, e.g. to publish it to a dead-letter topic. The callback receives the topic and
+// messages of the call, an empty topic and the raw buffered entry if it could not be decoded,
+// or an empty topic and no messages if it was lost, and the error wrapping ErrDecodeFailed,
+// ErrUnsupported, or ErrBufferedMessageLost.
+func (b *BufferingPublisher) OnDrop(fn func(topic string, messages [][]byte, err error)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onDrop = fn
+}
+
+// Dropped returns the number of buffered Publish calls dropped because they failed permanently.
+func (b *BufferingPublisher) Dropped() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped
+}
+
+// Pending returns the number of buffered Publish calls waiting to be delivered.
+func (b *BufferingPublisher) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.queue)
+}
+
+// Flush publishes the buffered messages in order. Calls failing permanently, because they
+// are lost, cannot be decoded, or the underlying publisher returns ErrUnsupported, are dropped and
+// reported to the OnDrop callback; Flush stops at the first other failure, which is returned
+// and retried later. It is called periodically by the flusher while the publisher is connected.
+func (b *BufferingPublisher) Flush(ctx context.Context) error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+	for {
+		b.mu.Lock()
+		if len(b.queue) == 0 {
+			b.mu.Unlock()
+			return nil
+		}
+		key := b.queue[0]
+		b.mu.Unlock()
+
+		if err := b.deliver(ctx, key); err != nil {
+			return err
+		}
+		b.mu.Lock()
+		b.queue = b.queue[1:]
+		b.mu.Unlock()
+	}
+}
+
+// IsConnected delegates to the underlying publisher.
+func (b *BufferingPublisher) IsConnected(ctx context.Context) bool {
+	return b.pub.IsConnected(ctx)
+}
+
+// CheckConnection delegates to the underlying publisher.
+func (b *BufferingPublisher) CheckConnection(ctx context.Context) error {
+	return b.pub.CheckConnection(ctx)
+}
+
+// Close stops the flusher and closes the underlying publisher. Messages still buffered stay
+// in the cache and are delivered by the next BufferingPublisher with the same name.
+func (b *BufferingPublisher) Close() error {
+	b.once.Do(func() {
+		close(b.stop)
+	})
+	<-b.done
+	return b.pub.Close()
+}
+
+// run retries the buffered messages every interval while the publisher is connected.
+func (b *BufferingPublisher) run() {
+	defer close(b.done)
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-b.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			if b.Pending() > 0 && b.pub.IsConnected(ctx) {
+				_ = b.Flush(ctx)
+			}
+		}
+	}
+}
+
+// enqueue stores a Publish call in the cache behind the already buffered ones.
+func (b *BufferingPublisher) enqueue(ctx context.Context, batch bufferedBatch) error {
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	seq := time.Now().UnixNano()
+	if seq <= b.lastSeq {
+		seq = b.lastSeq + 1
+	}
+	key := fmt.Sprintf("%s%020d", b.prefix, seq)
+	if err := b.cache.SetWithExpiration(ctx, key, data, 0); err != nil {
+		return err
+	}
+	b.lastSeq = seq
+	b.queue = append(b.queue, key)
+	return nil
+}
+
+// deliver publishes the Publish call buffered under key and removes it from the cache.
+func (b *BufferingPublisher) deliver(ctx context.Context, key string) error {
+	data, err := b.cache.Get(ctx, key)
+	if errors.Is(err, cache.ErrCacheNil) {
+		return b.drop(ctx, key, bufferedBatch{}, fmt.Errorf("%w: buffered message %q", ErrBufferedMessageLost, key))
+	}
+	if err != nil {
+		return err
+	}
+	var batch bufferedBatch
+	if err := json.Unmarshal([]byte(data), &batch); err != nil {
+		return b.drop(ctx, key, bufferedBatch{Messages: [][]byte{[]byte(data)}},
+			fmt.Errorf("%w: buffered message %q: %v", ErrDecodeFailed, key, err))
+	}
+	if batch.Priority != nil && len(batch.Messages) != 1 {
+		return b.drop(ctx, key, batch,
+			fmt.Errorf("%w: buffered message %q: %d prioritized messages", ErrDecodeFailed, key, len(batch.Messages)))
+	}
+	if err := b.send(ctx, batch); err != nil {
+		if errors.Is(err, ErrUnsupported) {
+			return b.drop(ctx, key, batch, err)
+		}
+		return err
+	}
+	return b.cache.Del(ctx, key)
+}
+
+// drop removes the Publish call buffered under key, which failed permanently with err, and
+// reports it to the OnDrop callback.
+func (b *BufferingPublisher) drop(ctx context.Context, key string, batch bufferedBatch, err error) error {
+	b.mu.Lock()
+	b.dropped++
+	onDrop := b.onDrop
+	b.mu.Unlock()
+	if onDrop != nil {
+		onDrop(batch.Topic, batch.Messages, err)
+	}
+	return b.cache.Del(ctx, key)
+}
+
+// escapeGlob escapes the characters of s that have a meaning in Redis-style glob patterns.
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,301 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/cache/memory"
+)
+
+// expiringLRU is a memory.LRU whose Set expires entries after ttl, as caches configured with
+// a default TTL do.
+type expiringLRU struct {
+	*memory.LRU
+	ttl time.Duration
+}
+
+func (c expiringLRU) Set(ctx context.Context, key string, value interface{}) error {
+	return c.SetWithExpiration(ctx, key, value, c.ttl)
+}
+
+// newTestBufferingPublisher creates a BufferingPublisher whose flusher does not run during the test.
+func newTestBufferingPublisher(t *testing.T, pub Publisher, c *memory.LRU) *BufferingPublisher {
+	t.Helper()
+	b, err := NewBufferingPublisher(context.Background(), pub, c, "test", time.Hour)
+	if err != nil {
+		t.Fatalf("NewBufferingPublisher: %v", err)
+	}
+	return b
+}
+
+func TestBufferingPublisherOutage(t *testing.T) {
+	ctx := context.Background()
+	pub := &fakePublisher{}
+	c := memory.NewLRU(0)
+	b := newTestBufferingPublisher(t, pub, c)
+
+	steps := []struct {
+		down        bool
+		data        string
+		wantPending int
+	}{
+		{false, "1", 0},
+		{true, "2", 1},
+		{true, "3", 2},
+		// Buffered messages are delivered first, so new ones queue behind them even once
+		// the broker is back.
+		{false, "4", 3},
+	}
+	for _, step := range steps {
+		pub.setDown(step.down)
+		if err := b.Publish(ctx, "orders", []byte(step.data)); err != nil {
+			t.Fatalf("Publish %s: %v", step.data, err)
+		}
+		if got := b.Pending(); got != step.wantPending {
+			t.Fatalf("after %s: got %d pending, want %d", step.data, got, step.wantPending)
+		}
+	}
+
+	if err := b.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	want := []string{"orders:1", "orders:2", "orders:3", "orders:4"}
+	if got := pub.messages(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if keys, _ := c.Keys(ctx, BufferKeyPrefix+"*"); len(keys) != 0 {
+		t.Fatalf("cache still holds %v", keys)
+	}
+}
+
+func TestBufferingPublisherFlushStopsAtTransientFailure(t *testing.T) {
+	ctx := context.Background()
+	pub := &fakePublisher{down: true}
+	b := newTestBufferingPublisher(t, pub, memory.NewLRU(0))
+	for _, data := range []string{"1", "2"} {
+		if err := b.Publish(ctx, "orders", []byte(data)); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+
+	if err := b.Flush(ctx); !errors.Is(err, errBrokerDown) {
+		t.Fatalf("Flush while down: got %v, want errBrokerDown", err)
+	}
+	if got := b.Pending(); got != 2 {
+		t.Fatalf("got %d pending, want 2", got)
+	}
+	if got := b.Dropped(); got != 0 {
+		t.Fatalf("got %d dropped, want 0", got)
+	}
+}
+
+func TestBufferingPublisherResumesAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	pub := &fakePublisher{down: true}
+	c := memory.NewLRU(0)
+	first := newTestBufferingPublisher(t, pub, c)
+	if err := first.Publish(ctx, "orders", []byte("1"), []byte("2")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if err := first.PublishWithPriority(ctx, "orders", 9, []byte("3")); err != nil {
+		t.Fatalf("PublishWithPriority: %v", err)
+	}
+	if err := first.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	pub.setDown(false)
+	second := newTestBufferingPublisher(t, pub, c)
+	if got := second.Pending(); got != 2 {
+		t.Fatalf("got %d pending after restart, want 2", got)
+	}
+	if err := second.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	want := []string{"orders:1", "orders:2", "orders:3"}
+	if got := pub.messages(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestBufferingPublisherIgnoresDefaultTTL(t *testing.T) {
+	ctx := context.Background()
+	pub := &fakePublisher{down: true}
+	// Entries stored with Set expire as soon as they are written.
+	c := expiringLRU{LRU: memory.NewLRU(0), ttl: time.Nanosecond}
+	b, err := NewBufferingPublisher(ctx, pub, c, "test", time.Hour)
+	if err != nil {
+		t.Fatalf("NewBufferingPublisher: %v", err)
+	}
+	defer b.Close()
+	if err := b.Publish(ctx, "orders", []byte("1")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	pub.setDown(false)
+	if err := b.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if got, want := pub.messages(), []string{"orders:1"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if got := b.Dropped(); got != 0 {
+		t.Fatalf("got %d dropped, want 0", got)
+	}
+}
+
+func TestBufferingPublisherDropsPermanentFailures(t *testing.T) {
+	ctx := context.Background()
+	pub := &fakePublisher{down: true}
+	c := memory.NewLRU(0)
+	// An entry left by a previous instance that cannot be decoded.
+	if err := c.Set(ctx, BufferKeyPrefix+"test:00000000000000000001", "not json"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	b := newTestBufferingPublisher(t, pub, c)
+	type drop struct {
+		topic string
+		err   error
+	}
+	var drops []drop
+	b.OnDrop(func(topic string, messages [][]byte, err error) {
+		drops = append(drops, drop{topic, err})
+	})
+
+	if err := b.Publish(ctx, "orders", []byte("1")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if err := b.PublishWithPriority(ctx, "orders", 9, []byte("urgent")); err != nil {
+		t.Fatalf("PublishWithPriority: %v", err)
+	}
+	if err := b.Publish(ctx, "orders", []byte("2")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	// The broker comes back without priority support.
+	pub.mu.Lock()
+	pub.down, pub.noPriority = false, true
+	pub.mu.Unlock()
+	if err := b.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	want := []string{"orders:1", "orders:2"}
+	if got := pub.messages(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if got := b.Pending(); got != 0 {
+		t.Fatalf("got %d pending, want 0", got)
+	}
+	if got := b.Dropped(); got != 2 {
+		t.Fatalf("got %d dropped, want 2", got)
+	}
+	wantDrops := []struct {
+		topic string
+		err   error
+	}{
+		{"", ErrDecodeFailed},
+		{"orders", ErrUnsupported},
+	}
+	if len(drops) != len(wantDrops) {
+		t.Fatalf("got %d drops, want %d", len(drops), len(wantDrops))
+	}
+	for i, w := range wantDrops {
+		if drops[i].topic != w.topic || !errors.Is(drops[i].err, w.err) {
+			t.Errorf("drop %d: got (%q, %v), want (%q, %v)", i, drops[i].topic, drops[i].err, w.topic, w.err)
+		}
+	}
+}
+
+func TestBufferingPublisherDropsLostMessages(t *testing.T) {
+	ctx := context.Background()
+	pub := &fakePublisher{down: true}
+	c := memory.NewLRU(0)
+	b := newTestBufferingPublisher(t, pub, c)
+	var dropErrs []error
+	b.OnDrop(func(topic string, messages [][]byte, err error) {
+		dropErrs = append(dropErrs, err)
+	})
+	if err := b.Publish(ctx, "orders", []byte("1")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if err := b.Publish(ctx, "orders", []byte("2")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	// The entry of the first call disappears from the cache, e.g. it is evicted.
+	keys, _ := c.Keys(ctx, BufferKeyPrefix+"*")
+	sort.Strings(keys)
+	if err := c.Del(ctx, keys[0]); err != nil {
+		t.Fatalf("Del: %v", err)
+	}
+
+	pub.setDown(false)
+	if err := b.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if got := pub.messages(); !reflect.DeepEqual(got, []string{"orders:2"}) {
+		t.Fatalf("got %v, want [orders:2]", got)
+	}
+	if got := b.Dropped(); got != 1 {
+		t.Fatalf("got %d dropped, want 1", got)
+	}
+	if len(dropErrs) != 1 || !errors.Is(dropErrs[0], ErrBufferedMessageLost) {
+		t.Errorf("got drops %v, want ErrBufferedMessageLost", dropErrs)
+	}
+}
+
+func TestBufferingPublisherNameIsNotAPattern(t *testing.T) {
+	ctx := context.Background()
+	pub := &fakePublisher{down: true}
+	c := memory.NewLRU(0)
+	other, err := NewBufferingPublisher(ctx, pub, c, "orders-api", time.Hour)
+	if err != nil {
+		t.Fatalf("NewBufferingPublisher: %v", err)
+	}
+	defer other.Close()
+	if err := other.Publish(ctx, "orders", []byte("1")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	// The glob characters of the name match literally, so the messages of other names are
+	// not loaded.
+	for _, name := range []string{"*", "orders-?pi", "[o]rders-api"} {
+		b, err := NewBufferingPublisher(ctx, pub, c, name, time.Hour)
+		if err != nil {
+			t.Fatalf("NewBufferingPublisher(%q): %v", name, err)
+		}
+		if got := b.Pending(); got != 0 {
+			t.Errorf("%q: got %d pending, want 0", name, got)
+		}
+		b.Close()
+	}
+}
+
+func TestBufferingPublisherBackgroundFlush(t *testing.T) {
+	ctx := context.Background()
+	pub := &fakePublisher{down: true}
+	b, err := NewBufferingPublisher(ctx, pub, memory.NewLRU(0), "test", 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewBufferingPublisher: %v", err)
+	}
+	defer b.Close()
+	if err := b.Publish(ctx, "orders", []byte("1")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	pub.setDown(false)
+	deadline := time.Now().Add(time.Second)
+	for b.Pending() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the flusher did not deliver the buffered message")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := pub.messages(); !reflect.DeepEqual(got, []string{"orders:1"}) {
+		t.Fatalf("got %v", got)
+	}
+}
@@ -0,0 +1,150 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/zeroxsolutions/barbatos/cache"
+)
+
+// CheckpointKeyPrefix is the prefix of the cache keys storing the checkpoints of Checkpointer.
+const CheckpointKeyPrefix = "pubsub:checkpoint:"
+
+// Offsetter is an optional interface implemented by messages of stream backends that carry
+// their position within the topic.
+type Offsetter interface {
+	// Offset returns the position of the message within its topic. Offsets increase
+	// monotonically in delivery order.
+	Offset() int64
+}
+
+// checkpointKey returns the cache key of the checkpoint of group for topic.
+func checkpointKey(group, topic string) string {
+	return CheckpointKeyPrefix + group + ":" + topic
+}
+
+// LoadCheckpoint returns the offset of the last message of topic successfully handled by
+// the consumer group, as stored by Checkpointer. The boolean result is false if no
+// checkpoint has been stored yet. Backends supporting seeking can use it on startup to
+// resume right after the checkpoint.
+func LoadCheckpoint(ctx context.Context, c cache.Cache, group, topic string) (int64, bool, error) {
+	value, err := c.Get(ctx, checkpointKey(group, topic))
+	if errors.Is(err, cache.ErrCacheNil) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	offset, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("pubsub: invalid checkpoint %q for topic %q: %w", value, topic, err)
+	}
+	return offset, true, nil
+}
+
+// topicCheckpoint is the checkpoint state of a topic tracked by Checkpointer.
+type topicCheckpoint struct {
+	// offset is the stored checkpoint; it is meaningful only if stored is true.
+	offset int64
+	stored bool
+	// failed holds the offsets of failed messages after the checkpoint, which must be
+	// redelivered and succeed before the checkpoint can move past them.
+	failed map[int64]struct{}
+}
+
+// blocked reports whether a failed message precedes offset.
+func (t *topicCheckpoint) blocked(offset int64) bool {
+	for failed := range t.failed {
+		if failed < offset {
+			return true
+		}
+	}
+	return false
+}
+
+// Checkpointer returns a Middleware that stores, in c, the offset of the last message that the
+// consumer group handled successfully, one checkpoint per topic, and skips messages at or
+// before the stored checkpoint. Since checkpoints survive restarts, a consumer that is
+// redelivered already-processed messages resumes after its last checkpoint without
+// reprocessing them, whether or not the backend can seek.
+//
+// The checkpoint never moves past a failed message: successes following a failure leave the
+// checkpoint where it is until the failed message is redelivered and succeeds, so that it is
+// not skipped as already processed. If it is never redelivered, e.g. because it was
+// dead-lettered, the messages after it are handled again after a restart. Delivery is
+// therefore at-least-once.
+//
+// Checkpoints are loaded once per topic and then tracked in memory, so a single consumer
+// should process each topic of a group, and it must handle the messages of a topic one at a
+// time in offset order: a message still being handled when a later one succeeds could be
+// skipped if it then fails. Messages that do not implement Offsetter are passed through
+// without checkpointing. The stored checkpoint only ever moves forward, and is stored without
+// expiration regardless of any default TTL of the cache.
+//
+//	handler := pubsub.Chain(handle, pubsub.Checkpointer(c, "billing"))
+func Checkpointer(c cache.Cache, group string) Middleware {
+	var (
+		mu     sync.Mutex
+		topics = make(map[string]*topicCheckpoint)
+	)
+	// load returns the state of topic, loading its stored checkpoint on first use.
+	// It must be called with mu held.
+	load := func(ctx context.Context, topic string) (*topicCheckpoint, error) {
+		if state, ok := topics[topic]; ok {
+			return state, nil
+		}
+		offset, stored, err := LoadCheckpoint(ctx, c, group, topic)
+		if err != nil {
+			return nil, err
+		}
+		state := &topicCheckpoint{offset: offset, stored: stored, failed: make(map[int64]struct{})}
+		topics[topic] = state
+		return state, nil
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, msg Message) error {
+			offsetter, ok := msg.(Offsetter)
+			if !ok {
+				return next(ctx, msg)
+			}
+			offset := offsetter.Offset()
+			topic := msg.Topic()
+
+			mu.Lock()
+			state, err := load(ctx, topic)
+			skip := err == nil && state.stored && offset <= state.offset
+			mu.Unlock()
+			if err != nil {
+				return err
+			}
+			if skip {
+				return nil
+			}
+
+			if err := next(ctx, msg); err != nil {
+				mu.Lock()
+				state.failed[offset] = struct{}{}
+				mu.Unlock()
+				return err
+			}
+
+			// The lock is held while writing, so that concurrent writes cannot move the
+			// stored checkpoint backwards.
+			mu.Lock()
+			defer mu.Unlock()
+			delete(state.failed, offset)
+			if state.blocked(offset) || (state.stored && offset <= state.offset) {
+				return nil
+			}
+			if err := c.SetWithExpiration(ctx, checkpointKey(group, topic), strconv.FormatInt(offset, 10), 0); err != nil {
+				return err
+			}
+			state.offset, state.stored = offset, true
+			return nil
+		}
+	}
+}
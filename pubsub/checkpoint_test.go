@@ -0,0 +1,158 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// offsetMessage is a Message of a stream backend carrying its offset.
+type offsetMessage struct {
+	topic  string
+	offset int64
+}
+
+func (m offsetMessage) Topic() string { return m.topic }
+func (m offsetMessage) Data() []byte  { return nil }
+func (m offsetMessage) Offset() int64 { return m.offset }
+
+// defaultTTLCache is a memCache whose Set expires entries after ttl, as caches configured
+// with a default TTL do.
+type defaultTTLCache struct {
+	*memCache
+	ttl time.Duration
+}
+
+func (c defaultTTLCache) Set(ctx context.Context, key string, value interface{}) error {
+	return c.SetWithExpiration(ctx, key, value, c.ttl)
+}
+
+func TestCheckpointer(t *testing.T) {
+	errHandler := errors.New("handler failed")
+	type delivery struct {
+		offset int64
+		fail   bool
+	}
+	tests := []struct {
+		name       string
+		deliveries []delivery
+		wantCalls  []int64
+		wantStored int64
+	}{
+		{
+			name:       "in order",
+			deliveries: []delivery{{1, false}, {2, false}, {3, false}},
+			wantCalls:  []int64{1, 2, 3},
+			wantStored: 3,
+		},
+		{
+			name:       "redelivered messages are skipped",
+			deliveries: []delivery{{1, false}, {2, false}, {1, false}, {2, false}, {3, false}},
+			wantCalls:  []int64{1, 2, 3},
+			wantStored: 3,
+		},
+		{
+			name: "a failure blocks the checkpoint until it is redelivered",
+			deliveries: []delivery{
+				{1, false}, {2, true}, {3, false}, {4, false},
+				// The failed message is redelivered and must not be skipped.
+				{2, false},
+			},
+			wantCalls:  []int64{1, 2, 3, 4, 2},
+			wantStored: 2,
+		},
+		{
+			name:       "a failure never redelivered keeps the checkpoint before it",
+			deliveries: []delivery{{1, false}, {2, true}, {3, false}},
+			wantCalls:  []int64{1, 2, 3},
+			wantStored: 1,
+		},
+		{
+			name:       "out of order completions never move the checkpoint backwards",
+			deliveries: []delivery{{5, false}, {3, false}},
+			wantCalls:  []int64{5},
+			wantStored: 5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := newMemCache()
+			var (
+				calls   []int64
+				failing = make(map[int64]bool)
+			)
+			handler := Chain(func(ctx context.Context, msg Message) error {
+				offset := msg.(Offsetter).Offset()
+				calls = append(calls, offset)
+				if failing[offset] {
+					return errHandler
+				}
+				return nil
+			}, Checkpointer(c, "billing"))
+
+			for _, d := range tt.deliveries {
+				failing[d.offset] = d.fail
+				err := handler(ctx, offsetMessage{topic: "invoices", offset: d.offset})
+				if d.fail != errors.Is(err, errHandler) {
+					t.Fatalf("offset %d: got error %v, want failure %v", d.offset, err, d.fail)
+				}
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Fatalf("handled %v, want %v", calls, tt.wantCalls)
+			}
+			stored, ok, err := LoadCheckpoint(ctx, c, "billing", "invoices")
+			if err != nil || !ok || stored != tt.wantStored {
+				t.Fatalf("stored checkpoint: got (%d, %v, %v), want %d", stored, ok, err, tt.wantStored)
+			}
+		})
+	}
+}
+
+func TestCheckpointerResumesAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	c := newMemCache()
+	var calls []int64
+	handle := func(ctx context.Context, msg Message) error {
+		calls = append(calls, msg.(Offsetter).Offset())
+		return nil
+	}
+
+	first := Chain(handle, Checkpointer(c, "billing"))
+	for _, offset := range []int64{1, 2} {
+		if err := first(ctx, offsetMessage{topic: "invoices", offset: offset}); err != nil {
+			t.Fatalf("offset %d: %v", offset, err)
+		}
+	}
+
+	// A new consumer is redelivered the topic from the start.
+	second := Chain(handle, Checkpointer(c, "billing"))
+	for _, offset := range []int64{1, 2, 3} {
+		if err := second(ctx, offsetMessage{topic: "invoices", offset: offset}); err != nil {
+			t.Fatalf("offset %d: %v", offset, err)
+		}
+	}
+	if want := []int64{1, 2, 3}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("handled %v, want %v", calls, want)
+	}
+	if _, ok, _ := LoadCheckpoint(ctx, c, "other-group", "invoices"); ok {
+		t.Fatal("checkpoint shared across groups")
+	}
+}
+
+func TestCheckpointerIgnoresDefaultTTL(t *testing.T) {
+	ctx := context.Background()
+	c := defaultTTLCache{memCache: newMemCache(), ttl: time.Minute}
+	handle := func(ctx context.Context, msg Message) error { return nil }
+	if err := Chain(handle, Checkpointer(c, "billing"))(ctx, offsetMessage{topic: "invoices", offset: 7}); err != nil {
+		t.Fatal(err)
+	}
+	c.advance(time.Hour)
+
+	offset, ok, err := LoadCheckpoint(ctx, c, "billing", "invoices")
+	if err != nil || !ok || offset != 7 {
+		t.Fatalf("LoadCheckpoint = %d, %v, %v, want 7, true, nil", offset, ok, err)
+	}
+}
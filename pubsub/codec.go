@@ -0,0 +1,50 @@
+package pubsub
+
+import "encoding/json"
+
+// ContentTypeHeader is the message header carrying the content type of the payload,
+// as reported by the Codec that encoded it.
+const ContentTypeHeader = "content-type"
+
+// ContentType returns the content type of msg as stamped in its ContentTypeHeader, or an
+// empty string if the message carries no headers or no content type.
+func ContentType(msg Message) string {
+	return header(msg, ContentTypeHeader)
+}
+
+// WithContentType sets the content type of the envelope.
+func WithContentType(contentType string) EnvelopeOption {
+	return WithHeader(ContentTypeHeader, contentType)
+}
+
+// Codec defines an interface for encoding values into message payloads and decoding
+// them back, decoupling messaging helpers from a single wire format.
+type Codec interface {
+	// Marshal encodes v into a payload.
+	Marshal(v interface{}) ([]byte, error)
+
+	// Unmarshal decodes the payload data into v, which must be a pointer.
+	Unmarshal(data []byte, v interface{}) error
+
+	// ContentType returns the MIME type of the payloads produced by the codec,
+	// e.g. "application/json".
+	ContentType() string
+}
+
+// JSONCodec is a Codec that encodes payloads as JSON using encoding/json.
+type JSONCodec struct{}
+
+// Marshal encodes v as JSON.
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal decodes the JSON payload data into v.
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// ContentType returns "application/json".
+func (JSONCodec) ContentType() string {
+	return "application/json"
+}
@@ -0,0 +1,106 @@
+package pubsub
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJSONCodec(t *testing.T) {
+	type order struct {
+		ID    string   `json:"id"`
+		Items []string `json:"items"`
+	}
+	tests := []struct {
+		name     string
+		value    interface{}
+		wantData string
+		decode   func(c Codec, data []byte) (interface{}, error)
+	}{
+		{
+			name:     "struct",
+			value:    order{ID: "42", Items: []string{"book"}},
+			wantData: `{"id":"42","items":["book"]}`,
+			decode: func(c Codec, data []byte) (interface{}, error) {
+				var v order
+				err := c.Unmarshal(data, &v)
+				return v, err
+			},
+		},
+		{
+			name:     "map",
+			value:    map[string]int{"a": 1},
+			wantData: `{"a":1}`,
+			decode: func(c Codec, data []byte) (interface{}, error) {
+				var v map[string]int
+				err := c.Unmarshal(data, &v)
+				return v, err
+			},
+		},
+		{
+			name:     "string",
+			value:    "hello",
+			wantData: `"hello"`,
+			decode: func(c Codec, data []byte) (interface{}, error) {
+				var v string
+				err := c.Unmarshal(data, &v)
+				return v, err
+			},
+		},
+	}
+	var codec Codec = JSONCodec{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := codec.Marshal(tt.value)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if string(data) != tt.wantData {
+				t.Errorf("Marshal = %s, want %s", data, tt.wantData)
+			}
+			got, err := tt.decode(codec, data)
+			if err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.value) {
+				t.Errorf("Unmarshal = %#v, want %#v", got, tt.value)
+			}
+		})
+	}
+}
+
+func TestJSONCodecErrors(t *testing.T) {
+	codec := JSONCodec{}
+	if _, err := codec.Marshal(make(chan int)); err == nil {
+		t.Error("Marshal succeeded with an unencodable value")
+	}
+	var v struct{ ID int }
+	if err := codec.Unmarshal([]byte(`{"ID":"not a number"}`), &v); err == nil {
+		t.Error("Unmarshal succeeded with a mismatched payload")
+	}
+	if got := codec.ContentType(); got != "application/json" {
+		t.Errorf("ContentType = %q, want application/json", got)
+	}
+}
+
+func TestContentType(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  Message
+		want string
+	}{
+		{"no headers", rawMessage{topic: "orders"}, ""},
+		{"no content type header", headeredMessage{rawMessage: rawMessage{topic: "orders"}}, ""},
+		{"content type header", headeredMessage{
+			rawMessage: rawMessage{topic: "orders"},
+			headers:    map[string]string{ContentTypeHeader: "application/x-protobuf"},
+		}, "application/x-protobuf"},
+		{"envelope", NewEnvelope("orders", nil, WithContentType("application/json")), "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ContentType(tt.msg); got != tt.want {
+				t.Errorf("ContentType = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
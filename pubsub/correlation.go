@@ -0,0 +1,70 @@
+package pubsub
+
+// CorrelationIDHeader is the message header carrying the ID shared by every message of a
+// workflow, typically the ID of the message that started it.
+const CorrelationIDHeader = "correlation-id"
+
+// CausationIDHeader is the message header carrying the ID of the message whose handling
+// caused this message to be published.
+const CausationIDHeader = "causation-id"
+
+// CorrelationID returns the correlation ID of msg as stamped in its CorrelationIDHeader, or
+// an empty string if the message carries no headers or no correlation ID.
+func CorrelationID(msg Message) string {
+	return header(msg, CorrelationIDHeader)
+}
+
+// CausationID returns the causation ID of msg as stamped in its CausationIDHeader, or an
+// empty string if the message carries no headers or no causation ID.
+func CausationID(msg Message) string {
+	return header(msg, CausationIDHeader)
+}
+
+// WithCorrelationID sets the correlation ID of the envelope.
+func WithCorrelationID(id string) EnvelopeOption {
+	return WithHeader(CorrelationIDHeader, id)
+}
+
+// WithCausationID sets the causation ID of the envelope.
+func WithCausationID(id string) EnvelopeOption {
+	return WithHeader(CausationIDHeader, id)
+}
+
+// DeriveFrom links the envelope to parent, the message being handled when it is published:
+// the correlation ID is copied from parent, or set to the parent's ID if parent starts the
+// workflow and has none, and the causation ID is set to the parent's ID. The parent's ID is
+// only known if it implements Identifier; otherwise only the correlation ID is copied.
+//
+//	func handle(ctx context.Context, msg pubsub.Message) error {
+//		return bus.PublishEnvelope(ctx, pubsub.NewEnvelope("invoices", invoice,
+//			pubsub.DeriveFrom(msg),
+//		))
+//	}
+func DeriveFrom(parent Message) EnvelopeOption {
+	var parentID string
+	if identifier, ok := parent.(Identifier); ok {
+		parentID = identifier.ID()
+	}
+	correlationID := CorrelationID(parent)
+	if correlationID == "" {
+		correlationID = parentID
+	}
+	return func(e *Envelope) {
+		if correlationID != "" {
+			e.headers[CorrelationIDHeader] = correlationID
+		}
+		if parentID != "" {
+			e.headers[CausationIDHeader] = parentID
+		}
+	}
+}
+
+// header returns the value of the given header of msg, or an empty string if msg carries no
+// headers or not this one.
+func header(msg Message, key string) string {
+	carrier, ok := msg.(HeaderCarrier)
+	if !ok {
+		return ""
+	}
+	return carrier.Headers()[key]
+}
@@ -0,0 +1,54 @@
+package pubsub
+
+import "testing"
+
+func TestDeriveFrom(t *testing.T) {
+	tests := []struct {
+		name            string
+		parent          Message
+		wantCorrelation string
+		wantCausation   string
+	}{
+		{"workflow start", NewEnvelope("orders", nil, WithID("m1")), "m1", "m1"},
+		{"inside a workflow", NewEnvelope("orders", nil, WithID("m2"), WithCorrelationID("m1")), "m1", "m2"},
+		{"without headers", idMessage{id: "m3"}, "m3", "m3"},
+		{"without ID", NewEnvelope("orders", nil, WithCorrelationID("m1")), "m1", ""},
+		{"raw message", rawMessage{topic: "orders"}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			child := NewEnvelope("invoices", nil, DeriveFrom(tt.parent))
+			if got := CorrelationID(child); got != tt.wantCorrelation {
+				t.Errorf("CorrelationID = %q, want %q", got, tt.wantCorrelation)
+			}
+			if got := CausationID(child); got != tt.wantCausation {
+				t.Errorf("CausationID = %q, want %q", got, tt.wantCausation)
+			}
+			if tt.wantCausation == "" {
+				if _, ok := child.Headers()[CausationIDHeader]; ok {
+					t.Error("empty causation header set")
+				}
+			}
+		})
+	}
+}
+
+func TestCorrelationHeaders(t *testing.T) {
+	e := NewEnvelope("orders", nil, WithCorrelationID("c1"), WithCausationID("p1"))
+	tests := []struct {
+		name string
+		msg  Message
+		want [2]string
+	}{
+		{"envelope", e, [2]string{"c1", "p1"}},
+		{"envelope methods", e, [2]string{e.CorrelationID(), e.CausationID()}},
+		{"without headers", rawMessage{}, [2]string{"", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := [2]string{CorrelationID(tt.msg), CausationID(tt.msg)}; got != tt.want {
+				t.Errorf("IDs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/cache"
+)
+
+// DedupKeyPrefix is the prefix of the cache keys used by Dedup to mark processed messages.
+const DedupKeyPrefix = "pubsub:dedup:"
+
+// DefaultDedupLockTTL is the default bound of how long Dedup holds the in-progress lock of
+// a message.
+const DefaultDedupLockTTL = time.Minute
+
+// DedupOption configures the Middleware returned by Dedup.
+type DedupOption func(*dedupConfig)
+
+// dedupConfig holds the settings of a Dedup middleware.
+type dedupConfig struct {
+	lockTTL time.Duration
+}
+
+// WithDedupLockTTL sets how long Dedup holds the in-progress lock of a message. It should
+// exceed the longest expected handler run: a message whose handler runs longer may be
+// processed again concurrently. It defaults to DefaultDedupLockTTL.
+func WithDedupLockTTL(d time.Duration) DedupOption {
+	return func(c *dedupConfig) {
+		c.lockTTL = d
+	}
+}
+
+// The values stored under a dedup key.
+const (
+	dedupInProgress = "processing"
+	dedupDone       = "done"
+)
+
+// Dedup returns a Middleware that skips messages whose ID was already processed within the
+// dedup window ttl, giving practical exactly-once processing on top of at-least-once
+// delivery.
+//
+// Before calling the next handler, the message ID is locked in c with SetNX for the lock TTL
+// set by WithDedupLockTTL. Once the handler succeeds, the lock is replaced by a processed
+// mark kept for ttl; if it fails, the lock is removed so a redelivery is processed again, and
+// if the process crashes, the lock expires. A duplicate of a processed message is skipped: nil is
+// returned so that it is acknowledged. A duplicate of a message still being processed is
+// rejected with ErrInProgress, so that it is redelivered rather than lost. Messages that do
+// not implement Identifier, or have an empty ID, are always processed.
+//
+//	handler := pubsub.Chain(handle, pubsub.Dedup(c, 24*time.Hour))
+func Dedup(c cache.Cache, ttl time.Duration, opts ...DedupOption) Middleware {
+	config := dedupConfig{lockTTL: DefaultDedupLockTTL}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, msg Message) error {
+			identifier, ok := msg.(Identifier)
+			if !ok || identifier.ID() == "" {
+				return next(ctx, msg)
+			}
+
+			key := DedupKeyPrefix + msg.Topic() + ":" + identifier.ID()
+			locked, err := c.SetNX(ctx, key, dedupInProgress, config.lockTTL)
+			if err != nil {
+				return err
+			}
+			if !locked {
+				state, err := c.Get(ctx, key)
+				if err != nil && !errors.Is(err, cache.ErrCacheNil) {
+					return err
+				}
+				if state == dedupDone {
+					return nil
+				}
+				return ErrInProgress
+			}
+			if err := next(ctx, msg); err != nil {
+				_ = c.Del(ctx, key)
+				return err
+			}
+			// The message was processed, so a failure to write the mark is not reported; the
+			// lock still rejects redeliveries until it expires.
+			_ = c.SetWithExpiration(ctx, key, dedupDone, ttl)
+			return nil
+		}
+	}
+}
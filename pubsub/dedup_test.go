@@ -0,0 +1,171 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// idMessage is a Message implementing Identifier.
+type idMessage struct {
+	rawMessage
+	id string
+}
+
+func (m idMessage) ID() string { return m.id }
+
+func TestDedup(t *testing.T) {
+	errHandler := errors.New("handler failed")
+	msg := func(topic, id string) Message {
+		return idMessage{rawMessage: rawMessage{topic: topic}, id: id}
+	}
+	tests := []struct {
+		name      string
+		messages  []Message
+		failFirst bool
+		wantCalls int
+	}{
+		{"duplicate skipped", []Message{msg("orders", "1"), msg("orders", "1")}, false, 1},
+		{"distinct IDs", []Message{msg("orders", "1"), msg("orders", "2")}, false, 2},
+		{"same ID on other topics", []Message{msg("orders", "1"), msg("refunds", "1")}, false, 2},
+		{"empty ID", []Message{msg("orders", ""), msg("orders", "")}, false, 2},
+		{"without Identifier", []Message{rawMessage{topic: "orders"}, rawMessage{topic: "orders"}}, false, 2},
+		{"redelivery after failure", []Message{msg("orders", "1"), msg("orders", "1"), msg("orders", "1")}, true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			calls := 0
+			handler := Chain(func(ctx context.Context, msg Message) error {
+				calls++
+				if tt.failFirst && calls == 1 {
+					return errHandler
+				}
+				return nil
+			}, Dedup(newMemCache(), time.Hour))
+
+			for i, m := range tt.messages {
+				err := handler(ctx, m)
+				if tt.failFirst && i == 0 {
+					if !errors.Is(err, errHandler) {
+						t.Fatalf("message %d: err = %v, want %v", i, err, errHandler)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("message %d: %v", i, err)
+				}
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("handler ran %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestDedupWindow(t *testing.T) {
+	ctx := context.Background()
+	c := newMemCache()
+	calls := 0
+	handler := Chain(func(ctx context.Context, msg Message) error {
+		calls++
+		return nil
+	}, Dedup(c, time.Hour))
+
+	msg := idMessage{rawMessage: rawMessage{topic: "orders"}, id: "1"}
+	if err := handler(ctx, msg); err != nil {
+		t.Fatal(err)
+	}
+	// Once the window has passed, the message is processed again.
+	c.advance(time.Hour)
+	if err := handler(ctx, msg); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2", calls)
+	}
+}
+
+func TestDedupCacheError(t *testing.T) {
+	c := newMemCache()
+	_ = c.Close()
+	handler := Chain(func(ctx context.Context, msg Message) error {
+		t.Error("handler ran despite the cache error")
+		return nil
+	}, Dedup(c, time.Hour))
+
+	msg := idMessage{rawMessage: rawMessage{topic: "orders"}, id: "1"}
+	if err := handler(context.Background(), msg); err == nil {
+		t.Error("handler succeeded with a closed cache")
+	}
+}
+
+func TestDedupInProgress(t *testing.T) {
+	ctx := context.Background()
+	started, release := make(chan struct{}), make(chan struct{})
+	calls := 0
+	handler := Chain(func(ctx context.Context, msg Message) error {
+		calls++
+		close(started)
+		<-release
+		return nil
+	}, Dedup(newMemCache(), time.Hour))
+
+	msg := idMessage{rawMessage: rawMessage{topic: "orders"}, id: "1"}
+	done := make(chan error, 1)
+	go func() { done <- handler(ctx, msg) }()
+	<-started
+
+	// The duplicate is rejected while the first delivery is being processed.
+	if err := handler(ctx, msg); !errors.Is(err, ErrInProgress) {
+		t.Errorf("duplicate in progress: err = %v, want %v", err, ErrInProgress)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	// Once processed, the duplicate is acknowledged.
+	if err := handler(ctx, msg); err != nil {
+		t.Errorf("duplicate after processing: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+}
+
+func TestDedupLockExpires(t *testing.T) {
+	const lockTTL = time.Minute
+	ctx := context.Background()
+	c := newMemCache()
+	// A previous consumer crashed while holding the lock.
+	if _, err := c.SetNX(ctx, DedupKeyPrefix+"orders:1", dedupInProgress, lockTTL); err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	handler := Chain(func(ctx context.Context, msg Message) error {
+		calls++
+		return nil
+	}, Dedup(c, time.Hour, WithDedupLockTTL(lockTTL)))
+
+	msg := idMessage{rawMessage: rawMessage{topic: "orders"}, id: "1"}
+	if err := handler(ctx, msg); !errors.Is(err, ErrInProgress) {
+		t.Fatalf("err = %v, want %v", err, ErrInProgress)
+	}
+	// The redelivery is processed once the lock has expired.
+	c.advance(lockTTL)
+	if err := handler(ctx, msg); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+	// The processed mark outlives the lock.
+	c.advance(lockTTL)
+	if err := handler(ctx, msg); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times after the lock TTL, want 1", calls)
+	}
+}
@@ -0,0 +1,129 @@
+package pubsub
+
+import "time"
+
+// Envelope is a ready-made Message value for in-memory buses, tests, and adapters.
+// Besides the topic and data it carries an ID, headers, a timestamp, and an optional
+// acknowledgement function, implementing Identifier, HeaderCarrier, Timestamper, and
+// Acknowledger.
+type Envelope struct {
+	topic     string
+	data      []byte
+	id        string
+	headers   map[string]string
+	timestamp time.Time
+	ack       func() error
+}
+
+var (
+	_ Message       = (*Envelope)(nil)
+	_ Identifier    = (*Envelope)(nil)
+	_ HeaderCarrier = (*Envelope)(nil)
+	_ Timestamper   = (*Envelope)(nil)
+	_ Acknowledger  = (*Envelope)(nil)
+)
+
+// EnvelopeOption configures an Envelope created with NewEnvelope.
+type EnvelopeOption func(e *Envelope)
+
+// WithID sets the ID of the envelope.
+func WithID(id string) EnvelopeOption {
+	return func(e *Envelope) {
+		e.id = id
+	}
+}
+
+// WithHeader sets a single header of the envelope.
+func WithHeader(key, value string) EnvelopeOption {
+	return func(e *Envelope) {
+		e.headers[key] = value
+	}
+}
+
+// WithHeaders sets several headers of the envelope. The map is copied.
+func WithHeaders(headers map[string]string) EnvelopeOption {
+	return func(e *Envelope) {
+		for key, value := range headers {
+			e.headers[key] = value
+		}
+	}
+}
+
+// WithTimestamp sets the timestamp of the envelope, which defaults to the creation time.
+func WithTimestamp(timestamp time.Time) EnvelopeOption {
+	return func(e *Envelope) {
+		e.timestamp = timestamp
+	}
+}
+
+// WithAck sets the function called by Ack, e.g. to acknowledge the underlying broker message.
+func WithAck(ack func() error) EnvelopeOption {
+	return func(e *Envelope) {
+		e.ack = ack
+	}
+}
+
+// NewEnvelope creates an Envelope for the given topic and data, configured by opts.
+//
+//	msg := pubsub.NewEnvelope("orders", payload,
+//		pubsub.WithID(id),
+//		pubsub.WithSchemaVersion(2),
+//	)
+func NewEnvelope(topic string, data []byte, opts ...EnvelopeOption) *Envelope {
+	e := &Envelope{
+		topic:     topic,
+		data:      data,
+		headers:   make(map[string]string),
+		timestamp: time.Now(),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Topic returns the topic of the envelope.
+func (e *Envelope) Topic() string {
+	return e.topic
+}
+
+// Data returns the payload of the envelope.
+func (e *Envelope) Data() []byte {
+	return e.data
+}
+
+// ID returns the ID of the envelope, or an empty string if none was set.
+func (e *Envelope) ID() string {
+	return e.id
+}
+
+// Headers returns the headers of the envelope. The returned map must not be modified.
+func (e *Envelope) Headers() map[string]string {
+	return e.headers
+}
+
+// CorrelationID returns the correlation ID header of the envelope, or an empty string if
+// none was set.
+func (e *Envelope) CorrelationID() string {
+	return e.headers[CorrelationIDHeader]
+}
+
+// CausationID returns the causation ID header of the envelope, or an empty string if none
+// was set.
+func (e *Envelope) CausationID() string {
+	return e.headers[CausationIDHeader]
+}
+
+// Timestamp returns the timestamp of the envelope.
+func (e *Envelope) Timestamp() time.Time {
+	return e.timestamp
+}
+
+// Ack calls the acknowledgement function of the envelope. It is a no-op returning nil
+// if none was set.
+func (e *Envelope) Ack() error {
+	if e.ack == nil {
+		return nil
+	}
+	return e.ack()
+}
@@ -0,0 +1,107 @@
+package pubsub
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewEnvelope(t *testing.T) {
+	timestamp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		opts        []EnvelopeOption
+		wantID      string
+		wantHeaders map[string]string
+		wantTime    time.Time
+	}{
+		{"defaults", nil, "", map[string]string{}, time.Time{}},
+		{"ID", []EnvelopeOption{WithID("42")}, "42", map[string]string{}, time.Time{}},
+		{"headers", []EnvelopeOption{
+			WithHeader("a", "1"),
+			WithHeaders(map[string]string{"b": "2", "c": "3"}),
+			WithHeader("c", "4"),
+		}, "", map[string]string{"a": "1", "b": "2", "c": "4"}, time.Time{}},
+		{"timestamp", []EnvelopeOption{WithTimestamp(timestamp)}, "", map[string]string{}, timestamp},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.Now()
+			e := NewEnvelope("orders", []byte("data"), tt.opts...)
+			if e.Topic() != "orders" || string(e.Data()) != "data" {
+				t.Errorf("envelope = %q %q, want orders data", e.Topic(), e.Data())
+			}
+			if e.ID() != tt.wantID {
+				t.Errorf("ID = %q, want %q", e.ID(), tt.wantID)
+			}
+			if len(e.Headers()) != len(tt.wantHeaders) {
+				t.Errorf("Headers = %v, want %v", e.Headers(), tt.wantHeaders)
+			}
+			for key, want := range tt.wantHeaders {
+				if got := e.Headers()[key]; got != want {
+					t.Errorf("header %q = %q, want %q", key, got, want)
+				}
+			}
+			if tt.wantTime.IsZero() {
+				if e.Timestamp().Before(before) || e.Timestamp().After(time.Now()) {
+					t.Errorf("Timestamp = %v, want the creation time", e.Timestamp())
+				}
+			} else if !e.Timestamp().Equal(tt.wantTime) {
+				t.Errorf("Timestamp = %v, want %v", e.Timestamp(), tt.wantTime)
+			}
+		})
+	}
+}
+
+func TestEnvelopeWithHeadersCopies(t *testing.T) {
+	headers := map[string]string{"a": "1"}
+	e := NewEnvelope("orders", nil, WithHeaders(headers))
+	headers["a"] = "changed"
+	if got := e.Headers()["a"]; got != "1" {
+		t.Errorf("header a = %q, want 1", got)
+	}
+}
+
+func TestEnvelopeWithSchemaVersion(t *testing.T) {
+	e := NewEnvelope("orders", nil, WithSchemaVersion(2))
+	if got := e.Headers()[SchemaVersionHeader]; got != "2" {
+		t.Errorf("header %q = %q, want 2", SchemaVersionHeader, got)
+	}
+	version, err := SchemaVersion(e)
+	if err != nil || version != 2 {
+		t.Errorf("SchemaVersion = %d, %v, want 2, nil", version, err)
+	}
+}
+
+func TestEnvelopeAck(t *testing.T) {
+	errAck := errors.New("ack failed")
+	tests := []struct {
+		name      string
+		ack       func() error
+		wantErr   error
+		wantCalls int
+	}{
+		{"without ack function", nil, nil, 0},
+		{"ack function", func() error { return nil }, nil, 1},
+		{"failing ack function", func() error { return errAck }, errAck, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			var opts []EnvelopeOption
+			if tt.ack != nil {
+				opts = append(opts, WithAck(func() error {
+					calls++
+					return tt.ack()
+				}))
+			}
+			var msg Message = NewEnvelope("orders", nil, opts...)
+			if err := msg.(Acknowledger).Ack(); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Ack: err = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("ack called %d times, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
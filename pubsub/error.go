@@ -8,3 +8,31 @@ import "errors"
 // This error indicates that the client could not establish a connection to the server
 // or broker, potentially due to network issues, incorrect credentials, or misconfigured endpoints.
 var ErrConnectFailed = errors.New("pubsub: connect is failed")
+
+// ErrClosed is returned when an operation is attempted on a publisher or subscriber that
+// has been closed.
+var ErrClosed = errors.New("pubsub: closed")
+
+// ErrInvalidSchemaVersion is returned when a message carries a schema version header
+// that is not a positive integer.
+var ErrInvalidSchemaVersion = errors.New("pubsub: invalid schema version")
+
+// ErrMigrationNotFound is returned when no chain of registered migrations leads from
+// a message's schema version to the requested target version.
+var ErrMigrationNotFound = errors.New("pubsub: migration not found")
+
+// ErrInProgress is returned by the Dedup middleware for a message whose ID is being processed
+// by another handler, so that it is redelivered later instead of being acknowledged.
+var ErrInProgress = errors.New("pubsub: message already in progress")
+
+// ErrDecodeFailed is returned when a message payload cannot be decoded into the expected type
+// by a Codec.
+var ErrDecodeFailed = errors.New("pubsub: decode failed")
+
+// ErrBufferedMessageLost is reported by a BufferingPublisher for a buffered Publish call whose
+// entry disappeared from the cache before it could be delivered, e.g. because it was evicted.
+var ErrBufferedMessageLost = errors.New("pubsub: buffered message lost")
+
+// ErrUnsupported is returned when an operation is not supported by the pub-sub backend,
+// such as PublishWithPriority on brokers without message priorities.
+var ErrUnsupported = errors.New("pubsub: unsupported operation")
@@ -0,0 +1,37 @@
+package pubsub
+
+import (
+	"context"
+	"time"
+)
+
+// DropExpired returns a Middleware that skips messages published more than maxAge ago, so
+// that a consumer catching up on a backlog does not act on outdated events.
+//
+// The age of a message is computed from its Timestamper timestamp; messages that do not
+// implement Timestamper, or have a zero timestamp, are always processed. Expired messages
+// are not passed to the next handler: they are acknowledged right away if they implement
+// Acknowledger, and nil is returned so that the subscriber acknowledges them otherwise.
+// If onDrop is not nil, it is called with every dropped message, e.g. to count drops.
+//
+//	handler := pubsub.Chain(handle, pubsub.DropExpired(5*time.Minute, func(msg pubsub.Message) {
+//		droppedTotal.WithLabelValues(msg.Topic()).Inc()
+//	}))
+func DropExpired(maxAge time.Duration, onDrop func(msg Message)) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, msg Message) error {
+			timestamper, ok := msg.(Timestamper)
+			if !ok || timestamper.Timestamp().IsZero() || time.Since(timestamper.Timestamp()) <= maxAge {
+				return next(ctx, msg)
+			}
+
+			if onDrop != nil {
+				onDrop(msg)
+			}
+			if acknowledger, ok := msg.(Acknowledger); ok {
+				return acknowledger.Ack()
+			}
+			return nil
+		}
+	}
+}
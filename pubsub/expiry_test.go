@@ -0,0 +1,78 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// timedMessage is a Message with a publication timestamp, recording its acknowledgement.
+type timedMessage struct {
+	rawMessage
+	published time.Time
+	ackErr    error
+	acked     bool
+}
+
+func (m *timedMessage) Timestamp() time.Time { return m.published }
+
+func (m *timedMessage) Ack() error {
+	m.acked = true
+	return m.ackErr
+}
+
+// untimedAckMessage is an acknowledgeable Message without a timestamp.
+type untimedAckMessage struct {
+	rawMessage
+}
+
+func (untimedAckMessage) Ack() error { return nil }
+
+func TestDropExpired(t *testing.T) {
+	errAck := errors.New("ack failed")
+	tests := []struct {
+		name        string
+		msg         Message
+		wantHandled bool
+		wantDropped bool
+		wantAcked   bool
+		wantErr     error
+	}{
+		{"recent", &timedMessage{published: time.Now().Add(-time.Second)}, true, false, false, nil},
+		{"expired", &timedMessage{published: time.Now().Add(-time.Hour)}, false, true, true, nil},
+		{"expired ack failure", &timedMessage{published: time.Now().Add(-time.Hour), ackErr: errAck}, false, true, true, errAck},
+		{"zero timestamp", &timedMessage{}, true, false, false, nil},
+		{"without timestamp", untimedAckMessage{}, true, false, false, nil},
+		{"raw message", rawMessage{topic: "orders"}, true, false, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handled, dropped := false, false
+			handler := Chain(func(ctx context.Context, msg Message) error {
+				handled = true
+				return nil
+			}, DropExpired(time.Minute, func(Message) { dropped = true }))
+
+			if err := handler(context.Background(), tt.msg); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if handled != tt.wantHandled || dropped != tt.wantDropped {
+				t.Errorf("handled = %v, dropped = %v, want %v, %v", handled, dropped, tt.wantHandled, tt.wantDropped)
+			}
+			if msg, ok := tt.msg.(*timedMessage); ok && msg.acked != tt.wantAcked {
+				t.Errorf("acked = %v, want %v", msg.acked, tt.wantAcked)
+			}
+		})
+	}
+}
+
+func TestDropExpiredWithoutCallback(t *testing.T) {
+	handler := DropExpired(time.Minute, nil)(func(ctx context.Context, msg Message) error {
+		t.Error("expired message handled")
+		return nil
+	})
+	if err := handler(context.Background(), &timedMessage{published: time.Now().Add(-time.Hour)}); err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+}
@@ -0,0 +1,30 @@
+package pubsub
+
+import "context"
+
+// HandlerFunc processes a single received message. Returning nil reports the message as
+// successfully processed; returning an error reports a failure, which consumers may use
+// to retry or dead-letter the message.
+type HandlerFunc func(ctx context.Context, msg Message) error
+
+// Middleware wraps a HandlerFunc to add behavior around message processing, such as
+// deduplication, logging, or metrics.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Chain wraps handler with the given middlewares. The first middleware is the outermost
+// one, so it sees each message first.
+//
+//	handler := pubsub.Chain(handle, pubsub.Dedup(c, time.Hour))
+func Chain(handler HandlerFunc, middlewares ...Middleware) HandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// Identifier is an optional interface implemented by messages that carry a unique ID
+// assigned by the publisher or the broker.
+type Identifier interface {
+	// ID returns the unique identifier of the message.
+	ID() string
+}
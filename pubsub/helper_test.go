@@ -0,0 +1,237 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/cache"
+)
+
+// rawMessage is a Message implementing none of the optional interfaces.
+type rawMessage struct {
+	topic string
+	data  []byte
+}
+
+func (m rawMessage) Topic() string { return m.topic }
+func (m rawMessage) Data() []byte  { return m.data }
+
+// headeredMessage is a rawMessage carrying headers.
+type headeredMessage struct {
+	rawMessage
+	headers map[string]string
+}
+
+func (m headeredMessage) Headers() map[string]string { return m.headers }
+
+// errBrokerDown is the error returned by a fakePublisher while it is down.
+var errBrokerDown = errors.New("broker down")
+
+// fakePublisher is a Publisher recording the published messages. While down, every publish
+// fails with errBrokerDown; with noPriority, PublishWithPriority returns ErrUnsupported.
+type fakePublisher struct {
+	mu         sync.Mutex
+	down       bool
+	noPriority bool
+	published  []string
+	closed     bool
+}
+
+var _ Publisher = (*fakePublisher)(nil)
+
+func (p *fakePublisher) Publish(ctx context.Context, topic string, messages ...[]byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.down {
+		return errBrokerDown
+	}
+	for _, msg := range messages {
+		p.published = append(p.published, topic+":"+string(msg))
+	}
+	return nil
+}
+
+func (p *fakePublisher) PublishWithPriority(ctx context.Context, topic string, priority uint8, msg []byte) error {
+	p.mu.Lock()
+	noPriority := p.noPriority
+	p.mu.Unlock()
+	if noPriority {
+		return ErrUnsupported
+	}
+	return p.Publish(ctx, topic, msg)
+}
+
+func (p *fakePublisher) IsConnected(ctx context.Context) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return !p.down
+}
+
+func (p *fakePublisher) CheckConnection(ctx context.Context) error {
+	if !p.IsConnected(ctx) {
+		return errBrokerDown
+	}
+	return nil
+}
+
+func (p *fakePublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+// setDown simulates an outage (true) or a recovery (false) of the broker.
+func (p *fakePublisher) setDown(down bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.down = down
+}
+
+// messages returns a copy of the published messages, formatted as "topic:data".
+func (p *fakePublisher) messages() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.published...)
+}
+
+// chanSubscriber is a Subscriber whose receiver is ch, recording the subscribed topics.
+// Receiver fails with err if set.
+type chanSubscriber struct {
+	ch     chan Message
+	err    error
+	topics []string
+}
+
+var _ Subscriber = (*chanSubscriber)(nil)
+
+func (s *chanSubscriber) Subscribe(ctx context.Context, topics ...string) error {
+	s.topics = append(s.topics, topics...)
+	return nil
+}
+
+func (s *chanSubscriber) Unsubscribe(ctx context.Context, topics ...string) error { return nil }
+func (s *chanSubscriber) IsConnected(ctx context.Context) bool                    { return true }
+func (s *chanSubscriber) CheckConnection(ctx context.Context) error               { return nil }
+func (s *chanSubscriber) Close() error                                            { return nil }
+
+func (s *chanSubscriber) Receiver(ctx context.Context) (<-chan Message, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.ch, nil
+}
+
+// errCacheClosed is the error returned by a closed memCache.
+var errCacheClosed = errors.New("cache closed")
+
+// memCache is a map-backed cache.Cache honoring expirations, used by the tests of the
+// package. The methods it does not implement panic through the nil embedded Cache.
+type memCache struct {
+	cache.Cache
+
+	mu      sync.Mutex
+	closed  bool
+	values  map[string]string
+	expires map[string]time.Time
+	// skew is added to the wall clock, so that tests can expire keys without sleeping.
+	skew time.Duration
+}
+
+func newMemCache() *memCache {
+	return &memCache{values: make(map[string]string), expires: make(map[string]time.Time)}
+}
+
+// advance moves the clock of the cache forward by d.
+func (c *memCache) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.skew += d
+}
+
+// now returns the current time of the cache. c.mu must be held.
+func (c *memCache) now() time.Time {
+	return time.Now().Add(c.skew)
+}
+
+// lookup returns the live value of key. c.mu must be held.
+func (c *memCache) lookup(key string) (string, bool) {
+	if expires, ok := c.expires[key]; ok && !c.now().Before(expires) {
+		delete(c.values, key)
+		delete(c.expires, key)
+	}
+	value, ok := c.values[key]
+	return value, ok
+}
+
+// store sets key to value with the given expiration. c.mu must be held.
+func (c *memCache) store(key string, value interface{}, expiration time.Duration) {
+	c.values[key] = fmt.Sprint(value)
+	delete(c.expires, key)
+	if expiration > 0 {
+		c.expires[key] = c.now().Add(expiration)
+	}
+}
+
+func (c *memCache) Get(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return "", errCacheClosed
+	}
+	value, ok := c.lookup(key)
+	if !ok {
+		return "", cache.ErrCacheNil
+	}
+	return value, nil
+}
+
+func (c *memCache) Set(ctx context.Context, key string, value interface{}) error {
+	return c.SetWithExpiration(ctx, key, value, 0)
+}
+
+func (c *memCache) SetWithExpiration(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return errCacheClosed
+	}
+	c.store(key, value, expiration)
+	return nil
+}
+
+func (c *memCache) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false, errCacheClosed
+	}
+	if _, ok := c.lookup(key); ok {
+		return false, nil
+	}
+	c.store(key, value, expiration)
+	return true, nil
+}
+
+func (c *memCache) Del(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return errCacheClosed
+	}
+	for _, key := range keys {
+		delete(c.values, key)
+		delete(c.expires, key)
+	}
+	return nil
+}
+
+func (c *memCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
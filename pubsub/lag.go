@@ -0,0 +1,17 @@
+package pubsub
+
+import "context"
+
+// LagReporter is an optional interface implemented by subscribers backed by stream systems
+// (e.g. Redis Streams or Kafka) that can report how far the consumer is behind the producer.
+// Callers can detect support with a type assertion:
+//
+//	if reporter, ok := subscriber.(pubsub.LagReporter); ok {
+//		lag, err := reporter.Lag(ctx)
+//	}
+type LagReporter interface {
+	// Lag returns the number of messages that have been published but not yet
+	// consumed by this subscriber. It returns 0 when the consumer is caught up,
+	// and an error if the backend could not be queried.
+	Lag(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,295 @@
+// Package memory provides an in-process pub-sub bus implementing pubsub.Publisher and
+// pubsub.Subscriber. It is intended for single-instance deployments, local development, and
+// tests where running a broker is not practical.
+package memory
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/pubsub"
+)
+
+// Bus is an in-process pub-sub bus. Messages published to a topic are delivered to every
+// Subscriber of the bus subscribed to it at publish time. The Bus itself is the Publisher.
+// Messages are received as *pubsub.Envelope values, numbered by the bus in publish order.
+//
+// Each subscriber queues its pending messages by priority: messages published with
+// PublishWithPriority are received ahead of pending messages of lower priority, and Publish
+// uses priority 0. Messages of equal priority are received in publish order. Bus is safe for
+// concurrent use.
+//
+// Each subscriber holds a bounded number of unconsumed messages, set with
+// WithReceiverBufferSize. Publishing to a subscriber whose buffer is full blocks until its
+// consumer receives a message, the subscriber is closed, or the publish context is done, so
+// a slow consumer applies backpressure to the publishers instead of messages being dropped.
+//
+// For testing resilience logic such as retries and circuit breakers, failures can be
+// injected into the publishing side with SetPublishError, SetConnected, and SetPublishDelay.
+// The connection state transitions caused by SetConnected and Close are reported to the
+// OnStateChange callback.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[*Subscriber]struct{}
+	seq         uint64
+	closed      bool
+
+	publishErr    error
+	disconnected  bool
+	publishDelay  time.Duration
+	onStateChange func(connected bool)
+}
+
+var (
+	_ pubsub.Publisher         = (*Bus)(nil)
+	_ pubsub.EnvelopePublisher = (*Bus)(nil)
+	_ pubsub.Notifier          = (*Bus)(nil)
+)
+
+// NewBus creates an empty Bus.
+//
+//	bus := memory.NewBus()
+//	sub := bus.NewSubscriber()
+//	err := sub.Subscribe(ctx, "orders")
+//	err = bus.Publish(ctx, "orders", payload)
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[*Subscriber]struct{})}
+}
+
+// NewSubscriber creates a Subscriber receiving messages from the bus, configured by opts.
+//
+//	sub := bus.NewSubscriber(memory.WithReceiverBufferSize(64))
+func (b *Bus) NewSubscriber(opts ...SubscriberOption) *Subscriber {
+	s := &Subscriber{
+		bus:        b,
+		bufferSize: DefaultReceiverBufferSize,
+		topics:     make(map[string]struct{}),
+		receiver:   make(chan pubsub.Message),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.slots = make(chan struct{}, s.bufferSize)
+	s.cond = sync.NewCond(&s.mu)
+
+	b.mu.Lock()
+	b.subscribers[s] = struct{}{}
+	b.mu.Unlock()
+	go s.dispatch()
+	return s
+}
+
+// SetPublishError makes every following publish fail with err, without delivering the
+// messages, until it is called again with nil.
+func (b *Bus) SetPublishError(err error) {
+	b.mu.Lock()
+	b.publishErr = err
+	b.mu.Unlock()
+}
+
+// OnStateChange registers a callback invoked when the bus is disconnected or reconnected by
+// SetConnected, and with false when a connected bus is closed. It implements pubsub.Notifier.
+func (b *Bus) OnStateChange(fn func(connected bool)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onStateChange = fn
+}
+
+// SetConnected simulates a lost (false) or restored (true) broker connection. While
+// disconnected, IsConnected reports false, and CheckConnection and every publish fail with
+// pubsub.ErrConnectFailed. Subscribers keep receiving the messages already published.
+func (b *Bus) SetConnected(connected bool) {
+	b.mu.Lock()
+	changed := !b.closed && b.disconnected == connected
+	b.disconnected = !connected
+	onStateChange := b.onStateChange
+	b.mu.Unlock()
+	if changed && onStateChange != nil {
+		onStateChange(connected)
+	}
+}
+
+// SetPublishDelay makes every following publish wait d before being attempted, or until its
+// context is done, in which case it fails with the context error. A d of 0 removes the delay.
+func (b *Bus) SetPublishDelay(d time.Duration) {
+	b.mu.Lock()
+	b.publishDelay = d
+	b.mu.Unlock()
+}
+
+// Publish delivers messages to the subscribers of topic with priority 0.
+func (b *Bus) Publish(ctx context.Context, topic string, messages ...[]byte) error {
+	if err := b.injectFailure(ctx); err != nil {
+		return err
+	}
+	return b.publish(ctx, topic, 0, messages)
+}
+
+// PublishWithPriority delivers msg to the subscribers of topic with the given priority, which
+// is also set in the pubsub.PriorityHeader header of the message.
+func (b *Bus) PublishWithPriority(ctx context.Context, topic string, priority uint8, msg []byte) error {
+	if err := b.injectFailure(ctx); err != nil {
+		return err
+	}
+	return b.publish(ctx, topic, priority, [][]byte{msg})
+}
+
+// PublishEnvelope delivers msg to the subscribers of its topic, keeping its headers (e.g. the
+// correlation and causation IDs set by pubsub.DeriveFrom, or the content type stamped by
+// pubsub.TypedPublisher) and timestamp. The bus assigns an ID to the message if it has none.
+// The priority is read from the pubsub.PriorityHeader header and defaults to 0.
+//
+//	err := bus.PublishEnvelope(ctx, pubsub.NewEnvelope("invoices", payload, pubsub.DeriveFrom(msg)))
+func (b *Bus) PublishEnvelope(ctx context.Context, msg *pubsub.Envelope) error {
+	if err := b.injectFailure(ctx); err != nil {
+		return err
+	}
+	priority, err := strconv.ParseUint(msg.Headers()[pubsub.PriorityHeader], 10, 8)
+	if err != nil {
+		priority = 0
+	}
+	seq, subscribers, err := b.next()
+	if err != nil {
+		return err
+	}
+	id := msg.ID()
+	if id == "" {
+		id = strconv.FormatUint(seq, 10)
+	}
+	return deliver(ctx, subscribers, &pending{
+		msg: pubsub.NewEnvelope(msg.Topic(), msg.Data(),
+			pubsub.WithID(id),
+			pubsub.WithTimestamp(msg.Timestamp()),
+			pubsub.WithHeaders(msg.Headers()),
+			pubsub.WithHeader(pubsub.PriorityHeader, strconv.FormatUint(priority, 10)),
+		),
+		priority: uint8(priority),
+		seq:      seq,
+	})
+}
+
+// injectFailure applies the injected publish delay and returns the injected error, if any.
+func (b *Bus) injectFailure(ctx context.Context) error {
+	b.mu.RLock()
+	delay, disconnected, err := b.publishDelay, b.disconnected, b.publishErr
+	b.mu.RUnlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if disconnected {
+		return pubsub.ErrConnectFailed
+	}
+	return err
+}
+
+// publish enqueues messages for every subscriber of topic.
+func (b *Bus) publish(ctx context.Context, topic string, priority uint8, messages [][]byte) error {
+	now := time.Now()
+	for _, data := range messages {
+		seq, subscribers, err := b.next()
+		if err != nil {
+			return err
+		}
+		err = deliver(ctx, subscribers, &pending{
+			msg: pubsub.NewEnvelope(topic, data,
+				pubsub.WithID(strconv.FormatUint(seq, 10)),
+				pubsub.WithTimestamp(now),
+				pubsub.WithHeader(pubsub.PriorityHeader, strconv.Itoa(int(priority))),
+			),
+			priority: priority,
+			seq:      seq,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// next numbers the next published message and returns its number with the current
+// subscribers. It returns pubsub.ErrClosed if the bus has been closed.
+func (b *Bus) next() (uint64, []*Subscriber, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return 0, nil, pubsub.ErrClosed
+	}
+	b.seq++
+	subscribers := make([]*Subscriber, 0, len(b.subscribers))
+	for s := range b.subscribers {
+		subscribers = append(subscribers, s)
+	}
+	return b.seq, subscribers, nil
+}
+
+// deliver enqueues p for every subscriber of its topic, waiting for room in their buffers.
+// If ctx is done while waiting, the message may have reached only some of the subscribers.
+func deliver(ctx context.Context, subscribers []*Subscriber, p *pending) error {
+	for _, s := range subscribers {
+		if err := s.enqueue(ctx, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsConnected reports whether the bus has not been closed nor disconnected by SetConnected.
+func (b *Bus) IsConnected(ctx context.Context) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return !b.closed && !b.disconnected
+}
+
+// CheckConnection returns pubsub.ErrClosed if the bus has been closed, and
+// pubsub.ErrConnectFailed if it was disconnected by SetConnected.
+func (b *Bus) CheckConnection(ctx context.Context) error {
+	b.mu.RLock()
+	closed, disconnected := b.closed, b.disconnected
+	b.mu.RUnlock()
+	if closed {
+		return pubsub.ErrClosed
+	}
+	if disconnected {
+		return pubsub.ErrConnectFailed
+	}
+	return ctx.Err()
+}
+
+// Close stops accepting messages and closes every subscriber of the bus.
+func (b *Bus) Close() error {
+	b.mu.Lock()
+	changed := !b.closed && !b.disconnected
+	b.closed = true
+	subscribers := make([]*Subscriber, 0, len(b.subscribers))
+	for s := range b.subscribers {
+		subscribers = append(subscribers, s)
+	}
+	onStateChange := b.onStateChange
+	b.mu.Unlock()
+
+	for _, s := range subscribers {
+		_ = s.Close()
+	}
+	if changed && onStateChange != nil {
+		onStateChange(false)
+	}
+	return nil
+}
+
+// remove unregisters s from the bus.
+func (b *Bus) remove(s *Subscriber) {
+	b.mu.Lock()
+	delete(b.subscribers, s)
+	b.mu.Unlock()
+}
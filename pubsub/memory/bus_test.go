@@ -0,0 +1,263 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/pubsub"
+)
+
+// receive returns the payload of the next message of ch, failing the test after a second.
+func receive(t *testing.T, ch <-chan pubsub.Message) string {
+	t.Helper()
+	select {
+	case msg, ok := <-ch:
+		if !ok {
+			t.Fatal("receiver closed")
+		}
+		return string(msg.Data())
+	case <-time.After(time.Second):
+		t.Fatal("no message received")
+		return ""
+	}
+}
+
+// waitQueued waits until n messages are queued in s behind the one offered on its receiver.
+func waitQueued(t *testing.T, s *Subscriber, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		s.mu.Lock()
+		queued := len(s.queue)
+		s.mu.Unlock()
+		if queued == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d queued messages, want %d", queued, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// newTestSubscriber creates a subscriber of bus subscribed to topics and returns its receiver.
+func newTestSubscriber(t *testing.T, bus *Bus, topics []string, opts ...SubscriberOption) (*Subscriber, <-chan pubsub.Message) {
+	t.Helper()
+	ctx := context.Background()
+	s := bus.NewSubscriber(opts...)
+	t.Cleanup(func() { _ = s.Close() })
+	if err := s.Subscribe(ctx, topics...); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	ch, err := s.Receiver(ctx)
+	if err != nil {
+		t.Fatalf("Receiver: %v", err)
+	}
+	return s, ch
+}
+
+func TestBusPublish(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus()
+	defer bus.Close()
+	_, orders := newTestSubscriber(t, bus, []string{"orders"})
+	_, all := newTestSubscriber(t, bus, []string{"orders", "invoices"})
+
+	if err := bus.Publish(ctx, "orders", []byte("o1"), []byte("o2")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if err := bus.Publish(ctx, "invoices", []byte("i1")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if err := bus.Publish(ctx, "orders", []byte("o3")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		ch   <-chan pubsub.Message
+		want []string
+	}{
+		{"orders subscriber", orders, []string{"o1", "o2", "o3"}},
+		{"orders and invoices subscriber", all, []string{"o1", "o2", "i1", "o3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, w := range tt.want {
+				if got := receive(t, tt.ch); got != w {
+					t.Fatalf("message %d: got %q, want %q", i, got, w)
+				}
+			}
+		})
+	}
+}
+
+func TestBusUnsubscribe(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus()
+	defer bus.Close()
+	s, ch := newTestSubscriber(t, bus, []string{"orders", "invoices"})
+
+	if err := s.Unsubscribe(ctx, "orders"); err != nil {
+		t.Fatalf("Unsubscribe: %v", err)
+	}
+	if err := bus.Publish(ctx, "orders", []byte("ignored")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if err := bus.Publish(ctx, "invoices", []byte("i1")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if got := receive(t, ch); got != "i1" {
+		t.Fatalf("got %q, want %q", got, "i1")
+	}
+}
+
+func TestBusPriority(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus()
+	defer bus.Close()
+	s, ch := newTestSubscriber(t, bus, []string{"orders"})
+
+	// The first message is offered on the receiver right away; the others queue behind it.
+	if err := bus.Publish(ctx, "orders", []byte("first")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	waitQueued(t, s, 0)
+
+	publishes := []struct {
+		priority uint8
+		data     string
+	}{
+		{0, "low-1"},
+		{0, "low-2"},
+		{9, "cancel"},
+		{5, "update"},
+		{0, "low-3"},
+		{9, "cancel-2"},
+	}
+	for _, p := range publishes {
+		if err := bus.PublishWithPriority(ctx, "orders", p.priority, []byte(p.data)); err != nil {
+			t.Fatalf("PublishWithPriority: %v", err)
+		}
+	}
+	if err := bus.Publish(ctx, "other", []byte("ignored")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	waitQueued(t, s, len(publishes))
+
+	want := []string{"first", "cancel", "cancel-2", "update", "low-1", "low-2", "low-3"}
+	for i, w := range want {
+		if got := receive(t, ch); got != w {
+			t.Fatalf("message %d: got %q, want %q", i, got, w)
+		}
+	}
+}
+
+func TestBusPriorityHeader(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus()
+	defer bus.Close()
+	_, ch := newTestSubscriber(t, bus, []string{"orders"})
+
+	if err := bus.PublishWithPriority(ctx, "orders", 7, []byte("x")); err != nil {
+		t.Fatalf("PublishWithPriority: %v", err)
+	}
+	msg := <-ch
+	if got := msg.(pubsub.HeaderCarrier).Headers()[pubsub.PriorityHeader]; got != "7" {
+		t.Fatalf("priority header: got %q, want %q", got, "7")
+	}
+}
+
+func TestBusPublishEnvelope(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus()
+	defer bus.Close()
+	_, ch := newTestSubscriber(t, bus, []string{"orders"})
+	timestamp := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		msg    *pubsub.Envelope
+		wantID string
+	}{
+		{"assigned ID", pubsub.NewEnvelope("orders", []byte("p"), pubsub.WithHeader("a", "1"), pubsub.WithTimestamp(timestamp)), "1"},
+		{"own ID", pubsub.NewEnvelope("orders", []byte("p"), pubsub.WithID("o1"), pubsub.WithHeader("a", "1"), pubsub.WithTimestamp(timestamp)), "o1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := bus.PublishEnvelope(ctx, tt.msg); err != nil {
+				t.Fatalf("PublishEnvelope: %v", err)
+			}
+			msg := (<-ch).(*pubsub.Envelope)
+			if msg.ID() != tt.wantID {
+				t.Errorf("ID = %q, want %q", msg.ID(), tt.wantID)
+			}
+			if got := msg.Headers()["a"]; got != "1" {
+				t.Errorf("header a = %q, want 1", got)
+			}
+			if !msg.Timestamp().Equal(timestamp) {
+				t.Errorf("Timestamp = %v, want %v", msg.Timestamp(), timestamp)
+			}
+		})
+	}
+}
+
+func TestBusTypedPublisherContentType(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus()
+	defer bus.Close()
+	_, ch := newTestSubscriber(t, bus, []string{"orders"})
+
+	if err := pubsub.NewTypedPublisher[string](bus, nil).Publish(ctx, "orders", "o1"); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	msg := <-ch
+	if got := pubsub.ContentType(msg); got != "application/json" {
+		t.Fatalf("content type: got %q, want %q", got, "application/json")
+	}
+	if got := string(msg.Data()); got != `"o1"` {
+		t.Fatalf("got %s, want %q", got, `"o1"`)
+	}
+}
+
+func TestBusSchemaVersion(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus()
+	defer bus.Close()
+	_, ch := newTestSubscriber(t, bus, []string{"orders"})
+
+	migrator := pubsub.NewMigrator()
+	migrator.RegisterMigration(1, 2, func(data []byte) ([]byte, error) { return append(data, "+v2"...), nil })
+	migrator.RegisterMigration(2, 3, func(data []byte) ([]byte, error) { return append(data, "+v3"...), nil })
+
+	if err := bus.PublishEnvelope(ctx, pubsub.NewEnvelope("orders", []byte("p"), pubsub.WithSchemaVersion(1))); err != nil {
+		t.Fatalf("PublishEnvelope: %v", err)
+	}
+	data, err := migrator.UpgradeTo(3, <-ch)
+	if err != nil {
+		t.Fatalf("UpgradeTo: %v", err)
+	}
+	if got := string(data); got != "p+v2+v3" {
+		t.Fatalf("got %q, want %q", got, "p+v2+v3")
+	}
+}
+
+func TestBusClose(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus()
+	s, ch := newTestSubscriber(t, bus, []string{"orders"})
+
+	if err := bus.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, ok := <-ch; ok {
+		t.Fatal("receiver still open after Close")
+	}
+	if err := bus.Publish(ctx, "orders", []byte("x")); err != pubsub.ErrClosed {
+		t.Fatalf("Publish after Close: got %v, want ErrClosed", err)
+	}
+	if err := s.Subscribe(ctx, "orders"); err != pubsub.ErrClosed {
+		t.Fatalf("Subscribe after Close: got %v, want ErrClosed", err)
+	}
+}
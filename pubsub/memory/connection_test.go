@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zeroxsolutions/barbatos/pubsub"
+)
+
+func TestCheckConnection(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		setup   func(bus *Bus, s *Subscriber)
+		wantBus error
+		wantSub error
+	}{
+		{"connected", context.Background(), func(*Bus, *Subscriber) {}, nil, nil},
+		{"disconnected", context.Background(), func(bus *Bus, _ *Subscriber) { bus.SetConnected(false) }, pubsub.ErrConnectFailed, nil},
+		{"subscriber closed", context.Background(), func(_ *Bus, s *Subscriber) { _ = s.Close() }, nil, pubsub.ErrClosed},
+		{"bus closed", context.Background(), func(bus *Bus, _ *Subscriber) { _ = bus.Close() }, pubsub.ErrClosed, pubsub.ErrClosed},
+		{"context cancelled", cancelled, func(*Bus, *Subscriber) {}, context.Canceled, context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := NewBus()
+			defer bus.Close()
+			s := bus.NewSubscriber()
+			defer s.Close()
+			tt.setup(bus, s)
+
+			if err := bus.CheckConnection(tt.ctx); !errors.Is(err, tt.wantBus) {
+				t.Errorf("Bus.CheckConnection: err = %v, want %v", err, tt.wantBus)
+			}
+			if err := s.CheckConnection(tt.ctx); !errors.Is(err, tt.wantSub) {
+				t.Errorf("Subscriber.CheckConnection: err = %v, want %v", err, tt.wantSub)
+			}
+		})
+	}
+}
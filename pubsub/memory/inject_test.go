@@ -0,0 +1,194 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cachememory "github.com/zeroxsolutions/barbatos/cache/memory"
+	"github.com/zeroxsolutions/barbatos/pubsub"
+)
+
+var errInjected = errors.New("injected failure")
+
+func TestBusInjectedFailures(t *testing.T) {
+	tests := []struct {
+		name      string
+		inject    func(b *Bus)
+		clear     func(b *Bus)
+		timeout   time.Duration
+		wantErr   error
+		connected bool
+	}{
+		{
+			name:      "publish error",
+			inject:    func(b *Bus) { b.SetPublishError(errInjected) },
+			clear:     func(b *Bus) { b.SetPublishError(nil) },
+			wantErr:   errInjected,
+			connected: true,
+		},
+		{
+			name:    "disconnected",
+			inject:  func(b *Bus) { b.SetConnected(false) },
+			clear:   func(b *Bus) { b.SetConnected(true) },
+			wantErr: pubsub.ErrConnectFailed,
+		},
+		{
+			name:      "delay beyond deadline",
+			inject:    func(b *Bus) { b.SetPublishDelay(time.Second) },
+			clear:     func(b *Bus) { b.SetPublishDelay(0) },
+			timeout:   10 * time.Millisecond,
+			wantErr:   context.DeadlineExceeded,
+			connected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := NewBus()
+			defer bus.Close()
+			_, ch := newTestSubscriber(t, bus, []string{"orders"})
+
+			tt.inject(bus)
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			publishers := map[string]func() error{
+				"Publish": func() error { return bus.Publish(ctx, "orders", []byte("lost")) },
+				"PublishWithPriority": func() error {
+					return bus.PublishWithPriority(ctx, "orders", 1, []byte("lost"))
+				},
+				"PublishEnvelope": func() error {
+					return bus.PublishEnvelope(ctx, pubsub.NewEnvelope("orders", []byte("lost")))
+				},
+			}
+			for name, publish := range publishers {
+				if err := publish(); !errors.Is(err, tt.wantErr) {
+					t.Errorf("%s: err = %v, want %v", name, err, tt.wantErr)
+				}
+			}
+			if got := bus.IsConnected(context.Background()); got != tt.connected {
+				t.Errorf("IsConnected = %v, want %v", got, tt.connected)
+			}
+
+			tt.clear(bus)
+			if err := bus.Publish(context.Background(), "orders", []byte("delivered")); err != nil {
+				t.Fatalf("Publish after clearing: %v", err)
+			}
+			// Failed publishes delivered nothing.
+			if got := receive(t, ch); got != "delivered" {
+				t.Errorf("received %q, want delivered", got)
+			}
+		})
+	}
+}
+
+func TestBusPublishDelay(t *testing.T) {
+	bus := NewBus()
+	defer bus.Close()
+	_, ch := newTestSubscriber(t, bus, []string{"orders"})
+
+	const delay = 30 * time.Millisecond
+	bus.SetPublishDelay(delay)
+	start := time.Now()
+	if err := bus.Publish(context.Background(), "orders", []byte("slow")); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < delay {
+		t.Errorf("Publish returned after %v, want at least %v", elapsed, delay)
+	}
+	if got := receive(t, ch); got != "slow" {
+		t.Errorf("received %q, want slow", got)
+	}
+}
+
+// newTestBufferingPublisher creates a BufferingPublisher delivering to bus and flushing every
+// interval.
+func newTestBufferingPublisher(t *testing.T, bus *Bus, interval time.Duration) *pubsub.BufferingPublisher {
+	t.Helper()
+	pub, err := pubsub.NewBufferingPublisher(context.Background(), bus, cachememory.NewLRU(0), "test", interval)
+	if err != nil {
+		t.Fatalf("NewBufferingPublisher: %v", err)
+	}
+	t.Cleanup(func() { _ = pub.Close() })
+	return pub
+}
+
+func TestBufferingPublisherOverBus(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus()
+	_, ch := newTestSubscriber(t, bus, []string{"orders"})
+	pub := newTestBufferingPublisher(t, bus, time.Hour)
+
+	steps := []struct {
+		name        string
+		do          func() error
+		wantPending int
+	}{
+		{"connected", func() error { return pub.Publish(ctx, "orders", []byte("1")) }, 0},
+		{"outage", func() error { bus.SetConnected(false); return nil }, 0},
+		{"buffered during outage", func() error { return pub.Publish(ctx, "orders", []byte("2"), []byte("3")) }, 1},
+		// Priority 0 is the priority of Publish, so the bus keeps the publish order.
+		{"priority buffered during outage", func() error { return pub.PublishWithPriority(ctx, "orders", 0, []byte("4")) }, 2},
+		{"reconnect with failing publishes", func() error {
+			bus.SetConnected(true)
+			bus.SetPublishError(errInjected)
+			return nil
+		}, 2},
+		{"flush fails", func() error {
+			if err := pub.Flush(ctx); !errors.Is(err, errInjected) {
+				t.Errorf("Flush: err = %v, want %v", err, errInjected)
+			}
+			return nil
+		}, 2},
+		{"publish behind buffered messages", func() error {
+			bus.SetPublishError(nil)
+			return pub.Publish(ctx, "orders", []byte("5"))
+		}, 3},
+		{"flush", func() error { return pub.Flush(ctx) }, 0},
+	}
+	for _, step := range steps {
+		if err := step.do(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if got := pub.Pending(); got != step.wantPending {
+			t.Fatalf("%s: Pending = %d, want %d", step.name, got, step.wantPending)
+		}
+	}
+
+	// Messages are received in publish order despite the outage.
+	for _, want := range []string{"1", "2", "3", "4", "5"} {
+		if got := receive(t, ch); got != want {
+			t.Fatalf("received %q, want %q", got, want)
+		}
+	}
+}
+
+func TestBufferingPublisherOverBusBackgroundFlush(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus()
+	_, ch := newTestSubscriber(t, bus, []string{"orders"})
+	pub := newTestBufferingPublisher(t, bus, 5*time.Millisecond)
+
+	bus.SetConnected(false)
+	for _, msg := range []string{"a", "b", "c"} {
+		if err := pub.Publish(ctx, "orders", []byte(msg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The flusher does not retry while the bus is disconnected.
+	time.Sleep(20 * time.Millisecond)
+	if got := pub.Pending(); got != 3 {
+		t.Fatalf("Pending = %d, want 3", got)
+	}
+
+	bus.SetConnected(true)
+	for _, want := range []string{"a", "b", "c"} {
+		if got := receive(t, ch); got != want {
+			t.Fatalf("received %q, want %q", got, want)
+		}
+	}
+}
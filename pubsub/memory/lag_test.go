@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/pubsub"
+)
+
+// waitLag waits until the lag of s is want.
+func waitLag(t *testing.T, s *Subscriber, want int64) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		lag, err := s.Lag(context.Background())
+		if err != nil {
+			t.Fatalf("Lag: %v", err)
+		}
+		if lag == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Lag = %d, want %d", lag, want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSubscriberLag(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages int
+		consume  int
+	}{
+		{"nothing published", 0, 0},
+		{"nothing consumed", 5, 0},
+		{"partly consumed", 5, 2},
+		{"fully consumed", 5, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			bus := NewBus()
+			defer bus.Close()
+			s, ch := newTestSubscriber(t, bus, []string{"orders"})
+
+			for i := 0; i < tt.messages; i++ {
+				if err := bus.Publish(ctx, "orders", []byte(strconv.Itoa(i))); err != nil {
+					t.Fatal(err)
+				}
+			}
+			// Messages of other topics do not count.
+			if err := bus.Publish(ctx, "invoices", []byte("other")); err != nil {
+				t.Fatal(err)
+			}
+			waitLag(t, s, int64(tt.messages))
+
+			for i := 0; i < tt.consume; i++ {
+				receive(t, ch)
+			}
+			waitLag(t, s, int64(tt.messages-tt.consume))
+		})
+	}
+}
+
+func TestSubscriberLagClosed(t *testing.T) {
+	bus := NewBus()
+	s := bus.NewSubscriber()
+	_ = s.Close()
+	if _, err := s.Lag(context.Background()); !errors.Is(err, pubsub.ErrClosed) {
+		t.Errorf("err = %v, want ErrClosed", err)
+	}
+}
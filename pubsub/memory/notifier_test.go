@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBusOnStateChange(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps func(b *Bus)
+		want  []bool
+	}{
+		{
+			name:  "disconnect and reconnect",
+			steps: func(b *Bus) { b.SetConnected(false); b.SetConnected(true) },
+			want:  []bool{false, true},
+		},
+		{
+			name:  "no transition",
+			steps: func(b *Bus) { b.SetConnected(true); b.SetConnected(true) },
+			want:  nil,
+		},
+		{
+			name:  "repeated disconnect",
+			steps: func(b *Bus) { b.SetConnected(false); b.SetConnected(false) },
+			want:  []bool{false},
+		},
+		{
+			name:  "close",
+			steps: func(b *Bus) { _ = b.Close(); _ = b.Close() },
+			want:  []bool{false},
+		},
+		{
+			name:  "close while disconnected",
+			steps: func(b *Bus) { b.SetConnected(false); _ = b.Close() },
+			want:  []bool{false},
+		},
+		{
+			name:  "reconnect after close",
+			steps: func(b *Bus) { _ = b.Close(); b.SetConnected(false); b.SetConnected(true) },
+			want:  []bool{false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := NewBus()
+			defer bus.Close()
+			var got []bool
+			bus.OnStateChange(func(connected bool) { got = append(got, connected) })
+
+			tt.steps(bus)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("state changes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package memory
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+
+	"github.com/zeroxsolutions/barbatos/pubsub"
+)
+
+// pending is a message waiting in the queue of a subscriber.
+type pending struct {
+	msg      pubsub.Message
+	priority uint8
+	seq      uint64
+}
+
+// queue is a heap of pending messages ordered by descending priority, then publish order.
+type queue []*pending
+
+func (q queue) Len() int { return len(q) }
+
+func (q queue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q queue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *queue) Push(x interface{}) { *q = append(*q, x.(*pending)) }
+
+func (q *queue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// DefaultReceiverBufferSize is the number of unconsumed messages a Subscriber holds when no
+// WithReceiverBufferSize option is given.
+const DefaultReceiverBufferSize = 1024
+
+// SubscriberOption configures a Subscriber created with Bus.NewSubscriber.
+type SubscriberOption func(s *Subscriber)
+
+// WithReceiverBufferSize sets the number of unconsumed messages the subscriber holds, which
+// defaults to DefaultReceiverBufferSize. Once n messages are waiting for the consumer,
+// publishing to the subscriber blocks until the consumer receives one. A size below 1 is
+// treated as 1.
+func WithReceiverBufferSize(n int) SubscriberOption {
+	return func(s *Subscriber) {
+		if n < 1 {
+			n = 1
+		}
+		s.bufferSize = n
+	}
+}
+
+// Subscriber is a pubsub.Subscriber receiving the messages of a Bus.
+//
+// Pending messages are held in a priority queue bounded by the receiver buffer size, and
+// handed over one at a time through an unbuffered receiver channel, so that a message
+// published with a higher priority overtakes every pending message of lower priority, except
+// the one already offered on the channel while the consumer is busy. The buffer size counts
+// both. Messages published to a topic before the subscriber subscribed to it are not received.
+type Subscriber struct {
+	bus        *Bus
+	bufferSize int
+	// slots holds one token per unconsumed message, bounding the queue to bufferSize.
+	slots    chan struct{}
+	receiver chan pubsub.Message
+	stop     chan struct{}
+	done     chan struct{}
+	once     sync.Once
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	topics map[string]struct{}
+	queue  queue
+	closed bool
+}
+
+var (
+	_ pubsub.Subscriber  = (*Subscriber)(nil)
+	_ pubsub.LagReporter = (*Subscriber)(nil)
+)
+
+// Subscribe subscribes to topics.
+func (s *Subscriber) Subscribe(ctx context.Context, topics ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return pubsub.ErrClosed
+	}
+	for _, topic := range topics {
+		s.topics[topic] = struct{}{}
+	}
+	return nil
+}
+
+// Unsubscribe unsubscribes from topics. Messages of these topics already queued are still received.
+func (s *Subscriber) Unsubscribe(ctx context.Context, topics ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return pubsub.ErrClosed
+	}
+	for _, topic := range topics {
+		delete(s.topics, topic)
+	}
+	return nil
+}
+
+// Receiver returns the channel delivering the messages of the subscribed topics, highest
+// priority first. The channel is closed by Close.
+func (s *Subscriber) Receiver(ctx context.Context) (<-chan pubsub.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, pubsub.ErrClosed
+	}
+	return s.receiver, nil
+}
+
+// IsConnected reports whether the subscriber has not been closed.
+func (s *Subscriber) IsConnected(ctx context.Context) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !s.closed
+}
+
+// CheckConnection returns pubsub.ErrClosed if the subscriber has been closed.
+func (s *Subscriber) CheckConnection(ctx context.Context) error {
+	if !s.IsConnected(ctx) {
+		return pubsub.ErrClosed
+	}
+	return ctx.Err()
+}
+
+// Lag returns the number of messages delivered to the subscriber that its consumer has not
+// received yet. It returns pubsub.ErrClosed if the subscriber has been closed.
+func (s *Subscriber) Lag(ctx context.Context) (int64, error) {
+	if !s.IsConnected(ctx) {
+		return 0, pubsub.ErrClosed
+	}
+	return int64(len(s.slots)), nil
+}
+
+// Close stops the delivery, discards the pending messages, and closes the receiver channel.
+// Calling Close more than once is a no-op.
+func (s *Subscriber) Close() error {
+	s.once.Do(func() {
+		s.bus.remove(s)
+		s.mu.Lock()
+		s.closed = true
+		s.queue = nil
+		s.cond.Broadcast()
+		s.mu.Unlock()
+		close(s.stop)
+		<-s.done
+		close(s.receiver)
+	})
+	return nil
+}
+
+// enqueue queues p if the subscriber is subscribed to its topic, waiting while the buffer is
+// full. It returns ctx.Err() if ctx is done first; a closed subscriber drops p.
+func (s *Subscriber) enqueue(ctx context.Context, p *pending) error {
+	s.mu.Lock()
+	_, subscribed := s.topics[p.msg.Topic()]
+	closed := s.closed
+	s.mu.Unlock()
+	if closed || !subscribed {
+		return nil
+	}
+
+	select {
+	case s.slots <- struct{}{}:
+	case <-s.stop:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	heap.Push(&s.queue, p)
+	s.cond.Signal()
+	return nil
+}
+
+// dispatch hands the pending messages over to the receiver channel, highest priority first,
+// until the subscriber is closed.
+func (s *Subscriber) dispatch() {
+	defer close(s.done)
+	for {
+		s.mu.Lock()
+		for len(s.queue) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if s.closed {
+			s.mu.Unlock()
+			return
+		}
+		next := heap.Pop(&s.queue).(*pending)
+		s.mu.Unlock()
+
+		select {
+		case s.receiver <- next.msg:
+			<-s.slots
+		case <-s.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSubscriberBackpressure(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		want int
+	}{
+		{"default minimum", 0, 1},
+		{"one", 1, 1},
+		{"several", 4, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			bus := NewBus()
+			defer bus.Close()
+			_, ch := newTestSubscriber(t, bus, []string{"orders"}, WithReceiverBufferSize(tt.size))
+
+			// The fetcher accepts want unconsumed messages without blocking.
+			for i := 0; i < tt.want; i++ {
+				short, cancel := context.WithTimeout(ctx, time.Second)
+				err := bus.Publish(short, "orders", []byte(fmt.Sprint(i)))
+				cancel()
+				if err != nil {
+					t.Fatalf("publish %d: %v", i, err)
+				}
+			}
+
+			// It pauses once the buffer is full.
+			short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			err := bus.Publish(short, "orders", []byte("overflow"))
+			cancel()
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("publish to a full buffer: got %v, want context.DeadlineExceeded", err)
+			}
+
+			// It resumes as the consumer drains.
+			published := make(chan error, 1)
+			go func() {
+				published <- bus.Publish(ctx, "orders", []byte(fmt.Sprint(tt.want)))
+			}()
+			select {
+			case err := <-published:
+				t.Fatalf("publish returned %v before the consumer drained", err)
+			case <-time.After(20 * time.Millisecond):
+			}
+			if got := receive(t, ch); got != "0" {
+				t.Fatalf("first message: got %q, want %q", got, "0")
+			}
+			select {
+			case err := <-published:
+				if err != nil {
+					t.Fatalf("publish after drain: %v", err)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("publish still blocked after the consumer drained")
+			}
+			for i := 1; i <= tt.want; i++ {
+				if got := receive(t, ch); got != fmt.Sprint(i) {
+					t.Fatalf("message %d: got %q", i, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSubscriberCloseUnblocksPublish(t *testing.T) {
+	ctx := context.Background()
+	bus := NewBus()
+	defer bus.Close()
+	s, _ := newTestSubscriber(t, bus, []string{"orders"}, WithReceiverBufferSize(1))
+
+	if err := bus.Publish(ctx, "orders", []byte("fills the buffer")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	published := make(chan error, 1)
+	go func() {
+		published <- bus.Publish(ctx, "orders", []byte("blocked"))
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	select {
+	case err := <-published:
+		if err != nil {
+			t.Fatalf("blocked publish: got %v, want nil", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("publish still blocked after the subscriber was closed")
+	}
+}
@@ -1,5 +1,7 @@
 package pubsub
 
+import "time"
+
 // Message defines an interface for a publish-subscribe messaging system.
 // It provides methods to retrieve the topic of the message and its associated data.
 type Message interface {
@@ -9,3 +11,17 @@ type Message interface {
 	// Data returns the payload of the message as a slice of bytes.
 	Data() []byte
 }
+
+// Timestamper is an optional interface implemented by messages that carry the time at
+// which they were published.
+type Timestamper interface {
+	// Timestamp returns the publication time of the message.
+	Timestamp() time.Time
+}
+
+// Acknowledger is an optional interface implemented by messages that must be explicitly
+// acknowledged to the pub-sub system once processed.
+type Acknowledger interface {
+	// Ack acknowledges the message so that it is not redelivered.
+	Ack() error
+}
@@ -0,0 +1,17 @@
+package pubsub
+
+// Notifier is an optional interface implemented by publishers and subscribers that can
+// report connection state transitions as they happen, instead of requiring callers to
+// poll IsConnected. Callers can detect support with a type assertion:
+//
+//	if notifier, ok := publisher.(pubsub.Notifier); ok {
+//		notifier.OnStateChange(func(connected bool) {
+//			// react to the new state
+//		})
+//	}
+type Notifier interface {
+	// OnStateChange registers a callback that is invoked whenever the connection
+	// to the pub-sub system is established (connected is true) or lost (connected is false).
+	// Implementations invoke the callback only on transitions, not on every health check.
+	OnStateChange(fn func(connected bool))
+}
@@ -0,0 +1,71 @@
+package pubsub
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// notifyingPublisher is a Publisher implementing Notifier whose connection state is
+// flipped by the test with setConnected.
+type notifyingPublisher struct {
+	Publisher
+
+	mu        sync.Mutex
+	connected bool
+	callbacks []func(connected bool)
+}
+
+func (p *notifyingPublisher) OnStateChange(fn func(connected bool)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.callbacks = append(p.callbacks, fn)
+}
+
+// setConnected changes the connection state and notifies the registered callbacks when it
+// differs from the current one.
+func (p *notifyingPublisher) setConnected(connected bool) {
+	p.mu.Lock()
+	if p.connected == connected {
+		p.mu.Unlock()
+		return
+	}
+	p.connected = connected
+	callbacks := append([]func(bool){}, p.callbacks...)
+	p.mu.Unlock()
+	for _, fn := range callbacks {
+		fn(connected)
+	}
+}
+
+func TestNotifier(t *testing.T) {
+	tests := []struct {
+		name  string
+		flips []bool
+		want  []bool
+	}{
+		{name: "connect", flips: []bool{true}, want: []bool{true}},
+		{name: "connect then lose", flips: []bool{true, false}, want: []bool{true, false}},
+		{name: "repeated states", flips: []bool{true, true, false, false, true}, want: []bool{true, false, true}},
+		{name: "no transition", flips: []bool{false}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Publisher = &notifyingPublisher{}
+			notifier, ok := p.(Notifier)
+			if !ok {
+				t.Fatal("publisher does not implement Notifier")
+			}
+			var got []bool
+			notifier.OnStateChange(func(connected bool) {
+				got = append(got, connected)
+			})
+			for _, connected := range tt.flips {
+				p.(*notifyingPublisher).setConnected(connected)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("callback states = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+module github.com/zeroxsolutions/barbatos/pubsub/otelpubsub
+
+go 1.18
+
+require (
+	github.com/zeroxsolutions/barbatos v0.0.0-00010101000000-000000000000
+	go.opentelemetry.io/otel v1.14.0
+	go.opentelemetry.io/otel/trace v1.14.0
+)
+
+require (
+	github.com/go-logr/logr v1.2.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+)
+
+replace github.com/zeroxsolutions/barbatos => ../../
@@ -0,0 +1,62 @@
+// Package otelpubsub provides OpenTelemetry instrumentation for the pubsub package.
+// It lives in its own module so that the core packages do not depend on OpenTelemetry.
+package otelpubsub
+
+import (
+	"context"
+
+	"github.com/zeroxsolutions/barbatos/pubsub"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies this package as the instrumentation library of its spans.
+const instrumentationName = "github.com/zeroxsolutions/barbatos/pubsub/otelpubsub"
+
+// Traced returns a consumer Middleware that starts a span for every message.
+//
+// The W3C trace context (traceparent and tracestate headers) is extracted from the
+// headers of messages implementing pubsub.HeaderCarrier, so the span becomes a child of
+// the producer's span. The span is named after the message topic, the handler runs with
+// the span's context, and a handler error is recorded on the span.
+//
+// A nil tracer uses the global tracer provider, which is a no-op until one is configured.
+//
+//	handler := pubsub.Chain(handle, otelpubsub.Traced(tp.Tracer("consumer")))
+func Traced(tracer trace.Tracer) pubsub.Middleware {
+	if tracer == nil {
+		tracer = otel.GetTracerProvider().Tracer(instrumentationName)
+	}
+	propagator := propagation.TraceContext{}
+
+	return func(next pubsub.HandlerFunc) pubsub.HandlerFunc {
+		return func(ctx context.Context, msg pubsub.Message) error {
+			if carrier, ok := msg.(pubsub.HeaderCarrier); ok {
+				ctx = propagator.Extract(ctx, propagation.MapCarrier(carrier.Headers()))
+			}
+
+			ctx, span := tracer.Start(ctx, msg.Topic(),
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(
+					attribute.String("messaging.destination.name", msg.Topic()),
+					attribute.Int("messaging.message.payload_size_bytes", len(msg.Data())),
+				),
+			)
+			defer span.End()
+
+			if identifier, ok := msg.(pubsub.Identifier); ok && identifier.ID() != "" {
+				span.SetAttributes(attribute.String("messaging.message.id", identifier.ID()))
+			}
+
+			err := next(ctx, msg)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return err
+		}
+	}
+}
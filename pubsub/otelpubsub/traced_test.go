@@ -0,0 +1,158 @@
+package otelpubsub
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zeroxsolutions/barbatos/pubsub"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordedSpan is a span started by recordingTracer.
+type recordedSpan struct {
+	trace.Span
+
+	name       string
+	parent     trace.SpanContext
+	kind       trace.SpanKind
+	attributes map[attribute.Key]attribute.Value
+	errs       []error
+	status     codes.Code
+	ended      bool
+}
+
+func (s *recordedSpan) End(...trace.SpanEndOption) { s.ended = true }
+
+func (s *recordedSpan) RecordError(err error, _ ...trace.EventOption) { s.errs = append(s.errs, err) }
+
+func (s *recordedSpan) SetStatus(code codes.Code, _ string) { s.status = code }
+
+func (s *recordedSpan) SetAttributes(kv ...attribute.KeyValue) {
+	for _, attr := range kv {
+		s.attributes[attr.Key] = attr.Value
+	}
+}
+
+// recordingTracer is a trace.Tracer recording the spans it starts.
+type recordingTracer struct {
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	config := trace.NewSpanStartConfig(opts...)
+	span := &recordedSpan{
+		Span:       trace.SpanFromContext(ctx),
+		name:       name,
+		parent:     trace.SpanContextFromContext(ctx),
+		kind:       config.SpanKind(),
+		attributes: make(map[attribute.Key]attribute.Value),
+	}
+	span.SetAttributes(config.Attributes()...)
+	t.spans = append(t.spans, span)
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+func TestTraced(t *testing.T) {
+	const (
+		traceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentID    = "00f067aa0ba902b7"
+		traceparent = "00-" + traceID + "-" + parentID + "-01"
+	)
+	errHandler := errors.New("handler failed")
+	tests := []struct {
+		name       string
+		msg        pubsub.Message
+		handlerErr error
+		wantParent string
+		wantID     string
+	}{
+		{"without headers", plainMessage{topic: "orders", data: []byte("abc")}, nil, "", ""},
+		{"with trace context", pubsub.NewEnvelope("orders", []byte("abc"),
+			pubsub.WithID("42"), pubsub.WithHeader("traceparent", traceparent)), nil, parentID, "42"},
+		{"invalid trace context", pubsub.NewEnvelope("orders", []byte("abc"),
+			pubsub.WithHeader("traceparent", "garbage")), nil, "", ""},
+		{"handler error", plainMessage{topic: "orders", data: []byte("abc")}, errHandler, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracer := &recordingTracer{}
+			var handlerSpan trace.Span
+			handler := pubsub.Chain(func(ctx context.Context, msg pubsub.Message) error {
+				handlerSpan = trace.SpanFromContext(ctx)
+				return tt.handlerErr
+			}, Traced(tracer))
+
+			if err := handler(context.Background(), tt.msg); !errors.Is(err, tt.handlerErr) {
+				t.Fatalf("err = %v, want %v", err, tt.handlerErr)
+			}
+			if len(tracer.spans) != 1 {
+				t.Fatalf("started %d spans, want 1", len(tracer.spans))
+			}
+			span := tracer.spans[0]
+			if span.name != "orders" || span.kind != trace.SpanKindConsumer || !span.ended {
+				t.Errorf("span = %q kind %v ended %v, want an ended consumer span named orders", span.name, span.kind, span.ended)
+			}
+			if handlerSpan != trace.Span(span) {
+				t.Error("handler did not run with the span context")
+			}
+
+			gotParent := ""
+			if span.parent.IsValid() {
+				gotParent = span.parent.SpanID().String()
+				if span.parent.TraceID().String() != traceID || !span.parent.IsRemote() {
+					t.Errorf("parent = %v, want remote span of trace %s", span.parent, traceID)
+				}
+			}
+			if gotParent != tt.wantParent {
+				t.Errorf("parent span = %q, want %q", gotParent, tt.wantParent)
+			}
+
+			if got := span.attributes["messaging.destination.name"].AsString(); got != "orders" {
+				t.Errorf("destination = %q, want orders", got)
+			}
+			if got := span.attributes["messaging.message.payload_size_bytes"].AsInt64(); got != 3 {
+				t.Errorf("payload size = %d, want 3", got)
+			}
+			if got := span.attributes["messaging.message.id"].AsString(); got != tt.wantID {
+				t.Errorf("message ID = %q, want %q", got, tt.wantID)
+			}
+
+			wantStatus := codes.Unset
+			if tt.handlerErr != nil {
+				wantStatus = codes.Error
+				if len(span.errs) != 1 || !errors.Is(span.errs[0], tt.handlerErr) {
+					t.Errorf("recorded errors = %v, want [%v]", span.errs, tt.handlerErr)
+				}
+			}
+			if span.status != wantStatus {
+				t.Errorf("status = %v, want %v", span.status, wantStatus)
+			}
+		})
+	}
+}
+
+func TestTracedDefaultTracer(t *testing.T) {
+	calls := 0
+	handler := pubsub.Chain(func(ctx context.Context, msg pubsub.Message) error {
+		calls++
+		return nil
+	}, Traced(nil))
+	if err := handler(context.Background(), plainMessage{topic: "orders"}); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+}
+
+// plainMessage is a pubsub.Message implementing none of the optional interfaces.
+type plainMessage struct {
+	topic string
+	data  []byte
+}
+
+func (m plainMessage) Topic() string { return m.topic }
+func (m plainMessage) Data() []byte  { return m.data }
@@ -0,0 +1,76 @@
+// Package prompubsub provides Prometheus instrumentation for the pubsub package.
+// It lives in its own module so that the core packages do not depend on Prometheus.
+package prompubsub
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zeroxsolutions/barbatos/pubsub"
+)
+
+// DefaultLagTimeout is the default bound of the Lag call made by a LagCollector on every
+// scrape.
+const DefaultLagTimeout = 5 * time.Second
+
+// LagCollectorOption configures a LagCollector.
+type LagCollectorOption func(*LagCollector)
+
+// WithLagTimeout sets the bound of the Lag call made on every scrape. It defaults to
+// DefaultLagTimeout.
+func WithLagTimeout(d time.Duration) LagCollectorOption {
+	return func(c *LagCollector) {
+		c.timeout = d
+	}
+}
+
+// LagCollector is a prometheus.Collector exporting the lag reported by a pubsub.LagReporter
+// in the pubsub_consumer_lag gauge. The lag is queried on every scrape; a scrape at which
+// the query fails exports no sample, so the gauge is absent rather than stale.
+type LagCollector struct {
+	reporter pubsub.LagReporter
+	desc     *prometheus.Desc
+	timeout  time.Duration
+}
+
+var _ prometheus.Collector = (*LagCollector)(nil)
+
+// NewLagCollector creates a LagCollector exporting the lag of reporter, labelled with the
+// given consumer name. Several collectors with different consumer names can be registered
+// with the same registry.
+//
+//	if reporter, ok := subscriber.(pubsub.LagReporter); ok {
+//		prometheus.MustRegister(prompubsub.NewLagCollector("orders-worker", reporter))
+//	}
+func NewLagCollector(consumer string, reporter pubsub.LagReporter, opts ...LagCollectorOption) *LagCollector {
+	c := &LagCollector{
+		reporter: reporter,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName("pubsub", "consumer", "lag"),
+			"Number of messages published but not yet consumed by the consumer.",
+			nil, prometheus.Labels{"consumer": consumer},
+		),
+		timeout: DefaultLagTimeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Describe sends the descriptor of the gauge.
+func (c *LagCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect queries the lag, bounded by the lag timeout, and sends it unless the query fails.
+func (c *LagCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	lag, err := c.reporter.Lag(ctx)
+	if err != nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(lag))
+}
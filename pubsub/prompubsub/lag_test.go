@@ -0,0 +1,105 @@
+package prompubsub
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/zeroxsolutions/barbatos/pubsub/memory"
+)
+
+// waitGauge waits until the gauge exported by c is want.
+func waitGauge(t *testing.T, c prometheus.Collector, want float64) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		got := testutil.ToFloat64(c)
+		if got == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("pubsub_consumer_lag = %v, want %v", got, want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLagCollector(t *testing.T) {
+	ctx := context.Background()
+	bus := memory.NewBus()
+	defer bus.Close()
+	s := bus.NewSubscriber()
+	defer s.Close()
+	if err := s.Subscribe(ctx, "orders"); err != nil {
+		t.Fatal(err)
+	}
+	ch, err := s.Receiver(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	collector := NewLagCollector("orders-worker", s)
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collector)
+
+	for i := 0; i < 3; i++ {
+		if err := bus.Publish(ctx, "orders", []byte(strconv.Itoa(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitGauge(t, collector, 3)
+	for i := 0; i < 3; i++ {
+		<-ch
+	}
+	waitGauge(t, collector, 0)
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(families) != 1 || families[0].GetName() != "pubsub_consumer_lag" {
+		t.Fatalf("gathered %v, want pubsub_consumer_lag", families)
+	}
+	if label := families[0].GetMetric()[0].GetLabel()[0]; label.GetName() != "consumer" || label.GetValue() != "orders-worker" {
+		t.Errorf("label = %v, want consumer=orders-worker", label)
+	}
+
+	// A closed subscriber cannot report its lag, so no sample is exported.
+	_ = s.Close()
+	if got := testutil.CollectAndCount(collector); got != 0 {
+		t.Errorf("%d samples after Close, want 0", got)
+	}
+}
+
+func TestLagCollectorConsumers(t *testing.T) {
+	bus := memory.NewBus()
+	defer bus.Close()
+	reg := prometheus.NewRegistry()
+	for _, consumer := range []string{"orders-worker", "invoices-worker"} {
+		s := bus.NewSubscriber()
+		defer s.Close()
+		if err := reg.Register(NewLagCollector(consumer, s)); err != nil {
+			t.Fatalf("Register(%s): %v", consumer, err)
+		}
+	}
+	if got := testutil.CollectAndCount(reg, "pubsub_consumer_lag"); got != 2 {
+		t.Errorf("%d series, want 2", got)
+	}
+}
+
+// stalledReporter is a pubsub.LagReporter whose Lag blocks until its context is done.
+type stalledReporter struct{}
+
+func (stalledReporter) Lag(ctx context.Context) (int64, error) {
+	<-ctx.Done()
+	return 0, ctx.Err()
+}
+
+func TestLagCollectorTimeout(t *testing.T) {
+	collector := NewLagCollector("orders-worker", stalledReporter{}, WithLagTimeout(time.Millisecond))
+	if got := testutil.CollectAndCount(collector); got != 0 {
+		t.Errorf("%d samples from a stalled reporter, want 0", got)
+	}
+}
@@ -0,0 +1,77 @@
+package prompubsub
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zeroxsolutions/barbatos/pubsub"
+)
+
+// Outcome label values of the consumer metrics.
+const (
+	// OutcomeSuccess labels messages whose handler returned nil.
+	OutcomeSuccess = "success"
+	// OutcomeFailure labels messages whose handler returned an error.
+	OutcomeFailure = "failure"
+)
+
+// WithMetrics returns a consumer Middleware that records, per topic and outcome, the number
+// of handled messages in the pubsub_consumer_messages_total counter and the handling latency
+// in the pubsub_consumer_handle_duration_seconds histogram.
+//
+// The collectors are registered with reg, or prometheus.DefaultRegisterer if reg is nil.
+// Calling WithMetrics several times with the same registerer shares the collectors, so one
+// middleware can be built per consumer. It panics if reg holds incompatible collectors
+// under the same names.
+//
+//	reg := prometheus.NewRegistry()
+//	handler := pubsub.Chain(handle, prompubsub.WithMetrics(reg))
+func WithMetrics(reg prometheus.Registerer) pubsub.Middleware {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	messages := register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "pubsub",
+		Subsystem: "consumer",
+		Name:      "messages_total",
+		Help:      "Number of messages handled by the consumer, by topic and outcome.",
+	}, []string{"topic", "outcome"}))
+	duration := register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "pubsub",
+		Subsystem: "consumer",
+		Name:      "handle_duration_seconds",
+		Help:      "Time spent handling a message, by topic and outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"topic", "outcome"}))
+
+	return func(next pubsub.HandlerFunc) pubsub.HandlerFunc {
+		return func(ctx context.Context, msg pubsub.Message) error {
+			start := time.Now()
+			err := next(ctx, msg)
+			outcome := OutcomeSuccess
+			if err != nil {
+				outcome = OutcomeFailure
+			}
+			messages.WithLabelValues(msg.Topic(), outcome).Inc()
+			duration.WithLabelValues(msg.Topic(), outcome).Observe(time.Since(start).Seconds())
+			return err
+		}
+	}
+}
+
+// register registers collector with reg, returning the collector already registered under
+// the same descriptor if any.
+func register[C prometheus.Collector](reg prometheus.Registerer, collector C) C {
+	if err := reg.Register(collector); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+		if errors.As(err, &registered) {
+			if existing, ok := registered.ExistingCollector.(C); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return collector
+}
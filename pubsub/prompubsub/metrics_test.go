@@ -0,0 +1,117 @@
+package prompubsub
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/zeroxsolutions/barbatos/pubsub"
+)
+
+var errHandle = errors.New("handle failed")
+
+// plainMessage is a pubsub.Message with a topic and no payload.
+type plainMessage string
+
+func (m plainMessage) Topic() string { return string(m) }
+func (m plainMessage) Data() []byte  { return nil }
+
+func TestWithMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	handler := pubsub.Chain(func(ctx context.Context, msg pubsub.Message) error {
+		if msg.Topic() == "failing" {
+			return errHandle
+		}
+		return nil
+	}, WithMetrics(reg))
+
+	for _, topic := range []string{"orders", "orders", "failing"} {
+		err := handler(context.Background(), plainMessage(topic))
+		if want := topic == "failing"; (err != nil) != want || (want && !errors.Is(err, errHandle)) {
+			t.Errorf("topic %s: err = %v", topic, err)
+		}
+	}
+
+	messages, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]float64)
+	observations := make(map[string]uint64)
+	for _, family := range messages {
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			key := labels["topic"] + "/" + labels["outcome"]
+			switch family.GetName() {
+			case "pubsub_consumer_messages_total":
+				counts[key] = metric.GetCounter().GetValue()
+			case "pubsub_consumer_handle_duration_seconds":
+				observations[key] = metric.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+
+	tests := []struct {
+		key  string
+		want float64
+	}{
+		{"orders/" + OutcomeSuccess, 2},
+		{"failing/" + OutcomeFailure, 1},
+		{"orders/" + OutcomeFailure, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if counts[tt.key] != tt.want {
+				t.Errorf("messages_total = %v, want %v", counts[tt.key], tt.want)
+			}
+			if observations[tt.key] != uint64(tt.want) {
+				t.Errorf("duration samples = %d, want %v", observations[tt.key], tt.want)
+			}
+		})
+	}
+}
+
+func TestWithMetricsSharesCollectors(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	handle := func(ctx context.Context, msg pubsub.Message) error { return nil }
+	first := pubsub.Chain(handle, WithMetrics(reg))
+	second := pubsub.Chain(handle, WithMetrics(reg))
+	_ = first(context.Background(), plainMessage("orders"))
+	_ = second(context.Background(), plainMessage("orders"))
+
+	if got := testutil.CollectAndCount(reg, "pubsub_consumer_messages_total"); got != 1 {
+		t.Fatalf("%d series, want 1", got)
+	}
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() == "pubsub_consumer_messages_total" {
+			if got := family.GetMetric()[0].GetCounter().GetValue(); got != 2 {
+				t.Errorf("messages_total = %v, want 2", got)
+			}
+		}
+	}
+}
+
+func TestWithMetricsIncompatibleCollector(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "pubsub",
+		Subsystem: "consumer",
+		Name:      "messages_total",
+		Help:      "Conflicting collector.",
+	}))
+	defer func() {
+		if recover() == nil {
+			t.Error("WithMetrics did not panic on an incompatible collector")
+		}
+	}()
+	WithMetrics(reg)
+}
@@ -0,0 +1,62 @@
+// Package protocodec provides a Protocol Buffers pubsub.Codec. It lives in its own module so
+// that the core packages do not depend on protobuf.
+package protocodec
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/zeroxsolutions/barbatos/pubsub"
+	"google.golang.org/protobuf/proto"
+)
+
+// ContentType is the content type of the payloads produced by Codec.
+const ContentType = "application/x-protobuf"
+
+// ErrNotProtoMessage is returned when a value given to Codec is not a protobuf message.
+var ErrNotProtoMessage = errors.New("protocodec: not a proto.Message")
+
+// Codec is a pubsub.Codec that encodes payloads in the Protocol Buffers wire format. Values
+// must be generated protobuf messages, e.g. *orderpb.OrderCreated.
+//
+//	orders := pubsub.NewTypedPublisher[*orderpb.OrderCreated](publisher, protocodec.Codec{})
+type Codec struct{}
+
+var _ pubsub.Codec = Codec{}
+
+// Marshal encodes the protobuf message v.
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", ErrNotProtoMessage, v)
+	}
+	return proto.Marshal(msg)
+}
+
+// Unmarshal decodes the payload data into v, which must be a protobuf message or a pointer to
+// one. In the latter case, as when decoding into a *orderpb.OrderCreated variable, a new
+// message is allocated and stored in it.
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	if msg, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, msg)
+	}
+	ptr := reflect.ValueOf(v)
+	if ptr.Kind() != reflect.Ptr || ptr.IsNil() || ptr.Elem().Kind() != reflect.Ptr {
+		return fmt.Errorf("%w: %T", ErrNotProtoMessage, v)
+	}
+	msg, ok := reflect.New(ptr.Elem().Type().Elem()).Interface().(proto.Message)
+	if !ok {
+		return fmt.Errorf("%w: %T", ErrNotProtoMessage, v)
+	}
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return err
+	}
+	ptr.Elem().Set(reflect.ValueOf(msg))
+	return nil
+}
+
+// ContentType returns "application/x-protobuf".
+func (Codec) ContentType() string {
+	return ContentType
+}
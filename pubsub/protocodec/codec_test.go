@@ -0,0 +1,108 @@
+package protocodec
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zeroxsolutions/barbatos/pubsub"
+	"github.com/zeroxsolutions/barbatos/pubsub/memory"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// newOrder returns a protobuf message standing for a generated order type.
+func newOrder(t *testing.T) *structpb.Struct {
+	t.Helper()
+	order, err := structpb.NewStruct(map[string]interface{}{"id": "42", "items": []interface{}{"book"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+func TestCodec(t *testing.T) {
+	order := newOrder(t)
+	tests := []struct {
+		name   string
+		decode func(c pubsub.Codec, data []byte) (proto.Message, error)
+	}{
+		{"message", func(c pubsub.Codec, data []byte) (proto.Message, error) {
+			v := &structpb.Struct{}
+			err := c.Unmarshal(data, v)
+			return v, err
+		}},
+		{"pointer to message", func(c pubsub.Codec, data []byte) (proto.Message, error) {
+			var v *structpb.Struct
+			err := c.Unmarshal(data, &v)
+			return v, err
+		}},
+	}
+	var codec pubsub.Codec = Codec{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := codec.Marshal(order)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			got, err := tt.decode(codec, data)
+			if err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !proto.Equal(got, order) {
+				t.Errorf("Unmarshal = %v, want %v", got, order)
+			}
+		})
+	}
+}
+
+func TestCodecErrors(t *testing.T) {
+	codec := Codec{}
+	if _, err := codec.Marshal(struct{ ID string }{"42"}); !errors.Is(err, ErrNotProtoMessage) {
+		t.Errorf("Marshal: err = %v, want ErrNotProtoMessage", err)
+	}
+	var s string
+	if err := codec.Unmarshal([]byte{}, &s); !errors.Is(err, ErrNotProtoMessage) {
+		t.Errorf("Unmarshal into *string: err = %v, want ErrNotProtoMessage", err)
+	}
+	var p *string
+	if err := codec.Unmarshal([]byte{}, &p); !errors.Is(err, ErrNotProtoMessage) {
+		t.Errorf("Unmarshal into **string: err = %v, want ErrNotProtoMessage", err)
+	}
+	if err := codec.Unmarshal([]byte{0xff}, &structpb.Struct{}); err == nil {
+		t.Error("Unmarshal succeeded with a malformed payload")
+	}
+	if got := codec.ContentType(); got != ContentType {
+		t.Errorf("ContentType = %q, want %q", got, ContentType)
+	}
+}
+
+func TestCodecTyped(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	bus := memory.NewBus()
+	defer bus.Close()
+	s := bus.NewSubscriber()
+	defer s.Close()
+	if err := s.Subscribe(ctx, "orders"); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := s.Receiver(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order := newOrder(t)
+	if err := pubsub.NewTypedPublisher[*structpb.Struct](bus, Codec{}).Publish(ctx, "orders", order); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	msg := <-raw
+	if got := pubsub.ContentType(msg); got != ContentType {
+		t.Errorf("content type = %q, want %q", got, ContentType)
+	}
+	var got *structpb.Struct
+	if err := (Codec{}).Unmarshal(msg.Data(), &got); err != nil || !proto.Equal(got, order) {
+		t.Errorf("received %v, %v, want %v", got, err, order)
+	}
+}
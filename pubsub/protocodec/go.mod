@@ -0,0 +1,10 @@
+module github.com/zeroxsolutions/barbatos/pubsub/protocodec
+
+go 1.18
+
+require (
+	github.com/zeroxsolutions/barbatos v0.0.0-00010101000000-000000000000
+	google.golang.org/protobuf v1.28.1
+)
+
+replace github.com/zeroxsolutions/barbatos => ../../
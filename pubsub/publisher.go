@@ -2,6 +2,10 @@ package pubsub
 
 import "context"
 
+// PriorityHeader is the message header carrying the priority given to PublishWithPriority
+// on backends without native message priorities.
+const PriorityHeader = "priority"
+
 // Publisher defines an interface for a publish-subscribe system's publisher.
 // It provides methods for publishing messages to a topic, checking the connection status,
 // and closing the publisher.
@@ -11,11 +15,30 @@ type Publisher interface {
 	// Returns an error if the operation fails.
 	Publish(ctx context.Context, topic string, messages ...[]byte) error
 
+	// PublishWithPriority sends msg to the specified topic with a priority hint, so that
+	// consumers receive higher-priority messages (e.g. cancellations) ahead of lower-priority
+	// ones still waiting in the queue. Backends with native priorities map it to the broker
+	// priority (e.g. the RabbitMQ message priority); others carry it in the PriorityHeader
+	// header, or return ErrUnsupported if they cannot carry it at all.
+	PublishWithPriority(ctx context.Context, topic string, priority uint8, msg []byte) error
+
 	// IsConnected checks if the publisher is currently connected to the pub-sub system.
 	// It accepts a context and returns true if connected, otherwise false.
 	IsConnected(ctx context.Context) bool
 
+	// CheckConnection performs a lightweight round-trip to the pub-sub system, bounded by the
+	// context deadline, and returns the underlying error if it fails. Unlike IsConnected,
+	// which may report a cached state, it always probes the broker.
+	CheckConnection(ctx context.Context) error
+
 	// Close closes the publisher and releases any resources.
 	// Returns an error if the operation fails.
 	Close() error
 }
+
+// EnvelopePublisher is an optional interface implemented by publishers that can publish an
+// Envelope with its headers, such as the in-memory bus.
+type EnvelopePublisher interface {
+	// PublishEnvelope sends msg to its topic, keeping its headers.
+	PublishEnvelope(ctx context.Context, msg *Envelope) error
+}
@@ -0,0 +1,111 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+// Router dispatches the messages received from a Subscriber to handlers registered per topic,
+// replacing manual switches on Message.Topic in consumers.
+//
+// Example:
+//
+//	router := pubsub.NewRouter(subscriber)
+//	router.Handle("orders", handleOrder)
+//	router.Handle("payments", handlePayment)
+//	router.HandleDefault(handleUnknown)
+//	err := router.Run(ctx)
+type Router struct {
+	subscriber Subscriber
+
+	mu       sync.RWMutex
+	topics   []string
+	handlers map[string]HandlerFunc
+	fallback HandlerFunc
+	onError  func(ctx context.Context, msg Message, err error)
+}
+
+// NewRouter creates a Router receiving messages from subscriber.
+func NewRouter(subscriber Subscriber) *Router {
+	return &Router{
+		subscriber: subscriber,
+		handlers:   make(map[string]HandlerFunc),
+	}
+}
+
+// Handle registers fn as the handler of the messages of topic, replacing any handler
+// previously registered for it. Handlers must be registered before Run is called.
+func (r *Router) Handle(topic string, fn HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.handlers[topic]; !ok {
+		r.topics = append(r.topics, topic)
+	}
+	r.handlers[topic] = fn
+}
+
+// HandleDefault registers fn as the handler of the messages whose topic has no handler.
+// Without a default handler, such messages are ignored.
+func (r *Router) HandleDefault(fn HandlerFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fallback = fn
+}
+
+// OnError registers fn to be called with every error returned by a handler.
+// Without it, handler errors are ignored and the next message is dispatched.
+func (r *Router) OnError(fn func(ctx context.Context, msg Message, err error)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onError = fn
+}
+
+// Run subscribes to every topic with a registered handler and dispatches the received
+// messages, one at a time, until ctx is done or the receiver channel is closed, in which
+// case it returns nil. It returns an error if the subscription or the receiver fails.
+func (r *Router) Run(ctx context.Context) error {
+	r.mu.RLock()
+	topics := make([]string, len(r.topics))
+	copy(topics, r.topics)
+	r.mu.RUnlock()
+
+	if len(topics) > 0 {
+		if err := r.subscriber.Subscribe(ctx, topics...); err != nil {
+			return err
+		}
+	}
+	messages, err := r.subscriber.Receiver(ctx)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			r.dispatch(ctx, msg)
+		}
+	}
+}
+
+// dispatch calls the handler matching the topic of msg and reports its error.
+func (r *Router) dispatch(ctx context.Context, msg Message) {
+	r.mu.RLock()
+	handler, ok := r.handlers[msg.Topic()]
+	if !ok {
+		handler = r.fallback
+	}
+	onError := r.onError
+	r.mu.RUnlock()
+
+	if handler == nil {
+		return
+	}
+	if err := handler(ctx, msg); err != nil && onError != nil {
+		onError(ctx, msg, err)
+	}
+}
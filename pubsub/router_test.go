@@ -0,0 +1,98 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRouter(t *testing.T) {
+	errHandler := errors.New("handler failed")
+	tests := []struct {
+		name         string
+		withDefault  bool
+		withOnError  bool
+		topics       []string
+		wantHandled  string
+		wantErrTopic string
+	}{
+		{"registered topics", false, false, []string{"orders", "payments", "orders"}, "[orders:orders payments:payments orders:orders]", ""},
+		{"unknown topic ignored", false, false, []string{"orders", "unknown"}, "[orders:orders]", ""},
+		{"unknown topic to default", true, false, []string{"unknown", "orders"}, "[default:unknown orders:orders]", ""},
+		{"handler error reported", false, true, []string{"failing", "orders"}, "[failing:failing orders:orders]", "failing"},
+		{"handler error ignored", false, false, []string{"failing", "orders"}, "[failing:failing orders:orders]", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &chanSubscriber{ch: make(chan Message, len(tt.topics))}
+			router := NewRouter(s)
+			var handled []string
+			handle := func(name string, err error) HandlerFunc {
+				return func(ctx context.Context, msg Message) error {
+					handled = append(handled, name+":"+msg.Topic())
+					return err
+				}
+			}
+			router.Handle("orders", handle("replaced", nil))
+			// A second registration replaces the handler.
+			router.Handle("orders", handle("orders", nil))
+			router.Handle("payments", handle("payments", nil))
+			router.Handle("failing", handle("failing", errHandler))
+			if tt.withDefault {
+				router.HandleDefault(handle("default", nil))
+			}
+			var errTopic string
+			if tt.withOnError {
+				router.OnError(func(ctx context.Context, msg Message, err error) {
+					if !errors.Is(err, errHandler) {
+						t.Errorf("OnError: err = %v, want %v", err, errHandler)
+					}
+					errTopic = msg.Topic()
+				})
+			}
+
+			for _, topic := range tt.topics {
+				s.ch <- rawMessage{topic: topic}
+			}
+			close(s.ch)
+			if err := router.Run(context.Background()); err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+
+			if got := fmt.Sprint(s.topics); got != "[orders payments failing]" {
+				t.Errorf("subscribed topics = %s, want [orders payments failing]", got)
+			}
+			if got := fmt.Sprint(handled); got != tt.wantHandled {
+				t.Errorf("handled = %s, want %s", got, tt.wantHandled)
+			}
+			if errTopic != tt.wantErrTopic {
+				t.Errorf("error reported for %q, want %q", errTopic, tt.wantErrTopic)
+			}
+		})
+	}
+}
+
+func TestRouterRunStops(t *testing.T) {
+	errReceiver := errors.New("receiver failed")
+	tests := []struct {
+		name    string
+		s       *chanSubscriber
+		wantErr error
+	}{
+		{"context done", &chanSubscriber{ch: make(chan Message)}, nil},
+		{"receiver error", &chanSubscriber{err: errReceiver}, errReceiver},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			router := NewRouter(tt.s)
+			router.Handle("orders", func(ctx context.Context, msg Message) error { return nil })
+			if err := router.Run(ctx); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Run: err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
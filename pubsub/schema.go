@@ -0,0 +1,126 @@
+package pubsub
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// SchemaVersionHeader is the message header carrying the schema version of the payload.
+// Publishers stamp it with WithSchemaVersion on the envelopes they publish through an
+// EnvelopePublisher, so consumers can upgrade older payloads with a Migrator.
+const SchemaVersionHeader = "schema-version"
+
+// DefaultSchemaVersion is the schema version assumed for messages without a SchemaVersionHeader.
+const DefaultSchemaVersion = 1
+
+// HeaderCarrier is an optional interface implemented by messages that carry headers.
+type HeaderCarrier interface {
+	// Headers returns the message headers as key-value pairs.
+	Headers() map[string]string
+}
+
+// SchemaVersion returns the schema version of msg as stamped in its SchemaVersionHeader.
+// It returns DefaultSchemaVersion if the message carries no headers or no version header,
+// and an error wrapping ErrInvalidSchemaVersion if the header is not a positive integer.
+func SchemaVersion(msg Message) (int, error) {
+	carrier, ok := msg.(HeaderCarrier)
+	if !ok {
+		return DefaultSchemaVersion, nil
+	}
+	value, ok := carrier.Headers()[SchemaVersionHeader]
+	if !ok {
+		return DefaultSchemaVersion, nil
+	}
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidSchemaVersion, value)
+	}
+	return version, nil
+}
+
+// WithSchemaVersion stamps the schema version of the envelope payload in its
+// SchemaVersionHeader.
+//
+//	err := bus.PublishEnvelope(ctx, pubsub.NewEnvelope("orders", payload, pubsub.WithSchemaVersion(3)))
+func WithSchemaVersion(version int) EnvelopeOption {
+	return WithHeader(SchemaVersionHeader, strconv.Itoa(version))
+}
+
+// MigrationFunc transforms a payload from one schema version to a newer one.
+type MigrationFunc func(data []byte) ([]byte, error)
+
+// migration is a registered step from one schema version to another.
+type migration struct {
+	to int
+	fn MigrationFunc
+}
+
+// Migrator is a consumer-side registry of payload migrations between schema versions.
+// It upgrades payloads of older messages by chaining the registered migrations.
+// A Migrator is safe for concurrent use.
+//
+//	migrator := pubsub.NewMigrator()
+//	migrator.RegisterMigration(1, 2, upgradeV1ToV2)
+//	migrator.RegisterMigration(2, 3, upgradeV2ToV3)
+//	data, err := migrator.UpgradeTo(3, msg)
+type Migrator struct {
+	mu         sync.RWMutex
+	migrations map[int]migration
+}
+
+// NewMigrator creates an empty Migrator.
+func NewMigrator() *Migrator {
+	return &Migrator{migrations: make(map[int]migration)}
+}
+
+// RegisterMigration registers fn as the migration from schema version from to version to.
+// Only one migration can start from a given version. It panics if to is not greater than
+// from, if fn is nil, or if a migration from the same version is already registered,
+// since these are programming errors.
+func (m *Migrator) RegisterMigration(from, to int, fn MigrationFunc) {
+	if to <= from {
+		panic(fmt.Sprintf("pubsub: invalid migration from version %d to %d", from, to))
+	}
+	if fn == nil {
+		panic("pubsub: nil migration function")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, exists := m.migrations[from]; exists {
+		panic(fmt.Sprintf("pubsub: migration from version %d already registered", from))
+	}
+	m.migrations[from] = migration{to: to, fn: fn}
+}
+
+// UpgradeTo returns the payload of msg upgraded to the target schema version by chaining
+// the registered migrations, starting from the version reported by SchemaVersion.
+// The payload is returned unchanged if the message is already at the target version.
+// It returns an error wrapping ErrMigrationNotFound if no chain of migrations leads
+// to the target version, or the error of the first migration that fails.
+func (m *Migrator) UpgradeTo(target int, msg Message) ([]byte, error) {
+	version, err := SchemaVersion(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data := msg.Data()
+	for version < target {
+		step, ok := m.migrations[version]
+		if !ok || step.to > target {
+			return nil, fmt.Errorf("%w: from version %d to %d", ErrMigrationNotFound, version, target)
+		}
+		if data, err = step.fn(data); err != nil {
+			return nil, fmt.Errorf("pubsub: migrate from version %d to %d: %w", version, step.to, err)
+		}
+		version = step.to
+	}
+	if version > target {
+		return nil, fmt.Errorf("%w: from version %d to %d", ErrMigrationNotFound, version, target)
+	}
+	return data, nil
+}
@@ -0,0 +1,102 @@
+package pubsub
+
+import (
+	"errors"
+	"testing"
+)
+
+// withVersion returns a message on the orders topic with the given schema version header.
+func withVersion(version, data string) Message {
+	return headeredMessage{
+		rawMessage: rawMessage{topic: "orders", data: []byte(data)},
+		headers:    map[string]string{SchemaVersionHeader: version},
+	}
+}
+
+func TestSchemaVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		msg     Message
+		want    int
+		wantErr error
+	}{
+		{"no headers", rawMessage{topic: "orders"}, DefaultSchemaVersion, nil},
+		{"no version header", headeredMessage{rawMessage: rawMessage{topic: "orders"}}, DefaultSchemaVersion, nil},
+		{"version header", withVersion("3", ""), 3, nil},
+		{"not a number", withVersion("v3", ""), 0, ErrInvalidSchemaVersion},
+		{"not positive", withVersion("0", ""), 0, ErrInvalidSchemaVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SchemaVersion(tt.msg)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SchemaVersion = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigratorUpgradeTo(t *testing.T) {
+	errMigration := errors.New("bad payload")
+	migrator := NewMigrator()
+	migrator.RegisterMigration(1, 2, func(data []byte) ([]byte, error) { return append(data, "+v2"...), nil })
+	migrator.RegisterMigration(2, 4, func(data []byte) ([]byte, error) { return append(data, "+v4"...), nil })
+	migrator.RegisterMigration(4, 5, func(data []byte) ([]byte, error) { return nil, errMigration })
+
+	tests := []struct {
+		name    string
+		msg     Message
+		target  int
+		want    string
+		wantErr error
+	}{
+		{"already at target", withVersion("2", "p"), 2, "p", nil},
+		{"one step", rawMessage{topic: "orders", data: []byte("p")}, 2, "p+v2", nil},
+		{"chained steps", withVersion("1", "p"), 4, "p+v2+v4", nil},
+		{"target skipped by a step", withVersion("2", "p"), 3, "", ErrMigrationNotFound},
+		{"no migration", withVersion("5", "p"), 6, "", ErrMigrationNotFound},
+		{"newer than target", withVersion("4", "p"), 2, "", ErrMigrationNotFound},
+		{"failing migration", withVersion("4", "p"), 5, "", errMigration},
+		{"invalid version", withVersion("x", "p"), 2, "", ErrInvalidSchemaVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := migrator.UpgradeTo(tt.target, tt.msg)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("UpgradeTo = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigratorRegisterMigrationPanics(t *testing.T) {
+	noop := func(data []byte) ([]byte, error) { return data, nil }
+	tests := []struct {
+		name     string
+		from, to int
+		fn       MigrationFunc
+	}{
+		{"backwards", 2, 1, noop},
+		{"same version", 2, 2, noop},
+		{"nil function", 1, 2, nil},
+		{"duplicate", 1, 3, noop},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrator := NewMigrator()
+			migrator.RegisterMigration(1, 2, noop)
+			defer func() {
+				if recover() == nil {
+					t.Error("RegisterMigration did not panic")
+				}
+			}()
+			migrator.RegisterMigration(tt.from, tt.to, tt.fn)
+		})
+	}
+}
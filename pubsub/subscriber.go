@@ -38,6 +38,12 @@ type Subscriber interface {
 	// The returned channel is receive-only and carries Message objects.
 	// If the operation fails, it returns an error.
 	//
+	// Flow control: the subscriber holds a bounded number of unconsumed messages, in the
+	// channel buffer or an internal queue, which concrete subscribers expose as a receiver
+	// buffer size option (e.g. memory.WithReceiverBufferSize). Once the buffer is full, the
+	// implementation blocks its fetch loop until the consumer drains a message, so a slow
+	// consumer applies backpressure to the broker instead of messages being dropped.
+	//
 	// Example:
 	//     messages, err := subscriber.Receiver(ctx)
 	//     for msg := range messages {
@@ -55,6 +61,17 @@ type Subscriber interface {
 	//     }
 	IsConnected(ctx context.Context) bool
 
+	// CheckConnection performs a lightweight round-trip to the pub-sub system, bounded by the
+	// context deadline, and returns the underlying error if it fails. Unlike IsConnected,
+	// which may report a cached state, it always probes the broker, which makes it suitable
+	// for readiness checks.
+	//
+	// Example:
+	//     if err := subscriber.CheckConnection(ctx); err != nil {
+	//         // report not ready
+	//     }
+	CheckConnection(ctx context.Context) error
+
 	// Close closes the subscriber and releases any resources associated with it.
 	// Returns an error if the operation fails (e.g., already closed).
 	//
@@ -0,0 +1,122 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+)
+
+// TypedPublisher is a Publisher facade bound to the message type T and a Codec, so values
+// are published without handling payload bytes.
+type TypedPublisher[T any] struct {
+	publisher Publisher
+	codec     Codec
+}
+
+// NewTypedPublisher creates a TypedPublisher encoding values of type T with codec before
+// publishing them through p. A nil codec defaults to JSONCodec.
+//
+//	orders := pubsub.NewTypedPublisher[OrderCreated](publisher, nil)
+//	err := orders.Publish(ctx, "orders", OrderCreated{ID: id})
+func NewTypedPublisher[T any](p Publisher, codec Codec) *TypedPublisher[T] {
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+	return &TypedPublisher[T]{publisher: p, codec: codec}
+}
+
+// Publish encodes v and publishes it to topic. If the publisher implements
+// EnvelopePublisher, the content type of the codec is stamped in the ContentTypeHeader of
+// the message.
+func (p *TypedPublisher[T]) Publish(ctx context.Context, topic string, v T) error {
+	data, err := p.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if publisher, ok := p.publisher.(EnvelopePublisher); ok {
+		return publisher.PublishEnvelope(ctx, NewEnvelope(topic, data, WithContentType(p.codec.ContentType())))
+	}
+	return p.publisher.Publish(ctx, topic, data)
+}
+
+// TypedSubscriber is a Subscriber facade bound to the message type T and a Codec, so
+// received messages are delivered as decoded values.
+type TypedSubscriber[T any] struct {
+	subscriber Subscriber
+	codec      Codec
+}
+
+// NewTypedSubscriber creates a TypedSubscriber decoding the messages received from s into
+// values of type T with codec. A nil codec defaults to JSONCodec.
+func NewTypedSubscriber[T any](s Subscriber, codec Codec) *TypedSubscriber[T] {
+	if codec == nil {
+		codec = JSONCodec{}
+	}
+	return &TypedSubscriber[T]{subscriber: s, codec: codec}
+}
+
+// Receive returns a channel of decoded values and a channel of decode errors, each wrapping
+// ErrDecodeFailed. Both channels are unbuffered and closed once ctx is done or the
+// subscriber's receiver channel is closed, so the consumer must read from both, typically
+// in a select loop, until they are closed. It returns an error if the receiver cannot be
+// obtained.
+//
+// Example:
+//
+//	values, errs, err := orders.Receive(ctx)
+//	for values != nil || errs != nil {
+//		select {
+//		case v, ok := <-values:
+//			if !ok {
+//				values = nil
+//				continue
+//			}
+//			// handle v
+//		case err, ok := <-errs:
+//			if !ok {
+//				errs = nil
+//				continue
+//			}
+//			// handle err
+//		}
+//	}
+func (s *TypedSubscriber[T]) Receive(ctx context.Context) (<-chan T, <-chan error, error) {
+	messages, err := s.subscriber.Receiver(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := make(chan T)
+	errs := make(chan error)
+	go func() {
+		defer close(values)
+		defer close(errs)
+		for {
+			var msg Message
+			select {
+			case <-ctx.Done():
+				return
+			case m, ok := <-messages:
+				if !ok {
+					return
+				}
+				msg = m
+			}
+
+			var value T
+			if err := s.codec.Unmarshal(msg.Data(), &value); err != nil {
+				select {
+				case errs <- fmt.Errorf("%w: topic %q: %v", ErrDecodeFailed, msg.Topic(), err):
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+			select {
+			case values <- value:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return values, errs, nil
+}
@@ -0,0 +1,157 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testOrder struct {
+	ID     string `json:"id"`
+	Amount int    `json:"amount"`
+}
+
+func TestTypedPublisher(t *testing.T) {
+	tests := []struct {
+		name    string
+		down    bool
+		value   testOrder
+		want    []string
+		wantErr error
+	}{
+		{"published", false, testOrder{ID: "o1", Amount: 3}, []string{`orders:{"id":"o1","amount":3}`}, nil},
+		{"publish failure", true, testOrder{ID: "o1"}, nil, errBrokerDown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &fakePublisher{down: tt.down}
+			err := NewTypedPublisher[testOrder](p, nil).Publish(context.Background(), "orders", tt.value)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got := p.messages(); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("published = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// envelopePublisher is a fakePublisher implementing EnvelopePublisher, recording the
+// published envelopes.
+type envelopePublisher struct {
+	fakePublisher
+	envelopes []*Envelope
+}
+
+func (p *envelopePublisher) PublishEnvelope(ctx context.Context, msg *Envelope) error {
+	if err := p.Publish(ctx, msg.Topic(), msg.Data()); err != nil {
+		return err
+	}
+	p.envelopes = append(p.envelopes, msg)
+	return nil
+}
+
+func TestTypedPublisherContentType(t *testing.T) {
+	p := &envelopePublisher{}
+	if err := NewTypedPublisher[testOrder](p, nil).Publish(context.Background(), "orders", testOrder{ID: "o1"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.envelopes) != 1 {
+		t.Fatalf("published %d envelopes, want 1", len(p.envelopes))
+	}
+	if got := ContentType(p.envelopes[0]); got != "application/json" {
+		t.Errorf("content type = %q, want application/json", got)
+	}
+	if got := p.messages(); strings.Join(got, "|") != `orders:{"id":"o1","amount":0}` {
+		t.Errorf("published = %v", got)
+	}
+}
+
+func TestTypedPublisherEncodeFailure(t *testing.T) {
+	p := &fakePublisher{}
+	err := NewTypedPublisher[chan int](p, nil).Publish(context.Background(), "orders", make(chan int))
+	if err == nil {
+		t.Fatal("publishing an unencodable value succeeded")
+	}
+	if got := p.messages(); len(got) != 0 {
+		t.Errorf("published = %v, want nothing", got)
+	}
+}
+
+func TestTypedSubscriber(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	s := &chanSubscriber{ch: make(chan Message, 3)}
+	s.ch <- rawMessage{topic: "orders", data: []byte(`{"id":"o1","amount":3}`)}
+	s.ch <- rawMessage{topic: "orders", data: []byte(`not json`)}
+	s.ch <- rawMessage{topic: "orders", data: []byte(`{"id":"o2"}`)}
+	close(s.ch)
+
+	values, errs, err := NewTypedSubscriber[testOrder](s, nil).Receive(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []testOrder
+	var decodeErrs []error
+	for values != nil || errs != nil {
+		select {
+		case v, ok := <-values:
+			if !ok {
+				values = nil
+				continue
+			}
+			got = append(got, v)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			decodeErrs = append(decodeErrs, err)
+		case <-ctx.Done():
+			t.Fatal("channels not closed with the receiver")
+		}
+	}
+
+	want := []testOrder{{ID: "o1", Amount: 3}, {ID: "o2"}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("values = %+v, want %+v", got, want)
+	}
+	if len(decodeErrs) != 1 || !errors.Is(decodeErrs[0], ErrDecodeFailed) {
+		t.Errorf("errors = %v, want one ErrDecodeFailed", decodeErrs)
+	}
+}
+
+func TestTypedSubscriberReceiverFailure(t *testing.T) {
+	s := &chanSubscriber{err: errBrokerDown}
+	if _, _, err := NewTypedSubscriber[testOrder](s, nil).Receive(context.Background()); !errors.Is(err, errBrokerDown) {
+		t.Errorf("err = %v, want %v", err, errBrokerDown)
+	}
+}
+
+func TestTypedSubscriberCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &chanSubscriber{ch: make(chan Message)}
+	values, errs, err := NewTypedSubscriber[testOrder](s, nil).Receive(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	timeout := time.After(time.Second)
+	for values != nil || errs != nil {
+		select {
+		case _, ok := <-values:
+			if !ok {
+				values = nil
+			}
+		case _, ok := <-errs:
+			if !ok {
+				errs = nil
+			}
+		case <-timeout:
+			t.Fatal("channels not closed after the context was canceled")
+		}
+	}
+}